@@ -5,6 +5,9 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+
 	"github.com/runningwild/javelin/machine"
 	"github.com/runningwild/javelin/opcode"
 )
@@ -31,154 +34,762 @@ type OpcodeInstruction interface {
 	Execute(m *machine.Machine)
 }
 
+// asmLexer splits assembly source into tokens.  Rules are tried in order, so registers and
+// keywords must come before Ident.
+var asmLexer = lexer.MustSimple([]lexer.SimpleRule{
+	{Name: "Comment", Pattern: `//[^\n]*`},
+	{Name: "Whitespace", Pattern: `[ \t\r]+`},
+	{Name: "EOL", Pattern: `[\n;]`},
+	{Name: "RegisterGeneral", Pattern: `(?i)\b([xw]([12]?[0-9]|30)|[xw]zr|w?sp)\b`},
+	{Name: "RegisterFP", Pattern: `(?i)\b[bhsdq]([12]?[0-9]|3[01])\b`},
+	{Name: "RegisterNeon", Pattern: `(?i)\bv([12]?[0-9]|3[01])\b`},
+	{Name: "TypeSpecifier", Pattern: `(?i)\.(16b|8b|8h|4h|4s|2s|2d|1d)\b`},
+	{Name: "ElementSpecifier", Pattern: `(?i)\.[bhsd]\b`},
+	{Name: "Shift", Pattern: `(?i)\b(lsl|lsr|asr|ror)\b`},
+	{Name: "Extend", Pattern: `(?i)\b[us]xt[bhwx]\b`},
+	{Name: "Float", Pattern: `[-+]?[0-9]+\.[0-9]+([eE][-+]?[0-9]+)?`},
+	{Name: "Integer", Pattern: `[-+]?(0[xX][0-9a-fA-F]+|[0-9]+)`},
+	{Name: "Ident", Pattern: `[a-zA-Z_.][a-zA-Z0-9_.]*`},
+	{Name: "Punct", Pattern: `[#,\[\]!{}:]`},
+})
+
+var parserOptions = []participle.Option{
+	participle.Lexer(asmLexer),
+	participle.Elide("Comment", "Whitespace"),
+	participle.CaseInsensitive("Ident", "Shift", "Extend"),
+	participle.Union[MnemonicInstruction](
+		&Addx{},
+		&AddWithCarry{},
+		&Ngc{},
+		&Cmp{},
+		&Neg{},
+		&Adr{},
+		&Branch{},
+		&BranchCond{},
+		&CompareBranch{},
+		&TestBranch{},
+		&BranchRegister{},
+		&LoadStorePair{},
+		&LoadStore{},
+		&LoadStoreStructure{},
+		&Mov{},
+		&MoveWide{},
+		&Logical{},
+		&Tst{},
+		&Mvn{},
+		&Bitfield{},
+		&Bfc{},
+		&Shift{},
+		&Extend{},
+		&Extr{},
+		&Multiply{},
+		&TwoSource{},
+		&OneSource{},
+		&VectorUnary{},
+		&VectorBinary{},
+		&VectorNarrow{},
+		&VectorLong{},
+		&Shll{},
+		&VectorPairwiseLong{},
+		&VectorAcrossLanes{},
+		&VectorPermute{},
+		&Ext{},
+		&TableLookup{},
+		&Dup{},
+		&Ins{},
+		&MoveToGeneral{},
+		&VectorLogical{},
+		&VectorCompareZero{},
+		&VectorShift{},
+		&VectorShiftNarrow{},
+		&FPUnary{},
+		&FPBinary{},
+		&FPMulAdd{},
+		&FPCompare{},
+		&Fcsel{},
+		&Fmov{},
+		&ConditionalSelect{},
+		&ConditionalSet{},
+		&ConditionalUnary{},
+		&ConditionalCompare{},
+		&Exception{},
+	),
+	// Forms of the same instruction often share a long prefix, like the registers of ADD (shifted
+	// register) and ADD (extended register), so the parser must be able to backtrack over them.
+	participle.UseLookahead(participle.MaxLookahead),
+}
+
+var (
+	programParser     = participle.MustBuild[AsmProgram](parserOptions...)
+	instructionParser = participle.MustBuild[AsmStatement](parserOptions...)
+)
+
 // AST structures for participle
 
+// AsmProgram is a source file with one instruction per line.
 type AsmProgram struct {
-	Instructions []MnemonicInstruction
+	Statements []*AsmStatement `EOL* (@@ (EOL+ | EOF))*`
 }
 
-type asmInstructions struct {
-	Instructions []AsmInstruction `(@@)*`
+// AsmStatement is an instruction, a label, or a label followed by an instruction.
+type AsmStatement struct {
+	Pos         lexer.Position
+	Label       *string             `( @Ident ":"`
+	Instruction MnemonicInstruction `  @@? | @@ )`
 }
 
-type AsmInstruction struct {
-	Add *Add `@@`
+// labelReference is implemented by instructions that can refer to a label.  Each of them
+// assembles to exactly one instruction, so that every label can be given an address before any
+// of them are resolved.
+type labelReference interface {
+	// resolve looks up the labels that the instruction refers to, given the address of the
+	// instruction itself.
+	resolve(labels map[string]uint64, pc uint64) error
 }
 
-type Add struct {
-	General *AddGeneralSuffix `"add" ( @@ |`
-	Vector  *AddVectorSuffix  `        @@ )`
+// Assemble parses and validates every instruction in src.  The first instruction is at address 0,
+// and a label is the address of the instruction that follows it.
+func Assemble(src string) ([]opcode.Instruction, error) {
+	prog, err := programParser.ParseString("", src)
+	if err != nil {
+		return nil, err
+	}
+	labels := map[string]uint64{}
+	var pc uint64
+	for _, stmt := range prog.Statements {
+		if stmt.Label != nil {
+			if _, ok := labels[*stmt.Label]; ok {
+				return nil, fmt.Errorf("%s: label %q is already defined", stmt.Pos, *stmt.Label)
+			}
+			labels[*stmt.Label] = pc
+		}
+		switch stmt.Instruction.(type) {
+		case nil:
+		case labelReference:
+			pc += 4
+		default:
+			// The instructions are validated again below, so errors are reported in order.
+			if ops, err := stmt.Instruction.Validate(); err == nil {
+				pc += 4 * uint64(len(ops))
+			}
+		}
+	}
+
+	var insts []opcode.Instruction
+	for _, stmt := range prog.Statements {
+		if stmt.Instruction == nil {
+			continue
+		}
+		if ref, ok := stmt.Instruction.(labelReference); ok {
+			if err := ref.resolve(labels, 4*uint64(len(insts))); err != nil {
+				return nil, fmt.Errorf("%s: %w", stmt.Pos, err)
+			}
+		}
+		ops, err := stmt.Instruction.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", stmt.Pos, err)
+		}
+		insts = append(insts, ops...)
+	}
+	return insts, nil
 }
 
-type AddGeneralSuffix struct {
-	Rd        int                 `@RegisterGeneral ","`
-	Rn        int                 `@RegisterGeneral ","`
-	Immediate *AddImmediateSuffix `( @@ |`
-	Shifted   *AddShiftedSuffix   `  @@ |`
-	Extended  *AddExtendedSuffix  `  @@ )`
+// ParseInstruction parses a single instruction without validating its operands.
+func ParseInstruction(asm string) (MnemonicInstruction, error) {
+	stmt, err := instructionParser.ParseString("", asm)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.Instruction == nil {
+		return nil, fmt.Errorf("%q is not an instruction", asm)
+	}
+	return stmt.Instruction, nil
 }
 
-// NEXT: Shifted and Extended cannot share a prefix so we need to split something out
-type AddImmediateSuffix struct {
-	Imm string `"#" @Integer`
+// lookupLabel returns the address of the label called name.
+func lookupLabel(labels map[string]uint64, name string) (uint64, error) {
+	addr, ok := labels[name]
+	if !ok {
+		return 0, fmt.Errorf("label %q is not defined", name)
+	}
+	return addr, nil
 }
 
-type AddShiftedOrExtendedSuffix struct {
-	Rm       int                `@RegisterGeneral`
-	Shifted  *AddShiftedSuffix  `( @@ |`
-	Extended *AddExtendedSuffix `  @@ )`
+// parseImmediate parses a decimal or hexadecimal integer with an optional sign.  Values up to
+// 2^64-1 are accepted and wrap around to negative numbers, so that 64-bit constants can be
+// written in hex.
+func parseImmediate(immstr string) (int64, error) {
+	neg := strings.HasPrefix(immstr, "-")
+	immstr = strings.TrimLeft(immstr, "+-")
+	base := 10
+	if strings.HasPrefix(immstr, "0x") || strings.HasPrefix(immstr, "0X") {
+		immstr = immstr[2:]
+		base = 16
+	}
+	v, err := strconv.ParseUint(immstr, base, 64)
+	if err != nil {
+		return 0, err
+	}
+	if neg {
+		return -int64(v), nil
+	}
+	return int64(v), nil
 }
 
-type AddShiftedSuffix struct {
-	Dir      *string `(@Shift`
-	ShiftAmt *int    `"#" @Integer)?`
+// Immediate is an integer operand.
+type Immediate int64
+
+func (i *Immediate) Capture(values []string) error {
+	v, err := parseImmediate(values[0])
+	*i = Immediate(v)
+	return err
 }
 
-type AddExtendedSuffix struct {
-	Extend   *string `(@(Extend|"lsl")`
-	ShiftAmt *int    `("#" @Integer)?)?`
+// GeneralRegister is a general purpose register operand.  Register 31 is either the zero register
+// or the stack pointer, depending on which was named.
+type GeneralRegister struct {
+	Sf uint32 // 1 for an x register, 0 for a w register
+	N  uint32 // 5 bits
+	SP bool
 }
 
-type AddVectorSuffix struct {
-	Vd RegisterNeon `@@ ","`
-	Vn RegisterNeon `@@ ","`
-	Vm RegisterNeon `@@`
+func (r *GeneralRegister) Capture(values []string) error {
+	name := strings.ToLower(values[0])
+	*r = GeneralRegister{}
+	switch name {
+	case "sp", "wsp":
+		r.N, r.SP = 31, true
+	case "xzr", "wzr":
+		r.N = 31
+	default:
+		n, err := strconv.Atoi(name[1:])
+		if err != nil {
+			return err
+		}
+		r.N = uint32(n)
+	}
+	if name[0] != 'w' {
+		r.Sf = 1
+	}
+	return nil
 }
 
-func (a *Add) Validate() ([]opcode.Instruction, error) {
+func (r GeneralRegister) String() string {
+	prefix := "w"
+	if r.Sf == 1 {
+		prefix = "x"
+	}
 	switch {
-	case a.General != nil:
-	case a.Vector != nil:
-		// TODO return opcode.AddVector
+	case r.SP && r.Sf == 1:
+		return "sp"
+	case r.SP:
+		return "wsp"
+	case r.N == 31:
+		return prefix + "zr"
 	}
-	return nil, nil
+	return fmt.Sprintf("%s%d", prefix, r.N)
 }
-func parseImmediate(immstr string) (int64, error) {
-	base := 10
-	if strings.HasPrefix(immstr, "0x") {
-		immstr = immstr[2:]
-		base = 16
+
+// zr returns the number of r for an operand in which register 31 is the zero register.
+func (r GeneralRegister) zr() (uint32, error) {
+	if r.SP {
+		return 0, fmt.Errorf("%v cannot be used here", r)
+	}
+	return r.N, nil
+}
+
+// sp returns the number of r for an operand in which register 31 is the stack pointer.
+func (r GeneralRegister) sp() (uint32, error) {
+	if r.N == 31 && !r.SP {
+		return 0, fmt.Errorf("%v cannot be used here", r)
+	}
+	return r.N, nil
+}
+
+// generalOperands checks that regs all have the same width and that none of them is the stack
+// pointer, and returns their width and register numbers.
+func generalOperands(regs ...GeneralRegister) (uint32, []uint32, error) {
+	n := make([]uint32, len(regs))
+	for i, r := range regs {
+		var err error
+		if n[i], err = r.zr(); err != nil {
+			return 0, nil, err
+		}
+		if r.Sf != regs[0].Sf {
+			return 0, nil, fmt.Errorf("%v and %v are not the same width", regs[0], r)
+		}
+	}
+	return regs[0].Sf, n, nil
+}
+
+// FPRegister is a scalar SIMD&FP register operand such as b0, h1, s2, d3 or q4.
+type FPRegister struct {
+	Size uint32 // log2 of the number of bytes in the register
+	N    uint32 // 5 bits
+}
+
+func (r *FPRegister) Capture(values []string) error {
+	name := strings.ToLower(values[0])
+	n, err := strconv.Atoi(name[1:])
+	if err != nil {
+		return err
+	}
+	*r = FPRegister{Size: uint32(strings.IndexByte("bhsdq", name[0])), N: uint32(n)}
+	return nil
+}
+
+func (r FPRegister) String() string {
+	return fmt.Sprintf("%c%d", "bhsdq"[r.Size], r.N)
+}
+
+// arrangements maps each arrangement specifier to its Q and size fields.
+var arrangements = map[string][2]uint32{
+	".8b":  {0, 0b00},
+	".16b": {1, 0b00},
+	".4h":  {0, 0b01},
+	".8h":  {1, 0b01},
+	".2s":  {0, 0b10},
+	".4s":  {1, 0b10},
+	".1d":  {0, 0b11},
+	".2d":  {1, 0b11},
+}
+
+// RegisterNeon is a SIMD&FP register operand with an arrangement specifier, such as v0.4s.
+type RegisterNeon struct {
+	N    uint32 // 5 bits
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+}
+
+func (r *RegisterNeon) Capture(values []string) error {
+	if len(values) != 2 {
+		return fmt.Errorf("%s needs an arrangement specifier, like %s.4s", values[0], values[0])
+	}
+	n, err := strconv.Atoi(values[0][1:])
+	if err != nil {
+		return err
+	}
+	a := arrangements[strings.ToLower(values[1])]
+	*r = RegisterNeon{N: uint32(n), Q: a[0], Size: a[1]}
+	return nil
+}
+
+func (r RegisterNeon) String() string {
+	for name, a := range arrangements {
+		if a == [2]uint32{r.Q, r.Size} {
+			return fmt.Sprintf("v%d%s", r.N, name)
+		}
+	}
+	return fmt.Sprintf("v%d", r.N)
+}
+
+// VectorElement is a SIMD&FP register operand with an element size but no number of elements,
+// such as v0.s, which is used to refer to a single lane.
+type VectorElement struct {
+	N    uint32 // 5 bits
+	Size uint32 // 2 bits
+}
+
+func (r *VectorElement) Capture(values []string) error {
+	if len(values) != 2 {
+		return fmt.Errorf("%s needs an element size, like %s.s", values[0], values[0])
+	}
+	n, err := strconv.Atoi(values[0][1:])
+	if err != nil {
+		return err
+	}
+	*r = VectorElement{N: uint32(n), Size: uint32(strings.IndexByte("bhsd", strings.ToLower(values[1])[1]))}
+	return nil
+}
+
+func (r VectorElement) String() string {
+	return fmt.Sprintf("v%d.%c", r.N, "bhsd"[r.Size])
+}
+
+// VectorLane is a single lane of a SIMD&FP register, such as v0.s[2].
+type VectorLane struct {
+	Element VectorElement `@(RegisterNeon ElementSpecifier)`
+	Index   Immediate     `"[" @Integer "]"`
+}
+
+func (l VectorLane) String() string {
+	return fmt.Sprintf("%v[%d]", l.Element, l.Index)
+}
+
+// imm5 returns the imm5 field that selects the lane in the Advanced SIMD copy instructions, whose
+// lowest set bit gives the element size and whose higher bits give the index.
+func (l VectorLane) imm5() (uint32, error) {
+	size := l.Element.Size
+	if l.Index < 0 || l.Index >= 16>>size {
+		return 0, fmt.Errorf("lane %d of %v is out of range [0, %d]", l.Index, l.Element, 16>>size-1)
+	}
+	return uint32(l.Index)<<(size+1) | 1<<size, nil
+}
+
+// VectorList is a list of SIMD&FP register operands with arrangement specifiers, such as
+// { v0.4s, v1.4s }.  Each register captured for the list is appended to it.
+type VectorList []RegisterNeon
+
+func (l *VectorList) Capture(values []string) error {
+	var r RegisterNeon
+	if err := r.Capture(values); err != nil {
+		return err
 	}
-	return strconv.ParseInt(immstr, base, 64)
+	*l = append(*l, r)
+	return nil
 }
 
+// ElementList is a list of SIMD&FP register operands with element sizes, such as { v0.s, v1.s }.
+// Each register captured for the list is appended to it.
+type ElementList []VectorElement
+
+func (l *ElementList) Capture(values []string) error {
+	var r VectorElement
+	if err := r.Capture(values); err != nil {
+		return err
+	}
+	*l = append(*l, r)
+	return nil
+}
+
+// sameArrangement checks that regs all have the same arrangement.
+func sameArrangement(regs ...RegisterNeon) error {
+	for _, r := range regs[1:] {
+		if r.Q != regs[0].Q || r.Size != regs[0].Size {
+			return fmt.Errorf("%v and %v do not have the same arrangement", regs[0], r)
+		}
+	}
+	return nil
+}
+
+// Addx is ADD, ADDS, SUB or SUBS in any of their forms.
 type Addx struct {
-	AddShiftedRegister  *AddShiftedRegister  `"add" (@@ |`
-	AddImmediate        *AddImmediate        `       @@ |`
-	AddExtendedRegister *AddExtendedRegister `       @@ |`
-	AddVector           *AddVector           `       @@ )`
+	Mnemonic            string               `@("add" | "adds" | "sub" | "subs")`
+	AddExtendedRegister *AddExtendedRegister `( @@`
+	AddShiftedRegister  *AddShiftedRegister  `| @@`
+	AddImmediate        *AddImmediate        `| @@`
+	AddVector           *AddVector           `| @@ )`
+}
+
+func (i *Addx) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	switch {
+	case i.AddExtendedRegister != nil:
+		return i.AddExtendedRegister.validate(mnemonic)
+	case i.AddShiftedRegister != nil:
+		return i.AddShiftedRegister.validate(mnemonic)
+	case i.AddImmediate != nil:
+		return i.AddImmediate.validate(mnemonic)
+	default:
+		return i.AddVector.validate(mnemonic)
+	}
+}
+
+func (i *Addx) resolve(labels map[string]uint64, pc uint64) error {
+	if i.AddImmediate != nil {
+		return i.AddImmediate.resolve(labels, pc)
+	}
+	return nil
+}
+
+// addSubRd returns the number of the destination register of mnemonic, which is the stack
+// pointer for ADD and SUB and the zero register for ADDS and SUBS, which set the flags.
+func addSubRd(mnemonic string, rd GeneralRegister) (uint32, error) {
+	if strings.HasSuffix(mnemonic, "s") {
+		return rd.zr()
+	}
+	return rd.sp()
 }
 
+// AddImmediate is the immediate form of ADD, ADDS, SUB or SUBS.  The immediate can be written as
+// :lo12:label, which is the low 12 bits of the address of the label, to complete an address whose
+// page was found with ADRP.  A negative immediate is assembled by swapping addition and
+// subtraction.
 type AddImmediate struct {
-	Rd  int    `@RegisterGeneral ","`
-	Rn  int    `@RegisterGeneral ","`
-	Imm string `"#" @Integer`
+	Rd    GeneralRegister `@RegisterGeneral ","`
+	Rn    GeneralRegister `@RegisterGeneral ","`
+	Imm   Immediate       `( "#"? @Integer`
+	Lo12  *string         `| ":" "lo12" ":" @Ident )`
+	Shift *Immediate      `("," "lsl" "#"? @Integer)?`
 }
 
-func (i *AddImmediate) Validate() ([]opcode.Instruction, error) {
-	immStr := i.Imm
-	base := 10
-	if strings.HasPrefix(immStr, "0x") {
-		immStr = immStr[2:]
-		base = 16
+func (i *AddImmediate) resolve(labels map[string]uint64, pc uint64) error {
+	if i.Lo12 == nil {
+		return nil
+	}
+	if i.Shift != nil {
+		return fmt.Errorf(":lo12:%s cannot be shifted", *i.Lo12)
 	}
-	imm, err := strconv.ParseInt(immStr, base, 64)
+	addr, err := lookupLabel(labels, *i.Lo12)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse immediate: %w", err)
+		return err
 	}
-	var op opcode.AddImmedite
-	op.Rd = uint32(i.Rd)
-	op.Rn = uint32(i.Rn)
+	i.Imm = Immediate(addr & 0xfff)
+	return nil
+}
+
+func (i *AddImmediate) validate(mnemonic string) ([]opcode.Instruction, error) {
+	rd, err := addSubRd(mnemonic, i.Rd)
+	if err != nil {
+		return nil, err
+	}
+	rn, err := i.Rn.sp()
+	if err != nil {
+		return nil, err
+	}
+	if i.Rd.Sf != i.Rn.Sf {
+		return nil, fmt.Errorf("%v and %v are not the same width", i.Rd, i.Rn)
+	}
+	sf := i.Rd.Sf
 
-	if imm&0xfff == imm {
-		op.Imm = uint32(imm)
-		op.Sh = 0
-		return []opcode.Instruction{&op}, nil
+	imm := int64(i.Imm)
+	if i.Shift != nil {
+		if *i.Shift != 0 && *i.Shift != 12 {
+			return nil, fmt.Errorf("immediate can only be shifted left by 0 or 12, not %d", *i.Shift)
+		}
+		imm <<= *i.Shift
 	}
-	if (imm>>12)&0xfff == imm {
-		op.Imm = uint32(imm >> 12)
-		op.Sh = 1
-		return []opcode.Instruction{&op}, nil
+	if imm < 0 {
+		imm = -imm
+		mnemonic = map[string]string{"add": "sub", "adds": "subs", "sub": "add", "subs": "adds"}[mnemonic]
+	}
+	var sh uint32
+	switch {
+	case imm&0xfff == imm:
+	case (imm>>12)&0xfff == imm>>12 && imm&0xfff == 0:
+		imm >>= 12
+		sh = 1
+	default:
+		return nil, fmt.Errorf("immediate %d cannot be represented as a 12-bit value with an optional 12-bit left shift", imm)
 	}
 
-	return nil, fmt.Errorf("immediate %d cannot be represented as a 12-bit value with an optional 12-bit left shift", imm)
+	switch mnemonic {
+	case "add":
+		return []opcode.Instruction{&opcode.AddImmedite{Sf: sf, Sh: sh, Imm: uint32(imm), Rn: rn, Rd: rd}}, nil
+	case "adds":
+		return []opcode.Instruction{&opcode.AddsImmediate{Sf: sf, Sh: sh, Imm: uint32(imm), Rn: rn, Rd: rd}}, nil
+	case "sub":
+		return []opcode.Instruction{&opcode.SubImmediate{Sf: sf, Sh: sh, Imm: uint32(imm), Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.SubsImmediate{Sf: sf, Sh: sh, Imm: uint32(imm), Rn: rn, Rd: rd}}, nil
 }
 
+// AddShiftedRegister is the shifted register form of ADD, ADDS, SUB or SUBS.
 type AddShiftedRegister struct {
-	Rd  int     `@RegisterGeneral  ","`
-	Rn  int     `@RegisterGeneral  ","`
-	Rm  int     `@RegisterGeneral (","`
-	Dir *string `  @Shift`
-	Amt *int    `  @Integer)?`
+	Rd  GeneralRegister `@RegisterGeneral  ","`
+	Rn  GeneralRegister `@RegisterGeneral  ","`
+	Rm  GeneralRegister `@RegisterGeneral (","`
+	Dir *string         `  @Shift`
+	Amt *Immediate      `  "#"? @Integer)?`
 }
 
-func (i *AddShiftedRegister) Validate() ([]opcode.Instruction, error) {
-	return nil, nil
+func (i *AddShiftedRegister) validate(mnemonic string) ([]opcode.Instruction, error) {
+	// The shifted register form cannot use the stack pointer, so an unshifted or slightly shifted
+	// Rm is added to it with the extended register form instead.
+	if (i.Rd.SP || i.Rn.SP) && (i.Dir == nil || strings.EqualFold(*i.Dir, "lsl")) {
+		ext := AddExtendedRegister{Rd: i.Rd, Rn: i.Rn, Rm: i.Rm, Extend: "uxtw", Amt: i.Amt}
+		if i.Rd.Sf == 1 {
+			ext.Extend = "uxtx"
+		}
+		return ext.validate(mnemonic)
+	}
+	sf, n, err := generalOperands(i.Rd, i.Rn, i.Rm)
+	if err != nil {
+		return nil, err
+	}
+	op, err := shiftedRegisterOperand(sf, i.Dir, i.Amt, false)
+	if err != nil {
+		return nil, err
+	}
+	switch mnemonic {
+	case "add":
+		return []opcode.Instruction{&opcode.AddShiftedRegister{Sf: sf, Shift: op.shift, Rm: n[2], Imm: op.amount, Rn: n[1], Rd: n[0]}}, nil
+	case "adds":
+		return []opcode.Instruction{&opcode.AddsShiftedRegister{Sf: sf, Shift: op.shift, Rm: n[2], Imm: op.amount, Rn: n[1], Rd: n[0]}}, nil
+	case "sub":
+		return []opcode.Instruction{&opcode.SubShiftedRegister{Sf: sf, Shift: op.shift, Rm: n[2], Imm: op.amount, Rn: n[1], Rd: n[0]}}, nil
+	}
+	return []opcode.Instruction{&opcode.SubsShiftedRegister{Sf: sf, Shift: op.shift, Rm: n[2], Imm: op.amount, Rn: n[1], Rd: n[0]}}, nil
+}
+
+// shiftOperand is the shift applied to the last register of a shifted register instruction.
+type shiftOperand struct {
+	shift  uint32 // 2 bits
+	amount uint32 // 6 bits
 }
 
+// shiftedRegisterOperand validates the optional shift of a shifted register instruction.  Only
+// logical instructions can use ROR.
+func shiftedRegisterOperand(sf uint32, dir *string, amt *Immediate, ror bool) (shiftOperand, error) {
+	if dir == nil {
+		return shiftOperand{}, nil
+	}
+	if strings.EqualFold(*dir, "ror") && !ror {
+		return shiftOperand{}, fmt.Errorf("ror cannot be used here")
+	}
+	shift := uint32(strings.Index("lsl lsr asr ror", strings.ToLower(*dir)) / 4)
+	if *amt < 0 || int64(*amt) >= 32<<sf {
+		return shiftOperand{}, fmt.Errorf("shift amount %d is out of range [0, %d]", *amt, 32<<sf-1)
+	}
+	return shiftOperand{shift: shift, amount: uint32(*amt)}, nil
+}
+
+// AddExtendedRegister is the extended register form of ADD, ADDS, SUB or SUBS.
 type AddExtendedRegister struct {
-	Rd     int     `@RegisterGeneral  ","`
-	Rn     int     `@RegisterGeneral  ","`
-	Rm     int     `@RegisterGeneral (","`
-	Extend *string `  @(Extend|"lsl")`
-	Amt    *int    `  @Integer?)?`
+	Rd     GeneralRegister `@RegisterGeneral  ","`
+	Rn     GeneralRegister `@RegisterGeneral  ","`
+	Rm     GeneralRegister `@RegisterGeneral ","`
+	Extend string          `@Extend`
+	Amt    *Immediate      `("#"? @Integer)?`
 }
 
-func (i *AddExtendedRegister) Validate() ([]opcode.Instruction, error) {
-	return nil, nil
+func (i *AddExtendedRegister) validate(mnemonic string) ([]opcode.Instruction, error) {
+	rd, err := addSubRd(mnemonic, i.Rd)
+	if err != nil {
+		return nil, err
+	}
+	rn, err := i.Rn.sp()
+	if err != nil {
+		return nil, err
+	}
+	rm, err := i.Rm.zr()
+	if err != nil {
+		return nil, err
+	}
+	if i.Rd.Sf != i.Rn.Sf {
+		return nil, fmt.Errorf("%v and %v are not the same width", i.Rd, i.Rn)
+	}
+	sf := i.Rd.Sf
+
+	opt := byte(strings.Index("uxtb uxth uxtw uxtx sxtb sxth sxtw sxtx", strings.ToLower(i.Extend)) / 5)
+	// Only UXTX and SXTX extend a 64-bit register.
+	want := uint32(0)
+	if sf == 1 && opt&0b011 == 0b011 {
+		want = 1
+	}
+	if i.Rm.Sf != want {
+		return nil, fmt.Errorf("%v cannot be extended with %s", i.Rm, i.Extend)
+	}
+	var imm uint32
+	if i.Amt != nil {
+		if *i.Amt < 0 || *i.Amt > 4 {
+			return nil, fmt.Errorf("extend shift amount %d is out of range [0, 4]", *i.Amt)
+		}
+		imm = uint32(*i.Amt)
+	}
+	switch mnemonic {
+	case "add":
+		return []opcode.Instruction{&opcode.AddExtendedRegister{Sf: sf, Opt: opt, Imm: imm, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "adds":
+		return []opcode.Instruction{&opcode.AddsExtendedRegister{Sf: sf, Opt: opt, Imm: imm, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "sub":
+		return []opcode.Instruction{&opcode.SubExtendedRegister{Sf: sf, Opt: opt, Imm: imm, Rm: rm, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.SubsExtendedRegister{Sf: sf, Opt: opt, Imm: imm, Rm: rm, Rn: rn, Rd: rd}}, nil
 }
 
+// AddVector is the vector form of ADD.  The vector form of SUB is assembled as a VectorBinary.
 type AddVector struct {
-	Vd RegisterNeon `@@ ","`
-	Vn RegisterNeon `@@ ","`
-	Vm RegisterNeon `@@`
+	Vd RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vn RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vm RegisterNeon `@(RegisterNeon TypeSpecifier)`
 }
 
-func (i *AddVector) Validate() ([]opcode.Instruction, error) {
-	return nil, nil
+func (i *AddVector) validate(mnemonic string) ([]opcode.Instruction, error) {
+	switch mnemonic {
+	case "sub":
+		return (&VectorBinary{Mnemonic: mnemonic, Vd: i.Vd, Vn: i.Vn, Vm: i.Vm}).Validate()
+	case "adds", "subs":
+		return nil, fmt.Errorf("%s has no vector form", mnemonic)
+	}
+	if err := sameArrangement(i.Vd, i.Vn, i.Vm); err != nil {
+		return nil, err
+	}
+	if i.Vd.Q == 0 && i.Vd.Size == 0b11 {
+		return nil, fmt.Errorf("%v is not a valid arrangement for add", i.Vd)
+	}
+	return []opcode.Instruction{&opcode.AddVector{Q: i.Vd.Q, Size: i.Vd.Size, Rm: i.Vm.N, Rn: i.Vn.N, Rd: i.Vd.N}}, nil
 }
 
-type RegisterNeon struct {
-	V int    `@RegisterNeon`
-	T string `@TypeSpecifier`
+// Cmp is SUBS or ADDS with the result discarded, as CMP or CMN.  A register operand is either
+// shifted or extended, depending on how it is written.
+type Cmp struct {
+	Mnemonic string           `@("cmp" | "cmn")`
+	Rn       GeneralRegister  `@RegisterGeneral ","`
+	Imm      *Immediate       `( "#"? @Integer`
+	Shift    *Immediate       `  ("," "lsl" "#"? @Integer)?`
+	Rm       *GeneralRegister `| @RegisterGeneral`
+	Dir      *string          `  ("," (@Shift | @Extend)`
+	Amt      *Immediate       `   ("#"? @Integer)?)? )`
+}
+
+func (i *Cmp) Validate() ([]opcode.Instruction, error) {
+	zr := GeneralRegister{Sf: i.Rn.Sf, N: 31}
+	inst := Addx{Mnemonic: "subs"}
+	if strings.EqualFold(i.Mnemonic, "cmn") {
+		inst.Mnemonic = "adds"
+	}
+	switch {
+	case i.Imm != nil:
+		inst.AddImmediate = &AddImmediate{Rd: zr, Rn: i.Rn, Imm: *i.Imm, Shift: i.Shift}
+	case i.Dir != nil && strings.Contains(strings.ToLower(*i.Dir), "xt"):
+		inst.AddExtendedRegister = &AddExtendedRegister{Rd: zr, Rn: i.Rn, Rm: *i.Rm, Extend: *i.Dir, Amt: i.Amt}
+	case i.Dir != nil && i.Amt == nil:
+		return nil, fmt.Errorf("%s needs a shift amount", strings.ToLower(*i.Dir))
+	default:
+		inst.AddShiftedRegister = &AddShiftedRegister{Rd: zr, Rn: i.Rn, Rm: *i.Rm, Dir: i.Dir, Amt: i.Amt}
+	}
+	return inst.Validate()
+}
+
+// Neg is SUB or SUBS from the zero register, as NEG or NEGS.
+type Neg struct {
+	Mnemonic string          `@("neg" | "negs")`
+	Rd       GeneralRegister `@RegisterGeneral ","`
+	Rm       GeneralRegister `@RegisterGeneral`
+	Dir      *string         `("," @Shift`
+	Amt      *Immediate      ` "#"? @Integer)?`
+}
+
+func (i *Neg) Validate() ([]opcode.Instruction, error) {
+	zr := GeneralRegister{Sf: i.Rd.Sf, N: 31}
+	shifted := &AddShiftedRegister{Rd: i.Rd, Rn: zr, Rm: i.Rm, Dir: i.Dir, Amt: i.Amt}
+	return (&Addx{Mnemonic: "sub" + strings.ToLower(i.Mnemonic)[3:], AddShiftedRegister: shifted}).Validate()
+}
+
+// AddWithCarry is ADC, ADCS, SBC or SBCS.
+type AddWithCarry struct {
+	Mnemonic string          `@("adc" | "adcs" | "sbc" | "sbcs")`
+	Rd       GeneralRegister `@RegisterGeneral ","`
+	Rn       GeneralRegister `@RegisterGeneral ","`
+	Rm       GeneralRegister `@RegisterGeneral`
+}
+
+func (i *AddWithCarry) Validate() ([]opcode.Instruction, error) {
+	sf, regs, err := generalOperands(i.Rd, i.Rn, i.Rm)
+	if err != nil {
+		return nil, err
+	}
+	rd, rn, rm := regs[0], regs[1], regs[2]
+	switch strings.ToLower(i.Mnemonic) {
+	case "adc":
+		return []opcode.Instruction{&opcode.Adc{Sf: sf, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "adcs":
+		return []opcode.Instruction{&opcode.Adcs{Sf: sf, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "sbc":
+		return []opcode.Instruction{&opcode.Sbc{Sf: sf, Rm: rm, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.Sbcs{Sf: sf, Rm: rm, Rn: rn, Rd: rd}}, nil
+}
+
+// Ngc is SBC or SBCS from the zero register.
+type Ngc struct {
+	Mnemonic string          `@("ngc" | "ngcs")`
+	Rd       GeneralRegister `@RegisterGeneral ","`
+	Rm       GeneralRegister `@RegisterGeneral`
+}
+
+func (i *Ngc) Validate() ([]opcode.Instruction, error) {
+	zr := GeneralRegister{Sf: i.Rd.Sf, N: 31}
+	return (&AddWithCarry{Mnemonic: "sb" + strings.ToLower(i.Mnemonic)[2:], Rd: i.Rd, Rn: zr, Rm: i.Rm}).Validate()
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// VectorAcrossLanes is a reduction of all the elements of Vn into the scalar register Rd: ADDV,
+// SMAXV, UMAXV, SMINV and UMINV keep the element size, SADDLV and UADDLV double it, and FMAXNMV
+// and FMINNMV reduce single precision elements.
+type VectorAcrossLanes struct {
+	Mnemonic string       `@("addv" | "saddlv" | "uaddlv" | "smaxv" | "umaxv" | "sminv" | "uminv" | "fmaxnmv" | "fminnmv")`
+	Rd       FPRegister   `@RegisterFP ","`
+	Vn       RegisterNeon `@(RegisterNeon TypeSpecifier)`
+}
+
+func (i *VectorAcrossLanes) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	q, size, rn, rd := i.Vn.Q, i.Vn.Size, i.Vn.N, i.Rd.N
+	switch mnemonic {
+	case "fmaxnmv", "fminnmv":
+		if q != 1 || size != 0b10 {
+			return nil, fmt.Errorf("%v is not a valid arrangement for %s", i.Vn, mnemonic)
+		}
+		if i.Rd.Size != 0b10 {
+			return nil, fmt.Errorf("%s of %v needs an s register, not %v", mnemonic, i.Vn, i.Rd)
+		}
+		if mnemonic == "fmaxnmv" {
+			return []opcode.Instruction{&opcode.Fmaxnmv{Q: q, Rn: rn, Rd: rd}}, nil
+		}
+		return []opcode.Instruction{&opcode.Fminnmv{Q: q, Rn: rn, Rd: rd}}, nil
+	}
+
+	if size == 0b11 || (q == 0 && size == 0b10) {
+		return nil, fmt.Errorf("%v is not a valid arrangement for %s", i.Vn, mnemonic)
+	}
+	want := FPRegister{Size: size, N: rd}
+	if mnemonic == "saddlv" || mnemonic == "uaddlv" {
+		want.Size++
+	}
+	if i.Rd.Size != want.Size {
+		return nil, fmt.Errorf("%s of %v needs %v, not %v", mnemonic, i.Vn, want, i.Rd)
+	}
+	switch mnemonic {
+	case "addv":
+		return []opcode.Instruction{&opcode.Addv{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "saddlv":
+		return []opcode.Instruction{&opcode.Saddlv{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "uaddlv":
+		return []opcode.Instruction{&opcode.Uaddlv{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "smaxv":
+		return []opcode.Instruction{&opcode.Smaxv{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "umaxv":
+		return []opcode.Instruction{&opcode.Umaxv{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "sminv":
+		return []opcode.Instruction{&opcode.Sminv{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.Uminv{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// bitfield returns SBFM, BFM or UBFM, selected by opc.
+func bitfield(opc, sf, immr, imms, rn, rd uint32) opcode.Instruction {
+	switch opc {
+	case 0b00:
+		return &opcode.Sbfm{Sf: sf, N: sf, Immr: immr, Imms: imms, Rn: rn, Rd: rd}
+	case 0b01:
+		return &opcode.Bfm{Sf: sf, N: sf, Immr: immr, Imms: imms, Rn: rn, Rd: rd}
+	}
+	return &opcode.Ubfm{Sf: sf, N: sf, Immr: immr, Imms: imms, Rn: rn, Rd: rd}
+}
+
+// bitfieldRange checks that a field of width bits starting at lsb fits in a datasize bit register.
+func bitfieldRange(lsb, width Immediate, datasize uint32) error {
+	if lsb < 0 || lsb >= Immediate(datasize) {
+		return fmt.Errorf("lsb %d is out of range [0, %d]", lsb, datasize-1)
+	}
+	if width < 1 || width > Immediate(datasize)-lsb {
+		return fmt.Errorf("width %d is out of range [1, %d]", width, Immediate(datasize)-lsb)
+	}
+	return nil
+}
+
+// Bitfield is SBFM, BFM or UBFM, or one of their aliases that extract a field from the source
+// register (SBFX, BFXIL and UBFX) or insert the bottom of it into a field (SBFIZ, BFI and UBFIZ).
+type Bitfield struct {
+	Mnemonic string          `@("sbfm" | "bfm" | "ubfm" | "sbfx" | "bfxil" | "ubfx" | "sbfiz" | "bfi" | "ubfiz")`
+	Rd       GeneralRegister `@RegisterGeneral ","`
+	Rn       GeneralRegister `@RegisterGeneral ","`
+	A        Immediate       `"#"? @Integer ","`
+	B        Immediate       `"#"? @Integer`
+}
+
+func (i *Bitfield) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	sf, regs, err := generalOperands(i.Rd, i.Rn)
+	if err != nil {
+		return nil, err
+	}
+	datasize := uint32(32) << sf
+	// The first letter of the mnemonic selects SBFM, BFM or UBFM.
+	opc := uint32(strings.IndexByte("sbu", mnemonic[0]))
+	var immr, imms uint32
+	switch mnemonic {
+	case "sbfm", "bfm", "ubfm":
+		for _, imm := range []Immediate{i.A, i.B} {
+			if imm < 0 || imm >= Immediate(datasize) {
+				return nil, fmt.Errorf("immediate %d is out of range [0, %d]", imm, datasize-1)
+			}
+		}
+		immr, imms = uint32(i.A), uint32(i.B)
+	case "sbfx", "bfxil", "ubfx":
+		if err := bitfieldRange(i.A, i.B, datasize); err != nil {
+			return nil, err
+		}
+		immr, imms = uint32(i.A), uint32(i.A+i.B-1)
+	default:
+		if err := bitfieldRange(i.A, i.B, datasize); err != nil {
+			return nil, err
+		}
+		immr, imms = -uint32(i.A)&(datasize-1), uint32(i.B-1)
+	}
+	return []opcode.Instruction{bitfield(opc, sf, immr, imms, regs[1], regs[0])}, nil
+}
+
+// Bfc is BFM from the zero register, clearing a field of the destination.
+type Bfc struct {
+	Rd    GeneralRegister `"bfc" @RegisterGeneral ","`
+	Lsb   Immediate       `"#"? @Integer ","`
+	Width Immediate       `"#"? @Integer`
+}
+
+func (i *Bfc) Validate() ([]opcode.Instruction, error) {
+	zr := GeneralRegister{Sf: i.Rd.Sf, N: 31}
+	return (&Bitfield{Mnemonic: "bfi", Rd: i.Rd, Rn: zr, A: i.Lsb, B: i.Width}).Validate()
+}
+
+// Shift is LSL, LSR, ASR or ROR, which are aliases of UBFM, SBFM and EXTR when shifting by a
+// constant and of LSLV, LSRV, ASRV and RORV when shifting by a register.
+type Shift struct {
+	Mnemonic string           `@Shift`
+	Rd       GeneralRegister  `@RegisterGeneral ","`
+	Rn       GeneralRegister  `@RegisterGeneral ","`
+	Amount   *Immediate       `( "#"? @Integer`
+	Rm       *GeneralRegister `| @RegisterGeneral )`
+}
+
+func (i *Shift) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	if i.Rm != nil {
+		return (&TwoSource{Mnemonic: mnemonic + "v", Rd: i.Rd, Rn: i.Rn, Rm: *i.Rm}).Validate()
+	}
+	sf, regs, err := generalOperands(i.Rd, i.Rn)
+	if err != nil {
+		return nil, err
+	}
+	datasize := uint32(32) << sf
+	if *i.Amount < 0 || *i.Amount >= Immediate(datasize) {
+		return nil, fmt.Errorf("shift amount %d is out of range [0, %d]", *i.Amount, datasize-1)
+	}
+	amount, rn, rd := uint32(*i.Amount), regs[1], regs[0]
+	switch mnemonic {
+	case "lsl":
+		return []opcode.Instruction{bitfield(0b10, sf, -amount&(datasize-1), datasize-1-amount, rn, rd)}, nil
+	case "lsr":
+		return []opcode.Instruction{bitfield(0b10, sf, amount, datasize-1, rn, rd)}, nil
+	case "asr":
+		return []opcode.Instruction{bitfield(0b00, sf, amount, datasize-1, rn, rd)}, nil
+	}
+	return []opcode.Instruction{&opcode.Extr{Sf: sf, N: sf, Rm: rn, Imms: amount, Rn: rn, Rd: rd}}, nil
+}
+
+// Extend is SXTB, SXTH, SXTW, UXTB or UXTH, which extend the bottom of a w register and are aliases
+// of SBFM and UBFM.
+type Extend struct {
+	Mnemonic string          `@Extend`
+	Rd       GeneralRegister `@RegisterGeneral ","`
+	Rn       GeneralRegister `@RegisterGeneral`
+}
+
+func (i *Extend) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	rd, err := i.Rd.zr()
+	if err != nil {
+		return nil, err
+	}
+	rn, err := i.Rn.zr()
+	if err != nil {
+		return nil, err
+	}
+	if i.Rn.Sf != 0 {
+		return nil, fmt.Errorf("%s extends a w register, not %v", mnemonic, i.Rn)
+	}
+	sf := i.Rd.Sf
+	switch mnemonic {
+	case "sxtb":
+		return []opcode.Instruction{bitfield(0b00, sf, 0, 7, rn, rd)}, nil
+	case "sxth":
+		return []opcode.Instruction{bitfield(0b00, sf, 0, 15, rn, rd)}, nil
+	case "sxtw":
+		if sf == 0 {
+			return nil, fmt.Errorf("sxtw writes an x register, not %v", i.Rd)
+		}
+		return []opcode.Instruction{bitfield(0b00, sf, 0, 31, rn, rd)}, nil
+	case "uxtb", "uxth":
+		if sf != 0 {
+			return nil, fmt.Errorf("%s writes a w register, not %v", mnemonic, i.Rd)
+		}
+		if mnemonic == "uxtb" {
+			return []opcode.Instruction{bitfield(0b10, sf, 0, 7, rn, rd)}, nil
+		}
+		return []opcode.Instruction{bitfield(0b10, sf, 0, 15, rn, rd)}, nil
+	}
+	return nil, fmt.Errorf("%s is not an instruction", mnemonic)
+}
+
+// Extr extracts a register from the concatenation of two others.
+type Extr struct {
+	Rd  GeneralRegister `"extr" @RegisterGeneral ","`
+	Rn  GeneralRegister `@RegisterGeneral ","`
+	Rm  GeneralRegister `@RegisterGeneral ","`
+	Lsb Immediate       `"#"? @Integer`
+}
+
+func (i *Extr) Validate() ([]opcode.Instruction, error) {
+	sf, regs, err := generalOperands(i.Rd, i.Rn, i.Rm)
+	if err != nil {
+		return nil, err
+	}
+	if i.Lsb < 0 || i.Lsb >= 32<<sf {
+		return nil, fmt.Errorf("lsb %d is out of range [0, %d]", i.Lsb, 32<<sf-1)
+	}
+	return []opcode.Instruction{&opcode.Extr{Sf: sf, N: sf, Rm: regs[2], Imms: uint32(i.Lsb), Rn: regs[1], Rd: regs[0]}}, nil
+}
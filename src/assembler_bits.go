@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// OneSource is RBIT, REV16, REV32, REV, CLZ or CLS on general purpose registers.  REV64 is
+// accepted as another name for the 64-bit REV.
+type OneSource struct {
+	Mnemonic string          `@("rbit" | "rev16" | "rev32" | "rev64" | "rev" | "clz" | "cls")`
+	Rd       GeneralRegister `@RegisterGeneral ","`
+	Rn       GeneralRegister `@RegisterGeneral`
+}
+
+func (i *OneSource) Validate() ([]opcode.Instruction, error) {
+	sf, regs, err := generalOperands(i.Rd, i.Rn)
+	if err != nil {
+		return nil, err
+	}
+	rd, rn := regs[0], regs[1]
+	mnemonic := strings.ToLower(i.Mnemonic)
+	if (mnemonic == "rev32" || mnemonic == "rev64") && sf != 1 {
+		return nil, fmt.Errorf("%s reverses x registers, not %v", mnemonic, i.Rd)
+	}
+	switch mnemonic {
+	case "rbit":
+		return []opcode.Instruction{&opcode.Rbit{Sf: sf, Rn: rn, Rd: rd}}, nil
+	case "rev16":
+		return []opcode.Instruction{&opcode.Rev16{Sf: sf, Rn: rn, Rd: rd}}, nil
+	case "rev32":
+		return []opcode.Instruction{&opcode.Rev32{Rn: rn, Rd: rd}}, nil
+	case "rev", "rev64":
+		return []opcode.Instruction{&opcode.Rev{Sf: sf, Rn: rn, Rd: rd}}, nil
+	case "clz":
+		return []opcode.Instruction{&opcode.Clz{Sf: sf, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.Cls{Sf: sf, Rn: rn, Rd: rd}}, nil
+}
+
+// vectorUnarySizes is the largest element size field allowed by each VectorUnary mnemonic.
+var vectorUnarySizes = map[string]uint32{
+	"cnt":   0b00,
+	"rbit":  0b00,
+	"rev16": 0b00,
+	"rev32": 0b01,
+	"rev64": 0b10,
+	"clz":   0b10,
+	"cls":   0b10,
+	"abs":   0b11,
+	"neg":   0b11,
+}
+
+// VectorUnary is CNT, RBIT, REV16, REV32, REV64, CLZ, CLS, ABS or NEG on vectors.
+type VectorUnary struct {
+	Mnemonic string       `@("cnt" | "rbit" | "rev16" | "rev32" | "rev64" | "clz" | "cls" | "abs" | "neg")`
+	Vd       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vn       RegisterNeon `@(RegisterNeon TypeSpecifier)`
+}
+
+func (i *VectorUnary) Validate() ([]opcode.Instruction, error) {
+	if err := sameArrangement(i.Vd, i.Vn); err != nil {
+		return nil, err
+	}
+	mnemonic := strings.ToLower(i.Mnemonic)
+	if i.Vd.Size > vectorUnarySizes[mnemonic] || (i.Vd.Q == 0 && i.Vd.Size == 0b11) {
+		return nil, fmt.Errorf("%v is not a valid arrangement for %s", i.Vd, mnemonic)
+	}
+	q, size, rn, rd := i.Vd.Q, i.Vd.Size, i.Vn.N, i.Vd.N
+	switch mnemonic {
+	case "cnt":
+		return []opcode.Instruction{&opcode.Cnt{Q: q, Rn: rn, Rd: rd}}, nil
+	case "rbit":
+		return []opcode.Instruction{&opcode.RbitVector{Q: q, Rn: rn, Rd: rd}}, nil
+	case "rev16":
+		return []opcode.Instruction{&opcode.Rev16Vector{Q: q, Rn: rn, Rd: rd}}, nil
+	case "rev32":
+		return []opcode.Instruction{&opcode.Rev32Vector{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "rev64":
+		return []opcode.Instruction{&opcode.Rev64{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "clz":
+		return []opcode.Instruction{&opcode.ClzVector{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "cls":
+		return []opcode.Instruction{&opcode.ClsVector{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "abs":
+		return []opcode.Instruction{&opcode.AbsVector{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.NegVector{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// resolveBranch sets offset to the distance from pc to label, unless the target was written as an
+// offset.
+func resolveBranch(offset *Immediate, label *string, labels map[string]uint64, pc uint64) error {
+	if label == nil {
+		return nil
+	}
+	addr, err := lookupLabel(labels, *label)
+	if err != nil {
+		return err
+	}
+	*offset = Immediate(addr - pc)
+	return nil
+}
+
+// Branch is B or BL to a label or to an offset in bytes from the instruction.
+type Branch struct {
+	Mnemonic string    `@("b" | "bl")`
+	Offset   Immediate `( "#" @Integer`
+	Label    *string   `| @Ident )`
+}
+
+func (i *Branch) resolve(labels map[string]uint64, pc uint64) error {
+	return resolveBranch(&i.Offset, i.Label, labels, pc)
+}
+
+func (i *Branch) Validate() ([]opcode.Instruction, error) {
+	imm, err := scaledOffset(int64(i.Offset), 2, 26)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ToLower(i.Mnemonic) == "bl" {
+		return []opcode.Instruction{&opcode.Bl{Imm: imm}}, nil
+	}
+	return []opcode.Instruction{&opcode.B{Imm: imm}}, nil
+}
+
+// BranchCond is B.cond to a label or to an offset in bytes from the instruction.
+type BranchCond struct {
+	Mnemonic string    `@("b.eq" | "b.ne" | "b.hs" | "b.cs" | "b.lo" | "b.cc" | "b.mi" | "b.pl" | "b.vs" | "b.vc" | "b.hi" | "b.ls" | "b.ge" | "b.lt" | "b.gt" | "b.le" | "b.al" | "b.nv")`
+	Offset   Immediate `( "#" @Integer`
+	Label    *string   `| @Ident )`
+}
+
+func (i *BranchCond) resolve(labels map[string]uint64, pc uint64) error {
+	return resolveBranch(&i.Offset, i.Label, labels, pc)
+}
+
+func (i *BranchCond) Validate() ([]opcode.Instruction, error) {
+	imm, err := scaledOffset(int64(i.Offset), 2, 19)
+	if err != nil {
+		return nil, err
+	}
+	cond := conditions[strings.ToLower(i.Mnemonic)[2:]]
+	return []opcode.Instruction{&opcode.BCond{Imm: imm, Cond: cond}}, nil
+}
+
+// CompareBranch is CBZ or CBNZ, which branch if a register is or is not zero.
+type CompareBranch struct {
+	Mnemonic string          `@("cbz" | "cbnz")`
+	Rt       GeneralRegister `@RegisterGeneral ","`
+	Offset   Immediate       `( "#" @Integer`
+	Label    *string         `| @Ident )`
+}
+
+func (i *CompareBranch) resolve(labels map[string]uint64, pc uint64) error {
+	return resolveBranch(&i.Offset, i.Label, labels, pc)
+}
+
+func (i *CompareBranch) Validate() ([]opcode.Instruction, error) {
+	rt, err := i.Rt.zr()
+	if err != nil {
+		return nil, err
+	}
+	imm, err := scaledOffset(int64(i.Offset), 2, 19)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ToLower(i.Mnemonic) == "cbnz" {
+		return []opcode.Instruction{&opcode.Cbnz{Sf: i.Rt.Sf, Imm: imm, Rt: rt}}, nil
+	}
+	return []opcode.Instruction{&opcode.Cbz{Sf: i.Rt.Sf, Imm: imm, Rt: rt}}, nil
+}
+
+// TestBranch is TBZ or TBNZ, which branch if a bit of a register is or is not zero.
+type TestBranch struct {
+	Mnemonic string          `@("tbz" | "tbnz")`
+	Rt       GeneralRegister `@RegisterGeneral ","`
+	Bit      Immediate       `"#"? @Integer ","`
+	Offset   Immediate       `( "#" @Integer`
+	Label    *string         `| @Ident )`
+}
+
+func (i *TestBranch) resolve(labels map[string]uint64, pc uint64) error {
+	return resolveBranch(&i.Offset, i.Label, labels, pc)
+}
+
+func (i *TestBranch) Validate() ([]opcode.Instruction, error) {
+	rt, err := i.Rt.zr()
+	if err != nil {
+		return nil, err
+	}
+	if i.Bit < 0 || int64(i.Bit) >= 32<<i.Rt.Sf {
+		return nil, fmt.Errorf("bit %d of %v is out of range [0, %d]", i.Bit, i.Rt, 32<<i.Rt.Sf-1)
+	}
+	imm, err := scaledOffset(int64(i.Offset), 2, 14)
+	if err != nil {
+		return nil, err
+	}
+	b5, b40 := uint32(i.Bit)>>5, uint32(i.Bit)&0b11111
+	if strings.ToLower(i.Mnemonic) == "tbnz" {
+		return []opcode.Instruction{&opcode.Tbnz{B5: b5, B40: b40, Imm: imm, Rt: rt}}, nil
+	}
+	return []opcode.Instruction{&opcode.Tbz{B5: b5, B40: b40, Imm: imm, Rt: rt}}, nil
+}
+
+// BranchRegister is BR, BLR or RET, which branch to the address in an x register.  RET returns to
+// x30 if no register is given.
+type BranchRegister struct {
+	Mnemonic string           `@("br" | "blr" | "ret")`
+	Rn       *GeneralRegister `@RegisterGeneral?`
+}
+
+func (i *BranchRegister) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	rn := GeneralRegister{Sf: 1, N: 30}
+	switch {
+	case i.Rn != nil:
+		rn = *i.Rn
+	case mnemonic != "ret":
+		return nil, fmt.Errorf("%s needs a register", mnemonic)
+	}
+	n, err := rn.zr()
+	if err != nil {
+		return nil, err
+	}
+	if rn.Sf != 1 {
+		return nil, fmt.Errorf("%s branches to an x register, not %v", mnemonic, rn)
+	}
+	switch mnemonic {
+	case "br":
+		return []opcode.Instruction{&opcode.Br{Rn: n}}, nil
+	case "blr":
+		return []opcode.Instruction{&opcode.Blr{Rn: n}}, nil
+	}
+	return []opcode.Instruction{&opcode.Ret{Rn: n}}, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// conditions maps each condition name, including the cs and cc synonyms of hs and lo, to its code.
+var conditions = map[string]uint32{
+	"eq": 0b0000, "ne": 0b0001, "hs": 0b0010, "cs": 0b0010, "lo": 0b0011, "cc": 0b0011,
+	"mi": 0b0100, "pl": 0b0101, "vs": 0b0110, "vc": 0b0111, "hi": 0b1000, "ls": 0b1001,
+	"ge": 0b1010, "lt": 0b1011, "gt": 0b1100, "le": 0b1101, "al": 0b1110, "nv": 0b1111,
+}
+
+// Condition is a condition code operand such as eq or hs.
+type Condition uint32
+
+func (c *Condition) Capture(values []string) error {
+	code, ok := conditions[strings.ToLower(values[0])]
+	if !ok {
+		return fmt.Errorf("%q is not a condition", values[0])
+	}
+	*c = Condition(code)
+	return nil
+}
+
+// inverse returns the opposite condition, for the aliases that are written with the condition
+// under which they do not select their first operand.  AL and NV have no inverse.
+func (c Condition) inverse() (uint32, error) {
+	if c&0b1110 == 0b1110 {
+		return 0, fmt.Errorf("the condition cannot be al or nv")
+	}
+	return uint32(c) ^ 1, nil
+}
+
+// conditionalSelect returns CSEL, CSINC, CSINV or CSNEG.
+func conditionalSelect(mnemonic string, sf, rm, cond, rn, rd uint32) opcode.Instruction {
+	switch mnemonic {
+	case "csel":
+		return &opcode.Csel{Sf: sf, Rm: rm, Cond: cond, Rn: rn, Rd: rd}
+	case "csinc":
+		return &opcode.Csinc{Sf: sf, Rm: rm, Cond: cond, Rn: rn, Rd: rd}
+	case "csinv":
+		return &opcode.Csinv{Sf: sf, Rm: rm, Cond: cond, Rn: rn, Rd: rd}
+	}
+	return &opcode.Csneg{Sf: sf, Rm: rm, Cond: cond, Rn: rn, Rd: rd}
+}
+
+// ConditionalSelect is CSEL, CSINC, CSINV or CSNEG.
+type ConditionalSelect struct {
+	Mnemonic string          `@("csel" | "csinc" | "csinv" | "csneg")`
+	Rd       GeneralRegister `@RegisterGeneral ","`
+	Rn       GeneralRegister `@RegisterGeneral ","`
+	Rm       GeneralRegister `@RegisterGeneral ","`
+	Cond     Condition       `@Ident`
+}
+
+func (i *ConditionalSelect) Validate() ([]opcode.Instruction, error) {
+	sf, regs, err := generalOperands(i.Rd, i.Rn, i.Rm)
+	if err != nil {
+		return nil, err
+	}
+	return []opcode.Instruction{conditionalSelect(strings.ToLower(i.Mnemonic), sf, regs[2], uint32(i.Cond), regs[1], regs[0])}, nil
+}
+
+// ConditionalSet is CSET or CSETM, which are CSINC and CSINV from the zero register with the
+// condition inverted.
+type ConditionalSet struct {
+	Mnemonic string          `@("cset" | "csetm")`
+	Rd       GeneralRegister `@RegisterGeneral ","`
+	Cond     Condition       `@Ident`
+}
+
+func (i *ConditionalSet) Validate() ([]opcode.Instruction, error) {
+	sf, regs, err := generalOperands(i.Rd)
+	if err != nil {
+		return nil, err
+	}
+	cond, err := i.Cond.inverse()
+	if err != nil {
+		return nil, err
+	}
+	if strings.ToLower(i.Mnemonic) == "cset" {
+		return []opcode.Instruction{conditionalSelect("csinc", sf, 31, cond, 31, regs[0])}, nil
+	}
+	return []opcode.Instruction{conditionalSelect("csinv", sf, 31, cond, 31, regs[0])}, nil
+}
+
+// ConditionalUnary is CINC, CINV or CNEG, which are CSINC, CSINV and CSNEG with the source register
+// repeated and the condition inverted.
+type ConditionalUnary struct {
+	Mnemonic string          `@("cinc" | "cinv" | "cneg")`
+	Rd       GeneralRegister `@RegisterGeneral ","`
+	Rn       GeneralRegister `@RegisterGeneral ","`
+	Cond     Condition       `@Ident`
+}
+
+func (i *ConditionalUnary) Validate() ([]opcode.Instruction, error) {
+	sf, regs, err := generalOperands(i.Rd, i.Rn)
+	if err != nil {
+		return nil, err
+	}
+	cond, err := i.Cond.inverse()
+	if err != nil {
+		return nil, err
+	}
+	mnemonic := "cs" + strings.ToLower(i.Mnemonic)[1:]
+	return []opcode.Instruction{conditionalSelect(mnemonic, sf, regs[1], cond, regs[1], regs[0])}, nil
+}
+
+// ConditionalCompare is CCMP or CCMN with a register or a 5-bit immediate.
+type ConditionalCompare struct {
+	Mnemonic string           `@("ccmp" | "ccmn")`
+	Rn       GeneralRegister  `@RegisterGeneral ","`
+	Imm      *Immediate       `( "#"? @Integer`
+	Rm       *GeneralRegister `| @RegisterGeneral ) ","`
+	Nzcv     Immediate        `"#"? @Integer ","`
+	Cond     Condition        `@Ident`
+}
+
+func (i *ConditionalCompare) Validate() ([]opcode.Instruction, error) {
+	if i.Nzcv < 0 || i.Nzcv > 15 {
+		return nil, fmt.Errorf("flags %d are out of range [0, 15]", i.Nzcv)
+	}
+	nzcv, cond := uint32(i.Nzcv), uint32(i.Cond)
+	ccmp := strings.ToLower(i.Mnemonic) == "ccmp"
+	if i.Rm != nil {
+		sf, regs, err := generalOperands(i.Rn, *i.Rm)
+		if err != nil {
+			return nil, err
+		}
+		if ccmp {
+			return []opcode.Instruction{&opcode.CcmpRegister{Sf: sf, Rm: regs[1], Cond: cond, Rn: regs[0], Nzcv: nzcv}}, nil
+		}
+		return []opcode.Instruction{&opcode.CcmnRegister{Sf: sf, Rm: regs[1], Cond: cond, Rn: regs[0], Nzcv: nzcv}}, nil
+	}
+	sf, regs, err := generalOperands(i.Rn)
+	if err != nil {
+		return nil, err
+	}
+	if *i.Imm < 0 || *i.Imm > 31 {
+		return nil, fmt.Errorf("immediate %d is out of range [0, 31]", *i.Imm)
+	}
+	imm := uint32(*i.Imm)
+	if ccmp {
+		return []opcode.Instruction{&opcode.CcmpImmediate{Sf: sf, Imm: imm, Cond: cond, Rn: regs[0], Nzcv: nzcv}}, nil
+	}
+	return []opcode.Instruction{&opcode.CcmnImmediate{Sf: sf, Imm: imm, Cond: cond, Rn: regs[0], Nzcv: nzcv}}, nil
+}
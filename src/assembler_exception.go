@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// Exception is BRK or HLT with a 16-bit immediate that is ignored by the machine but is available
+// to a debugger.
+type Exception struct {
+	Mnemonic string    `@("brk" | "hlt")`
+	Imm      Immediate `"#"? @Integer`
+}
+
+func (i *Exception) Validate() ([]opcode.Instruction, error) {
+	if i.Imm < 0 || i.Imm > 0xffff {
+		return nil, fmt.Errorf("immediate %d is out of range [0, 65535]", i.Imm)
+	}
+	if strings.ToLower(i.Mnemonic) == "brk" {
+		return []opcode.Instruction{&opcode.Brk{Imm: uint32(i.Imm)}}, nil
+	}
+	return []opcode.Instruction{&opcode.Hlt{Imm: uint32(i.Imm)}}, nil
+}
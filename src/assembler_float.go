@@ -0,0 +1,294 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// fpOperands returns the ftype field shared by the scalar floating-point registers regs, which
+// must all be h, s or d registers of the same width.
+func fpOperands(mnemonic string, regs ...FPRegister) (uint32, error) {
+	for _, r := range regs {
+		if r.Size != regs[0].Size {
+			return 0, fmt.Errorf("%v and %v are not the same width", regs[0], r)
+		}
+	}
+	switch regs[0].Size {
+	case 0b01:
+		return 0b11, nil
+	case 0b10:
+		return 0b00, nil
+	case 0b11:
+		return 0b01, nil
+	}
+	return 0, fmt.Errorf("%v is not a valid register for %s", regs[0], mnemonic)
+}
+
+// FloatImmediate is a floating-point constant operand, written as a decimal number.
+type FloatImmediate float64
+
+func (f *FloatImmediate) Capture(values []string) error {
+	v, err := strconv.ParseFloat(values[0], 64)
+	*f = FloatImmediate(v)
+	return err
+}
+
+// imm8 returns the 8-bit encoding of f used by FMOV, which holds ±n/16 * 2^r for 16 <= n <= 31 and
+// -3 <= r <= 4, or false if f is not one of those values.
+func (f FloatImmediate) imm8() (uint32, bool) {
+	for imm8 := uint32(0); imm8 < 256; imm8++ {
+		v := math.Ldexp(float64(16+imm8&0b1111)/16, int(imm8>>4&0b111^0b100)-3)
+		if imm8>>7 == 1 {
+			v = -v
+		}
+		if v == float64(f) {
+			return imm8, true
+		}
+	}
+	return 0, false
+}
+
+// FPUnary is FABS, FNEG or FSQRT on scalar registers.
+type FPUnary struct {
+	Mnemonic string     `@("fabs" | "fneg" | "fsqrt")`
+	Rd       FPRegister `@RegisterFP ","`
+	Rn       FPRegister `@RegisterFP`
+}
+
+func (i *FPUnary) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	ftype, err := fpOperands(mnemonic, i.Rd, i.Rn)
+	if err != nil {
+		return nil, err
+	}
+	switch mnemonic {
+	case "fabs":
+		return []opcode.Instruction{&opcode.Fabs{Ftype: ftype, Rn: i.Rn.N, Rd: i.Rd.N}}, nil
+	case "fneg":
+		return []opcode.Instruction{&opcode.Fneg{Ftype: ftype, Rn: i.Rn.N, Rd: i.Rd.N}}, nil
+	}
+	return []opcode.Instruction{&opcode.Fsqrt{Ftype: ftype, Rn: i.Rn.N, Rd: i.Rd.N}}, nil
+}
+
+// FPBinary is a scalar floating-point instruction with two sources: FADD, FSUB, FMUL, FNMUL, FDIV,
+// FMAX, FMIN, FMAXNM or FMINNM.
+type FPBinary struct {
+	Mnemonic string     `@("fadd" | "fsub" | "fmul" | "fnmul" | "fdiv" | "fmax" | "fmin" | "fmaxnm" | "fminnm")`
+	Rd       FPRegister `@RegisterFP ","`
+	Rn       FPRegister `@RegisterFP ","`
+	Rm       FPRegister `@RegisterFP`
+}
+
+func (i *FPBinary) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	ftype, err := fpOperands(mnemonic, i.Rd, i.Rn, i.Rm)
+	if err != nil {
+		return nil, err
+	}
+	rm, rn, rd := i.Rm.N, i.Rn.N, i.Rd.N
+	switch mnemonic {
+	case "fadd":
+		return []opcode.Instruction{&opcode.Fadd{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "fsub":
+		return []opcode.Instruction{&opcode.Fsub{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "fmul":
+		return []opcode.Instruction{&opcode.Fmul{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "fnmul":
+		return []opcode.Instruction{&opcode.Fnmul{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "fdiv":
+		return []opcode.Instruction{&opcode.Fdiv{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "fmax":
+		return []opcode.Instruction{&opcode.Fmax{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "fmin":
+		return []opcode.Instruction{&opcode.Fmin{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "fmaxnm":
+		return []opcode.Instruction{&opcode.Fmaxnm{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.Fminnm{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}}, nil
+}
+
+// FPMulAdd is FMADD, FMSUB, FNMADD or FNMSUB.
+type FPMulAdd struct {
+	Mnemonic string     `@("fmadd" | "fmsub" | "fnmadd" | "fnmsub")`
+	Rd       FPRegister `@RegisterFP ","`
+	Rn       FPRegister `@RegisterFP ","`
+	Rm       FPRegister `@RegisterFP ","`
+	Ra       FPRegister `@RegisterFP`
+}
+
+func (i *FPMulAdd) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	ftype, err := fpOperands(mnemonic, i.Rd, i.Rn, i.Rm, i.Ra)
+	if err != nil {
+		return nil, err
+	}
+	rm, ra, rn, rd := i.Rm.N, i.Ra.N, i.Rn.N, i.Rd.N
+	switch mnemonic {
+	case "fmadd":
+		return []opcode.Instruction{&opcode.Fmadd{Ftype: ftype, Rm: rm, Ra: ra, Rn: rn, Rd: rd}}, nil
+	case "fmsub":
+		return []opcode.Instruction{&opcode.Fmsub{Ftype: ftype, Rm: rm, Ra: ra, Rn: rn, Rd: rd}}, nil
+	case "fnmadd":
+		return []opcode.Instruction{&opcode.Fnmadd{Ftype: ftype, Rm: rm, Ra: ra, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.Fnmsub{Ftype: ftype, Rm: rm, Ra: ra, Rn: rn, Rd: rd}}, nil
+}
+
+// FPCompare is FCMP or FCMPE, with a register or #0.0.
+type FPCompare struct {
+	Mnemonic string          `@("fcmp" | "fcmpe")`
+	Rn       FPRegister      `@RegisterFP ","`
+	Rm       *FPRegister     `(  @RegisterFP`
+	Zero     *FloatImmediate ` | "#" @Float )`
+}
+
+func (i *FPCompare) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	if i.Zero != nil {
+		if *i.Zero != 0 {
+			return nil, fmt.Errorf("%s can only compare with #0.0, not #%v", mnemonic, float64(*i.Zero))
+		}
+		ftype, err := fpOperands(mnemonic, i.Rn)
+		if err != nil {
+			return nil, err
+		}
+		if mnemonic == "fcmp" {
+			return []opcode.Instruction{&opcode.FcmpZero{Ftype: ftype, Rn: i.Rn.N}}, nil
+		}
+		return []opcode.Instruction{&opcode.FcmpeZero{Ftype: ftype, Rn: i.Rn.N}}, nil
+	}
+	ftype, err := fpOperands(mnemonic, i.Rn, *i.Rm)
+	if err != nil {
+		return nil, err
+	}
+	if mnemonic == "fcmp" {
+		return []opcode.Instruction{&opcode.Fcmp{Ftype: ftype, Rm: i.Rm.N, Rn: i.Rn.N}}, nil
+	}
+	return []opcode.Instruction{&opcode.Fcmpe{Ftype: ftype, Rm: i.Rm.N, Rn: i.Rn.N}}, nil
+}
+
+// Fcsel is FCSEL.
+type Fcsel struct {
+	Rd   FPRegister `"fcsel" @RegisterFP ","`
+	Rn   FPRegister `@RegisterFP ","`
+	Rm   FPRegister `@RegisterFP ","`
+	Cond Condition  `@Ident`
+}
+
+func (i *Fcsel) Validate() ([]opcode.Instruction, error) {
+	ftype, err := fpOperands("fcsel", i.Rd, i.Rn, i.Rm)
+	if err != nil {
+		return nil, err
+	}
+	return []opcode.Instruction{&opcode.Fcsel{Ftype: ftype, Rm: i.Rm.N, Cond: uint32(i.Cond), Rn: i.Rn.N, Rd: i.Rd.N}}, nil
+}
+
+// FmovOperand is an operand of FMOV: a scalar floating-point register, a general register, the
+// upper half of a vector register written as Vn.d[1], or a floating-point constant.
+type FmovOperand struct {
+	FP      *FPRegister      `  @RegisterFP`
+	General *GeneralRegister `| @RegisterGeneral`
+	Lane    *VectorLane      `| @@`
+	Imm     *FloatImmediate  `| "#" @(Float | Integer)`
+}
+
+func (o FmovOperand) String() string {
+	switch {
+	case o.FP != nil:
+		return o.FP.String()
+	case o.General != nil:
+		return o.General.String()
+	case o.Lane != nil:
+		return o.Lane.String()
+	}
+	return fmt.Sprintf("#%v", float64(*o.Imm))
+}
+
+// upperHalf reports whether o is Vn.d[1], the only lane FMOV can move to or from.
+func (o FmovOperand) upperHalf() bool {
+	return o.Lane != nil && o.Lane.Element.Size == 0b11 && o.Lane.Index == 1
+}
+
+// Fmov is FMOV between floating-point registers, between a floating-point and a general register,
+// or of a constant.
+type Fmov struct {
+	Dst FmovOperand `"fmov" @@ ","`
+	Src FmovOperand `@@`
+}
+
+func (i *Fmov) Validate() ([]opcode.Instruction, error) {
+	dst, src := i.Dst, i.Src
+	switch {
+	case dst.FP != nil && src.FP != nil:
+		ftype, err := fpOperands("fmov", *dst.FP, *src.FP)
+		if err != nil {
+			return nil, err
+		}
+		return []opcode.Instruction{&opcode.FmovRegister{Ftype: ftype, Rn: src.FP.N, Rd: dst.FP.N}}, nil
+	case dst.FP != nil && src.Imm != nil:
+		ftype, err := fpOperands("fmov", *dst.FP)
+		if err != nil {
+			return nil, err
+		}
+		if *src.Imm == 0 && !math.Signbit(float64(*src.Imm)) {
+			// +0.0 has no imm8 encoding, so it is moved from the zero register instead.
+			var sf uint32
+			if ftype == 0b01 {
+				sf = 1
+			}
+			return []opcode.Instruction{&opcode.FmovFromGeneral{Sf: sf, Ftype: ftype, Rn: 31, Rd: dst.FP.N}}, nil
+		}
+		imm8, ok := src.Imm.imm8()
+		if !ok {
+			return nil, fmt.Errorf("%v cannot be encoded as an fmov immediate", src)
+		}
+		return []opcode.Instruction{&opcode.FmovImmediate{Ftype: ftype, Imm8: imm8, Rd: dst.FP.N}}, nil
+	case dst.FP != nil && src.General != nil:
+		ftype, rn, err := fmovGeneral(*dst.FP, *src.General)
+		if err != nil {
+			return nil, err
+		}
+		return []opcode.Instruction{&opcode.FmovFromGeneral{Sf: src.General.Sf, Ftype: ftype, Rn: rn, Rd: dst.FP.N}}, nil
+	case dst.General != nil && src.FP != nil:
+		ftype, rd, err := fmovGeneral(*src.FP, *dst.General)
+		if err != nil {
+			return nil, err
+		}
+		return []opcode.Instruction{&opcode.FmovToGeneral{Sf: dst.General.Sf, Ftype: ftype, Rn: src.FP.N, Rd: rd}}, nil
+	case dst.upperHalf() && src.General != nil && src.General.Sf == 1:
+		rn, err := src.General.zr()
+		if err != nil {
+			return nil, err
+		}
+		return []opcode.Instruction{&opcode.FmovFromGeneral{Sf: 1, Ftype: 0b10, Rmode: 0b01, Rn: rn, Rd: dst.Lane.Element.N}}, nil
+	case dst.General != nil && dst.General.Sf == 1 && src.upperHalf():
+		rd, err := dst.General.zr()
+		if err != nil {
+			return nil, err
+		}
+		return []opcode.Instruction{&opcode.FmovToGeneral{Sf: 1, Ftype: 0b10, Rmode: 0b01, Rn: src.Lane.Element.N, Rd: rd}}, nil
+	}
+	return nil, fmt.Errorf("fmov cannot move %v to %v", src, dst)
+}
+
+// fmovGeneral returns the ftype of an FMOV between the floating-point register f and the general
+// register r, and the number of r.  Half precision registers go with either width of general
+// register, single with w registers and double with x registers.
+func fmovGeneral(f FPRegister, r GeneralRegister) (uint32, uint32, error) {
+	n, err := r.zr()
+	if err != nil {
+		return 0, 0, err
+	}
+	ftype, err := fpOperands("fmov", f)
+	if err != nil {
+		return 0, 0, err
+	}
+	if ftype != 0b11 && ftype != r.Sf {
+		return 0, 0, fmt.Errorf("%v and %v are not the same width", f, r)
+	}
+	return ftype, n, nil
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// TransferRegister is a register loaded or stored by a load/store instruction, either a general
+// purpose register or a scalar SIMD&FP register.
+type TransferRegister struct {
+	General *GeneralRegister `  @RegisterGeneral`
+	FP      *FPRegister      `| @RegisterFP`
+}
+
+func (r TransferRegister) String() string {
+	if r.General != nil {
+		return r.General.String()
+	}
+	return r.FP.String()
+}
+
+// ImmediateAddress is a base register with an optional immediate offset: [Xn|SP{, #imm}] for a
+// plain offset, [Xn|SP, #imm]! for pre-indexing and [Xn|SP], #imm for post-indexing.
+type ImmediateAddress struct {
+	Base      GeneralRegister `"[" @RegisterGeneral`
+	Offset    *Immediate      `( "," "#"? @Integer "]"`
+	PreIndex  bool            `  @"!"?`
+	PostIndex *Immediate      `| "]" ("," "#"? @Integer)? )`
+}
+
+// base returns the number of the base register, which must be an x register or SP.
+func (a ImmediateAddress) base() (uint32, error) {
+	return baseRegister(a.Base)
+}
+
+// baseRegister returns the number of r, which must be an x register or SP, as the base register
+// of an address.
+func baseRegister(r GeneralRegister) (uint32, error) {
+	if r.Sf == 0 {
+		return 0, fmt.Errorf("base register %v is not a 64-bit register", r)
+	}
+	return r.sp()
+}
+
+// offset returns the offset of the address, and whether and when it is written back to the base.
+func (a ImmediateAddress) offset() (offset int64, writeback, postIndex bool) {
+	switch {
+	case a.PostIndex != nil:
+		return int64(*a.PostIndex), true, true
+	case a.Offset != nil:
+		return int64(*a.Offset), a.PreIndex, false
+	}
+	return 0, false, false
+}
+
+// scaledOffset returns offset divided by 1<<scale as a width bit field, or an error if it is not a
+// multiple of 1<<scale in the range of a signed width bit field.
+func scaledOffset(offset int64, scale uint32, width int) (uint32, error) {
+	lo, hi := int64(-1)<<(width-1)<<scale, (int64(1)<<(width-1)-1)<<scale
+	if offset < lo || offset > hi || offset&(1<<scale-1) != 0 {
+		return 0, fmt.Errorf("offset %d is not a multiple of %d in the range [%d, %d]", offset, 1<<scale, lo, hi)
+	}
+	return uint32(offset>>scale) & (1<<width - 1), nil
+}
+
+// LoadStorePair is LDP, LDPSW or STP with a pair of general purpose or SIMD&FP registers.
+type LoadStorePair struct {
+	Mnemonic string           `@("ldp" | "ldpsw" | "stp")`
+	Rt       TransferRegister `@@ ","`
+	Rt2      TransferRegister `@@ ","`
+	Address  ImmediateAddress `@@`
+}
+
+func (i *LoadStorePair) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	rn, err := i.Address.base()
+	if err != nil {
+		return nil, err
+	}
+
+	var opc, v, l, scale, rt, rt2 uint32
+	switch {
+	case i.Rt.General != nil && i.Rt2.General != nil:
+		sf, n, err := generalOperands(*i.Rt.General, *i.Rt2.General)
+		if err != nil {
+			return nil, err
+		}
+		rt, rt2 = n[0], n[1]
+		opc, scale = sf<<1, 2+sf
+		if mnemonic == "ldpsw" {
+			if sf == 0 {
+				return nil, fmt.Errorf("ldpsw loads x registers, not %v", i.Rt)
+			}
+			opc, scale = 0b01, 2
+		}
+	case i.Rt.FP != nil && i.Rt2.FP != nil && mnemonic != "ldpsw":
+		if i.Rt.FP.Size != i.Rt2.FP.Size {
+			return nil, fmt.Errorf("%v and %v are not the same width", i.Rt, i.Rt2)
+		}
+		if i.Rt.FP.Size < 2 {
+			return nil, fmt.Errorf("%s cannot transfer %v", mnemonic, i.Rt)
+		}
+		rt, rt2 = i.Rt.FP.N, i.Rt2.FP.N
+		v, opc, scale = 1, i.Rt.FP.Size-2, i.Rt.FP.Size
+	default:
+		return nil, fmt.Errorf("%s cannot transfer %v and %v", mnemonic, i.Rt, i.Rt2)
+	}
+	if mnemonic != "stp" {
+		l = 1
+		if rt == rt2 {
+			return nil, fmt.Errorf("%s cannot load %v twice", mnemonic, i.Rt)
+		}
+	}
+
+	offset, writeback, postIndex := i.Address.offset()
+	imm, err := scaledOffset(offset, scale, 7)
+	if err != nil {
+		return nil, err
+	}
+	if writeback && v == 0 && rn != 31 && (rn == rt || rn == rt2) {
+		return nil, fmt.Errorf("%s with writeback to %v, which it also transfers, is unpredictable", mnemonic, i.Address.Base)
+	}
+	switch {
+	case postIndex:
+		return []opcode.Instruction{&opcode.LoadStorePairPostIndexed{Opc: opc, V: v, L: l, Imm: imm, Rt2: rt2, Rn: rn, Rt: rt}}, nil
+	case writeback:
+		return []opcode.Instruction{&opcode.LoadStorePairPreIndexed{Opc: opc, V: v, L: l, Imm: imm, Rt2: rt2, Rn: rn, Rt: rt}}, nil
+	}
+	return []opcode.Instruction{&opcode.LoadStorePairOffset{Opc: opc, V: v, L: l, Imm: imm, Rt2: rt2, Rn: rn, Rt: rt}}, nil
+}
+
+// RegisterAddress is a base register plus an offset register, which is either an x register that
+// can be shifted left by the size of the transfer, or a w register that is extended:
+// [Xn|SP, Xm{, lsl|sxtx {#amount}}] or [Xn|SP, Wm, uxtw|sxtw {#amount}].
+type RegisterAddress struct {
+	Base   GeneralRegister `"[" @RegisterGeneral ","`
+	Index  GeneralRegister `@RegisterGeneral`
+	Extend *string         `("," (@Extend | @Shift)`
+	Amount *Immediate      `  ("#"? @Integer)? )? "]"`
+}
+
+// PageOffsetAddress is a base register plus the low 12 bits of the address of a label,
+// [Xn|SP, :lo12:label], to complete an address whose page was found with ADRP.
+type PageOffsetAddress struct {
+	Base  GeneralRegister `"[" @RegisterGeneral "," ":" "lo12" ":"`
+	Label string          `@Ident "]"`
+}
+
+// LoadStore is LDR, STR or one of their byte, halfword, sign-extending or unscaled variants with a
+// general purpose or scalar SIMD&FP register.  The address is a base register with an immediate
+// offset, a base register plus a register, a base register plus :lo12:label, or for LDR and LDRSW
+// a label or an offset in bytes from the instruction.  LDR and STR with an offset that they cannot
+// scale are assembled as LDUR and STUR.
+type LoadStore struct {
+	Mnemonic   string             `@("ldr" | "ldrb" | "ldrh" | "ldrsb" | "ldrsh" | "ldrsw" | "str" | "strb" | "strh" | "ldur" | "ldurb" | "ldurh" | "ldursb" | "ldursh" | "ldursw" | "stur" | "sturb" | "sturh")`
+	Rt         TransferRegister   `@@ ","`
+	Register   *RegisterAddress   `( @@`
+	PageOffset *PageOffsetAddress `| @@`
+	Address    *ImmediateAddress  `| @@`
+	Offset     *Immediate         `| "#" @Integer`
+	Label      *string            `| @Ident )`
+}
+
+func (i *LoadStore) resolve(labels map[string]uint64, pc uint64) error {
+	switch {
+	case i.PageOffset != nil:
+		addr, err := lookupLabel(labels, i.PageOffset.Label)
+		if err != nil {
+			return err
+		}
+		offset := Immediate(addr & 0xfff)
+		i.Address = &ImmediateAddress{Base: i.PageOffset.Base, Offset: &offset}
+	case i.Label != nil:
+		addr, err := lookupLabel(labels, *i.Label)
+		if err != nil {
+			return err
+		}
+		offset := Immediate(addr - pc)
+		i.Offset = &offset
+	}
+	return nil
+}
+
+// loadStoreFields returns the size, V and opc fields shared by the load/store register
+// instructions for mnemonic, which is not an unscaled form, transferring rt.  It also returns the
+// number of rt and log2 of the number of bytes transferred.
+func loadStoreFields(mnemonic string, rt TransferRegister) (size, v, opc, n, scale uint32, err error) {
+	var load uint32
+	if mnemonic[0] == 'l' {
+		load = 1
+	}
+	if rt.FP != nil {
+		if mnemonic != "ldr" && mnemonic != "str" {
+			return 0, 0, 0, 0, 0, fmt.Errorf("%s cannot transfer %v", mnemonic, rt)
+		}
+		return rt.FP.Size & 0b11, 1, rt.FP.Size>>2<<1 | load, rt.FP.N, rt.FP.Size, nil
+	}
+	if n, err = rt.General.zr(); err != nil {
+		return 0, 0, 0, 0, 0, err
+	}
+	sf := rt.General.Sf
+	switch mnemonic {
+	case "ldr", "str":
+		size, opc = 2+sf, load
+	case "ldrb", "strb", "ldrh", "strh":
+		if sf == 1 {
+			return 0, 0, 0, 0, 0, fmt.Errorf("%s transfers a w register, not %v", mnemonic, rt)
+		}
+		size, opc = uint32(strings.IndexByte("bh", mnemonic[len(mnemonic)-1])), load
+	case "ldrsb", "ldrsh":
+		size, opc = uint32(strings.IndexByte("bh", mnemonic[len(mnemonic)-1])), 0b11-sf
+	case "ldrsw":
+		if sf == 0 {
+			return 0, 0, 0, 0, 0, fmt.Errorf("ldrsw loads an x register, not %v", rt)
+		}
+		size, opc = 0b10, 0b10
+	}
+	return size, 0, opc, n, size, nil
+}
+
+func (i *LoadStore) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	unscaled := mnemonic[2] == 'u'
+	if unscaled {
+		mnemonic = mnemonic[:2] + mnemonic[3:]
+	}
+	size, v, opc, rt, scale, err := loadStoreFields(mnemonic, i.Rt)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case i.Register != nil:
+		if unscaled {
+			return nil, fmt.Errorf("%s cannot have a register offset", strings.ToLower(i.Mnemonic))
+		}
+		return i.Register.validate(size, v, opc, rt, scale)
+	case i.Address == nil:
+		return i.literal(mnemonic, unscaled, rt)
+	}
+
+	rn, err := i.Address.base()
+	if err != nil {
+		return nil, err
+	}
+	offset, writeback, postIndex := i.Address.offset()
+	switch {
+	case i.PageOffset != nil:
+		if unscaled || offset&(1<<scale-1) != 0 {
+			return nil, fmt.Errorf(":lo12:%s is 0x%x, which %s cannot use as an offset", i.PageOffset.Label, offset, strings.ToLower(i.Mnemonic))
+		}
+		return []opcode.Instruction{&opcode.LoadStoreUnsignedImmediate{Size: size, V: v, Opc: opc, Imm: uint32(offset >> scale), Rn: rn, Rt: rt}}, nil
+	case writeback && unscaled:
+		return nil, fmt.Errorf("%s cannot write back to the base register", strings.ToLower(i.Mnemonic))
+	case writeback:
+		imm, err := scaledOffset(offset, 0, 9)
+		if err != nil {
+			return nil, err
+		}
+		if v == 0 && rn != 31 && rn == rt {
+			return nil, fmt.Errorf("%s with writeback to %v, which it also transfers, is unpredictable", mnemonic, i.Address.Base)
+		}
+		if postIndex {
+			return []opcode.Instruction{&opcode.LoadStorePostIndexed{Size: size, V: v, Opc: opc, Imm: imm, Rn: rn, Rt: rt}}, nil
+		}
+		return []opcode.Instruction{&opcode.LoadStorePreIndexed{Size: size, V: v, Opc: opc, Imm: imm, Rn: rn, Rt: rt}}, nil
+	case !unscaled && offset >= 0 && offset&(1<<scale-1) == 0 && offset>>scale < 1<<12:
+		return []opcode.Instruction{&opcode.LoadStoreUnsignedImmediate{Size: size, V: v, Opc: opc, Imm: uint32(offset >> scale), Rn: rn, Rt: rt}}, nil
+	}
+	imm, err := scaledOffset(offset, 0, 9)
+	if err != nil && !unscaled {
+		return nil, fmt.Errorf("offset %d is neither a multiple of %d in the range [0, %d] nor in the range [-256, 255]", offset, 1<<scale, 4095<<scale)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []opcode.Instruction{&opcode.LoadStoreUnscaled{Size: size, V: v, Opc: opc, Imm: imm, Rn: rn, Rt: rt}}, nil
+}
+
+// literal validates LDR or LDRSW of a label, or of an offset from the instruction.
+func (i *LoadStore) literal(mnemonic string, unscaled bool, rt uint32) ([]opcode.Instruction, error) {
+	if unscaled || (mnemonic != "ldr" && mnemonic != "ldrsw") {
+		return nil, fmt.Errorf("%s cannot load a literal", strings.ToLower(i.Mnemonic))
+	}
+	var opc, v uint32
+	switch {
+	case i.Rt.FP != nil && i.Rt.FP.Size < 2:
+		return nil, fmt.Errorf("ldr cannot load a literal into %v", i.Rt)
+	case i.Rt.FP != nil:
+		opc, v = i.Rt.FP.Size-2, 1
+	case mnemonic == "ldrsw":
+		opc = 0b10
+	default:
+		opc = i.Rt.General.Sf
+	}
+	var offset int64
+	if i.Offset != nil {
+		offset = int64(*i.Offset)
+	}
+	imm, err := scaledOffset(offset, 2, 19)
+	if err != nil {
+		return nil, err
+	}
+	return []opcode.Instruction{&opcode.LoadLiteral{Opc: opc, V: v, Imm: imm, Rt: rt}}, nil
+}
+
+// validate returns the load/store register (register offset) instruction with the given fields
+// that uses a.  The offset register can only be shifted by 0 or by scale.
+func (a *RegisterAddress) validate(size, v, opc, rt, scale uint32) ([]opcode.Instruction, error) {
+	rn, err := baseRegister(a.Base)
+	if err != nil {
+		return nil, err
+	}
+	rm, err := a.Index.zr()
+	if err != nil {
+		return nil, err
+	}
+	extend := "lsl"
+	if a.Extend != nil {
+		extend = strings.ToLower(*a.Extend)
+	}
+	option := map[string]uint32{"uxtw": 0b010, "lsl": 0b011, "sxtw": 0b110, "sxtx": 0b111}
+	opt, ok := option[extend]
+	if !ok || a.Index.Sf != opt&1 {
+		return nil, fmt.Errorf("%v cannot be extended with %s", a.Index, extend)
+	}
+	if extend == "lsl" && a.Extend != nil && a.Amount == nil {
+		return nil, fmt.Errorf("lsl needs a shift amount")
+	}
+	var s uint32
+	if a.Amount != nil {
+		if *a.Amount != 0 && *a.Amount != Immediate(scale) {
+			return nil, fmt.Errorf("offset register can only be shifted by 0 or %d, not %d", scale, *a.Amount)
+		}
+		// A shift of 0 is only encoded in S for byte transfers, where it is the same as scale.
+		if *a.Amount == Immediate(scale) {
+			s = 1
+		}
+	}
+	return []opcode.Instruction{&opcode.LoadStoreRegisterOffset{Size: size, V: v, Opc: opc, Rm: rm, Option: opt, S: s, Rn: rn, Rt: rt}}, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// Logical is AND, ORR, EOR, ANDS, BIC, ORN, EON or BICS with either a bitmask immediate or a
+// shifted register.  The immediate forms of BIC, ORN, EON and BICS are written as the immediate
+// forms of AND, ORR, EOR and ANDS with the immediate inverted.
+type Logical struct {
+	Mnemonic string           `@("and" | "orr" | "eor" | "ands" | "bic" | "orn" | "eon" | "bics")`
+	Rd       GeneralRegister  `@RegisterGeneral ","`
+	Rn       GeneralRegister  `@RegisterGeneral ","`
+	Imm      *Immediate       `( "#"? @Integer`
+	Rm       *GeneralRegister `| @RegisterGeneral`
+	Dir      *string          `  ("," @Shift`
+	Amt      *Immediate       `   "#"? @Integer)? )`
+}
+
+// logicalOpcs maps each mnemonic to its opc and N fields.
+var logicalOpcs = map[string][2]uint32{
+	"and":  {0b00, 0},
+	"bic":  {0b00, 1},
+	"orr":  {0b01, 0},
+	"orn":  {0b01, 1},
+	"eor":  {0b10, 0},
+	"eon":  {0b10, 1},
+	"ands": {0b11, 0},
+	"bics": {0b11, 1},
+}
+
+func (i *Logical) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	opc, n := logicalOpcs[mnemonic][0], logicalOpcs[mnemonic][1]
+	if i.Imm == nil {
+		sf, regs, err := generalOperands(i.Rd, i.Rn, *i.Rm)
+		if err != nil {
+			return nil, err
+		}
+		op, err := shiftedRegisterOperand(sf, i.Dir, i.Amt, true)
+		if err != nil {
+			return nil, err
+		}
+		return []opcode.Instruction{logicalShiftedRegister(opc, n, sf, op, regs[2], regs[1], regs[0])}, nil
+	}
+
+	// Only the instructions that do not set flags can write to the stack pointer.
+	var rd uint32
+	var err error
+	if opc == 0b11 {
+		rd, err = i.Rd.zr()
+	} else {
+		rd, err = i.Rd.sp()
+	}
+	if err != nil {
+		return nil, err
+	}
+	rn, err := i.Rn.zr()
+	if err != nil {
+		return nil, err
+	}
+	if i.Rd.Sf != i.Rn.Sf {
+		return nil, fmt.Errorf("%v and %v are not the same width", i.Rd, i.Rn)
+	}
+	sf := i.Rd.Sf
+	value, err := bitmaskOperand(sf, *i.Imm)
+	if err != nil {
+		return nil, err
+	}
+	if n == 1 {
+		value = ^value
+		if sf == 0 {
+			value = uint64(uint32(value))
+		}
+	}
+	immN, immr, imms, ok := opcode.EncodeBitmaskImmediate(value, 32<<sf)
+	if !ok {
+		if n == 1 {
+			return nil, fmt.Errorf("%s needs the inverse of its immediate, 0x%x, to be a bitmask immediate: a repeating 2, 4, 8, 16, 32 or 64-bit element containing a single rotated run of ones", mnemonic, value)
+		}
+		return nil, fmt.Errorf("0x%x is not a bitmask immediate: a repeating 2, 4, 8, 16, 32 or 64-bit element containing a single rotated run of ones", value)
+	}
+	switch opc {
+	case 0b00:
+		return []opcode.Instruction{&opcode.AndImmediate{Sf: sf, N: immN, Immr: immr, Imms: imms, Rn: rn, Rd: rd}}, nil
+	case 0b01:
+		return []opcode.Instruction{&opcode.OrrImmediate{Sf: sf, N: immN, Immr: immr, Imms: imms, Rn: rn, Rd: rd}}, nil
+	case 0b10:
+		return []opcode.Instruction{&opcode.EorImmediate{Sf: sf, N: immN, Immr: immr, Imms: imms, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.AndsImmediate{Sf: sf, N: immN, Immr: immr, Imms: imms, Rn: rn, Rd: rd}}, nil
+}
+
+// bitmaskOperand returns imm as the operand of a 32 or 64-bit logical instruction.  A 32-bit
+// immediate may be written as either a signed or unsigned number.
+func bitmaskOperand(sf uint32, imm Immediate) (uint64, error) {
+	if sf == 1 {
+		return uint64(imm), nil
+	}
+	if imm < math.MinInt32 || imm > math.MaxUint32 {
+		return 0, fmt.Errorf("immediate %d does not fit in 32 bits", imm)
+	}
+	return uint64(uint32(imm)), nil
+}
+
+// logicalShiftedRegister returns the logical shifted register instruction selected by opc and n.
+func logicalShiftedRegister(opc, n, sf uint32, op shiftOperand, rm, rn, rd uint32) opcode.Instruction {
+	switch opc<<1 | n {
+	case 0b000:
+		return &opcode.AndShiftedRegister{Sf: sf, Shift: op.shift, Rm: rm, Imm: op.amount, Rn: rn, Rd: rd}
+	case 0b001:
+		return &opcode.BicShiftedRegister{Sf: sf, Shift: op.shift, Rm: rm, Imm: op.amount, Rn: rn, Rd: rd}
+	case 0b010:
+		return &opcode.OrrShiftedRegister{Sf: sf, Shift: op.shift, Rm: rm, Imm: op.amount, Rn: rn, Rd: rd}
+	case 0b011:
+		return &opcode.OrnShiftedRegister{Sf: sf, Shift: op.shift, Rm: rm, Imm: op.amount, Rn: rn, Rd: rd}
+	case 0b100:
+		return &opcode.EorShiftedRegister{Sf: sf, Shift: op.shift, Rm: rm, Imm: op.amount, Rn: rn, Rd: rd}
+	case 0b101:
+		return &opcode.EonShiftedRegister{Sf: sf, Shift: op.shift, Rm: rm, Imm: op.amount, Rn: rn, Rd: rd}
+	case 0b110:
+		return &opcode.AndsShiftedRegister{Sf: sf, Shift: op.shift, Rm: rm, Imm: op.amount, Rn: rn, Rd: rd}
+	}
+	return &opcode.BicsShiftedRegister{Sf: sf, Shift: op.shift, Rm: rm, Imm: op.amount, Rn: rn, Rd: rd}
+}
+
+// Tst is ANDS with the result discarded.
+type Tst struct {
+	Rn  GeneralRegister  `"tst" @RegisterGeneral ","`
+	Imm *Immediate       `( "#"? @Integer`
+	Rm  *GeneralRegister `| @RegisterGeneral`
+	Dir *string          `  ("," @Shift`
+	Amt *Immediate       `   "#"? @Integer)? )`
+}
+
+func (i *Tst) Validate() ([]opcode.Instruction, error) {
+	zr := GeneralRegister{Sf: i.Rn.Sf, N: 31}
+	return (&Logical{Mnemonic: "ands", Rd: zr, Rn: i.Rn, Imm: i.Imm, Rm: i.Rm, Dir: i.Dir, Amt: i.Amt}).Validate()
+}
+
+// Mvn is ORN from the zero register.
+type Mvn struct {
+	Rd  GeneralRegister `"mvn" @RegisterGeneral ","`
+	Rm  GeneralRegister `@RegisterGeneral`
+	Dir *string         `("," @Shift`
+	Amt *Immediate      ` "#"? @Integer)?`
+}
+
+func (i *Mvn) Validate() ([]opcode.Instruction, error) {
+	zr := GeneralRegister{Sf: i.Rd.Sf, N: 31}
+	return (&Logical{Mnemonic: "orn", Rd: i.Rd, Rn: zr, Rm: &i.Rm, Dir: i.Dir, Amt: i.Amt}).Validate()
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// MoveWide is MOVZ, MOVN or MOVK with an optional shift of the immediate.
+type MoveWide struct {
+	Mnemonic string          `@("movz" | "movn" | "movk")`
+	Rd       GeneralRegister `@RegisterGeneral ","`
+	Imm      Immediate       `"#"? @Integer`
+	Shift    *Immediate      `("," "lsl" "#"? @Integer)?`
+}
+
+func (i *MoveWide) Validate() ([]opcode.Instruction, error) {
+	rd, err := i.Rd.zr()
+	if err != nil {
+		return nil, err
+	}
+	sf := i.Rd.Sf
+	if i.Imm < 0 || i.Imm > 0xffff {
+		return nil, fmt.Errorf("immediate %d is out of range [0, 65535]", i.Imm)
+	}
+	var hw uint32
+	if i.Shift != nil {
+		if *i.Shift < 0 || *i.Shift >= 32<<sf || *i.Shift%16 != 0 {
+			return nil, fmt.Errorf("immediate can only be shifted left by a multiple of 16 less than %d, not %d", 32<<sf, *i.Shift)
+		}
+		hw = uint32(*i.Shift / 16)
+	}
+	imm := uint32(i.Imm)
+	switch strings.ToLower(i.Mnemonic) {
+	case "movz":
+		return []opcode.Instruction{&opcode.Movz{Sf: sf, Hw: hw, Imm: imm, Rd: rd}}, nil
+	case "movn":
+		return []opcode.Instruction{&opcode.Movn{Sf: sf, Hw: hw, Imm: imm, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.Movk{Sf: sf, Hw: hw, Imm: imm, Rd: rd}}, nil
+}
+
+type Mov struct {
+	MovImmediate *MovImmediate `"mov" (@@ |`
+	MovRegister  *MovRegister  `        @@ )`
+}
+
+func (i *Mov) Validate() ([]opcode.Instruction, error) {
+	if i.MovImmediate != nil {
+		return i.MovImmediate.Validate()
+	}
+	return i.MovRegister.Validate()
+}
+
+// MovRegister copies one general purpose register to another, using ADD if either is the stack
+// pointer and ORR otherwise.
+type MovRegister struct {
+	Rd GeneralRegister `@RegisterGeneral ","`
+	Rm GeneralRegister `@RegisterGeneral`
+}
+
+func (i *MovRegister) Validate() ([]opcode.Instruction, error) {
+	if i.Rd.SP || i.Rm.SP {
+		return (&Addx{Mnemonic: "add", AddImmediate: &AddImmediate{Rd: i.Rd, Rn: i.Rm}}).Validate()
+	}
+	zr := GeneralRegister{Sf: i.Rd.Sf, N: 31}
+	return (&Logical{Mnemonic: "orr", Rd: i.Rd, Rn: zr, Rm: &i.Rm}).Validate()
+}
+
+// MovImmediate is the MOV pseudo-instruction, which writes any constant to a register using as
+// few instructions as possible.
+type MovImmediate struct {
+	Rd  GeneralRegister `@RegisterGeneral ","`
+	Imm Immediate       `"#"? @Integer`
+}
+
+func (i *MovImmediate) Validate() ([]opcode.Instruction, error) {
+	sf := i.Rd.Sf
+	value := uint64(i.Imm)
+	if sf == 0 {
+		if i.Imm < math.MinInt32 || i.Imm > math.MaxUint32 {
+			return nil, fmt.Errorf("immediate %d does not fit in %v", i.Imm, i.Rd)
+		}
+		value = uint64(uint32(value))
+	}
+
+	if i.Rd.SP {
+		// Only ORR can write to the stack pointer.
+		n, immr, imms, ok := opcode.EncodeBitmaskImmediate(value, 32<<sf)
+		if !ok {
+			return nil, fmt.Errorf("%v can only be set to a bitmask immediate, which 0x%x is not", i.Rd, value)
+		}
+		return []opcode.Instruction{&opcode.OrrImmediate{Sf: sf, N: n, Immr: immr, Imms: imms, Rn: 31, Rd: 31}}, nil
+	}
+
+	// Like AddImmediate, prefer the simplest form that works: MOVZ, then MOVN, then ORR, each
+	// followed by as many MOVKs as are needed to fix up the halfwords they got wrong.
+	best := moveWideSequence(sf, i.Rd.N, value, false)
+	if insts := moveWideSequence(sf, i.Rd.N, value, true); len(insts) < len(best) {
+		best = insts
+	}
+	if insts := bitmaskSequence(sf, i.Rd.N, value); insts != nil && len(insts) < len(best) {
+		best = insts
+	}
+	return best, nil
+}
+
+// moveWideSequence returns a MOVZ, or a MOVN if inverted is set, followed by a MOVK for each
+// halfword of value that the first instruction does not write correctly.
+func moveWideSequence(sf, rd uint32, value uint64, inverted bool) []opcode.Instruction {
+	fill := uint32(0)
+	if inverted {
+		fill = 0xffff
+	}
+	var insts []opcode.Instruction
+	for hw := uint32(0); hw < 2<<sf; hw++ {
+		chunk := uint32(value>>(16*hw)) & 0xffff
+		switch {
+		case chunk == fill:
+		case len(insts) > 0:
+			insts = append(insts, &opcode.Movk{Sf: sf, Hw: hw, Imm: chunk, Rd: rd})
+		case inverted:
+			insts = append(insts, &opcode.Movn{Sf: sf, Hw: hw, Imm: ^chunk & 0xffff, Rd: rd})
+		default:
+			insts = append(insts, &opcode.Movz{Sf: sf, Hw: hw, Imm: chunk, Rd: rd})
+		}
+	}
+	if len(insts) > 0 {
+		return insts
+	}
+	if inverted {
+		return []opcode.Instruction{&opcode.Movn{Sf: sf, Rd: rd}}
+	}
+	return []opcode.Instruction{&opcode.Movz{Sf: sf, Rd: rd}}
+}
+
+// bitmaskSequence returns an ORR of the bitmask immediate that has the most halfwords in common
+// with value, followed by a MOVK for each halfword that differs, or nil if no bitmask immediate has
+// any halfwords in common with value.
+func bitmaskSequence(sf, rd uint32, value uint64) []opcode.Instruction {
+	width := uint32(32) << sf
+	var best uint64
+	bestDiffs := int(width/16) + 1
+	for n := uint32(0); n <= sf; n++ {
+		for immr := uint32(0); immr < width; immr++ {
+			for imms := uint32(0); imms < 64; imms++ {
+				b, ok := opcode.DecodeBitmaskImmediate(n, immr, imms, width)
+				if !ok {
+					continue
+				}
+				if diffs := len(differentHalfwords(width, b, value)); diffs < bestDiffs {
+					best, bestDiffs = b, diffs
+				}
+			}
+		}
+	}
+	if bestDiffs >= int(width/16) {
+		return nil
+	}
+	n, immr, imms, _ := opcode.EncodeBitmaskImmediate(best, width)
+	insts := []opcode.Instruction{&opcode.OrrImmediate{Sf: sf, N: n, Immr: immr, Imms: imms, Rn: 31, Rd: rd}}
+	for _, hw := range differentHalfwords(width, best, value) {
+		insts = append(insts, &opcode.Movk{Sf: sf, Hw: hw, Imm: uint32(value>>(16*hw)) & 0xffff, Rd: rd})
+	}
+	return insts
+}
+
+// differentHalfwords returns the index of each of the width/16 halfwords in which a and b differ.
+func differentHalfwords(width uint32, a, b uint64) []uint32 {
+	var hws []uint32
+	for hw := uint32(0); hw < width/16; hw++ {
+		if (a^b)>>(16*hw)&0xffff != 0 {
+			hws = append(hws, hw)
+		}
+	}
+	return hws
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// multiplyAliases maps each multiply alias to the multiply-add instruction it is written as, with
+// the zero register as the accumulator.
+var multiplyAliases = map[string]string{
+	"mul":    "madd",
+	"mneg":   "msub",
+	"smull":  "smaddl",
+	"smnegl": "smsubl",
+	"umull":  "umaddl",
+	"umnegl": "umsubl",
+}
+
+// Multiply is one of the data processing (3 source) instructions, MADD, MSUB, SMADDL, SMSUBL,
+// UMADDL, UMSUBL, SMULH and UMULH, or one of the aliases that leave out the accumulator.
+type Multiply struct {
+	Mnemonic string           `@("madd" | "msub" | "smaddl" | "smsubl" | "umaddl" | "umsubl" | "smulh" | "umulh" | "mul" | "mneg" | "smull" | "smnegl" | "umull" | "umnegl")`
+	Rd       GeneralRegister  `@RegisterGeneral ","`
+	Rn       GeneralRegister  `@RegisterGeneral ","`
+	Rm       GeneralRegister  `@RegisterGeneral`
+	Ra       *GeneralRegister `("," @RegisterGeneral)?`
+}
+
+func (i *Multiply) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	base, alias := multiplyAliases[mnemonic]
+	high := mnemonic == "smulh" || mnemonic == "umulh"
+	var ra GeneralRegister
+	switch {
+	case (alias || high) && i.Ra != nil:
+		return nil, fmt.Errorf("%s does not take an accumulator", mnemonic)
+	case alias:
+		mnemonic, ra = base, GeneralRegister{Sf: i.Rd.Sf, N: 31}
+	case !high && i.Ra == nil:
+		return nil, fmt.Errorf("%s needs an accumulator", mnemonic)
+	case !high:
+		ra = *i.Ra
+	}
+
+	switch mnemonic {
+	case "madd", "msub":
+		sf, regs, err := generalOperands(i.Rd, i.Rn, i.Rm, ra)
+		if err != nil {
+			return nil, err
+		}
+		if mnemonic == "madd" {
+			return []opcode.Instruction{&opcode.Madd{Sf: sf, Rm: regs[2], Ra: regs[3], Rn: regs[1], Rd: regs[0]}}, nil
+		}
+		return []opcode.Instruction{&opcode.Msub{Sf: sf, Rm: regs[2], Ra: regs[3], Rn: regs[1], Rd: regs[0]}}, nil
+	case "smulh", "umulh":
+		sf, regs, err := generalOperands(i.Rd, i.Rn, i.Rm)
+		if err != nil {
+			return nil, err
+		}
+		if sf != 1 {
+			return nil, fmt.Errorf("%s multiplies x registers, not %v", mnemonic, i.Rd)
+		}
+		if mnemonic == "smulh" {
+			return []opcode.Instruction{&opcode.Smulh{Rm: regs[2], Ra: 31, Rn: regs[1], Rd: regs[0]}}, nil
+		}
+		return []opcode.Instruction{&opcode.Umulh{Rm: regs[2], Ra: 31, Rn: regs[1], Rd: regs[0]}}, nil
+	}
+
+	// The long multiplies take w registers and accumulate into x registers.
+	sf, acc, err := generalOperands(i.Rd, ra)
+	if err != nil {
+		return nil, err
+	}
+	if sf != 1 {
+		return nil, fmt.Errorf("%s writes an x register, not %v", mnemonic, i.Rd)
+	}
+	nsf, src, err := generalOperands(i.Rn, i.Rm)
+	if err != nil {
+		return nil, err
+	}
+	if nsf != 0 {
+		return nil, fmt.Errorf("%s multiplies w registers, not %v", mnemonic, i.Rn)
+	}
+	switch mnemonic {
+	case "smaddl":
+		return []opcode.Instruction{&opcode.Smaddl{Rm: src[1], Ra: acc[1], Rn: src[0], Rd: acc[0]}}, nil
+	case "smsubl":
+		return []opcode.Instruction{&opcode.Smsubl{Rm: src[1], Ra: acc[1], Rn: src[0], Rd: acc[0]}}, nil
+	case "umaddl":
+		return []opcode.Instruction{&opcode.Umaddl{Rm: src[1], Ra: acc[1], Rn: src[0], Rd: acc[0]}}, nil
+	}
+	return []opcode.Instruction{&opcode.Umsubl{Rm: src[1], Ra: acc[1], Rn: src[0], Rd: acc[0]}}, nil
+}
+
+// TwoSource is UDIV, SDIV, LSLV, LSRV, ASRV or RORV.
+type TwoSource struct {
+	Mnemonic string          `@("udiv" | "sdiv" | "lslv" | "lsrv" | "asrv" | "rorv")`
+	Rd       GeneralRegister `@RegisterGeneral ","`
+	Rn       GeneralRegister `@RegisterGeneral ","`
+	Rm       GeneralRegister `@RegisterGeneral`
+}
+
+func (i *TwoSource) Validate() ([]opcode.Instruction, error) {
+	sf, regs, err := generalOperands(i.Rd, i.Rn, i.Rm)
+	if err != nil {
+		return nil, err
+	}
+	rd, rn, rm := regs[0], regs[1], regs[2]
+	switch strings.ToLower(i.Mnemonic) {
+	case "udiv":
+		return []opcode.Instruction{&opcode.Udiv{Sf: sf, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "sdiv":
+		return []opcode.Instruction{&opcode.Sdiv{Sf: sf, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "lslv":
+		return []opcode.Instruction{&opcode.Lslv{Sf: sf, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "lsrv":
+		return []opcode.Instruction{&opcode.Lsrv{Sf: sf, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "asrv":
+		return []opcode.Instruction{&opcode.Asrv{Sf: sf, Rm: rm, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.Rorv{Sf: sf, Rm: rm, Rn: rn, Rd: rd}}, nil
+}
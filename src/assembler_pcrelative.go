@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// Adr is ADR or ADRP with a label or an offset in bytes from the instruction.  The offset of ADRP
+// is from the start of the instruction's 4KB page, and must be a whole number of pages.
+type Adr struct {
+	Mnemonic string          `@("adr" | "adrp")`
+	Rd       GeneralRegister `@RegisterGeneral ","`
+	Offset   Immediate       `( "#" @Integer`
+	Label    *string         `| @Ident )`
+}
+
+func (i *Adr) resolve(labels map[string]uint64, pc uint64) error {
+	if i.Label == nil {
+		return nil
+	}
+	addr, err := lookupLabel(labels, *i.Label)
+	if err != nil {
+		return err
+	}
+	if strings.ToLower(i.Mnemonic) == "adrp" {
+		addr, pc = addr&^0xfff, pc&^0xfff
+	}
+	i.Offset = Immediate(addr - pc)
+	return nil
+}
+
+func (i *Adr) Validate() ([]opcode.Instruction, error) {
+	_, regs, err := generalOperands(i.Rd)
+	if err != nil {
+		return nil, err
+	}
+	if i.Rd.Sf != 1 {
+		return nil, fmt.Errorf("%s writes an x register, not %v", strings.ToLower(i.Mnemonic), i.Rd)
+	}
+	if strings.ToLower(i.Mnemonic) == "adr" {
+		if i.Offset < -1<<20 || i.Offset >= 1<<20 {
+			return nil, fmt.Errorf("offset %d is out of range [%d, %d]", i.Offset, -1<<20, 1<<20-1)
+		}
+		imm := uint32(i.Offset) & (1<<21 - 1)
+		return []opcode.Instruction{&opcode.Adr{Immlo: imm & 0b11, Immhi: imm >> 2, Rd: regs[0]}}, nil
+	}
+	if i.Offset&0xfff != 0 {
+		return nil, fmt.Errorf("offset %d is not a multiple of 4096", i.Offset)
+	}
+	if i.Offset < -1<<32 || i.Offset >= 1<<32 {
+		return nil, fmt.Errorf("offset %d is out of range [%d, %d]", i.Offset, int64(-1<<32), int64(1<<32-1<<12))
+	}
+	imm := uint32(i.Offset>>12) & (1<<21 - 1)
+	return []opcode.Instruction{&opcode.Adrp{Immlo: imm & 0b11, Immhi: imm >> 2, Rd: regs[0]}}, nil
+}
@@ -1,28 +1,33 @@
 package main
 
 import (
+	"context"
+	"strings"
 	"testing"
+
+	"github.com/runningwild/javelin/machine"
+	"github.com/runningwild/javelin/opcode"
 )
 
 func TestParseInstruction(t *testing.T) {
 	// Test ADD
-	addAsm := "add r7, r2, r4"
+	addAsm := "add x7, x2, x4"
 	addInst, err := ParseInstruction(addAsm)
 	if err != nil {
 		t.Fatalf("Failed to parse ADD: %v", err)
 	}
-	if expected, ok := addInst.(*ADDInstruction); !ok {
-		t.Errorf("ADD parsed as a %T, want %T", addInst, expected)
+	if expected, ok := addInst.(*Addx); !ok || addInst.(*Addx).AddShiftedRegister == nil {
+		t.Errorf("ADD parsed as a %T, want %T with a shifted register", addInst, expected)
 	}
 
-	// Test VADD.I32
-	vaddAsm := "VADD.I32 D4, D0, D2"
+	// Test vector ADD
+	vaddAsm := "ADD V4.2D, V0.2D, V2.2D"
 	vaddInst, err := ParseInstruction(vaddAsm)
 	if err != nil {
-		t.Fatalf("Failed to parse VADD.I32: %v", err)
+		t.Fatalf("Failed to parse vector ADD: %v", err)
 	}
-	if expected, ok := addInst.(*ADDInstruction); !ok {
-		t.Errorf("VADD parsed as a %T, want %T", vaddInst, expected)
+	if expected, ok := vaddInst.(*Addx); !ok || vaddInst.(*Addx).AddVector == nil {
+		t.Errorf("vector ADD parsed as a %T, want %T with a vector", vaddInst, expected)
 	}
 
 	// Test invalid instruction
@@ -33,9 +38,997 @@ func TestParseInstruction(t *testing.T) {
 	}
 
 	// Test invalid register
-	invalidRegAsm := "ADD R33, R1, R2"
+	invalidRegAsm := "ADD X33, X1, X2"
 	_, err = ParseInstruction(invalidRegAsm)
 	if err == nil {
 		t.Errorf("Expected error for invalid register, got nil")
 	}
 }
+
+func TestAssemble(t *testing.T) {
+	for _, tc := range []struct {
+		asm  string
+		want []uint32
+	}{
+		{"add x2, x3, x5", []uint32{0x8b050062}},
+		{"add w1, w2, w3, asr #31", []uint32{0x0b837c41}},
+		{"add w2, w3, #5", []uint32{0x11001462}},
+		{"add sp, x1, #4095, lsl #12", []uint32{0x917ffc3f}},
+		{"add x0, x1, #0x1000", []uint32{0x91400420}},
+		{"add x0, sp, w1, uxtw #2", []uint32{0x8b214be0}},
+		{"add x1, x2, x3, sxtx", []uint32{0x8b23e041}},
+		{"add x0, sp, x1", []uint32{0x8b2163e0}},
+		{"add sp, sp, x1, lsl #3", []uint32{0x8b216fff}},
+		{"add w0, wsp, w1", []uint32{0x0b2143e0}},
+		{"add v0.4s, v1.4s, v2.4s", []uint32{0x4ea28420}},
+		{"adc x0, x1, x2", []uint32{0x9a020020}},
+		{"adcs w0, w1, w2", []uint32{0x3a020020}},
+		{"sbc x0, x1, x2", []uint32{0xda020020}},
+		{"sbcs w0, w1, w2", []uint32{0x7a020020}},
+		{"ngc x0, x1", []uint32{0xda0103e0}},
+		{"ngcs w0, w1", []uint32{0x7a0103e0}},
+		{"ADD X0, X1, #-0", []uint32{0x91000020}},
+		{"sub x0, x1, x2", []uint32{0xcb020020}},
+		{"sub w0, w1, w2, lsl #3", []uint32{0x4b020c20}},
+		{"subs x0, x1, #4095", []uint32{0xf13ffc20}},
+		{"adds w0, w1, #1, lsl #12", []uint32{0x31400420}},
+		{"adds x0, sp, x1", []uint32{0xab2163e0}},
+		{"sub sp, sp, #16", []uint32{0xd10043ff}},
+		{"sub x0, sp, w1, uxtw #2", []uint32{0xcb214be0}},
+		{"subs x0, x1, x2, asr #63", []uint32{0xeb82fc20}},
+		{"adds x0, x1, w2, sxtw", []uint32{0xab22c020}},
+		{"add x0, x1, #-16", []uint32{0xd1004020}},
+		{"sub x0, x1, #-1, lsl #12", []uint32{0x91400420}},
+		{"sub v0.4s, v1.4s, v2.4s", []uint32{0x6ea28420}},
+		{"cmp x0, #1", []uint32{0xf100041f}},
+		{"cmp x0, #1, lsl #12", []uint32{0xf140041f}},
+		{"cmp x0, #-1", []uint32{0xb100041f}},
+		{"cmp w0, w1", []uint32{0x6b01001f}},
+		{"cmp x0, x1, lsl #4", []uint32{0xeb01101f}},
+		{"cmp sp, x1", []uint32{0xeb2163ff}},
+		{"cmp x0, w1, uxtb #1", []uint32{0xeb21041f}},
+		{"cmn x0, #1", []uint32{0xb100041f}},
+		{"cmn w0, w1, asr #2", []uint32{0x2b81081f}},
+		{"CMN SP, W1, SXTW", []uint32{0xab21c3ff}},
+		{"neg x0, x1", []uint32{0xcb0103e0}},
+		{"neg w0, w1, lsl #2", []uint32{0x4b010be0}},
+		{"negs x0, x1, asr #1", []uint32{0xeb8107e0}},
+		{"ldr x0, [x1]", []uint32{0xf9400020}},
+		{"ldr w0, [sp, #16380]", []uint32{0xb97fffe0}},
+		{"str x0, [x1, #32760]", []uint32{0xf93ffc20}},
+		{"ldrb w0, [x1, #4095]", []uint32{0x397ffc20}},
+		{"strh w0, [x1, #2]", []uint32{0x79000420}},
+		{"ldrsb x0, [x1]", []uint32{0x39800020}},
+		{"ldrsh w0, [x1, #2]", []uint32{0x79c00420}},
+		{"ldrsw x0, [x1, #4]", []uint32{0xb9800420}},
+		{"ldr b0, [x1, #1]", []uint32{0x3d400420}},
+		{"str q0, [sp, #65520]", []uint32{0x3dbfffe0}},
+		{"ldr x0, [x1, #-8]", []uint32{0xf85f8020}},
+		{"ldr w0, [x1, #3]", []uint32{0xb8403020}},
+		{"ldur x0, [x1, #8]", []uint32{0xf8408020}},
+		{"sturh w0, [x1, #-2]", []uint32{0x781fe020}},
+		{"ldursb w0, [x1, #255]", []uint32{0x38cff020}},
+		{"ldr x0, [x1, #8]!", []uint32{0xf8408c20}},
+		{"str w0, [sp, #-16]!", []uint32{0xb81f0fe0}},
+		{"ldrb w0, [x1], #1", []uint32{0x38401420}},
+		{"ldr d0, [x1], #-256", []uint32{0xfc500420}},
+		{"ldrsw x0, [x1, #4]!", []uint32{0xb8804c20}},
+		{"ldr x0, [x1, x2]", []uint32{0xf8626820}},
+		{"ldr x0, [x1, x2, lsl #3]", []uint32{0xf8627820}},
+		{"ldr x0, [x1, x2, lsl #0]", []uint32{0xf8626820}},
+		{"ldr w0, [x1, w2, uxtw #2]", []uint32{0xb8625820}},
+		{"str x0, [sp, w2, sxtw]", []uint32{0xf822cbe0}},
+		{"ldrb w0, [x1, x2, lsl #0]", []uint32{0x38627820}},
+		{"ldrsh x0, [x1, x2, sxtx #1]", []uint32{0x78a2f820}},
+		{"ldr q0, [x1, x2, lsl #4]", []uint32{0x3ce27820}},
+		{"str xzr, [x1, xzr]", []uint32{0xf83f683f}},
+		{"ldr x0, #8", []uint32{0x58000040}},
+		{"ldr w1, #-4", []uint32{0x18ffffe1}},
+		{"ldrsw x0, #1048572", []uint32{0x987fffe0}},
+		{"ldr q1, #16", []uint32{0x9c000081}},
+		{"ldr s2, #-1048576", []uint32{0x1c800002}},
+		{"ldp x29, x30, [sp], #16", []uint32{0xa8c17bfd}},
+		{"stp x29, x30, [sp, #-16]!", []uint32{0xa9bf7bfd}},
+		{"ldpsw x0, x1, [x2, #8]", []uint32{0x69410440}},
+		{"stp q0, q1, [sp, #1008]", []uint32{0xad1f87e0}},
+		{"ldp s0, s1, [x1], #4", []uint32{0x2cc08420}},
+		{"ldp w0, w1, [x0]", []uint32{0x29400400}},
+		{"stp d8, d9, [sp, #-64]!", []uint32{0x6dbc27e8}},
+		{"movz x0, #0x1234, lsl #16", []uint32{0xd2a24680}},
+		{"movn w1, #0", []uint32{0x12800001}},
+		{"movk x2, #0xbeef, lsl #32", []uint32{0xf2d7dde2}},
+		{"movk w2, #1, lsl #16", []uint32{0x72a00022}},
+		{"movz x0, #5", []uint32{0xd28000a0}},
+		{"mov x0, #0", []uint32{0xd2800000}},
+		{"mov x0, #0xffff0000", []uint32{0xd2bfffe0}},
+		{"mov x0, #0xffffffffffff1234", []uint32{0x929db960}},
+		{"mov x0, #-1", []uint32{0x92800000}},
+		{"mov w0, #-2", []uint32{0x12800020}},
+		{"mov w3, #0xffff", []uint32{0x529fffe3}},
+		{"mov x0, #0x5555555555555555", []uint32{0xb200f3e0}},
+		{"mov w0, #0xff00ff", []uint32{0x32009fe0}},
+		{"mov sp, #0xff", []uint32{0xb2401fff}},
+		{"and x0, x1, #0xff", []uint32{0x92401c20}},
+		{"and sp, x1, #0xff", []uint32{0x92401c3f}},
+		{"ands w0, w1, #1", []uint32{0x72000020}},
+		{"tst x1, #0xff", []uint32{0xf2401c3f}},
+		{"eor w0, w1, #0x80000000", []uint32{0x52010020}},
+		{"and w0, w1, #-2", []uint32{0x121f7820}},
+		{"bic x0, x1, #0xff", []uint32{0x9278dc20}},
+		{"bic w0, w1, #0xffff0000", []uint32{0x12003c20}},
+		{"orn x0, x1, #1", []uint32{0xb27ff820}},
+		{"eon w0, w1, #1", []uint32{0x521f7820}},
+		{"bics x0, x1, #1", []uint32{0xf27ff820}},
+		{"and x0, x1, x2, ror #3", []uint32{0x8ac20c20}},
+		{"bic w0, w1, w2, lsr #0", []uint32{0x0a620020}},
+		{"orr x0, x1, x2", []uint32{0xaa020020}},
+		{"orn w0, wzr, w2, asr #2", []uint32{0x2aa20be0}},
+		{"eon x0, x1, x2", []uint32{0xca220020}},
+		{"ands x0, x1, x2", []uint32{0xea020020}},
+		{"tst x1, x2, lsl #4", []uint32{0xea02103f}},
+		{"bics xzr, x1, x2", []uint32{0xea22003f}},
+		{"mvn x0, x1", []uint32{0xaa2103e0}},
+		{"mov x0, x1", []uint32{0xaa0103e0}},
+		{"mov w0, w1", []uint32{0x2a0103e0}},
+		{"mov xzr, x3", []uint32{0xaa0303ff}},
+		{"mov sp, x1", []uint32{0x9100003f}},
+		{"mov x1, sp", []uint32{0x910003e1}},
+		{"mov wsp, w1", []uint32{0x1100003f}},
+		{"asr x0, x1, #3", []uint32{0x9343fc20}},
+		{"asr w0, w1, #31", []uint32{0x131f7c20}},
+		{"lsl x0, x1, #4", []uint32{0xd37cec20}},
+		{"lsl w0, w1, #31", []uint32{0x53010020}},
+		{"LSL X0, X1, #0", []uint32{0xd340fc20}},
+		{"lsr x0, x1, #63", []uint32{0xd37ffc20}},
+		{"ror w0, w1, #5", []uint32{0x13811420}},
+		{"sbfiz x0, x1, #4, #8", []uint32{0x937c1c20}},
+		{"sbfx w0, w1, #4, #8", []uint32{0x13042c20}},
+		{"ubfiz w0, w1, #3, #4", []uint32{0x531d0c20}},
+		{"ubfx x0, x1, #8, #8", []uint32{0xd3483c20}},
+		{"bfi w0, w1, #8, #4", []uint32{0x33180c20}},
+		{"bfxil x0, x1, #0, #64", []uint32{0xb340fc20}},
+		{"bfc x0, #8, #4", []uint32{0xb3780fe0}},
+		{"sbfm x0, x1, #0, #0", []uint32{0x93400020}},
+		{"ubfm w0, w1, #0, #0", []uint32{0x53000020}},
+		{"bfm w0, w1, #0, #31", []uint32{0x33007c20}},
+		{"sxtb x0, w1", []uint32{0x93401c20}},
+		{"sxth w0, w1", []uint32{0x13003c20}},
+		{"sxtw x0, w1", []uint32{0x93407c20}},
+		{"uxtb w0, w1", []uint32{0x53001c20}},
+		{"uxth w0, w1", []uint32{0x53003c20}},
+		{"extr x0, x1, x2, #7", []uint32{0x93c21c20}},
+		{"madd x0, x1, x2, x3", []uint32{0x9b020c20}},
+		{"msub w0, w1, w2, w3", []uint32{0x1b028c20}},
+		{"mul x0, x1, x2", []uint32{0x9b027c20}},
+		{"mneg w0, w1, w2", []uint32{0x1b02fc20}},
+		{"smaddl x0, w1, w2, x3", []uint32{0x9b220c20}},
+		{"smsubl x0, w1, w2, x3", []uint32{0x9b228c20}},
+		{"umaddl x0, w1, w2, x3", []uint32{0x9ba20c20}},
+		{"umsubl x0, w1, w2, x3", []uint32{0x9ba28c20}},
+		{"smull x0, w1, w2", []uint32{0x9b227c20}},
+		{"umull x0, w1, w2", []uint32{0x9ba27c20}},
+		{"smnegl x0, w1, w2", []uint32{0x9b22fc20}},
+		{"umnegl x0, w1, w2", []uint32{0x9ba2fc20}},
+		{"smulh x0, x1, x2", []uint32{0x9b427c20}},
+		{"umulh x0, x1, x2", []uint32{0x9bc27c20}},
+		{"udiv w0, w1, w2", []uint32{0x1ac20820}},
+		{"sdiv x0, x1, x2", []uint32{0x9ac20c20}},
+		{"lslv x0, x1, x2", []uint32{0x9ac22020}},
+		{"lsl w0, w1, w2", []uint32{0x1ac22020}},
+		{"lsr x0, x1, x2", []uint32{0x9ac22420}},
+		{"asr x0, x1, x2", []uint32{0x9ac22820}},
+		{"ror w0, w1, w2", []uint32{0x1ac22c20}},
+		{"csel x0, x1, x2, eq", []uint32{0x9a820020}},
+		{"csinc w0, w1, w2, ne", []uint32{0x1a821420}},
+		{"csinv x0, x1, x2, cs", []uint32{0xda822020}},
+		{"csneg w0, w1, w2, LT", []uint32{0x5a82b420}},
+		{"cset x0, eq", []uint32{0x9a9f17e0}},
+		{"csetm w0, cc", []uint32{0x5a9f23e0}},
+		{"cinc x0, x1, gt", []uint32{0x9a81d420}},
+		{"cinv w0, w1, le", []uint32{0x5a81c020}},
+		{"cneg x0, x1, mi", []uint32{0xda815420}},
+		{"ccmp x1, x2, #4, ne", []uint32{0xfa421024}},
+		{"ccmn w1, #31, #15, al", []uint32{0x3a5fe82f}},
+		{"ccmp w1, #0, #0, eq", []uint32{0x7a400820}},
+		{"ccmn x1, x2, #0, nv", []uint32{0xba42f020}},
+		{"rbit w0, w1", []uint32{0x5ac00020}},
+		{"rev16 x0, x1", []uint32{0xdac00420}},
+		{"rev w0, w1", []uint32{0x5ac00820}},
+		{"rev32 x0, x1", []uint32{0xdac00820}},
+		{"rev64 x0, x1", []uint32{0xdac00c20}},
+		{"clz x0, x1", []uint32{0xdac01020}},
+		{"cls w0, w1", []uint32{0x5ac01420}},
+		{"cnt v0.8b, v1.8b", []uint32{0x0e205820}},
+		{"rbit v0.16b, v1.16b", []uint32{0x6e605820}},
+		{"rev16 v0.16b, v1.16b", []uint32{0x4e201820}},
+		{"rev32 v0.8h, v1.8h", []uint32{0x6e600820}},
+		{"rev64 v0.2s, v1.2s", []uint32{0x0ea00820}},
+		{"CLZ V0.4S, V1.4S", []uint32{0x6ea04820}},
+		{"cls v0.8h, v1.8h", []uint32{0x4e604820}},
+		{"ld1 {v0.16b}, [x0]", []uint32{0x4c407000}},
+		{"ld1 {v0.8b, v1.8b}, [x0]", []uint32{0x0c40a000}},
+		{"ld1 {v31.2d, v0.2d, v1.2d, v2.2d}, [x0]", []uint32{0x4c402c1f}},
+		{"ld2 {v0.8h, v1.8h}, [x0], #32", []uint32{0x4cdf8400}},
+		{"ld3 {v0.2s, v1.2s, v2.2s}, [x0], x2", []uint32{0x0cc24800}},
+		{"st1 {v0.1d}, [x0], #8", []uint32{0x0c9f7c00}},
+		{"LD1 {V0.B}[15], [X0]", []uint32{0x4d401c00}},
+		{"ld2 {v0.s, v1.s}[1], [x0], x3", []uint32{0x0de39000}},
+		{"ld4 {v0.d, v1.d, v2.d, v3.d}[1], [x0]", []uint32{0x4d60a400}},
+		{"st3 {v0.b, v1.b, v2.b}[7], [x0], #3", []uint32{0x0d9f3c00}},
+		{"st1 {v0.d}[0], [sp]", []uint32{0x0d0087e0}},
+		{"ld1r {v0.4s}, [x0]", []uint32{0x4d40c800}},
+		{"ld3r {v0.1d, v1.1d, v2.1d}, [x0], x5", []uint32{0x0dc5ec00}},
+		{"sub v0.4s, v1.4s, v2.4s", []uint32{0x6ea28420}},
+		{"mul v0.8h, v1.8h, v2.8h", []uint32{0x4e629c20}},
+		{"mla v0.2s, v1.2s, v2.2s", []uint32{0x0ea29420}},
+		{"MLS V0.16B, V1.16B, V2.16B", []uint32{0x6e229420}},
+		{"abs v0.2d, v1.2d", []uint32{0x4ee0b820}},
+		{"neg v0.4h, v1.4h", []uint32{0x2e60b820}},
+		{"sabd v0.8b, v1.8b, v2.8b", []uint32{0x0e227420}},
+		{"umax v0.16b, v1.16b, v2.16b", []uint32{0x6e226420}},
+		{"smin v0.2s, v1.2s, v2.2s", []uint32{0x0ea26c20}},
+		{"shadd v0.8b, v1.8b, v2.8b", []uint32{0x0e220420}},
+		{"urhadd v0.16b, v1.16b, v2.16b", []uint32{0x6e221420}},
+		{"addp v0.2d, v1.2d, v2.2d", []uint32{0x4ee2bc20}},
+		{"sqadd v0.16b, v1.16b, v2.16b", []uint32{0x4e220c20}},
+		{"uqadd v0.2d, v1.2d, v2.2d", []uint32{0x6ee20c20}},
+		{"sqsub v0.4h, v1.4h, v2.4h", []uint32{0x0e622c20}},
+		{"uqsub v0.4s, v1.4s, v2.4s", []uint32{0x6ea22c20}},
+		{"sqdmulh v0.8h, v1.8h, v2.8h", []uint32{0x4e62b420}},
+		{"sqrdmulh v0.2s, v1.2s, v2.2s", []uint32{0x2ea2b420}},
+		{"sqxtn v0.8b, v1.8h", []uint32{0x0e214820}},
+		{"SQXTN2 V0.8H, V1.4S", []uint32{0x4e614820}},
+		{"uqxtn v0.2s, v1.2d", []uint32{0x2ea14820}},
+		{"saddl v0.8h, v1.8b, v2.8b", []uint32{0x0e220020}},
+		{"uaddl2 v0.4s, v1.8h, v2.8h", []uint32{0x6e620020}},
+		{"saddw v0.2d, v1.2d, v2.2s", []uint32{0x0ea21020}},
+		{"ssubw2 v0.4s, v1.4s, v2.8h", []uint32{0x4e623020}},
+		{"addhn v0.8b, v1.8h, v2.8h", []uint32{0x0e224020}},
+		{"raddhn2 v0.8h, v1.4s, v2.4s", []uint32{0x6e624020}},
+		{"smull v0.8h, v1.8b, v2.8b", []uint32{0x0e22c020}},
+		{"UMULL2 V0.4S, V1.8H, V2.8H", []uint32{0x6e62c020}},
+		{"xtn v0.8b, v1.8h", []uint32{0x0e212820}},
+		{"sqxtun2 v0.16b, v1.8h", []uint32{0x6e212820}},
+		{"shll v0.8h, v1.8b, #8", []uint32{0x2e213820}},
+		{"shll2 v0.2d, v1.4s, #32", []uint32{0x6ea13820}},
+		{"saddlp v0.4h, v1.8b", []uint32{0x0e202820}},
+		{"uadalp v0.8h, v1.16b", []uint32{0x6e206820}},
+		{"addv b0, v1.16b", []uint32{0x4e31b820}},
+		{"addv h0, v1.4h", []uint32{0x0e71b820}},
+		{"saddlv s0, v1.8h", []uint32{0x4e703820}},
+		{"UADDLV D0, V1.4S", []uint32{0x6eb03820}},
+		{"smaxv b0, v1.8b", []uint32{0x0e30a820}},
+		{"uminv b31, v1.16b", []uint32{0x6e31a83f}},
+		{"fmaxnmv s0, v1.4s", []uint32{0x6e30c820}},
+		{"fminnmv s0, v1.4s", []uint32{0x6eb0c820}},
+		{"zip1 v0.16b, v1.16b, v2.16b", []uint32{0x4e023820}},
+		{"TRN2 V0.2S, V1.2S, V2.2S", []uint32{0x0e826820}},
+		{"uzp1 v0.2d, v1.2d, v2.2d", []uint32{0x4ec21820}},
+		{"ext v0.8b, v1.8b, v2.8b, #7", []uint32{0x2e023820}},
+		{"tbl v0.16b, {v1.16b, v2.16b}, v3.16b", []uint32{0x4e032020}},
+		{"tbx v0.8b, { v31.16b, v0.16b, v1.16b, v2.16b }, v3.8b", []uint32{0x0e0373e0}},
+		{"dup v0.4s, v1.s[2]", []uint32{0x4e140420}},
+		{"dup v0.8h, v1.h[7]", []uint32{0x4e1e0420}},
+		{"dup v0.2d, x1", []uint32{0x4e080c20}},
+		{"dup v0.8b, wzr", []uint32{0x0e010fe0}},
+		{"mov v0.s[1], v1.s[3]", []uint32{0x6e0c6420}},
+		{"ins v2.h[3], v3.h[5]", []uint32{0x6e0e5462}},
+		{"ins v0.d[1], x1", []uint32{0x4e181c20}},
+		{"mov v0.b[15], w2", []uint32{0x4e1f1c40}},
+		{"umov w0, v1.b[3]", []uint32{0x0e073c20}},
+		{"mov w0, v1.s[1]", []uint32{0x0e0c3c20}},
+		{"umov x0, v1.d[1]", []uint32{0x4e183c20}},
+		{"smov x0, v1.s[1]", []uint32{0x4e0c2c20}},
+		{"smov w0, v1.h[7]", []uint32{0x0e1e2c20}},
+		{"cmeq v0.4s, v1.4s, v2.4s", []uint32{0x6ea28c20}},
+		{"cmgt v0.8b, v1.8b, v2.8b", []uint32{0x0e223420}},
+		{"cmhs v0.4h, v1.4h, v2.4h", []uint32{0x2e623c20}},
+		{"cmtst v0.2s, v1.2s, v2.2s", []uint32{0x0ea28c20}},
+		{"cmle v0.4s, v1.4s, v2.4s", []uint32{0x4ea13c40}},
+		{"cmlt v0.8b, v1.8b, v2.8b", []uint32{0x0e213440}},
+		{"cmlo v0.2d, v1.2d, v2.2d", []uint32{0x6ee13440}},
+		{"cmls v0.8h, v1.8h, v2.8h", []uint32{0x6e613c40}},
+		{"cmeq v0.2d, v1.2d, #0", []uint32{0x4ee09820}},
+		{"CMLT V0.8B, V1.8B, #0", []uint32{0x0e20a820}},
+		{"and v0.8b, v1.8b, v2.8b", []uint32{0x0e221c20}},
+		{"orr v0.8b, v1.8b, v2.8b", []uint32{0x0ea21c20}},
+		{"eor v0.8b, v1.8b, v2.8b", []uint32{0x2e221c20}},
+		{"bsl v0.16b, v1.16b, v2.16b", []uint32{0x6e621c20}},
+		{"bif v0.16b, v1.16b, v2.16b", []uint32{0x6ee21c20}},
+		{"not v0.16b, v1.16b", []uint32{0x6e205820}},
+		{"mvn v0.8b, v1.8b", []uint32{0x2e205820}},
+		{"mov v0.8b, v1.8b", []uint32{0x0ea11c20}},
+		{"and x0, x1, x2", []uint32{0x8a020020}},
+		{"sshr v0.16b, v1.16b, #3", []uint32{0x4f0d0420}},
+		{"ushr v0.4s, v1.4s, #32", []uint32{0x6f200420}},
+		{"sshr v0.2d, v1.2d, #64", []uint32{0x4f400420}},
+		{"urshr v0.2d, v1.2d, #1", []uint32{0x6f7f2420}},
+		{"ursra v0.2s, v1.2s, #7", []uint32{0x2f393420}},
+		{"shl v0.4h, v1.4h, #15", []uint32{0x0f1f5420}},
+		{"sli v0.8b, v1.8b, #4", []uint32{0x2f0c5420}},
+		{"sri v0.2d, v1.2d, #64", []uint32{0x6f404420}},
+		{"shrn v0.8b, v1.8h, #4", []uint32{0x0f0c8420}},
+		{"rshrn2 v0.16b, v1.8h, #4", []uint32{0x4f0c8c20}},
+		{"sqrshrn2 v0.4s, v1.2d, #32", []uint32{0x4f209c20}},
+		{"sqrshrun v0.2s, v1.2d, #1", []uint32{0x2f3f8c20}},
+		{"sshl v0.4s, v1.4s, v2.4s", []uint32{0x4ea24420}},
+		{"uqrshl v0.4h, v1.4h, v2.4h", []uint32{0x2e625c20}},
+		{"fadd h0, h1, h2", []uint32{0x1ee22820}},
+		{"fnmul d0, d1, d2", []uint32{0x1e628820}},
+		{"fsqrt s0, s1", []uint32{0x1e21c020}},
+		{"fnmadd h0, h1, h2, h3", []uint32{0x1fe20c20}},
+		{"fcmp h0, #0.0", []uint32{0x1ee02008}},
+		{"fcmpe s0, #0.0", []uint32{0x1e202018}},
+		{"fmov h0, #0.0", []uint32{0x1ee703e0}},
+		{"fmov d0, #0.0", []uint32{0x9e6703e0}},
+		{"fcsel h0, h1, h2, lt", []uint32{0x1ee2bc20}},
+		{"fmov s0, s1", []uint32{0x1e204020}},
+		{"fmov d0, #-0.125", []uint32{0x1e781000}},
+		{"fmov s0, #2", []uint32{0x1e201000}},
+		{"fmov h0, #31.0", []uint32{0x1ee7f000}},
+		{"fmov w0, h1", []uint32{0x1ee60020}},
+		{"fmov d0, xzr", []uint32{0x9e6703e0}},
+		{"fmov x0, v1.d[1]", []uint32{0x9eae0020}},
+		{"fmov v0.d[1], x1", []uint32{0x9eaf0020}},
+		{"adr x0, #-1048576", []uint32{0x10800000}},
+		{"adrp x3, #-4096", []uint32{0xf0ffffe3}},
+		{"ADRP X5, #-4294967296", []uint32{0x90800005}},
+		{"brk #0x3e8", []uint32{0xd4207d00}},
+		{"hlt #0", []uint32{0xd4400000}},
+		{"HLT 0xf000", []uint32{0xd45e0000}},
+		{"brk #65535", []uint32{0xd43fffe0}},
+		{"b #8", []uint32{0x14000002}},
+		{"bl #-4", []uint32{0x97ffffff}},
+		{"b #134217724", []uint32{0x15ffffff}},
+		{"bl #-134217728", []uint32{0x96000000}},
+		{"b.ne #-8", []uint32{0x54ffffc1}},
+		{"B.CS #1048572", []uint32{0x547fffe2}},
+		{"b.al #0", []uint32{0x5400000e}},
+		{"cbz x0, #16", []uint32{0xb4000080}},
+		{"cbnz w3, #-1048576", []uint32{0x35800003}},
+		{"tbz w0, #3, #8", []uint32{0x36180040}},
+		{"tbnz x1, #63, #-32768", []uint32{0xb7fc0001}},
+		{"tbz x2, #5, #32764", []uint32{0x362bffe2}},
+		{"br x1", []uint32{0xd61f0020}},
+		{"blr x30", []uint32{0xd63f03c0}},
+		{"ret", []uint32{0xd65f03c0}},
+		{"ret x2", []uint32{0xd65f0040}},
+		{"loop: b.eq done\nb loop\ndone: ret", []uint32{0x54000040, 0x17ffffff, 0xd65f03c0}},
+		{
+			"start:\nadr x1, start\nloop: add x0, x0, #1\nadr x2, loop\nadr x3, end\nend:",
+			[]uint32{0x10000001, 0x91000400, 0x10ffffe2, 0x10000023},
+		},
+		{
+			"\n// prologue\nstp x29, x30, [sp, #-16]!\n\nadd x0, x0, #1 // increment\nldp x29, x30, [sp], #16; add x1, x1, x0\n",
+			[]uint32{0xa9bf7bfd, 0x91000400, 0xa8c17bfd, 0x8b000021},
+		},
+	} {
+		insts, err := Assemble(tc.asm)
+		if err != nil {
+			t.Errorf("Assemble(%q) failed: %v", tc.asm, err)
+			continue
+		}
+		if len(insts) != len(tc.want) {
+			t.Errorf("Assemble(%q) returned %d instructions, want %d", tc.asm, len(insts), len(tc.want))
+			continue
+		}
+		for i, inst := range insts {
+			if got := inst.Encode(); got != tc.want[i] {
+				t.Errorf("Assemble(%q)[%d] = %v (0x%08x), want 0x%08x", tc.asm, i, inst, got, tc.want[i])
+			}
+		}
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	for _, asm := range []string{
+		"add x0, x1, #4097",
+		"add x0, x1, #1, lsl #1",
+		"add x0, w1, #1",
+		"add x0, xzr, #1",
+		"add x0, x1, x2, ror #1",
+		"add w0, w1, w2, lsl #32",
+		"add x0, x1, x2, uxtw",
+		"add x0, x1, w2, uxtw #5",
+		"add v0.4s, v1.4s, v2.2s",
+		"add v0.1d, v1.1d, v2.1d",
+		"add x0, x1, x2 add x3, x4, x5",
+		"subs sp, x0, #1",
+		"sub x0, x1, #4097",
+		"sub x0, x1, x2, ror #1",
+		"adds v0.4s, v1.4s, v2.4s",
+		"cmp x0, #4097",
+		"cmp x0, x1, lsl",
+		"cmn w0, x1",
+		"cmp x0, x1, uxtw",
+		"neg x0, #1",
+		"neg x0, x1, uxtw",
+		"negs sp, x1",
+		"b #2",
+		"b #134217728",
+		"bl #-134217732",
+		"b.eq #1048576",
+		"b.xx #8",
+		"cbz x0, #-1048580",
+		"cbz sp, #8",
+		"tbz w0, #32, #8",
+		"tbnz x0, #64, #8",
+		"tbz x0, #0, #32768",
+		"tbz x0, #0, end\n" + strings.Repeat("add x0, x0, #1\n", 8192) + "end: ret",
+		"b nowhere",
+		"br",
+		"br sp",
+		"blr w1",
+		"ldrb x0, [x1]",
+		"ldrsw w0, [x1]",
+		"ldrb b0, [x1]",
+		"ldr sp, [x1]",
+		"ldr x0, [w1]",
+		"ldr x0, [xzr]",
+		"ldr x0, [x0, #8]!",
+		"str x0, [x0], #8",
+		"ldr x0, [x1, #256]!",
+		"ldr x0, [x1, #32768]",
+		"ldur x0, [x1, #256]",
+		"ldur x0, [x1, #8]!",
+		"ldur x0, [x1, x2]",
+		"ldr x0, [x1, x2, lsl #1]",
+		"ldr x0, [x1, x2, lsl]",
+		"ldr x0, [x1, x2, asr #3]",
+		"ldr x0, [x1, w2]",
+		"ldr x0, [x1, w2, uxtb]",
+		"ldr x0, [x1, sp]",
+		"ldr w0, #2",
+		"ldr b0, #8",
+		"ldrb w0, #8",
+		"ldr x0, nowhere",
+		"ldr x0, [x1, :lo12:nowhere]",
+		"adc x0, x1, w2",
+		"adc sp, x1, x2",
+		"adcs x0, x1, #1",
+		"ngc x0, sp",
+		"rev32 w0, w1",
+		"rev64 w0, w1",
+		"clz x0, w1",
+		"rbit sp, x1",
+		"cnt v0.8h, v1.8h",
+		"cnt v0.8b, v1.16b",
+		"rbit v0.4s, v1.4s",
+		"rev16 v0.4h, v1.4h",
+		"rev32 v0.2s, v1.2s",
+		"rev64 v0.2d, v1.2d",
+		"clz v0.1d, v1.1d",
+		"cls v0.2d, v1.2d",
+		"adr x0, nowhere",
+		"adr x0, 16",
+		"adr w0, #0",
+		"adr sp, #0",
+		"adr x0, #1048576",
+		"adrp x0, #4097",
+		"adrp x0, #4294967296",
+		"a:\na: adr x0, a",
+		"add x0, x0, :lo12:nowhere",
+		"a: add x0, x0, :lo12:a, lsl #12",
+		"ldp x0, x0, [x1]",
+		"ldp x0, w1, [x2]",
+		"ldp x0, d1, [x2]",
+		"ldp x0, x1, [w2]",
+		"ldp x0, x1, [xzr]",
+		"ldp x0, x1, [x2, #4]",
+		"ldp x0, x1, [x2, #512]",
+		"ldp w0, w1, [x2, #-260]",
+		"ldp x0, x1, [x0], #16",
+		"ldpsw w0, w1, [x2]",
+		"ldpsw s0, s1, [x2]",
+		"stp q0, q1, [x0, #1024]",
+		"movz x0, #0x10000",
+		"movz x0, #-1",
+		"movz x0, #1, lsl #8",
+		"movz w0, #1, lsl #32",
+		"movk sp, #1",
+		"mov w0, #0x100000000",
+		"mov w0, #-0x80000001",
+		"mov sp, #0x1234",
+		"and x0, x1, #0x1234",
+		"and x0, x1, #0",
+		"orr w0, w1, #0xffffffff",
+		"bic x0, x1, #-1",
+		"and w0, w1, #0x100000000",
+		"ands sp, x1, #1",
+		"and x0, sp, #1",
+		"and x0, x1, w2",
+		"and sp, x1, x2",
+		"tst w1, #0x12345",
+		"mvn x0, sp",
+		"mov x0, w1",
+		"mov sp, xzr",
+		"lsl x0, x1, #64",
+		"asr w0, w1, #32",
+		"lsr x0, w1, #1",
+		"ror sp, x1, #1",
+		"ubfx w0, w1, #31, #2",
+		"sbfiz x0, x1, #0, #0",
+		"bfi x0, x1, #64, #1",
+		"ubfm w0, w1, #32, #0",
+		"sxtw w0, w1",
+		"sxtb x0, x1",
+		"uxtb x0, w1",
+		"uxtw x0, w1",
+		"extr w0, w1, w2, #32",
+		"madd x0, x1, x2",
+		"mul x0, x1, x2, x3",
+		"smulh x0, x1, x2, x3",
+		"smulh w0, w1, w2",
+		"madd x0, x1, w2, x3",
+		"smull x0, x1, x2",
+		"smull w0, w1, w2",
+		"smaddl x0, w1, w2, w3",
+		"udiv x0, x1, w2",
+		"sdiv sp, x1, x2",
+		"lsl x0, x1, w2",
+		"csel x0, x1, x2, xx",
+		"csel x0, x1, w2, eq",
+		"cset x0, al",
+		"cinc x0, x1, nv",
+		"ccmp x1, #32, #0, eq",
+		"ccmp x1, x2, #16, eq",
+		"ccmn sp, #1, #0, eq",
+		"sub v0.1d, v1.1d, v2.1d",
+		"mul v0.2d, v1.2d, v2.2d",
+		"smax v0.4s, v1.4s, v2.2s",
+		"abs v0.1d, v1.1d",
+		"mla v0.4s, v1.4s",
+		"sqdmulh v0.16b, v1.16b, v2.16b",
+		"sqadd v0.1d, v1.1d, v2.1d",
+		"sqxtn v0.16b, v1.8h",
+		"sqxtn2 v0.8b, v1.8h",
+		"sqxtn v0.8b, v1.4s",
+		"uqxtn v0.1d, v1.2d",
+		"saddl v0.8h, v1.16b, v2.16b",
+		"saddl2 v0.8h, v1.8b, v2.8b",
+		"saddl v0.4s, v1.8b, v2.8b",
+		"saddw v0.8h, v1.8b, v2.8b",
+		"addhn v0.8h, v1.8h, v2.8h",
+		"smull v0.2d, v1.1d, v2.1d",
+		"xtn v0.8h, v1.8h",
+		"shll v0.8h, v1.8b, #16",
+		"shll2 v0.4s, v1.4h, #16",
+		"saddlp v0.8h, v1.8b",
+		"uaddlp v0.2d, v1.2d",
+		"addv s0, v1.2s",
+		"addv d0, v1.2d",
+		"addv h0, v1.16b",
+		"saddlv b0, v1.16b",
+		"uaddlv s0, v1.4s",
+		"fmaxnmv s0, v1.2s",
+		"fminnmv d0, v1.4s",
+		"addv v0.16b, v1.16b",
+		"ldp b0, b1, [x0]",
+		"zip1 v0.1d, v1.1d, v2.1d",
+		"zip1 v0.4s, v1.4s, v2.2s",
+		"ext v0.4s, v1.4s, v2.4s, #3",
+		"ext v0.8b, v1.8b, v2.8b, #8",
+		"tbl v0.16b, {v1.8b}, v2.16b",
+		"tbl v0.16b, {v1.16b, v3.16b}, v2.16b",
+		"tbl v0.16b, {v1.16b, v2.16b, v3.16b, v4.16b, v5.16b}, v6.16b",
+		"tbl v0.4s, {v1.16b}, v2.4s",
+		"dup v0.4s, v1.h[2]",
+		"dup v0.4s, v1.s[4]",
+		"dup v0.1d, x1",
+		"dup v0.2d, w1",
+		"dup v0.4s, x1",
+		"mov v0.s[1], v1.d[0]",
+		"mov v0.b[16], w1",
+		"mov v0.s[0], x1",
+		"umov x0, v1.s[1]",
+		"umov w0, v1.d[0]",
+		"mov w0, v1.h[1]",
+		"mov x0, v1.s[1]",
+		"smov w0, v1.s[0]",
+		"smov x0, v1.d[0]",
+		"smov sp, v1.b[0]",
+		"cmeq v0.1d, v1.1d, v2.1d",
+		"cmhi v0.4s, v1.4s, v2.2s",
+		"cmeq v0.4s, v1.4s, #1",
+		"cmle v0.1d, v1.1d, #0",
+		"cmle v0.1d, v1.1d, v2.1d",
+		"cmlo v0.4s, v1.4s, v2.2s",
+		"and v0.4s, v1.4s, v2.4s",
+		"eor v0.16b, v1.16b, v2.8b",
+		"bsl v0.2d, v1.2d, v2.2d",
+		"not v0.4h, v1.4h",
+		"mov v0.4s, v1.4s",
+		"orr v0.16b, v1.16b",
+		"mvn v0.16b, v1.16b, v2.16b",
+		"sshr v0.4s, v1.4s, #0",
+		"ushr v0.8b, v1.8b, #9",
+		"shl v0.2s, v1.2s, #32",
+		"sli v0.1d, v1.1d, #1",
+		"sri v0.4s, v1.2s, #1",
+		"shrn v0.16b, v1.8h, #1",
+		"rshrn2 v0.8h, v1.8h, #1",
+		"sqshrn v0.2s, v1.2d, #33",
+		"sshl v0.1d, v1.1d, v2.1d",
+		"fadd s0, s1, d2",
+		"fabs b0, b1",
+		"fmadd q0, q1, q2, q3",
+		"fcmp s0, #1.0",
+		"fcsel d0, d1, d2, xx",
+		"fmov s0, #0.1",
+		"fmov s0, #-0.0",
+		"fcmp d0, #0",
+		"fmov w0, d1",
+		"fmov x0, s1",
+		"fmov sp, s1",
+		"fmov x0, v1.d[0]",
+		"fmov w0, v1.d[1]",
+		"fmov #1.0, s0",
+		"ld1 {v0.16b, v2.16b}, [x0]",
+		"ld1 {v0.s, v1.s}[0], [x0]",
+		"st1 {v0.b, v1.b}[1], [x0], #2",
+		"ld2 {v0.16b}, [x0]",
+		"ld1 {v0.16b, v1.16b, v2.16b, v3.16b, v4.16b}, [x0]",
+		"ld1 {v0.16b, v1.8b}, [x0]",
+		"ld1 {v0.16b}, [x0], #8",
+		"ld1 {v0.16b}, [x0], xzr",
+		"ld1 {v0.16b}, [w0]",
+		"ld2 {v0.1d, v1.1d}, [x0]",
+		"ld1 {v0.s}[4], [x0]",
+		"ld1 {v0.s, v1.d}[0], [x0]",
+		"ld1 {v0.s}, [x0]",
+		"ld1 {v0.4s}[0], [x0]",
+		"ld1r {v0.s}[0], [x0]",
+		"brk #65536",
+		"hlt #-1",
+		"hlt x0",
+	} {
+		if insts, err := Assemble(asm); err == nil {
+			t.Errorf("Assemble(%q) = %v, want an error", asm, insts)
+		}
+	}
+}
+
+func TestAssembleMovImmediate(t *testing.T) {
+	for _, tc := range []struct {
+		asm  string
+		want uint64
+		n    int
+	}{
+		{"mov x0, #0", 0, 1},
+		{"mov x0, #0x12345678", 0x12345678, 2},
+		{"mov x0, #0xffffffff12345678", 0xffffffff12345678, 2},
+		{"mov x0, #0x1234567887654321", 0x1234567887654321, 4},
+		{"mov x0, #0x5555555555551234", 0x5555555555551234, 2},
+		{"mov x0, #0x00ff00ff12345678", 0x00ff00ff12345678, 3},
+		{"mov x0, #0xfffe0000fffe0000", 0xfffe0000fffe0000, 1},
+		{"mov x0, #-0x8000000000000000", 0x8000000000000000, 1},
+		{"mov w0, #0x12345678", 0x12345678, 2},
+		{"mov w0, #0xf0f0f0f0", 0xf0f0f0f0, 1},
+		{"mov w0, #-0x80000000", 0x80000000, 1},
+	} {
+		insts, err := Assemble(tc.asm)
+		if err != nil {
+			t.Errorf("Assemble(%q) failed: %v", tc.asm, err)
+			continue
+		}
+		if len(insts) != tc.n {
+			t.Errorf("Assemble(%q) = %v, want %d instructions", tc.asm, insts, tc.n)
+		}
+		m := machine.New(4096)
+		for _, inst := range insts {
+			inst.Execute(m)
+		}
+		if m.R[0] != tc.want {
+			t.Errorf("Assemble(%q) = %v, which sets x0 to 0x%x, want 0x%x", tc.asm, insts, m.R[0], tc.want)
+		}
+	}
+}
+
+func TestAssembleLabels(t *testing.T) {
+	// data is in the second page, and each page is reached with ADRP from the other.
+	src := "start: adrp x0, data\nadd x0, x0, :lo12:data\n" +
+		strings.Repeat("add x1, x1, #1\n", 2000) +
+		"data: adrp x3, start\nadd x3, x3, :lo12:start\n"
+	insts, err := Assemble(src)
+	if err != nil {
+		t.Fatalf("Assemble failed: %v", err)
+	}
+	const data = 8 + 4*2000
+	for _, tc := range []struct {
+		i    int
+		want uint32
+	}{
+		{0, 0xb0000000},          // adrp x0, #4096
+		{1, 0x913d2000},          // add x0, x0, #0xf48
+		{data / 4, 0xf0ffffe3},   // adrp x3, #-4096
+		{data/4 + 1, 0x91000063}, // add x3, x3, #0
+	} {
+		if got := insts[tc.i].Encode(); got != tc.want {
+			t.Errorf("instruction %d = %v (0x%08x), want 0x%08x", tc.i, insts[tc.i], got, tc.want)
+		}
+	}
+
+	m := machine.New(4096)
+	for _, pc := range []uint64{0, 4, data, data + 4} {
+		m.PC = pc
+		insts[pc/4].Execute(m)
+	}
+	if m.R[0] != data || m.R[3] != 0 {
+		t.Errorf("x0 = 0x%x and x3 = 0x%x, want 0x%x and 0", m.R[0], m.R[3], data)
+	}
+}
+
+func TestAssembleLoadLabels(t *testing.T) {
+	// value is in the second page and is reached with ADRP and :lo12:, and as a literal.
+	src := "adrp x0, value\nldr x1, [x0, :lo12:value]\nldr x2, value\nldrsw x3, value\n" +
+		strings.Repeat("add x1, x1, #1\n", 1102) +
+		"value: movn x4, #1\nmovn x5, #0\n"
+	insts, err := Assemble(src)
+	if err != nil {
+		t.Fatalf("Assemble failed: %v", err)
+	}
+	for i, want := range []uint32{
+		0xb0000000, // adrp x0, #4096
+		0xf940a401, // ldr x1, [x0, #328]
+		0x58008a02, // ldr x2, #4416
+		0x980089e3, // ldrsw x3, #4412
+	} {
+		if got := insts[i].Encode(); got != want {
+			t.Errorf("instruction %d = %v (0x%08x), want 0x%08x", i, insts[i], got, want)
+		}
+	}
+
+	m := machine.New(8192)
+	for i, inst := range insts {
+		m.Write(uint64(4*i), 4, uint64(inst.Encode()))
+	}
+	for pc := uint64(0); pc < 16; pc += 4 {
+		m.PC = pc
+		insts[pc/4].Execute(m)
+	}
+	if m.R[1] != 0x9280000592800024 || m.R[2] != 0x9280000592800024 || m.R[3] != 0xffffffff92800024 {
+		t.Errorf("x1 = 0x%x, x2 = 0x%x and x3 = 0x%x, want 0x9280000592800024 twice and 0xffffffff92800024", m.R[1], m.R[2], m.R[3])
+	}
+}
+
+// runAssembled assembles src into memory at address 0 and runs it until it stops.
+func runAssembled(t *testing.T, src string) (*machine.Machine, machine.StopReason) {
+	t.Helper()
+	insts, err := Assemble(src)
+	if err != nil {
+		t.Fatalf("Assemble(%q) failed: %v", src, err)
+	}
+	m := machine.New(4096)
+	for i, inst := range insts {
+		m.Write(uint64(4*i), 4, uint64(inst.Encode()))
+	}
+	m.Decode = opcode.Decoder
+	m.Budget = 1000
+	reason, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("running %q returned %v: %v", src, reason, err)
+	}
+	return m, reason
+}
+
+func TestAssembleRun(t *testing.T) {
+	for _, tc := range []struct {
+		src    string
+		reason machine.StopReason
+		x0, pc uint64
+	}{
+		{"mov x0, #5\nadd x0, x0, #2\nhlt #0\nadd x0, x0, #1", machine.StopHalt, 7, 8},
+		{"add x0, x0, #1\nbrk #0x3e8\nadd x0, x0, #1", machine.StopBreakpoint, 1, 4},
+		{"mov x1, #10\nloop: add x0, x0, #3\nsub x1, x1, #1\ncbnz x1, loop\nhlt #0", machine.StopHalt, 30, 16},
+		{"mov x1, #5\nloop: add x0, x0, #2\nsubs x1, x1, #1\nb.ne loop\nhlt #0", machine.StopHalt, 10, 16},
+		{"mov x0, #1\nbl double\nbl double\nhlt #0\ndouble: add x0, x0, x0\nret", machine.StopHalt, 4, 12},
+		{"adr x2, seven\nblr x2\nhlt #0\nseven: mov x0, #7\nret", machine.StopHalt, 7, 8},
+		{"mov x1, #4\ntbnz x1, #2, set\nhlt #0\nset: mov x0, #1\ntbz x1, #3, done\nhlt #0\ndone: b #0x10\nhlt #0\nhlt #0\nhlt #0\nhlt #1", machine.StopHalt, 1, 40},
+	} {
+		m, reason := runAssembled(t, tc.src)
+		if reason != tc.reason || m.R[0] != tc.x0 || m.PC != tc.pc {
+			t.Errorf("running %q stopped with %v, x0 = %d and pc = %d, want %v, %d and %d", tc.src, reason, m.R[0], m.PC, tc.reason, tc.x0, tc.pc)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, word := range []uint32{
+		0xf9400020, // ldr x0, [x1]
+		0x3dbfffe0, // str q0, [sp, #65520]
+		0xf85f8020, // ldur x0, [x1, #-8]
+		0x38cff020, // ldursb w0, [x1, #255]
+		0xb81f0fe0, // str w0, [sp, #-16]!
+		0xfc500420, // ldr d0, [x1], #-256
+		0x38627820, // ldrb w0, [x1, x2, lsl #0]
+		0xb8625820, // ldr w0, [x1, w2, uxtw #2]
+		0xf822cbe0, // str x0, [sp, w2, sxtw]
+		0x78a2f820, // ldrsh x0, [x1, x2, sxtx #1]
+		0x987fffe0, // ldrsw x0, #1048572
+		0x1c800002, // ldr s2, #-1048576
+		0xdac00020, // rbit x0, x1
+		0x5ac00420, // rev16 w0, w1
+		0x5ac00820, // rev w0, w1
+		0xdac00820, // rev32 x0, x1
+		0xdac00c20, // rev x0, x1
+		0x5ac01020, // clz w0, w1
+		0xdac01420, // cls x0, x1
+		0x4e205820, // cnt v0.16b, v1.16b
+		0x2e605820, // rbit v0.8b, v1.8b
+		0x2e200820, // rev32 v0.8b, v1.8b
+		0x4e200820, // rev64 v0.16b, v1.16b
+		0x0ea04820, // cls v0.2s, v1.2s
+		0x10000080, // adr x0, #16
+		0x70ffffe0, // adr x0, #-1
+		0xf07fffe1, // adrp x1, #4294963200
+		0x9a020020, // adc x0, x1, x2
+		0x3a020020, // adcs w0, w1, w2
+		0xda0203e0, // ngc x0, x2
+		0x7a0103e0, // ngcs w0, w1
+		0x9b020c20, // madd x0, x1, x2, x3
+		0x1b028c20, // msub w0, w1, w2, w3
+		0x9b027c20, // mul x0, x1, x2
+		0x1b02fc20, // mneg w0, w1, w2
+		0x9b220c20, // smaddl x0, w1, w2, x3
+		0x9b228c20, // smsubl x0, w1, w2, x3
+		0x9ba20c20, // umaddl x0, w1, w2, x3
+		0x9ba28c20, // umsubl x0, w1, w2, x3
+		0x9b227c20, // smull x0, w1, w2
+		0x9b22fc20, // smnegl x0, w1, w2
+		0x9ba27c20, // umull x0, w1, w2
+		0x9ba2fc20, // umnegl x0, w1, w2
+		0x9b427c20, // smulh x0, x1, x2
+		0x9bc27c20, // umulh x0, x1, x2
+		0x1ac20820, // udiv w0, w1, w2
+		0x9ac20c20, // sdiv x0, x1, x2
+		0x9ac22020, // lsl x0, x1, x2
+		0x1ac22420, // lsr w0, w1, w2
+		0x9ac22820, // asr x0, x1, x2
+		0x1ac22c20, // ror w0, w1, w2
+		0x9343fc20, // asr x0, x1, #3
+		0x937c1c20, // sbfiz x0, x1, #4, #8
+		0xd3483c20, // ubfx x0, x1, #8, #8
+		0x53001c20, // uxtb w0, w1
+		0x93407c20, // sxtw x0, w1
+		0xb3780fe0, // bfc x0, #8, #4
+		0x13811420, // ror w0, w1, #5
+		0x9a820020, // csel x0, x1, x2, eq
+		0x9a9f17e0, // cset x0, eq
+		0x5a9f23e0, // csetm w0, lo
+		0x9a81d420, // cinc x0, x1, gt
+		0x5a81c020, // cinv w0, w1, le
+		0xda9f07e0, // cneg x0, xzr, ne
+		0xda81e420, // csneg x0, x1, x1, al
+		0xfa421024, // ccmp x1, x2, #4, ne
+		0x3a5fe82f, // ccmn w1, #31, #15, al
+		0x6ea27420, // uabd v0.4s, v1.4s, v2.4s
+		0x4e626420, // smax v0.8h, v1.8h, v2.8h
+		0x2e626c20, // umin v0.4h, v1.4h, v2.4h
+		0x6ea20420, // uhadd v0.4s, v1.4s, v2.4s
+		0x4e621420, // srhadd v0.8h, v1.8h, v2.8h
+		0x0e62bc20, // addp v0.4h, v1.4h, v2.4h
+		0x4e60b820, // abs v0.8h, v1.8h
+		0x6e214820, // uqxtn2 v0.16b, v1.8h
+		0x0e622c20, // sqsub v0.4h, v1.4h, v2.4h
+		0x4ea2b420, // sqdmulh v0.4s, v1.4s, v2.4s
+		0x6e221020, // uaddw2 v0.8h, v1.8h, v2.16b
+		0x0e622020, // ssubl v0.4s, v1.4h, v2.4h
+		0x6ea22020, // usubl2 v0.2d, v1.4s, v2.4s
+		0x2e223020, // usubw v0.8h, v1.8h, v2.8b
+		0x0ea26020, // subhn v0.2s, v1.2d, v2.2d
+		0x6e226020, // rsubhn2 v0.16b, v1.8h, v2.8h
+		0x0e225020, // sabal v0.8h, v1.8b, v2.8b
+		0x6e625020, // uabal2 v0.4s, v1.8h, v2.8h
+		0x0ea27020, // sabdl v0.2d, v1.2s, v2.2s
+		0x6e227020, // uabdl2 v0.8h, v1.16b, v2.16b
+		0x0e628020, // smlal v0.4s, v1.4h, v2.4h
+		0x6ea28020, // umlal2 v0.2d, v1.4s, v2.4s
+		0x4e22a020, // smlsl2 v0.8h, v1.16b, v2.16b
+		0x2e62a020, // umlsl v0.4s, v1.4h, v2.4h
+		0x4ea12820, // xtn2 v0.4s, v1.2d
+		0x2e612820, // sqxtun v0.4h, v1.4s
+		0x6ea02820, // uaddlp v0.2d, v1.4s
+		0x0ea06820, // sadalp v0.1d, v1.2s
+		0x0c407000, // ld1 { v0.8b }, [x0]
+		0x4c9f2000, // st1 { v0.16b, v1.16b, v2.16b, v3.16b }, [x0], #64
+		0x4cdf8c00, // ld2 { v0.2d, v1.2d }, [x0], #32
+		0x4d40e400, // ld3r { v0.8h, v1.8h, v2.8h }, [x0]
+		0x0d9f8400, // st1 { v0.d }[0], [x0], #8
+		0x0de0c400, // ld2r { v0.4h, v1.4h }, [x0], x0
+		0x4eb1b820, // addv s0, v1.4s
+		0x0e303820, // saddlv h0, v1.8b
+		0x6e70a820, // umaxv h0, v1.8h
+		0x4eb1a820, // sminv s0, v1.4s
+		0x6eb0c820, // fminnmv s0, v1.4s
+		0x4e427820, // zip2 v0.8h, v1.8h, v2.8h
+		0x0e826820, // trn2 v0.2s, v1.2s, v2.2s
+		0x6e021820, // ext v0.16b, v1.16b, v2.16b, #3
+		0x0e044020, // tbl v0.8b, { v1.16b, v2.16b, v3.16b }, v4.8b
+		0x4e1f0420, // dup v0.16b, v1.b[15]
+		0x0e040fe0, // dup v0.2s, wzr
+		0x6e1f041f, // mov v31.b[15], v0.b[0]
+		0x4e1e1c40, // mov v0.h[7], w2
+		0x4e183c20, // mov x0, v1.d[1]
+		0x0e1e2c20, // smov w0, v1.h[7]
+		0x4ee23c20, // cmge v0.2d, v1.2d, v2.2d
+		0x6e623420, // cmhi v0.8h, v1.8h, v2.8h
+		0x0e608820, // cmgt v0.4h, v1.4h, #0
+		0x6e208820, // cmge v0.16b, v1.16b, #0
+		0x6ea09820, // cmle v0.4s, v1.4s, #0
+		0x4e621c20, // bic v0.16b, v1.16b, v2.16b
+		0x4ee21c20, // orn v0.16b, v1.16b, v2.16b
+		0x2ea21c20, // bit v0.8b, v1.8b, v2.8b
+		0x4ea11c20, // mov v0.16b, v1.16b
+		0x6e205820, // mvn v0.16b, v1.16b
+		0x4f1b3420, // srsra v0.8h, v1.8h, #5
+		0x4f3f1420, // ssra v0.4s, v1.4s, #1
+		0x2f081420, // usra v0.8b, v1.8b, #8
+		0x0f189420, // sqshrn v0.4h, v1.4s, #8
+		0x2f3d9c20, // uqrshrn v0.2s, v1.2d, #3
+		0x2f0d8420, // sqshrun v0.8b, v1.8h, #3
+		0x6ee25420, // urshl v0.2d, v1.2d, v2.2d
+		0x4e624c20, // sqshl v0.8h, v1.8h, v2.8h
+		0x1e60c020, // fabs d0, d1
+		0x1ee14020, // fneg h0, h1
+		0x1e221820, // fdiv s0, s1, s2
+		0x1e623820, // fsub d0, d1, d2
+		0x1e224820, // fmax s0, s1, s2
+		0x1e625820, // fmin d0, d1, d2
+		0x1ee26820, // fmaxnm h0, h1, h2
+		0x1e227820, // fminnm s0, s1, s2
+		0x1e620820, // fmul d0, d1, d2
+		0x1f020c20, // fmadd s0, s1, s2, s3
+		0x1f428c20, // fmsub d0, d1, d2, d3
+		0x1f228c20, // fnmsub s0, s1, s2, s3
+		0x1e612000, // fcmp d0, d1
+		0x1e212010, // fcmpe s0, s1
+		0x1e602018, // fcmpe d0, #0.0
+		0x1e2ff000, // fmov s0, #1.93750000
+		0x1e3f1000, // fmov s0, #-1.50000000
+		0x1e270020, // fmov s0, w1
+		0x9e660020, // fmov x0, d1
+		0x9ee70020, // fmov h0, x1
+		0xcb020020, // sub x0, x1, x2
+		0xf13ffc20, // subs x0, x1, #4095
+		0x31400420, // adds w0, w1, #1, lsl #12
+		0xab2163e0, // adds x0, sp, x1
+		0xd10043ff, // sub sp, sp, #16
+		0xcb214be0, // sub x0, sp, w1, uxtw #2
+		0xf100041f, // cmp x0, #1
+		0xeb01101f, // cmp x0, x1, lsl #4
+		0xeb2163ff, // cmp sp, x1
+		0xeb21041f, // cmp x0, w1, uxtb #1
+		0xb100041f, // cmn x0, #1
+		0x2b81081f, // cmn w0, w1, asr #2
+		0xab21c3ff, // cmn sp, w1, sxtw
+		0xcb0103e0, // neg x0, x1
+		0xeb8107e0, // negs x0, x1, asr #1
+		0xd4207d00, // brk #0x3e8
+		0xd45e0000, // hlt #0xf000
+		0x14000002, // b #8
+		0x97ffffff, // bl #-4
+		0x54ffffc1, // b.ne #-8
+		0xb4000080, // cbz x0, #16
+		0x35800003, // cbnz w3, #-1048576
+		0x36180040, // tbz w0, #3, #8
+		0xb7fc0001, // tbnz x1, #63, #-32768
+		0xd61f0020, // br x1
+		0xd63f03c0, // blr x30
+		0xd65f03c0, // ret
+		0xd65f0040, // ret x2
+	} {
+		inst, err := opcode.Decode(word)
+		if err != nil {
+			t.Errorf("Decode(0x%08x) failed: %v", word, err)
+			continue
+		}
+		insts, err := Assemble(inst.String())
+		if err != nil {
+			t.Errorf("Assemble(%q) failed: %v", inst, err)
+			continue
+		}
+		if len(insts) != 1 || insts[0].Encode() != word {
+			t.Errorf("Assemble(%q) = %v, want 0x%08x", inst, insts, word)
+		}
+	}
+}
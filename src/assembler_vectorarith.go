@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// compareAliases maps each register compare alias to the compare that it assembles to with its
+// source registers swapped.
+var compareAliases = map[string]string{
+	"cmle": "cmge",
+	"cmlt": "cmgt",
+	"cmls": "cmhs",
+	"cmlo": "cmhi",
+}
+
+// vectorBinarySizes is the largest element size field allowed by each VectorBinary mnemonic.
+var vectorBinarySizes = map[string]uint32{
+	"sub":      0b11,
+	"addp":     0b11,
+	"mul":      0b10,
+	"mla":      0b10,
+	"mls":      0b10,
+	"sabd":     0b10,
+	"uabd":     0b10,
+	"smax":     0b10,
+	"umax":     0b10,
+	"smin":     0b10,
+	"umin":     0b10,
+	"shadd":    0b10,
+	"uhadd":    0b10,
+	"srhadd":   0b10,
+	"urhadd":   0b10,
+	"sqadd":    0b11,
+	"uqadd":    0b11,
+	"sqsub":    0b11,
+	"uqsub":    0b11,
+	"sqdmulh":  0b10,
+	"sqrdmulh": 0b10,
+	"cmeq":     0b11,
+	"cmgt":     0b11,
+	"cmge":     0b11,
+	"cmhi":     0b11,
+	"cmhs":     0b11,
+	"cmtst":    0b11,
+	"sshl":     0b11,
+	"ushl":     0b11,
+	"srshl":    0b11,
+	"urshl":    0b11,
+	"sqshl":    0b11,
+	"uqshl":    0b11,
+	"sqrshl":   0b11,
+	"uqrshl":   0b11,
+}
+
+// VectorBinary is an integer vector instruction with two source registers that all have the same
+// arrangement: SUB, MUL, MLA, MLS, SABD, UABD, SMAX, UMAX, SMIN, UMIN, SHADD, UHADD, SRHADD,
+// URHADD, ADDP, one of the saturating SQADD, UQADD, SQSUB, UQSUB, SQDMULH and SQRDMULH, or one of
+// the compares CMEQ, CMGT, CMGE, CMHI, CMHS and CMTST and their aliases CMLE, CMLT, CMLS and
+// CMLO, or one of the shifts by register SSHL, USHL, SRSHL, URSHL, SQSHL, UQSHL, SQRSHL and
+// UQRSHL.
+type VectorBinary struct {
+	Mnemonic string       `@("sub" | "mul" | "mla" | "mls" | "sabd" | "uabd" | "smax" | "umax" | "smin" | "umin" | "shadd" | "uhadd" | "srhadd" | "urhadd" | "addp" | "sqadd" | "uqadd" | "sqsub" | "uqsub" | "sqdmulh" | "sqrdmulh" | "cmeq" | "cmgt" | "cmge" | "cmhi" | "cmhs" | "cmtst" | "cmle" | "cmlt" | "cmls" | "cmlo" | "sshl" | "ushl" | "srshl" | "urshl" | "sqshl" | "uqshl" | "sqrshl" | "uqrshl")`
+	Vd       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vn       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vm       RegisterNeon `@(RegisterNeon TypeSpecifier)`
+}
+
+func (i *VectorBinary) Validate() ([]opcode.Instruction, error) {
+	if err := sameArrangement(i.Vd, i.Vn, i.Vm); err != nil {
+		return nil, err
+	}
+	mnemonic := strings.ToLower(i.Mnemonic)
+	q, size, rm, rn, rd := i.Vd.Q, i.Vd.Size, i.Vm.N, i.Vn.N, i.Vd.N
+	if alias, ok := compareAliases[mnemonic]; ok {
+		mnemonic, rm, rn = alias, rn, rm
+	}
+	if i.Vd.Size > vectorBinarySizes[mnemonic] || (i.Vd.Q == 0 && i.Vd.Size == 0b11) || (strings.HasSuffix(mnemonic, "mulh") && i.Vd.Size == 0b00) {
+		return nil, fmt.Errorf("%v is not a valid arrangement for %s", i.Vd, strings.ToLower(i.Mnemonic))
+	}
+	switch mnemonic {
+	case "sub":
+		return []opcode.Instruction{&opcode.SubVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "mul":
+		return []opcode.Instruction{&opcode.MulVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "mla":
+		return []opcode.Instruction{&opcode.MlaVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "mls":
+		return []opcode.Instruction{&opcode.MlsVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "sabd":
+		return []opcode.Instruction{&opcode.Sabd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "uabd":
+		return []opcode.Instruction{&opcode.Uabd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "smax":
+		return []opcode.Instruction{&opcode.Smax{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "umax":
+		return []opcode.Instruction{&opcode.Umax{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "smin":
+		return []opcode.Instruction{&opcode.Smin{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "umin":
+		return []opcode.Instruction{&opcode.Umin{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "shadd":
+		return []opcode.Instruction{&opcode.Shadd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "uhadd":
+		return []opcode.Instruction{&opcode.Uhadd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "srhadd":
+		return []opcode.Instruction{&opcode.Srhadd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "urhadd":
+		return []opcode.Instruction{&opcode.Urhadd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "sqadd":
+		return []opcode.Instruction{&opcode.Sqadd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "uqadd":
+		return []opcode.Instruction{&opcode.Uqadd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "sqsub":
+		return []opcode.Instruction{&opcode.Sqsub{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "uqsub":
+		return []opcode.Instruction{&opcode.Uqsub{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "sqdmulh":
+		return []opcode.Instruction{&opcode.Sqdmulh{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "sqrdmulh":
+		return []opcode.Instruction{&opcode.Sqrdmulh{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "cmeq":
+		return []opcode.Instruction{&opcode.CmeqRegister{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "cmgt":
+		return []opcode.Instruction{&opcode.CmgtRegister{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "cmge":
+		return []opcode.Instruction{&opcode.CmgeRegister{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "cmhi":
+		return []opcode.Instruction{&opcode.Cmhi{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "cmhs":
+		return []opcode.Instruction{&opcode.Cmhs{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "cmtst":
+		return []opcode.Instruction{&opcode.Cmtst{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "sshl":
+		return []opcode.Instruction{&opcode.Sshl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "ushl":
+		return []opcode.Instruction{&opcode.Ushl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "srshl":
+		return []opcode.Instruction{&opcode.Srshl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "urshl":
+		return []opcode.Instruction{&opcode.Urshl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "sqshl":
+		return []opcode.Instruction{&opcode.SqshlRegister{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "uqshl":
+		return []opcode.Instruction{&opcode.UqshlRegister{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "sqrshl":
+		return []opcode.Instruction{&opcode.Sqrshl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "uqrshl":
+		return []opcode.Instruction{&opcode.Uqrshl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.AddpVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+}
+
+// VectorNarrow is a narrowing vector instruction, XTN, SQXTN, UQXTN or SQXTUN, whose source
+// elements are twice the size of its destination elements.  The forms with a 2 suffix write the
+// upper half of Vd.
+type VectorNarrow struct {
+	Mnemonic string       `@("xtn" | "xtn2" | "sqxtn" | "sqxtn2" | "uqxtn" | "uqxtn2" | "sqxtun" | "sqxtun2")`
+	Vd       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vn       RegisterNeon `@(RegisterNeon TypeSpecifier)`
+}
+
+func (i *VectorNarrow) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	var q uint32
+	if strings.HasSuffix(mnemonic, "2") {
+		q = 1
+	}
+	if i.Vd.Q != q || i.Vd.Size == 0b11 {
+		return nil, fmt.Errorf("%v is not a valid arrangement for %s", i.Vd, mnemonic)
+	}
+	if i.Vn.Q != 1 || i.Vn.Size != i.Vd.Size+1 {
+		return nil, fmt.Errorf("%s narrows %v from a vector of elements twice the size, not %v", mnemonic, i.Vd, i.Vn)
+	}
+	size, rn, rd := i.Vd.Size, i.Vn.N, i.Vd.N
+	switch strings.TrimSuffix(mnemonic, "2") {
+	case "xtn":
+		return []opcode.Instruction{&opcode.Xtn{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "sqxtn":
+		return []opcode.Instruction{&opcode.Sqxtn{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "uqxtn":
+		return []opcode.Instruction{&opcode.Uqxtn{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.Sqxtun{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// The opcodes of the load/store multiple structures instructions, for each number of registers in
+// the list of LD1 and ST1, and for each number of elements in the structures of LD2-LD4 and
+// ST2-ST4.
+var (
+	multipleOpcodes1 = [5]uint32{1: 0b0111, 2: 0b1010, 3: 0b0110, 4: 0b0010}
+	multipleOpcodesN = [5]uint32{2: 0b1000, 3: 0b0100, 4: 0b0000}
+)
+
+// LoadStoreStructure is LD1-LD4 or ST1-ST4 of whole registers or of a single lane, or LD1R-LD4R,
+// with an optional post-index by an immediate or a register.  The registers in the list must be
+// consecutive, but can wrap around from v31 to v0.
+type LoadStoreStructure struct {
+	Mnemonic string           `@("ld1" | "ld2" | "ld3" | "ld4" | "st1" | "st2" | "st3" | "st4" | "ld1r" | "ld2r" | "ld3r" | "ld4r")`
+	List     VectorList       `"{" ( @(RegisterNeon TypeSpecifier) ("," @(RegisterNeon TypeSpecifier))*`
+	Elements ElementList      `    | @(RegisterNeon ElementSpecifier) ("," @(RegisterNeon ElementSpecifier))* ) "}"`
+	Index    *Immediate       `("[" @Integer "]")? ","`
+	Rn       GeneralRegister  `"[" @RegisterGeneral "]"`
+	Imm      *Immediate       `("," ( "#" @Integer`
+	Rm       *GeneralRegister `    | @RegisterGeneral ))?`
+}
+
+// consecutive checks that the register numbers in n follow each other.
+func consecutive(n []uint32) error {
+	for i := range n[1:] {
+		if n[i+1] != (n[i]+1)%32 {
+			return fmt.Errorf("v%d does not follow v%d", n[i+1], n[i])
+		}
+	}
+	return nil
+}
+
+func (i *LoadStoreStructure) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	selem := int(mnemonic[2] - '0')
+	replicate := strings.HasSuffix(mnemonic, "r")
+	var l uint32
+	if mnemonic[0] == 'l' {
+		l = 1
+	}
+
+	if i.Rn.Sf != 1 || (i.Rn.N == 31 && !i.Rn.SP) {
+		return nil, fmt.Errorf("the base register must be an x register or sp, not %v", i.Rn)
+	}
+	if i.Rm != nil && (i.Rm.Sf != 1 || i.Rm.N == 31) {
+		return nil, fmt.Errorf("the post-index register must be x0-x30, not %v", i.Rm)
+	}
+
+	var n []uint32
+	for _, r := range i.List {
+		n = append(n, r.N)
+	}
+	for _, r := range i.Elements {
+		n = append(n, r.N)
+	}
+	if err := consecutive(n); err != nil {
+		return nil, err
+	}
+	if (selem > 1 || replicate || i.Elements != nil) && len(n) != selem {
+		if selem == 1 {
+			return nil, fmt.Errorf("%s needs a list of one register, not %d", mnemonic, len(n))
+		}
+		return nil, fmt.Errorf("%s needs a list of %d registers, not %d", mnemonic, selem, len(n))
+	}
+	if len(n) > 4 {
+		return nil, fmt.Errorf("a list of %d registers is too long", len(n))
+	}
+
+	// The single structure forms encode the lane in Q, S and size, and the number of elements in
+	// the low bit of the opcode and R.
+	var q, s, size, op, r, bytes uint32
+	single := i.Elements != nil || replicate
+	switch {
+	case i.Elements != nil:
+		if replicate {
+			return nil, fmt.Errorf("%s cannot be used with a lane", mnemonic)
+		}
+		if i.Index == nil {
+			return nil, fmt.Errorf("%v needs a lane index, like %v[0]", i.Elements[0], i.Elements[0])
+		}
+		scale := i.Elements[0].Size
+		for _, e := range i.Elements {
+			if e.Size != scale {
+				return nil, fmt.Errorf("%v and %v do not have the same element size", i.Elements[0], e)
+			}
+		}
+		if *i.Index < 0 || *i.Index >= 16>>scale {
+			return nil, fmt.Errorf("lane %d is out of range [0, %d]", *i.Index, 16>>scale-1)
+		}
+		index := uint32(*i.Index)
+		switch scale {
+		case 0:
+			op, q, s, size = 0b000, index>>3, index>>2&1, index&0b11
+		case 1:
+			op, q, s, size = 0b010, index>>2, index>>1&1, (index&1)<<1
+		case 2:
+			op, q, s = 0b100, index>>1, index&1
+		case 3:
+			op, q, size = 0b100, index, 0b01
+		}
+		op |= uint32(selem-1) >> 1
+		r = uint32(selem-1) & 1
+		bytes = uint32(selem) << scale
+	case i.Index != nil:
+		return nil, fmt.Errorf("a lane needs registers with an element size, like v0.s")
+	default:
+		for _, v := range i.List {
+			if v.Q != i.List[0].Q || v.Size != i.List[0].Size {
+				return nil, fmt.Errorf("%v and %v do not have the same arrangement", i.List[0], v)
+			}
+		}
+		q, size = i.List[0].Q, i.List[0].Size
+		switch {
+		case replicate:
+			op, r, s = 0b110|uint32(selem-1)>>1, uint32(selem-1)&1, 0
+			bytes = uint32(selem) << size
+		case selem > 1 && q == 0 && size == 0b11:
+			return nil, fmt.Errorf("%v is not a valid arrangement for %s", i.List[0], mnemonic)
+		case selem > 1:
+			op = multipleOpcodesN[selem]
+			bytes = uint32(selem) * 8 << q
+		default:
+			op = multipleOpcodes1[len(n)]
+			bytes = uint32(len(n)) * 8 << q
+		}
+	}
+
+	rm := uint32(31)
+	switch {
+	case i.Imm != nil && *i.Imm != Immediate(bytes):
+		return nil, fmt.Errorf("%s of these registers can only be post-indexed by #%d, not #%d", mnemonic, bytes, *i.Imm)
+	case i.Rm != nil:
+		rm = i.Rm.N
+	}
+	post := i.Imm != nil || i.Rm != nil
+	switch {
+	case single && post:
+		return []opcode.Instruction{&opcode.LoadStoreSinglePostIndexed{Q: q, L: l, R: r, Rm: rm, Opcode: op, S: s, Size: size, Rn: i.Rn.N, Rt: n[0]}}, nil
+	case single:
+		return []opcode.Instruction{&opcode.LoadStoreSingle{Q: q, L: l, R: r, Opcode: op, S: s, Size: size, Rn: i.Rn.N, Rt: n[0]}}, nil
+	case post:
+		return []opcode.Instruction{&opcode.LoadStoreMultiplePostIndexed{Q: q, L: l, Rm: rm, Opcode: op, Size: size, Rn: i.Rn.N, Rt: n[0]}}, nil
+	}
+	return []opcode.Instruction{&opcode.LoadStoreMultiple{Q: q, L: l, Opcode: op, Size: size, Rn: i.Rn.N, Rt: n[0]}}, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// VectorLogical is a bitwise operation on whole vectors, AND, BIC, ORR, ORN, EOR, BSL, BIT or BIF,
+// or NOT and its MVN alias, or the MOV alias of ORR.  Only the 8b and 16b arrangements are allowed,
+// since the operations do not depend on the element size.
+type VectorLogical struct {
+	Mnemonic string        `@("and" | "bic" | "orr" | "orn" | "eor" | "bsl" | "bit" | "bif" | "not" | "mvn" | "mov")`
+	Vd       RegisterNeon  `@(RegisterNeon TypeSpecifier) ","`
+	Vn       RegisterNeon  `@(RegisterNeon TypeSpecifier)`
+	Vm       *RegisterNeon `("," @(RegisterNeon TypeSpecifier))?`
+}
+
+func (i *VectorLogical) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	unary := mnemonic == "not" || mnemonic == "mvn" || mnemonic == "mov"
+	switch {
+	case unary && i.Vm != nil:
+		return nil, fmt.Errorf("%s takes two registers", mnemonic)
+	case !unary && i.Vm == nil:
+		return nil, fmt.Errorf("%s takes three registers", mnemonic)
+	}
+	vm := i.Vn
+	if i.Vm != nil {
+		vm = *i.Vm
+	}
+	if err := sameArrangement(i.Vd, i.Vn, vm); err != nil {
+		return nil, err
+	}
+	if err := byteVector(i.Vd, mnemonic); err != nil {
+		return nil, err
+	}
+	q, rm, rn, rd := i.Vd.Q, vm.N, i.Vn.N, i.Vd.N
+	switch mnemonic {
+	case "and":
+		return []opcode.Instruction{&opcode.AndVector{Q: q, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "bic":
+		return []opcode.Instruction{&opcode.BicVector{Q: q, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "orr", "mov":
+		return []opcode.Instruction{&opcode.OrrVector{Q: q, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "orn":
+		return []opcode.Instruction{&opcode.OrnVector{Q: q, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "eor":
+		return []opcode.Instruction{&opcode.EorVector{Q: q, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "bsl":
+		return []opcode.Instruction{&opcode.Bsl{Q: q, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "bit":
+		return []opcode.Instruction{&opcode.Bit{Q: q, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "bif":
+		return []opcode.Instruction{&opcode.Bif{Q: q, Rm: rm, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.Not{Q: q, Rn: rn, Rd: rd}}, nil
+}
+
+// VectorCompareZero is CMEQ, CMGT, CMGE, CMLE or CMLT comparing each element of Vn with zero.
+type VectorCompareZero struct {
+	Mnemonic string       `@("cmeq" | "cmgt" | "cmge" | "cmle" | "cmlt")`
+	Vd       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vn       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Zero     Immediate    `"#" @Integer`
+}
+
+func (i *VectorCompareZero) Validate() ([]opcode.Instruction, error) {
+	if err := sameArrangement(i.Vd, i.Vn); err != nil {
+		return nil, err
+	}
+	mnemonic := strings.ToLower(i.Mnemonic)
+	if i.Vd.Q == 0 && i.Vd.Size == 0b11 {
+		return nil, fmt.Errorf("%v is not a valid arrangement for %s", i.Vd, mnemonic)
+	}
+	if i.Zero != 0 {
+		return nil, fmt.Errorf("%s can only compare with #0, not #%d", mnemonic, i.Zero)
+	}
+	q, size, rn, rd := i.Vd.Q, i.Vd.Size, i.Vn.N, i.Vd.N
+	switch mnemonic {
+	case "cmeq":
+		return []opcode.Instruction{&opcode.CmeqZero{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "cmgt":
+		return []opcode.Instruction{&opcode.CmgtZero{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "cmge":
+		return []opcode.Instruction{&opcode.CmgeZero{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "cmle":
+		return []opcode.Instruction{&opcode.CmleZero{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.CmltZero{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+}
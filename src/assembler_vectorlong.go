@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// VectorLong is a vector instruction from the three different group, whose operands do not all
+// have the same element size: the long forms (SADDL, SMULL, ...) widen two narrow sources, the
+// wide forms (SADDW, ...) widen the second source only, and the high narrow forms (ADDHN, ...)
+// narrow the result.  The forms with a 2 suffix use the upper half of their narrow operands.
+type VectorLong struct {
+	Mnemonic string       `@("saddl" | "saddl2" | "uaddl" | "uaddl2" | "saddw" | "saddw2" | "uaddw" | "uaddw2" | "ssubl" | "ssubl2" | "usubl" | "usubl2" | "ssubw" | "ssubw2" | "usubw" | "usubw2" | "addhn" | "addhn2" | "raddhn" | "raddhn2" | "subhn" | "subhn2" | "rsubhn" | "rsubhn2" | "sabal" | "sabal2" | "uabal" | "uabal2" | "sabdl" | "sabdl2" | "uabdl" | "uabdl2" | "smlal" | "smlal2" | "umlal" | "umlal2" | "smlsl" | "smlsl2" | "umlsl" | "umlsl2" | "smull" | "smull2" | "umull" | "umull2")`
+	Vd       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vn       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vm       RegisterNeon `@(RegisterNeon TypeSpecifier)`
+}
+
+func (i *VectorLong) Validate() ([]opcode.Instruction, error) {
+	name := strings.ToLower(i.Mnemonic)
+	mnemonic := strings.TrimSuffix(name, "2")
+	var q uint32
+	if mnemonic != name {
+		q = 1
+	}
+	// The size of the narrow elements comes from Vd for the high narrow forms, and from Vm for the
+	// others.
+	narrow := i.Vm
+	if strings.HasSuffix(mnemonic, "hn") {
+		narrow = i.Vd
+	}
+	if narrow.Q != q || narrow.Size == 0b11 {
+		return nil, fmt.Errorf("%v is not a valid arrangement for %s", narrow, name)
+	}
+	size := narrow.Size
+	n, w := RegisterNeon{Q: q, Size: size}, RegisterNeon{Q: 1, Size: size + 1}
+	want := [3]RegisterNeon{w, n, n}
+	switch {
+	case strings.HasSuffix(mnemonic, "hn"):
+		want = [3]RegisterNeon{n, w, w}
+	case strings.HasSuffix(mnemonic, "w"):
+		want = [3]RegisterNeon{w, w, n}
+	}
+	for j, r := range [3]RegisterNeon{i.Vd, i.Vn, i.Vm} {
+		if r.Q != want[j].Q || r.Size != want[j].Size {
+			want[j].N = r.N
+			return nil, fmt.Errorf("%s with %v needs %v, not %v", name, narrow, want[j], r)
+		}
+	}
+	rm, rn, rd := i.Vm.N, i.Vn.N, i.Vd.N
+	switch mnemonic {
+	case "saddl":
+		return []opcode.Instruction{&opcode.Saddl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "uaddl":
+		return []opcode.Instruction{&opcode.Uaddl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "saddw":
+		return []opcode.Instruction{&opcode.Saddw{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "uaddw":
+		return []opcode.Instruction{&opcode.Uaddw{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "ssubl":
+		return []opcode.Instruction{&opcode.Ssubl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "usubl":
+		return []opcode.Instruction{&opcode.Usubl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "ssubw":
+		return []opcode.Instruction{&opcode.Ssubw{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "usubw":
+		return []opcode.Instruction{&opcode.Usubw{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "addhn":
+		return []opcode.Instruction{&opcode.Addhn{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "raddhn":
+		return []opcode.Instruction{&opcode.Raddhn{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "subhn":
+		return []opcode.Instruction{&opcode.Subhn{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "rsubhn":
+		return []opcode.Instruction{&opcode.Rsubhn{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "sabal":
+		return []opcode.Instruction{&opcode.Sabal{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "uabal":
+		return []opcode.Instruction{&opcode.Uabal{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "sabdl":
+		return []opcode.Instruction{&opcode.Sabdl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "uabdl":
+		return []opcode.Instruction{&opcode.Uabdl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "smlal":
+		return []opcode.Instruction{&opcode.Smlal{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "umlal":
+		return []opcode.Instruction{&opcode.Umlal{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "smlsl":
+		return []opcode.Instruction{&opcode.Smlsl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "umlsl":
+		return []opcode.Instruction{&opcode.Umlsl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "smull":
+		return []opcode.Instruction{&opcode.SmullVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.UmullVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+}
+
+// Shll is SHLL or SHLL2, whose shift must be the size of the source elements.
+type Shll struct {
+	Mnemonic string       `@("shll" | "shll2")`
+	Vd       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vn       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Shift    Immediate    `"#" @Integer`
+}
+
+func (i *Shll) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	var q uint32
+	if mnemonic == "shll2" {
+		q = 1
+	}
+	if i.Vn.Q != q || i.Vn.Size == 0b11 {
+		return nil, fmt.Errorf("%v is not a valid arrangement for %s", i.Vn, mnemonic)
+	}
+	if i.Vd.Q != 1 || i.Vd.Size != i.Vn.Size+1 {
+		return nil, fmt.Errorf("%s widens %v to a vector of elements twice the size, not %v", mnemonic, i.Vn, i.Vd)
+	}
+	if esize := 8 << i.Vn.Size; int64(i.Shift) != int64(esize) {
+		return nil, fmt.Errorf("%s of %v shifts by #%d, not #%d", mnemonic, i.Vn, esize, i.Shift)
+	}
+	return []opcode.Instruction{&opcode.Shll{Q: q, Size: i.Vn.Size, Rn: i.Vn.N, Rd: i.Vd.N}}, nil
+}
+
+// VectorPairwiseLong is SADDLP, UADDLP, SADALP or UADALP, which add adjacent pairs of elements
+// of Vn into the elements of Vd, which are twice the size.
+type VectorPairwiseLong struct {
+	Mnemonic string       `@("saddlp" | "uaddlp" | "sadalp" | "uadalp")`
+	Vd       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vn       RegisterNeon `@(RegisterNeon TypeSpecifier)`
+}
+
+func (i *VectorPairwiseLong) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	if i.Vn.Size == 0b11 {
+		return nil, fmt.Errorf("%v is not a valid arrangement for %s", i.Vn, mnemonic)
+	}
+	if i.Vd.Q != i.Vn.Q || i.Vd.Size != i.Vn.Size+1 {
+		return nil, fmt.Errorf("%s adds pairs of elements of %v into a vector of half as many elements, not %v", mnemonic, i.Vn, i.Vd)
+	}
+	q, size, rn, rd := i.Vn.Q, i.Vn.Size, i.Vn.N, i.Vd.N
+	switch mnemonic {
+	case "saddlp":
+		return []opcode.Instruction{&opcode.Saddlp{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "uaddlp":
+		return []opcode.Instruction{&opcode.Uaddlp{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	case "sadalp":
+		return []opcode.Instruction{&opcode.Sadalp{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.Uadalp{Q: q, Size: size, Rn: rn, Rd: rd}}, nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// VectorPermute is ZIP1, ZIP2, UZP1, UZP2, TRN1 or TRN2, which interleave or de-interleave the
+// elements of two vectors with the same arrangement.
+type VectorPermute struct {
+	Mnemonic string       `@("zip1" | "zip2" | "uzp1" | "uzp2" | "trn1" | "trn2")`
+	Vd       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vn       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vm       RegisterNeon `@(RegisterNeon TypeSpecifier)`
+}
+
+func (i *VectorPermute) Validate() ([]opcode.Instruction, error) {
+	if err := sameArrangement(i.Vd, i.Vn, i.Vm); err != nil {
+		return nil, err
+	}
+	mnemonic := strings.ToLower(i.Mnemonic)
+	if i.Vd.Q == 0 && i.Vd.Size == 0b11 {
+		return nil, fmt.Errorf("%v is not a valid arrangement for %s", i.Vd, mnemonic)
+	}
+	q, size, rm, rn, rd := i.Vd.Q, i.Vd.Size, i.Vm.N, i.Vn.N, i.Vd.N
+	switch mnemonic {
+	case "zip1":
+		return []opcode.Instruction{&opcode.Zip1{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "zip2":
+		return []opcode.Instruction{&opcode.Zip2{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "uzp1":
+		return []opcode.Instruction{&opcode.Uzp1{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "uzp2":
+		return []opcode.Instruction{&opcode.Uzp2{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	case "trn1":
+		return []opcode.Instruction{&opcode.Trn1{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.Trn2{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}}, nil
+}
+
+// byteVector checks that r has one of the byte arrangements, 8b or 16b, which are the only ones
+// allowed by EXT, TBL and TBX.
+func byteVector(r RegisterNeon, mnemonic string) error {
+	if r.Size != 0b00 {
+		return fmt.Errorf("%v is not a valid arrangement for %s", r, mnemonic)
+	}
+	return nil
+}
+
+// Ext extracts a vector from a pair of vectors, starting at the byte of Vn given by Index.
+type Ext struct {
+	Vd    RegisterNeon `"ext" @(RegisterNeon TypeSpecifier) ","`
+	Vn    RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vm    RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Index Immediate    `"#" @Integer`
+}
+
+func (i *Ext) Validate() ([]opcode.Instruction, error) {
+	if err := sameArrangement(i.Vd, i.Vn, i.Vm); err != nil {
+		return nil, err
+	}
+	if err := byteVector(i.Vd, "ext"); err != nil {
+		return nil, err
+	}
+	if n := Immediate(8 << i.Vd.Q); i.Index < 0 || i.Index >= n {
+		return nil, fmt.Errorf("index %d is out of range [0, %d] for %v", i.Index, n-1, i.Vd)
+	}
+	return []opcode.Instruction{&opcode.Ext{Q: i.Vd.Q, Rm: i.Vm.N, Imm4: uint32(i.Index), Rn: i.Vn.N, Rd: i.Vd.N}}, nil
+}
+
+// TableLookup is TBL or TBX, which look up each byte of Vm in a table of one to four consecutive
+// 16b registers.
+type TableLookup struct {
+	Mnemonic string       `@("tbl" | "tbx")`
+	Vd       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Table    VectorList   `"{" @(RegisterNeon TypeSpecifier) ("," @(RegisterNeon TypeSpecifier))* "}" ","`
+	Vm       RegisterNeon `@(RegisterNeon TypeSpecifier)`
+}
+
+func (i *TableLookup) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	if err := sameArrangement(i.Vd, i.Vm); err != nil {
+		return nil, err
+	}
+	if err := byteVector(i.Vd, mnemonic); err != nil {
+		return nil, err
+	}
+	var n []uint32
+	for _, r := range i.Table {
+		if r.Q != 1 || r.Size != 0b00 {
+			return nil, fmt.Errorf("the table of %s must be 16b registers, not %v", mnemonic, r)
+		}
+		n = append(n, r.N)
+	}
+	if err := consecutive(n); err != nil {
+		return nil, err
+	}
+	if len(n) > 4 {
+		return nil, fmt.Errorf("a table of %d registers is too long", len(n))
+	}
+	length := uint32(len(n) - 1)
+	if mnemonic == "tbx" {
+		return []opcode.Instruction{&opcode.Tbx{Q: i.Vd.Q, Rm: i.Vm.N, Len: length, Rn: n[0], Rd: i.Vd.N}}, nil
+	}
+	return []opcode.Instruction{&opcode.Tbl{Q: i.Vd.Q, Rm: i.Vm.N, Len: length, Rn: n[0], Rd: i.Vd.N}}, nil
+}
+
+// elementRegister checks that r is the general purpose register that holds an element with the
+// given size field: an x register for 64-bit elements and a w register otherwise.
+func elementRegister(r GeneralRegister, size uint32) error {
+	want := GeneralRegister{N: r.N}
+	if size == 0b11 {
+		want.Sf = 1
+	}
+	if r.Sf != want.Sf || r.SP {
+		return fmt.Errorf("%v is not a valid register for %s elements, use %v", r, "bhsd"[size:size+1], want)
+	}
+	return nil
+}
+
+// Dup copies a lane of a vector, or the low bits of a general purpose register, to every element
+// of Vd.
+type Dup struct {
+	Vd      RegisterNeon     `"dup" @(RegisterNeon TypeSpecifier) ","`
+	Element *VectorLane      `( @@`
+	Rn      *GeneralRegister `| @RegisterGeneral )`
+}
+
+func (i *Dup) Validate() ([]opcode.Instruction, error) {
+	if i.Vd.Q == 0 && i.Vd.Size == 0b11 {
+		return nil, fmt.Errorf("%v is not a valid arrangement for dup", i.Vd)
+	}
+	if i.Rn != nil {
+		if err := elementRegister(*i.Rn, i.Vd.Size); err != nil {
+			return nil, err
+		}
+		return []opcode.Instruction{&opcode.DupGeneral{Q: i.Vd.Q, Imm5: 1 << i.Vd.Size, Rn: i.Rn.N, Rd: i.Vd.N}}, nil
+	}
+	if i.Element.Element.Size != i.Vd.Size {
+		return nil, fmt.Errorf("dup to %v needs a lane with the same element size, not %v", i.Vd, i.Element)
+	}
+	imm5, err := i.Element.imm5()
+	if err != nil {
+		return nil, err
+	}
+	return []opcode.Instruction{&opcode.DupElement{Q: i.Vd.Q, Imm5: imm5, Rn: i.Element.Element.N, Rd: i.Vd.N}}, nil
+}
+
+// Ins is INS, or its MOV alias, which copies a lane of a vector, or the low bits of a general
+// purpose register, to a lane of Vd without changing its other lanes.
+type Ins struct {
+	Mnemonic string           `@("ins" | "mov")`
+	Dst      VectorLane       `@@ ","`
+	Src      *VectorLane      `( @@`
+	Rn       *GeneralRegister `| @RegisterGeneral )`
+}
+
+func (i *Ins) Validate() ([]opcode.Instruction, error) {
+	imm5, err := i.Dst.imm5()
+	if err != nil {
+		return nil, err
+	}
+	size, rd := i.Dst.Element.Size, i.Dst.Element.N
+	if i.Rn != nil {
+		if err := elementRegister(*i.Rn, size); err != nil {
+			return nil, err
+		}
+		return []opcode.Instruction{&opcode.InsGeneral{Imm5: imm5, Rn: i.Rn.N, Rd: rd}}, nil
+	}
+	if i.Src.Element.Size != size {
+		return nil, fmt.Errorf("%v and %v do not have the same element size", i.Dst, i.Src)
+	}
+	if _, err := i.Src.imm5(); err != nil {
+		return nil, err
+	}
+	return []opcode.Instruction{&opcode.InsElement{Imm5: imm5, Imm4: uint32(i.Src.Index) << size, Rn: i.Src.Element.N, Rd: rd}}, nil
+}
+
+// MoveToGeneral is UMOV, SMOV or the MOV alias of UMOV, which copy a lane of a vector to a general
+// purpose register, zero or sign extending it.  MOV is only used for lanes that fill Rd.
+type MoveToGeneral struct {
+	Mnemonic string          `@("umov" | "smov" | "mov")`
+	Rd       GeneralRegister `@RegisterGeneral ","`
+	Src      VectorLane      `@@`
+}
+
+func (i *MoveToGeneral) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	imm5, err := i.Src.imm5()
+	if err != nil {
+		return nil, err
+	}
+	if i.Rd.SP {
+		return nil, fmt.Errorf("%s cannot write %v", mnemonic, i.Rd)
+	}
+	size, q := i.Src.Element.Size, i.Rd.Sf
+	var valid bool
+	switch mnemonic {
+	case "smov":
+		valid = size < q+0b10
+	case "umov":
+		valid = size == 0b11 == (q == 1)
+	default:
+		valid = size == q+0b10
+	}
+	if !valid {
+		return nil, fmt.Errorf("%s cannot copy %v to %v", mnemonic, i.Src, i.Rd)
+	}
+	if mnemonic == "smov" {
+		return []opcode.Instruction{&opcode.Smov{Q: q, Imm5: imm5, Rn: i.Src.Element.N, Rd: i.Rd.N}}, nil
+	}
+	return []opcode.Instruction{&opcode.Umov{Q: q, Imm5: imm5, Rn: i.Src.Element.N, Rd: i.Rd.N}}, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// shiftImmediate returns the immh and immb fields for a shift of an element of 2^size bytes.  Left
+// shifts add the amount to the element size and right shifts subtract it from twice the size.
+func shiftImmediate(size uint32, shift Immediate, left bool) (immh, immb uint32) {
+	esize := Immediate(8 << size)
+	imm := 2*esize - shift
+	if left {
+		imm = esize + shift
+	}
+	return uint32(imm) >> 3, uint32(imm) & 0b111
+}
+
+// VectorShift is a shift of every element of a vector by an immediate: SSHR, USHR, SSRA, USRA,
+// SRSHR, URSHR, SRSRA, URSRA, SRI, SHL or SLI.
+type VectorShift struct {
+	Mnemonic string       `@("sshr" | "ushr" | "ssra" | "usra" | "srshr" | "urshr" | "srsra" | "ursra" | "sri" | "shl" | "sli")`
+	Vd       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vn       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Shift    Immediate    `"#" @Integer`
+}
+
+func (i *VectorShift) Validate() ([]opcode.Instruction, error) {
+	if err := sameArrangement(i.Vd, i.Vn); err != nil {
+		return nil, err
+	}
+	mnemonic := strings.ToLower(i.Mnemonic)
+	if i.Vd.Q == 0 && i.Vd.Size == 0b11 {
+		return nil, fmt.Errorf("%v is not a valid arrangement for %s", i.Vd, mnemonic)
+	}
+	left := mnemonic == "shl" || mnemonic == "sli"
+	lo, hi := Immediate(1), Immediate(8<<i.Vd.Size)
+	if left {
+		lo, hi = 0, hi-1
+	}
+	if i.Shift < lo || i.Shift > hi {
+		return nil, fmt.Errorf("shift %d is out of range [%d, %d] for %v", i.Shift, lo, hi, i.Vd)
+	}
+	immh, immb := shiftImmediate(i.Vd.Size, i.Shift, left)
+	q, rn, rd := i.Vd.Q, i.Vn.N, i.Vd.N
+	switch mnemonic {
+	case "sshr":
+		return []opcode.Instruction{&opcode.Sshr{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "ushr":
+		return []opcode.Instruction{&opcode.Ushr{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "ssra":
+		return []opcode.Instruction{&opcode.Ssra{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "usra":
+		return []opcode.Instruction{&opcode.Usra{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "srshr":
+		return []opcode.Instruction{&opcode.Srshr{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "urshr":
+		return []opcode.Instruction{&opcode.Urshr{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "srsra":
+		return []opcode.Instruction{&opcode.Srsra{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "ursra":
+		return []opcode.Instruction{&opcode.Ursra{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "sri":
+		return []opcode.Instruction{&opcode.Sri{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "shl":
+		return []opcode.Instruction{&opcode.Shl{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.Sli{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+}
+
+// VectorShiftNarrow is a narrowing shift right by an immediate: SHRN, RSHRN, SQSHRN, UQSHRN,
+// SQRSHRN, UQRSHRN, SQSHRUN or SQRSHRUN.  As with VectorNarrow, the forms with a 2 suffix write
+// the upper half of Vd.
+type VectorShiftNarrow struct {
+	Mnemonic string       `@("shrn" | "shrn2" | "rshrn" | "rshrn2" | "sqshrn" | "sqshrn2" | "uqshrn" | "uqshrn2" | "sqrshrn" | "sqrshrn2" | "uqrshrn" | "uqrshrn2" | "sqshrun" | "sqshrun2" | "sqrshrun" | "sqrshrun2")`
+	Vd       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Vn       RegisterNeon `@(RegisterNeon TypeSpecifier) ","`
+	Shift    Immediate    `"#" @Integer`
+}
+
+func (i *VectorShiftNarrow) Validate() ([]opcode.Instruction, error) {
+	mnemonic := strings.ToLower(i.Mnemonic)
+	var q uint32
+	if strings.HasSuffix(mnemonic, "2") {
+		q = 1
+	}
+	if i.Vd.Q != q || i.Vd.Size == 0b11 {
+		return nil, fmt.Errorf("%v is not a valid arrangement for %s", i.Vd, mnemonic)
+	}
+	if i.Vn.Q != 1 || i.Vn.Size != i.Vd.Size+1 {
+		return nil, fmt.Errorf("%s narrows %v from a vector of elements twice the size, not %v", mnemonic, i.Vd, i.Vn)
+	}
+	if hi := Immediate(8 << i.Vd.Size); i.Shift < 1 || i.Shift > hi {
+		return nil, fmt.Errorf("shift %d is out of range [1, %d] for %v", i.Shift, hi, i.Vd)
+	}
+	immh, immb := shiftImmediate(i.Vd.Size, i.Shift, false)
+	rn, rd := i.Vn.N, i.Vd.N
+	switch strings.TrimSuffix(mnemonic, "2") {
+	case "shrn":
+		return []opcode.Instruction{&opcode.Shrn{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "rshrn":
+		return []opcode.Instruction{&opcode.Rshrn{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "sqshrn":
+		return []opcode.Instruction{&opcode.Sqshrn{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "uqshrn":
+		return []opcode.Instruction{&opcode.Uqshrn{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "sqrshrn":
+		return []opcode.Instruction{&opcode.Sqrshrn{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "uqrshrn":
+		return []opcode.Instruction{&opcode.Uqrshrn{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	case "sqshrun":
+		return []opcode.Instruction{&opcode.Sqshrun{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+	}
+	return []opcode.Instruction{&opcode.Sqrshrun{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}}, nil
+}
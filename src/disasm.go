@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/runningwild/javelin/opcode"
+)
+
+// disasm implements "javelin disasm", which prints every instruction in either a raw binary or the
+// .text section of an ELF file along with its address and encoding.
+func disasm(args []string) error {
+	fs := flag.NewFlagSet("disasm", flag.ExitOnError)
+	base := fs.Uint64("base", 0, "address of the first byte of a raw binary, ignored for ELF files")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: javelin disasm [-base addr] file\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	path := fs.Arg(0)
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %q: %w", path, err)
+	}
+	addr := *base
+	if bytes.HasPrefix(code, []byte(elf.ELFMAG)) {
+		code, addr, err = elfText(code)
+		if err != nil {
+			return fmt.Errorf("failed to load %q: %w", path, err)
+		}
+	}
+	return disassemble(os.Stdout, code, addr)
+}
+
+// elfText returns the contents and address of the .text section of an AArch64 ELF file.
+func elfText(data []byte) ([]byte, uint64, error) {
+	f, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	if f.Machine != elf.EM_AARCH64 {
+		return nil, 0, fmt.Errorf("ELF machine is %v, not %v", f.Machine, elf.EM_AARCH64)
+	}
+	text := f.Section(".text")
+	if text == nil {
+		return nil, 0, fmt.Errorf("no .text section")
+	}
+	code, err := text.Data()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read .text section: %w", err)
+	}
+	return code, text.Addr, nil
+}
+
+// disassemble writes one line per word in code, the first of which is at addr.  Words that cannot
+// be decoded are written as .inst directives, and PC-relative instructions are annotated with the
+// address they refer to.
+func disassemble(w io.Writer, code []byte, addr uint64) error {
+	for ; len(code) >= 4; code, addr = code[4:], addr+4 {
+		word := binary.LittleEndian.Uint32(code)
+		var text string
+		inst, err := opcode.Decode(word)
+		if err != nil {
+			text = fmt.Sprintf(".inst 0x%08x // %v", word, errors.Unwrap(err))
+		} else {
+			text = inst.String()
+		}
+		if rel, ok := inst.(opcode.PCRelative); ok {
+			text += fmt.Sprintf(" // 0x%x", rel.Target(addr))
+		}
+		if _, err := fmt.Fprintf(w, "%8x:\t%08x\t%s\n", addr, word, text); err != nil {
+			return err
+		}
+	}
+	if len(code) > 0 {
+		return fmt.Errorf("%d trailing bytes at 0x%x are not a whole instruction", len(code), addr)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestDisassemble(t *testing.T) {
+	for _, tc := range []struct {
+		code []byte
+		addr uint64
+		want string
+		err  string
+	}{
+		{
+			code: []byte{0x00, 0x7d, 0x20, 0xd4},
+			addr: 0x400000,
+			want: "  400000:\td4207d00\tbrk #0x3e8\n",
+		},
+		{
+			code: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x7d, 0x20, 0xd4},
+			addr: 0x10,
+			want: "      10:\t00000000\t.inst 0x00000000 // unallocated encoding\n" +
+				"      14:\td4207d00\tbrk #0x3e8\n",
+		},
+		{
+			code: []byte{0x02, 0x00, 0x00, 0x14},
+			addr: 0x1000,
+			want: "    1000:\t14000002\tb #8 // 0x1008\n",
+		},
+		{
+			code: []byte{0x00, 0x7d, 0x20, 0xd4, 0x00, 0x7d},
+			addr: 0x20,
+			want: "      20:\td4207d00\tbrk #0x3e8\n",
+			err:  "2 trailing bytes at 0x24 are not a whole instruction",
+		},
+	} {
+		var buf bytes.Buffer
+		err := disassemble(&buf, tc.code, tc.addr)
+		if got := buf.String(); got != tc.want {
+			t.Errorf("disassemble(% x, 0x%x) wrote %q, want %q", tc.code, tc.addr, got, tc.want)
+		}
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("disassemble(% x, 0x%x) returned %v", tc.code, tc.addr, err)
+		case tc.err != "" && (err == nil || err.Error() != tc.err):
+			t.Errorf("disassemble(% x, 0x%x) returned %v, want %q", tc.code, tc.addr, err, tc.err)
+		}
+	}
+}
+
+// elfHeader returns a little-endian 64-bit ELF file for machine that has no sections.
+func elfHeader(machine elf.Machine) []byte {
+	hdr := elf.Header64{
+		Type:    uint16(elf.ET_EXEC),
+		Machine: uint16(machine),
+		Version: uint32(elf.EV_CURRENT),
+		Ehsize:  uint16(binary.Size(elf.Header64{})),
+	}
+	copy(hdr.Ident[:], elf.ELFMAG)
+	hdr.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	hdr.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	hdr.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, &hdr)
+	return buf.Bytes()
+}
+
+func TestElfTextErrors(t *testing.T) {
+	for _, tc := range []struct {
+		data []byte
+		err  string
+	}{
+		{elfHeader(elf.EM_X86_64), "ELF machine is EM_X86_64, not EM_AARCH64"},
+		{elfHeader(elf.EM_AARCH64), "no .text section"},
+		{[]byte(elf.ELFMAG), "EOF"},
+	} {
+		if _, _, err := elfText(tc.data); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("elfText(% x) returned %v, want an error containing %q", tc.data, err, tc.err)
+		}
+	}
+}
@@ -39,6 +39,35 @@ func (v *VectorRegister) Set(lane, esize int, val uint64) {
 	}
 }
 
+// Condition flags, as returned by Machine.NZCV.
+const (
+	FlagN uint32 = 0b1000 // Negative
+	FlagZ uint32 = 0b0100 // Zero
+	FlagC uint32 = 0b0010 // Carry
+	FlagV uint32 = 0b0001 // Overflow
+)
+
+// Floating-point Status Register bits.  QC is set by the saturating integer instructions, the
+// rest are the cumulative floating-point exception flags.
+const (
+	FPSRQC  uint32 = 1 << 27 // Saturation
+	FPSRIDC uint32 = 1 << 7  // Input Denormal
+	FPSRIXC uint32 = 1 << 4  // Inexact
+	FPSRUFC uint32 = 1 << 3  // Underflow
+	FPSROFC uint32 = 1 << 2  // Overflow
+	FPSRDZC uint32 = 1 << 1  // Divide by Zero
+	FPSRIOC uint32 = 1 << 0  // Invalid Operation
+)
+
+// Floating-point Control Register bits.  The rounding mode is the 2-bit field at FPCRRModeShift.
+const (
+	FPCRAHP        uint32 = 1 << 26 // Alternative half-precision
+	FPCRDN         uint32 = 1 << 25 // Default NaN
+	FPCRFZ         uint32 = 1 << 24 // Flush-to-zero
+	FPCRRModeShift        = 22
+	FPCRFZ16       uint32 = 1 << 19 // Flush-to-zero for half-precision
+)
+
 // Machine represents the state of the ARMv8-A machine.
 type Machine struct {
 	// General-purpose registers x0-x30.
@@ -49,10 +78,27 @@ type Machine struct {
 	PC uint64
 	// Stack Pointer.
 	SP uint64
-	// Current Program Status Register.
+	// Current Program Status Register.  The condition flags are held in bits 31:28, use NZCV and
+	// SetNZCV to access them.
 	CPSR uint32
+	// Floating-point Status and Control Registers, made of the FPSR and FPCR bits.
+	FPSR uint32
+	FPCR uint32
 	// Memory. A simple byte slice for simulation.
 	Memory []byte
+
+	// Decode turns a fetched word into an instruction.  It must be set before calling Step or Run.
+	Decode func(word uint32) (Instruction, error)
+	// Breakpoints are addresses at which Run stops before executing the instruction there.
+	Breakpoints map[uint64]bool
+	// Budget is the number of instructions a single call to Run may execute, or 0 for no limit.
+	Budget uint64
+	// Retired counts the instructions that have completed.
+	Retired uint64
+
+	stop     StopReason
+	fault    error
+	branched bool
 }
 
 // New creates a new Machine with initialized memory.
@@ -62,6 +108,48 @@ func New(memorySize int) *Machine {
 	}
 }
 
+// NZCV returns the condition flags as a 4-bit value, a combination of FlagN, FlagZ, FlagC and
+// FlagV.
+func (m *Machine) NZCV() uint32 {
+	return m.CPSR >> 28
+}
+
+// SetNZCV sets the condition flags from the low 4 bits of nzcv.
+func (m *Machine) SetNZCV(nzcv uint32) {
+	m.CPSR = m.CPSR&0x0fffffff | (nzcv&0b1111)<<28
+}
+
+// ConditionHolds reports whether the current flags satisfy the 4-bit condition code cond, as used
+// by B.cond and the conditional select and compare instructions.
+func (m *Machine) ConditionHolds(cond uint32) bool {
+	nzcv := m.NZCV()
+	n, z, c, v := nzcv&FlagN != 0, nzcv&FlagZ != 0, nzcv&FlagC != 0, nzcv&FlagV != 0
+	var result bool
+	switch cond >> 1 & 0b111 {
+	case 0b000: // EQ or NE
+		result = z
+	case 0b001: // CS or CC
+		result = c
+	case 0b010: // MI or PL
+		result = n
+	case 0b011: // VS or VC
+		result = v
+	case 0b100: // HI or LS
+		result = c && !z
+	case 0b101: // GE or LT
+		result = n == v
+	case 0b110: // GT or LE
+		result = n == v && !z
+	case 0b111: // AL
+		result = true
+	}
+	// The odd conditions are the inverse of the even ones, except that NV behaves like AL.
+	if cond&1 == 1 && cond&0b1111 != 0b1111 {
+		result = !result
+	}
+	return result
+}
+
 // PrintState prints the current state of the machine's registers and PC.
 func (m *Machine) PrintState() {
 	fmt.Println("Registers:")
@@ -71,4 +159,13 @@ func (m *Machine) PrintState() {
 	fmt.Printf("  PC: 0x%x\n", m.PC)
 	fmt.Printf("  SP: 0x%x\n", m.SP)
 	fmt.Printf("CPSR: 0x%x\n", m.CPSR)
+	nzcv := []byte("nzcv")
+	for i := range nzcv {
+		if m.NZCV()&(FlagN>>i) != 0 {
+			nzcv[i] -= 'a' - 'A'
+		}
+	}
+	fmt.Printf("NZCV: %s\n", nzcv)
+	fmt.Printf("FPSR: 0x%x\n", m.FPSR)
+	fmt.Printf("FPCR: 0x%x\n", m.FPCR)
 }
@@ -0,0 +1,49 @@
+package machine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNZCV(t *testing.T) {
+	m := New(0)
+	m.CPSR = 0x0000_03c0
+	m.SetNZCV(FlagN | FlagC)
+	if m.CPSR != 0xa000_03c0 {
+		t.Errorf("SetNZCV(N|C) left CPSR=0x%08x, want 0xa00003c0", m.CPSR)
+	}
+	if got := m.NZCV(); got != FlagN|FlagC {
+		t.Errorf("NZCV() = %04b, want %04b", got, FlagN|FlagC)
+	}
+	m.SetNZCV(FlagZ | 0b10000)
+	if got := m.NZCV(); got != FlagZ || m.CPSR&0x0fffffff != 0x03c0 {
+		t.Errorf("SetNZCV(Z) left NZCV=%04b CPSR=0x%08x", got, m.CPSR)
+	}
+}
+
+func TestConditionHolds(t *testing.T) {
+	// Each entry lists the conditions, eq through nv, that hold for the flags.
+	for _, tc := range []struct {
+		nzcv uint32
+		want string
+	}{
+		{0, "ne cc pl vc ls ge gt al nv"},
+		{FlagZ | FlagC, "eq cs pl vc ls ge le al nv"},
+		{FlagC, "ne cs pl vc hi ge gt al nv"},
+		{FlagN, "ne cc mi vc ls lt le al nv"},
+		{FlagN | FlagV, "ne cc mi vs ls ge gt al nv"},
+		{FlagV, "ne cc pl vs ls lt le al nv"},
+	} {
+		m := New(0)
+		m.SetNZCV(tc.nzcv)
+		var got []string
+		for cond, name := range strings.Fields("eq ne cs cc mi pl vs vc hi ls ge lt gt le al nv") {
+			if m.ConditionHolds(uint32(cond)) {
+				got = append(got, name)
+			}
+		}
+		if strings.Join(got, " ") != tc.want {
+			t.Errorf("with nzcv=%04b got %q, want %q", tc.nzcv, strings.Join(got, " "), tc.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package machine
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// MemoryFault is the error returned for an access that falls outside of Machine.Memory.
+type MemoryFault struct {
+	Addr  uint64
+	Size  int
+	Write bool
+}
+
+func (f *MemoryFault) Error() string {
+	kind := "read"
+	if f.Write {
+		kind = "write"
+	}
+	return fmt.Sprintf("%d byte %s at 0x%x is outside of memory", f.Size, kind, f.Addr)
+}
+
+func (m *Machine) span(addr uint64, size int, write bool) ([]byte, error) {
+	if addr > uint64(len(m.Memory)) || uint64(len(m.Memory))-addr < uint64(size) {
+		return nil, &MemoryFault{Addr: addr, Size: size, Write: write}
+	}
+	return m.Memory[addr : addr+uint64(size)], nil
+}
+
+// Read returns the little-endian value of size bytes (1, 2, 4 or 8) at addr.
+func (m *Machine) Read(addr uint64, size int) (uint64, error) {
+	b, err := m.span(addr, size, false)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(b)), nil
+	case 8:
+		return binary.LittleEndian.Uint64(b), nil
+	}
+	panic(fmt.Sprintf("invalid read size %d", size))
+}
+
+// Write stores the low size bytes (1, 2, 4 or 8) of val at addr in little-endian order.
+func (m *Machine) Write(addr uint64, size int, val uint64) error {
+	b, err := m.span(addr, size, true)
+	if err != nil {
+		return err
+	}
+	switch size {
+	case 1:
+		b[0] = byte(val)
+	case 2:
+		binary.LittleEndian.PutUint16(b, uint16(val))
+	case 4:
+		binary.LittleEndian.PutUint32(b, uint32(val))
+	case 8:
+		binary.LittleEndian.PutUint64(b, val)
+	default:
+		panic(fmt.Sprintf("invalid write size %d", size))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+)
+
+// Instruction is anything the machine can execute.  Instructions report anything other than
+// normal completion by calling Stop.
+type Instruction interface {
+	Execute(m *Machine)
+}
+
+// StopReason says why Step or Run returned control to the caller.
+type StopReason int
+
+const (
+	// StopNone means the instruction completed and execution can continue.
+	StopNone StopReason = iota
+	// StopHalt means a halt instruction was executed.
+	StopHalt
+	// StopFault means an instruction could not be fetched, decoded or executed.
+	StopFault
+	// StopBreakpoint means a breakpoint instruction was executed or PC reached one of the
+	// addresses in Breakpoints.
+	StopBreakpoint
+	// StopBudget means Run executed Budget instructions.
+	StopBudget
+	// StopCancelled means the context passed to Run was done.
+	StopCancelled
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case StopNone:
+		return "none"
+	case StopHalt:
+		return "halt"
+	case StopFault:
+		return "fault"
+	case StopBreakpoint:
+		return "breakpoint"
+	case StopBudget:
+		return "budget exhausted"
+	case StopCancelled:
+		return "cancelled"
+	}
+	return fmt.Sprintf("StopReason(%d)", int(r))
+}
+
+// Stop is called by an instruction that cannot complete normally.  PC is left pointing at the
+// instruction, so a caller that wants to continue past a halt or breakpoint must advance it.
+func (m *Machine) Stop(reason StopReason, err error) {
+	m.stop = reason
+	m.fault = err
+}
+
+// BranchTo is called by an instruction that transfers control to target.  PC is set immediately,
+// so instructions that read PC must do so before calling BranchTo.
+func (m *Machine) BranchTo(target uint64) {
+	m.PC = target
+	m.branched = true
+}
+
+// Step fetches the instruction at PC, decodes it, executes it and, unless the instruction branched,
+// advances PC.  It returns StopNone if the instruction completed, otherwise the reason it did not.
+func (m *Machine) Step() (StopReason, error) {
+	if m.PC%4 != 0 {
+		return StopFault, fmt.Errorf("pc 0x%x is not word aligned", m.PC)
+	}
+	word, err := m.Read(m.PC, 4)
+	if err != nil {
+		return StopFault, fmt.Errorf("failed to fetch instruction: %w", err)
+	}
+	inst, err := m.Decode(uint32(word))
+	if err != nil {
+		return StopFault, fmt.Errorf("failed to decode instruction at 0x%x: %w", m.PC, err)
+	}
+	m.stop, m.fault, m.branched = StopNone, nil, false
+	inst.Execute(m)
+	if m.stop != StopNone {
+		return m.stop, m.fault
+	}
+	m.Retired++
+	if !m.branched {
+		m.PC += 4
+	}
+	return StopNone, nil
+}
+
+// Run steps the machine until an instruction stops it, PC reaches a breakpoint, Budget
+// instructions have been executed or ctx is done.  A breakpoint at PC when Run is called is
+// ignored so that Run can be used to continue from one.
+func (m *Machine) Run(ctx context.Context) (StopReason, error) {
+	for n := uint64(0); ; n++ {
+		if err := ctx.Err(); err != nil {
+			return StopCancelled, err
+		}
+		if m.Budget != 0 && n >= m.Budget {
+			return StopBudget, nil
+		}
+		if n > 0 && m.Breakpoints[m.PC] {
+			return StopBreakpoint, nil
+		}
+		if reason, err := m.Step(); reason != StopNone {
+			return reason, err
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// testInst lets plain functions act as instructions.  newTestMachine lays out a program so that
+// the word at 4*i decodes to its i'th instruction.
+type testInst func(m *Machine)
+
+func (f testInst) Execute(m *Machine) { f(m) }
+
+func newTestMachine(prog ...testInst) *Machine {
+	m := New(4 * (len(prog) + 1))
+	for i := range prog {
+		m.Write(uint64(4*i), 4, uint64(i))
+	}
+	m.Write(uint64(4*len(prog)), 4, 0xffffffff)
+	m.Decode = func(word uint32) (Instruction, error) {
+		if int(word) >= len(prog) {
+			return nil, fmt.Errorf("bad word 0x%x", word)
+		}
+		return prog[word], nil
+	}
+	return m
+}
+
+func incr(m *Machine) { m.R[0]++ }
+
+func TestStep(t *testing.T) {
+	m := newTestMachine(incr, incr)
+	for i := 0; i < 2; i++ {
+		if reason, err := m.Step(); reason != StopNone || err != nil {
+			t.Fatalf("step %d returned %v, %v", i, reason, err)
+		}
+	}
+	if m.R[0] != 2 || m.PC != 8 || m.Retired != 2 {
+		t.Errorf("got x0=%d pc=%d retired=%d, want x0=2 pc=8 retired=2", m.R[0], m.PC, m.Retired)
+	}
+	if reason, err := m.Step(); reason != StopFault || err == nil {
+		t.Errorf("stepping an undecodable word returned %v, %v, want a fault", reason, err)
+	}
+	m.PC = 64
+	var fault *MemoryFault
+	if reason, err := m.Step(); reason != StopFault || !errors.As(err, &fault) {
+		t.Errorf("stepping outside memory returned %v, %v, want a memory fault", reason, err)
+	}
+	m.PC = 2
+	if reason, _ := m.Step(); reason != StopFault {
+		t.Errorf("stepping at an unaligned pc returned %v, want a fault", reason)
+	}
+}
+
+func TestRun(t *testing.T) {
+	halt := func(m *Machine) { m.Stop(StopHalt, nil) }
+	m := newTestMachine(incr, incr, incr, halt)
+	if reason, err := m.Run(context.Background()); reason != StopHalt || err != nil {
+		t.Fatalf("Run returned %v, %v, want %v", reason, err, StopHalt)
+	}
+	if m.R[0] != 3 || m.PC != 12 {
+		t.Errorf("got x0=%d pc=%d, want x0=3 pc=12", m.R[0], m.PC)
+	}
+
+	m = newTestMachine(incr, incr, incr, halt)
+	m.Breakpoints = map[uint64]bool{0: true, 8: true}
+	if reason, _ := m.Run(context.Background()); reason != StopBreakpoint || m.PC != 8 {
+		t.Errorf("Run returned %v at pc=%d, want %v at pc=8", reason, m.PC, StopBreakpoint)
+	}
+	if reason, _ := m.Run(context.Background()); reason != StopHalt {
+		t.Errorf("continuing from a breakpoint returned %v, want %v", reason, StopHalt)
+	}
+
+	m = newTestMachine(incr, incr, incr, halt)
+	m.Budget = 2
+	if reason, _ := m.Run(context.Background()); reason != StopBudget || m.R[0] != 2 {
+		t.Errorf("Run returned %v with x0=%d, want %v with x0=2", reason, m.R[0], StopBudget)
+	}
+
+	m = newTestMachine(incr, incr, incr, halt)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if reason, err := m.Run(ctx); reason != StopCancelled || !errors.Is(err, context.Canceled) {
+		t.Errorf("Run returned %v, %v, want %v", reason, err, StopCancelled)
+	}
+}
@@ -1,23 +1,30 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/runningwild/javelin/machine"
-	"github.com/runningwild/javelin/parser"
+	"github.com/runningwild/javelin/opcode"
 )
 
 func main() {
-	addAsm := `
-add x2, x3, x5
-add w2, w3, #5
-`
-	p := parser.New(addAsm)
-	insts, err := p.Parse()
-	if err != nil {
-		fmt.Printf("Failed to parse ADD: %v", err)
-		os.Exit(1)
+	if len(os.Args) > 1 && os.Args[1] == "disasm" {
+		if err := disasm(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// add x2, x3, x5
+	// add w2, w2, #5
+	// hlt #0
+	insts := []opcode.Instruction{
+		&opcode.AddShiftedRegister{Sf: 1, Rm: 5, Rn: 3, Rd: 2},
+		&opcode.AddImmedite{Imm: 5, Rn: 2, Rd: 2},
+		&opcode.Hlt{},
 	}
 
 	for _, inst := range insts {
@@ -25,11 +32,16 @@ add w2, w3, #5
 	}
 
 	m := machine.New(1024 * 1024)
+	m.Decode = opcode.Decoder
+	for i, inst := range insts {
+		m.Write(uint64(4*i), 4, uint64(inst.Encode()))
+	}
 	m.R[3] = 10
 	m.R[5] = 20
 
-	for _, inst := range insts {
-		inst.Execute(m)
+	if reason, err := m.Run(context.Background()); reason != machine.StopHalt {
+		fmt.Printf("Machine stopped unexpectedly (%v): %v\n", reason, err)
+		os.Exit(1)
 	}
 
 	fmt.Printf("R[2]: %d\n", m.R[2])
@@ -0,0 +1,250 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// encodeSIMDAcrossLanes encodes an instruction from the Advanced SIMD across lanes group.
+func encodeSIMDAcrossLanes(q, u, size, opcode, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{0, 1},
+		{q, 1},
+		{u, 1},
+		{0b01110, 5},
+		{size, 2},
+		{0b11000, 5},
+		{opcode, 5},
+		{0b10, 2},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// reduce combines the elements of xs with f, pairing off the two halves of the list recursively
+// as the Arm pseudocode's Reduce does, which decides the result of a non-associative f.
+func reduce(xs []uint64, f func(x, y uint64) uint64) uint64 {
+	if len(xs) == 1 {
+		return xs[0]
+	}
+	return f(reduce(xs[:len(xs)/2], f), reduce(xs[len(xs)/2:], f))
+}
+
+// elements returns the esize-bit elements of Vn, of which there are 128/esize if q is 1 and
+// 64/esize otherwise.
+func elements(m *machine.Machine, q uint32, esize int, rn uint32) []uint64 {
+	xs := make([]uint64, vectorBits(q)/esize)
+	for i := range xs {
+		xs[i] = m.V[rn&0b11111].Get(i, esize)
+	}
+	return xs
+}
+
+// executeAcrossLanes writes the reduction of the esize-bit elements of Vn with f to the
+// resultSize-bit scalar Vd.  If extend is not nil it is applied to each element first.
+func executeAcrossLanes(m *machine.Machine, q uint32, esize, resultSize int, rn, rd uint32, extend func(x uint64) uint64, f func(x, y uint64) uint64) {
+	xs := elements(m, q, esize, rn)
+	if extend != nil {
+		for i := range xs {
+			xs[i] = extend(xs[i])
+		}
+	}
+	setScalar(m, rd, resultSize, reduce(xs, f))
+}
+
+// acrossLanesOperands formats the operands of an across lanes instruction whose result has
+// 8<<dsize bits.
+func acrossLanesOperands(q, size, dsize, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s", scalarName(rd, dsize), vecName(rn, q, size))
+}
+
+// ADDV
+type Addv struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Addv) Encode() uint32 {
+	return encodeSIMDAcrossLanes(op.Q, 0, op.Size, 0b11011, op.Rn, op.Rd)
+}
+
+func (op *Addv) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeAcrossLanes(m, op.Q, esize, esize, op.Rn, op.Rd, nil, func(x, y uint64) uint64 { return x + y })
+}
+
+func (op *Addv) String() string {
+	return "addv " + acrossLanesOperands(op.Q, op.Size, op.Size, op.Rn, op.Rd)
+}
+
+// SADDLV
+type Saddlv struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Saddlv) Encode() uint32 {
+	return encodeSIMDAcrossLanes(op.Q, 0, op.Size, 0b00011, op.Rn, op.Rd)
+}
+
+func (op *Saddlv) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	extend := func(x uint64) uint64 { return extendElement(x, esize, true) }
+	executeAcrossLanes(m, op.Q, esize, 2*esize, op.Rn, op.Rd, extend, func(x, y uint64) uint64 { return x + y })
+}
+
+func (op *Saddlv) String() string {
+	return "saddlv " + acrossLanesOperands(op.Q, op.Size, op.Size+1, op.Rn, op.Rd)
+}
+
+// UADDLV
+type Uaddlv struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uaddlv) Encode() uint32 {
+	return encodeSIMDAcrossLanes(op.Q, 1, op.Size, 0b00011, op.Rn, op.Rd)
+}
+
+func (op *Uaddlv) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeAcrossLanes(m, op.Q, esize, 2*esize, op.Rn, op.Rd, nil, func(x, y uint64) uint64 { return x + y })
+}
+
+func (op *Uaddlv) String() string {
+	return "uaddlv " + acrossLanesOperands(op.Q, op.Size, op.Size+1, op.Rn, op.Rd)
+}
+
+// SMAXV
+type Smaxv struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Smaxv) Encode() uint32 {
+	return encodeSIMDAcrossLanes(op.Q, 0, op.Size, 0b01010, op.Rn, op.Rd)
+}
+
+func (op *Smaxv) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeAcrossLanes(m, op.Q, esize, esize, op.Rn, op.Rd, nil, func(x, y uint64) uint64 { return maximum(x, y, esize, true, false) })
+}
+
+func (op *Smaxv) String() string {
+	return "smaxv " + acrossLanesOperands(op.Q, op.Size, op.Size, op.Rn, op.Rd)
+}
+
+// UMAXV
+type Umaxv struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Umaxv) Encode() uint32 {
+	return encodeSIMDAcrossLanes(op.Q, 1, op.Size, 0b01010, op.Rn, op.Rd)
+}
+
+func (op *Umaxv) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeAcrossLanes(m, op.Q, esize, esize, op.Rn, op.Rd, nil, func(x, y uint64) uint64 { return maximum(x, y, esize, false, false) })
+}
+
+func (op *Umaxv) String() string {
+	return "umaxv " + acrossLanesOperands(op.Q, op.Size, op.Size, op.Rn, op.Rd)
+}
+
+// SMINV
+type Sminv struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sminv) Encode() uint32 {
+	return encodeSIMDAcrossLanes(op.Q, 0, op.Size, 0b11010, op.Rn, op.Rd)
+}
+
+func (op *Sminv) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeAcrossLanes(m, op.Q, esize, esize, op.Rn, op.Rd, nil, func(x, y uint64) uint64 { return maximum(x, y, esize, true, true) })
+}
+
+func (op *Sminv) String() string {
+	return "sminv " + acrossLanesOperands(op.Q, op.Size, op.Size, op.Rn, op.Rd)
+}
+
+// UMINV
+type Uminv struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uminv) Encode() uint32 {
+	return encodeSIMDAcrossLanes(op.Q, 1, op.Size, 0b11010, op.Rn, op.Rd)
+}
+
+func (op *Uminv) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeAcrossLanes(m, op.Q, esize, esize, op.Rn, op.Rd, nil, func(x, y uint64) uint64 { return maximum(x, y, esize, false, true) })
+}
+
+func (op *Uminv) String() string {
+	return "uminv " + acrossLanesOperands(op.Q, op.Size, op.Size, op.Rn, op.Rd)
+}
+
+// FMAXNMV
+type Fmaxnmv struct {
+	Q  uint32 // 1 bit
+	Sz uint32 // 1 bit
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Fmaxnmv) Encode() uint32 {
+	return encodeSIMDAcrossLanes(op.Q, 1, 0<<1|op.Sz, 0b01100, op.Rn, op.Rd)
+}
+
+func (op *Fmaxnmv) Execute(m *machine.Machine) {
+	esize := elementBits(0b10 | op.Sz)
+	executeAcrossLanes(m, op.Q, esize, esize, op.Rn, op.Rd, nil, func(x, y uint64) uint64 { return fpMaxNum(m, x, y, esize, false) })
+}
+
+func (op *Fmaxnmv) String() string {
+	return "fmaxnmv " + acrossLanesOperands(op.Q, 0b10|op.Sz, 0b10|op.Sz, op.Rn, op.Rd)
+}
+
+// FMINNMV
+type Fminnmv struct {
+	Q  uint32 // 1 bit
+	Sz uint32 // 1 bit
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Fminnmv) Encode() uint32 {
+	return encodeSIMDAcrossLanes(op.Q, 1, 1<<1|op.Sz, 0b01100, op.Rn, op.Rd)
+}
+
+func (op *Fminnmv) Execute(m *machine.Machine) {
+	esize := elementBits(0b10 | op.Sz)
+	executeAcrossLanes(m, op.Q, esize, esize, op.Rn, op.Rd, nil, func(x, y uint64) uint64 { return fpMaxNum(m, x, y, esize, true) })
+}
+
+func (op *Fminnmv) String() string {
+	return "fminnmv " + acrossLanesOperands(op.Q, 0b10|op.Sz, 0b10|op.Sz, op.Rn, op.Rd)
+}
@@ -0,0 +1,52 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestAcrossLanesExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm  string
+		inst Instruction
+		fpcr uint32
+		v1   machine.VectorRegister
+		want machine.VectorRegister
+		fpsr uint32
+	}{
+		{"addv b0, v1.16b", &Addv{Q: 1, Rn: 1}, 0, bytesFrom(1, 1, 16), vec(0x88, 0), 0},
+		{"addv h0, v1.4h", &Addv{Size: 0b01, Rn: 1}, 0, vec(0x0002_ffff, 7), vec(1, 0), 0},
+		{"saddlv h0, v1.8b", &Saddlv{Rn: 1}, 0, vec(0x8080808080808080, 5), vec(0xfc00, 0), 0},
+		{"saddlv s0, v1.8h", &Saddlv{Q: 1, Size: 0b01, Rn: 1}, 0, vec(^uint64(0), ^uint64(0)), vec(0xfffffff8, 0), 0},
+		{"uaddlv d0, v1.4s", &Uaddlv{Q: 1, Size: 0b10, Rn: 1}, 0, vec(^uint64(0), ^uint64(0)), vec(0x3fffffffc, 0), 0},
+		{"smaxv b0, v1.8b", &Smaxv{Rn: 1}, 0, vec(0xff7f80, 0x7f), vec(0x7f, 0), 0},
+		{"umaxv h0, v1.8h", &Umaxv{Q: 1, Size: 0b01, Rn: 1}, 0, vec(0x8000_0001, 0xffff000000000000), vec(0xffff, 0), 0},
+		{"sminv s0, v1.4s", &Sminv{Q: 1, Size: 0b10, Rn: 1}, 0, vec(0x80000000_00000005, 0x00000007_ffffffff), vec(0x80000000, 0), 0},
+		{"uminv b0, v1.16b", &Uminv{Q: 1, Rn: 1}, 0, vec(^uint64(0), 0xffffffff03ffffff), vec(3, 0), 0},
+		{"fmaxnmv s0, v1.4s", &Fmaxnmv{Q: 1, Rn: 1}, 0, vec(0x7fc00000_3f800000, 0x40600000_c0000000), vec(0x40600000, 0), 0},
+		{"fminnmv s0, v1.4s", &Fminnmv{Q: 1, Rn: 1}, 0, vec(0x80000000_00000000, 0x7fc00000_3f800000), vec(0x80000000, 0), 0},
+		{"fmaxnmv s0, v1.4s", &Fmaxnmv{Q: 1, Rn: 1}, 0, vec(0x7f800001_3f800000, 0x00000000_40000000), vec(0x40000000, 0), machine.FPSRIOC},
+		{"fmaxnmv s0, v1.4s", &Fmaxnmv{Q: 1, Rn: 1}, 0, vec(0x7fc00002_7fc00001, 0x7fc00004_7fc00003), vec(0x7fc00001, 0), 0},
+		{"fmaxnmv s0, v1.4s", &Fmaxnmv{Q: 1, Rn: 1}, machine.FPCRDN, vec(0x7fc00002_7fc00001, 0x7fc00004_7fc00003), vec(0x7fc00000, 0), 0},
+		{"fminnmv s0, v1.4s", &Fminnmv{Q: 1, Rn: 1}, 0, vec(0xff800000_7f800000, 0x7f800001_7f800001), vec(0xff800000, 0), machine.FPSRIOC},
+	} {
+		m := load(tc.inst)
+		m.FPCR = tc.fpcr
+		m.V[0] = vec(^uint64(0), ^uint64(0))
+		m.V[1] = tc.v1
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.V[0] != tc.want {
+			t.Errorf("%s with v1 = %x: v0 = %x, want %x", tc.asm, tc.v1, m.V[0], tc.want)
+		}
+		if m.FPSR != tc.fpsr {
+			t.Errorf("%s with v1 = %x: FPSR = 0x%x, want 0x%x", tc.asm, tc.v1, m.FPSR, tc.fpsr)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
@@ -0,0 +1,605 @@
+package opcode
+
+import (
+	"fmt"
+	gobits "math/bits"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// reg returns general purpose register n truncated to 32 bits unless sf is 1.  Register 31 is the
+// stack pointer if sp is true, otherwise it reads as zero.
+func reg(m *machine.Machine, sf, n uint32, sp bool) uint64 {
+	n &= 0b11111
+	var v uint64
+	switch {
+	case n == 31 && sp:
+		v = m.SP
+	case n == 31:
+		v = 0
+	default:
+		v = m.R[n]
+	}
+	if sf&1 == 0 {
+		v = uint64(uint32(v))
+	}
+	return v
+}
+
+// setReg writes v to general purpose register n, zero extending from 32 bits unless sf is 1.
+// Register 31 is the stack pointer if sp is true, otherwise the write is discarded.
+func setReg(m *machine.Machine, sf, n uint32, sp bool, v uint64) {
+	n &= 0b11111
+	if sf&1 == 0 {
+		v = uint64(uint32(v))
+	}
+	switch {
+	case n == 31 && sp:
+		m.SP = v
+	case n == 31:
+	default:
+		m.R[n] = v
+	}
+}
+
+// shiftReg shifts a 32 or 64-bit register value by amount, using the shift type encoding shared by
+// all shifted register instructions.
+func shiftReg(sf uint32, v uint64, shift, amount uint32) uint64 {
+	datasize := uint32(32)
+	if sf&1 == 1 {
+		datasize = 64
+	}
+	amount %= datasize
+	var result uint64
+	switch shift & 0b11 {
+	case 0b00: // LSL
+		result = v << amount
+	case 0b01: // LSR
+		result = v >> amount
+	case 0b10: // ASR
+		if datasize == 32 {
+			result = uint64(int32(v) >> amount)
+		} else {
+			result = uint64(int64(v) >> amount)
+		}
+	case 0b11: // ROR
+		if datasize == 32 {
+			result = uint64(gobits.RotateLeft32(uint32(v), -int(amount)))
+		} else {
+			result = gobits.RotateLeft64(v, -int(amount))
+		}
+	}
+	if datasize == 32 {
+		result = uint64(uint32(result))
+	}
+	return result
+}
+
+// extendReg extends the low bits of v according to the extend type in opt and then shifts it left.
+func extendReg(sf uint32, v uint64, opt, shift uint32) uint64 {
+	var extended uint64
+	switch opt & 0b111 {
+	case 0b000: // UXTB
+		extended = uint64(uint8(v))
+	case 0b001: // UXTH
+		extended = uint64(uint16(v))
+	case 0b010: // UXTW
+		extended = uint64(uint32(v))
+	case 0b011: // UXTX
+		extended = v
+	case 0b100: // SXTB
+		extended = uint64(int8(v))
+	case 0b101: // SXTH
+		extended = uint64(int16(v))
+	case 0b110: // SXTW
+		extended = uint64(int32(v))
+	case 0b111: // SXTX
+		extended = v
+	}
+	extended <<= shift
+	if sf&1 == 0 {
+		extended = uint64(uint32(extended))
+	}
+	return extended
+}
+
+// addWithCarry returns x + y + carry at the width given by sf, along with the NZCV flags that the
+// addition produces.
+func addWithCarry(sf uint32, x, y, carry uint64) (uint64, uint32) {
+	var result uint64
+	var nzcv uint32
+	if sf&1 == 0 {
+		x, y = uint64(uint32(x)), uint64(uint32(y))
+		sum := x + y + carry
+		result = uint64(uint32(sum))
+		if sum>>32 != 0 {
+			nzcv |= machine.FlagC
+		}
+		if ((x^result)&(y^result))>>31&1 == 1 {
+			nzcv |= machine.FlagV
+		}
+		if result>>31 == 1 {
+			nzcv |= machine.FlagN
+		}
+	} else {
+		var c uint64
+		result, c = gobits.Add64(x, y, carry)
+		if c != 0 {
+			nzcv |= machine.FlagC
+		}
+		if ((x^result)&(y^result))>>63 == 1 {
+			nzcv |= machine.FlagV
+		}
+		if result>>63 == 1 {
+			nzcv |= machine.FlagN
+		}
+	}
+	if result == 0 {
+		nzcv |= machine.FlagZ
+	}
+	return result, nzcv
+}
+
+// addSub is the operation shared by every form of ADD, ADDS, SUB and SUBS.  The result is written to
+// register rd, which is the stack pointer if rdSP is true and register 31 is given.
+func addSub(m *machine.Machine, sf uint32, op1, op2 uint64, sub, setFlags bool, rd uint32, rdSP bool) {
+	carry := uint64(0)
+	if sub {
+		op2 = ^op2
+		carry = 1
+	}
+	result, nzcv := addWithCarry(sf, op1, op2, carry)
+	if setFlags {
+		m.SetNZCV(nzcv)
+	}
+	setReg(m, sf, rd, rdSP, result)
+}
+
+func encodeAddSubImmediate(op, s, sf, sh, imm, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{op, 1},
+		{s, 1},
+		{0b100010, 6},
+		{sh, 1},
+		{imm, 12},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+func executeAddSubImmediate(m *machine.Machine, sub, setFlags bool, sf, sh, imm, rn, rd uint32) {
+	op2 := uint64(imm & 0xfff)
+	if sh&1 == 1 {
+		op2 <<= 12
+	}
+	addSub(m, sf, reg(m, sf, rn, true), op2, sub, setFlags, rd, !setFlags)
+}
+
+func addSubImmediateOperands(sf, sh, imm, rn uint32) string {
+	s := fmt.Sprintf("%s, #%d", regName(sf, rn, true), imm&0xfff)
+	if sh&1 == 1 {
+		s += ", lsl #12"
+	}
+	return s
+}
+
+func encodeAddSubShiftedRegister(op, s, sf, shift, rm, imm, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{op, 1},
+		{s, 1},
+		{0b01011, 5},
+		{shift, 2},
+		{0, 1},
+		{rm, 5},
+		{imm, 6},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+func executeAddSubShiftedRegister(m *machine.Machine, sub, setFlags bool, sf, shift, rm, imm, rn, rd uint32) {
+	op2 := shiftReg(sf, reg(m, sf, rm, false), shift, imm&0b111111)
+	addSub(m, sf, reg(m, sf, rn, false), op2, sub, setFlags, rd, false)
+}
+
+// shiftedRegisterOperand formats a shifted register operand, omitting the shift if it is LSL #0.
+func shiftedRegisterOperand(sf, rm, shift, imm uint32) string {
+	s := regName(sf, rm, false)
+	if shift&0b11 != 0 || imm != 0 {
+		s += fmt.Sprintf(", %s #%d", shiftNames[shift&0b11], imm&0b111111)
+	}
+	return s
+}
+
+func encodeAddSubExtendedRegister(op, s, sf, opt, imm, rm, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{op, 1},
+		{s, 1},
+		{0b01011, 5},
+		{0b00, 2}, // opt
+		{1, 1},
+		{rm, 5},
+		{opt, 3},
+		{imm, 3},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+func executeAddSubExtendedRegister(m *machine.Machine, sub, setFlags bool, sf, opt, imm, rm, rn, rd uint32) {
+	op2 := extendReg(sf, reg(m, 1, rm, false), opt, imm&0b111)
+	addSub(m, sf, reg(m, sf, rn, true), op2, sub, setFlags, rd, !setFlags)
+}
+
+// extendedRegisterOperand formats an extended register operand.  sp says whether one of the other
+// operands is the stack pointer, which makes LSL the preferred name for the extend.
+func extendedRegisterOperand(sf, opt, imm, rm uint32, sp bool) string {
+	// Only UXTX and SXTX take an x register.
+	var rmSf uint32
+	if sf&1 == 1 && opt&0b011 == 0b011 {
+		rmSf = 1
+	}
+	return regName(rmSf, rm, false) + extendSuffix(sf, opt, imm, sp)
+}
+
+// extendSuffix formats the extend and shift of an extended register operand.  LSL is preferred
+// over UXTX (or UXTW for 32-bit operations) when sp says one of the other operands is the stack
+// pointer, and is omitted entirely if the shift is zero.
+func extendSuffix(sf, opt, imm uint32, sp bool) string {
+	opt &= 0b111
+	imm &= 0b111
+	if sp && opt == 0b010|sf&1 {
+		if imm == 0 {
+			return ""
+		}
+		return fmt.Sprintf(", lsl #%d", imm)
+	}
+	if imm == 0 {
+		return ", " + extendNames[opt]
+	}
+	return fmt.Sprintf(", %s #%d", extendNames[opt], imm)
+}
+
+// ADDS (immediate)
+type AddsImmediate struct {
+	Sf  uint32 // 1 bit
+	Sh  uint32 // 1 bit
+	Imm uint32 // 12 bits
+	Rn  uint32 // 5 bits
+	Rd  uint32 // 5 bits
+}
+
+func (op *AddsImmediate) Encode() uint32 {
+	return encodeAddSubImmediate(0, 1, op.Sf, op.Sh, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *AddsImmediate) Execute(m *machine.Machine) {
+	executeAddSubImmediate(m, false, true, op.Sf, op.Sh, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *AddsImmediate) String() string {
+	if op.Rd&0b11111 == 31 {
+		return "cmn " + addSubImmediateOperands(op.Sf, op.Sh, op.Imm, op.Rn)
+	}
+	return fmt.Sprintf("adds %s, %s", regName(op.Sf, op.Rd, false), addSubImmediateOperands(op.Sf, op.Sh, op.Imm, op.Rn))
+}
+
+// SUB (immediate)
+type SubImmediate struct {
+	Sf  uint32 // 1 bit
+	Sh  uint32 // 1 bit
+	Imm uint32 // 12 bits
+	Rn  uint32 // 5 bits
+	Rd  uint32 // 5 bits
+}
+
+func (op *SubImmediate) Encode() uint32 {
+	return encodeAddSubImmediate(1, 0, op.Sf, op.Sh, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *SubImmediate) Execute(m *machine.Machine) {
+	executeAddSubImmediate(m, true, false, op.Sf, op.Sh, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *SubImmediate) String() string {
+	return fmt.Sprintf("sub %s, %s", regName(op.Sf, op.Rd, true), addSubImmediateOperands(op.Sf, op.Sh, op.Imm, op.Rn))
+}
+
+// SUBS (immediate)
+type SubsImmediate struct {
+	Sf  uint32 // 1 bit
+	Sh  uint32 // 1 bit
+	Imm uint32 // 12 bits
+	Rn  uint32 // 5 bits
+	Rd  uint32 // 5 bits
+}
+
+func (op *SubsImmediate) Encode() uint32 {
+	return encodeAddSubImmediate(1, 1, op.Sf, op.Sh, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *SubsImmediate) Execute(m *machine.Machine) {
+	executeAddSubImmediate(m, true, true, op.Sf, op.Sh, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *SubsImmediate) String() string {
+	if op.Rd&0b11111 == 31 {
+		return "cmp " + addSubImmediateOperands(op.Sf, op.Sh, op.Imm, op.Rn)
+	}
+	return fmt.Sprintf("subs %s, %s", regName(op.Sf, op.Rd, false), addSubImmediateOperands(op.Sf, op.Sh, op.Imm, op.Rn))
+}
+
+// ADDS (shifted register)
+type AddsShiftedRegister struct {
+	Sf    uint32 // 1 bit
+	Shift uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Imm   uint32 // 6 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *AddsShiftedRegister) Encode() uint32 {
+	return encodeAddSubShiftedRegister(0, 1, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *AddsShiftedRegister) Execute(m *machine.Machine) {
+	executeAddSubShiftedRegister(m, false, true, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *AddsShiftedRegister) String() string {
+	operands := fmt.Sprintf("%s, %s", regName(op.Sf, op.Rn, false), shiftedRegisterOperand(op.Sf, op.Rm, op.Shift, op.Imm))
+	if op.Rd&0b11111 == 31 {
+		return "cmn " + operands
+	}
+	return fmt.Sprintf("adds %s, %s", regName(op.Sf, op.Rd, false), operands)
+}
+
+// SUB (shifted register)
+type SubShiftedRegister struct {
+	Sf    uint32 // 1 bit
+	Shift uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Imm   uint32 // 6 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *SubShiftedRegister) Encode() uint32 {
+	return encodeAddSubShiftedRegister(1, 0, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *SubShiftedRegister) Execute(m *machine.Machine) {
+	executeAddSubShiftedRegister(m, true, false, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *SubShiftedRegister) String() string {
+	rm := shiftedRegisterOperand(op.Sf, op.Rm, op.Shift, op.Imm)
+	if op.Rn&0b11111 == 31 {
+		return fmt.Sprintf("neg %s, %s", regName(op.Sf, op.Rd, false), rm)
+	}
+	return fmt.Sprintf("sub %s, %s, %s", regName(op.Sf, op.Rd, false), regName(op.Sf, op.Rn, false), rm)
+}
+
+// SUBS (shifted register)
+type SubsShiftedRegister struct {
+	Sf    uint32 // 1 bit
+	Shift uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Imm   uint32 // 6 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *SubsShiftedRegister) Encode() uint32 {
+	return encodeAddSubShiftedRegister(1, 1, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *SubsShiftedRegister) Execute(m *machine.Machine) {
+	executeAddSubShiftedRegister(m, true, true, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *SubsShiftedRegister) String() string {
+	rm := shiftedRegisterOperand(op.Sf, op.Rm, op.Shift, op.Imm)
+	switch {
+	case op.Rd&0b11111 == 31:
+		return fmt.Sprintf("cmp %s, %s", regName(op.Sf, op.Rn, false), rm)
+	case op.Rn&0b11111 == 31:
+		return fmt.Sprintf("negs %s, %s", regName(op.Sf, op.Rd, false), rm)
+	}
+	return fmt.Sprintf("subs %s, %s, %s", regName(op.Sf, op.Rd, false), regName(op.Sf, op.Rn, false), rm)
+}
+
+// ADDS (extended register)
+type AddsExtendedRegister struct {
+	Sf  uint32 // 1 bit
+	Opt byte   // 3 bits
+	Imm uint32 // 3 bits
+	Rm  uint32 // 5 bits
+	Rn  uint32 // 5 bits
+	Rd  uint32 // 5 bits
+}
+
+func (op *AddsExtendedRegister) Encode() uint32 {
+	return encodeAddSubExtendedRegister(0, 1, op.Sf, uint32(op.Opt), op.Imm, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *AddsExtendedRegister) Execute(m *machine.Machine) {
+	executeAddSubExtendedRegister(m, false, true, op.Sf, uint32(op.Opt), op.Imm, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *AddsExtendedRegister) String() string {
+	operands := fmt.Sprintf("%s, %s", regName(op.Sf, op.Rn, true), extendedRegisterOperand(op.Sf, uint32(op.Opt), op.Imm, op.Rm, op.Rn&0b11111 == 31))
+	if op.Rd&0b11111 == 31 {
+		return "cmn " + operands
+	}
+	return fmt.Sprintf("adds %s, %s", regName(op.Sf, op.Rd, false), operands)
+}
+
+// SUB (extended register)
+type SubExtendedRegister struct {
+	Sf  uint32 // 1 bit
+	Opt byte   // 3 bits
+	Imm uint32 // 3 bits
+	Rm  uint32 // 5 bits
+	Rn  uint32 // 5 bits
+	Rd  uint32 // 5 bits
+}
+
+func (op *SubExtendedRegister) Encode() uint32 {
+	return encodeAddSubExtendedRegister(1, 0, op.Sf, uint32(op.Opt), op.Imm, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *SubExtendedRegister) Execute(m *machine.Machine) {
+	executeAddSubExtendedRegister(m, true, false, op.Sf, uint32(op.Opt), op.Imm, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *SubExtendedRegister) String() string {
+	return fmt.Sprintf("sub %s, %s, %s", regName(op.Sf, op.Rd, true), regName(op.Sf, op.Rn, true), extendedRegisterOperand(op.Sf, uint32(op.Opt), op.Imm, op.Rm, op.Rd&0b11111 == 31 || op.Rn&0b11111 == 31))
+}
+
+// SUBS (extended register)
+type SubsExtendedRegister struct {
+	Sf  uint32 // 1 bit
+	Opt byte   // 3 bits
+	Imm uint32 // 3 bits
+	Rm  uint32 // 5 bits
+	Rn  uint32 // 5 bits
+	Rd  uint32 // 5 bits
+}
+
+func (op *SubsExtendedRegister) Encode() uint32 {
+	return encodeAddSubExtendedRegister(1, 1, op.Sf, uint32(op.Opt), op.Imm, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *SubsExtendedRegister) Execute(m *machine.Machine) {
+	executeAddSubExtendedRegister(m, true, true, op.Sf, uint32(op.Opt), op.Imm, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *SubsExtendedRegister) String() string {
+	operands := fmt.Sprintf("%s, %s", regName(op.Sf, op.Rn, true), extendedRegisterOperand(op.Sf, uint32(op.Opt), op.Imm, op.Rm, op.Rn&0b11111 == 31))
+	if op.Rd&0b11111 == 31 {
+		return "cmp " + operands
+	}
+	return fmt.Sprintf("subs %s, %s", regName(op.Sf, op.Rd, false), operands)
+}
+
+func encodeAddSubWithCarry(op, s, sf, rm, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{op, 1},
+		{s, 1},
+		{0b11010000, 8},
+		{rm, 5},
+		{0b000000, 6},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// executeAddSubWithCarry adds Rm and the carry flag to Rn, or for SBC and SBCS subtracts Rm and the
+// inverse of the carry flag.
+func executeAddSubWithCarry(m *machine.Machine, sub, setFlags bool, sf, rm, rn, rd uint32) {
+	op2 := reg(m, sf, rm, false)
+	if sub {
+		op2 = ^op2
+	}
+	var carry uint64
+	if m.NZCV()&machine.FlagC != 0 {
+		carry = 1
+	}
+	result, nzcv := addWithCarry(sf, reg(m, sf, rn, false), op2, carry)
+	if setFlags {
+		m.SetNZCV(nzcv)
+	}
+	setReg(m, sf, rd, false, result)
+}
+
+// ADC
+type Adc struct {
+	Sf uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Adc) Encode() uint32 {
+	return encodeAddSubWithCarry(0, 0, op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Adc) Execute(m *machine.Machine) {
+	executeAddSubWithCarry(m, false, false, op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Adc) String() string {
+	return "adc " + twoSourceOperands(op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+// ADCS
+type Adcs struct {
+	Sf uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Adcs) Encode() uint32 {
+	return encodeAddSubWithCarry(0, 1, op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Adcs) Execute(m *machine.Machine) {
+	executeAddSubWithCarry(m, false, true, op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Adcs) String() string {
+	return "adcs " + twoSourceOperands(op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+// SBC
+type Sbc struct {
+	Sf uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Sbc) Encode() uint32 {
+	return encodeAddSubWithCarry(1, 0, op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sbc) Execute(m *machine.Machine) {
+	executeAddSubWithCarry(m, true, false, op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sbc) String() string {
+	if op.Rn&0b11111 == 31 {
+		return fmt.Sprintf("ngc %s, %s", regName(op.Sf, op.Rd, false), regName(op.Sf, op.Rm, false))
+	}
+	return "sbc " + twoSourceOperands(op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+// SBCS
+type Sbcs struct {
+	Sf uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Sbcs) Encode() uint32 {
+	return encodeAddSubWithCarry(1, 1, op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sbcs) Execute(m *machine.Machine) {
+	executeAddSubWithCarry(m, true, true, op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sbcs) String() string {
+	if op.Rn&0b11111 == 31 {
+		return fmt.Sprintf("ngcs %s, %s", regName(op.Sf, op.Rd, false), regName(op.Sf, op.Rm, false))
+	}
+	return "sbcs " + twoSourceOperands(op.Sf, op.Rm, op.Rn, op.Rd)
+}
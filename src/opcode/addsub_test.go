@@ -0,0 +1,123 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestAddSubExecute(t *testing.T) {
+	const (
+		n = machine.FlagN
+		z = machine.FlagZ
+		c = machine.FlagC
+		v = machine.FlagV
+	)
+	for _, tc := range []struct {
+		asm      string
+		inst     Instruction
+		x1, x2   uint64
+		sp       uint64
+		wantX0   uint64
+		wantSP   uint64
+		wantNZCV uint32
+	}{
+		{"add w0, w1, w2", &AddShiftedRegister{Rm: 2, Rn: 1, Rd: 0}, 0xffffffff, 1, 0, 0, 0, 0},
+		{"add x0, xzr, x1", &AddShiftedRegister{Sf: 1, Rm: 1, Rn: 31, Rd: 0}, 7, 0, 100, 7, 100, 0},
+		{"add x0, x1, x2, asr #4", &AddShiftedRegister{Sf: 1, Shift: 0b10, Imm: 4, Rm: 2, Rn: 1, Rd: 0}, 1, 0xfffffffffffffff0, 0, 0, 0, 0},
+		{"add x0, sp, w1, sxtw #2", &AddExtendedRegister{Sf: 1, Opt: 0b110, Imm: 2, Rm: 1, Rn: 31, Rd: 0}, 0xffffffff, 0, 100, 96, 100, 0},
+		{"mov sp, x1", &AddImmedite{Sf: 1, Rn: 1, Rd: 31}, 64, 0, 0, 0, 64, 0},
+		{"add w0, w1, #1", &AddImmedite{Imm: 1, Rn: 1, Rd: 0}, 0xffffffff, 0, 0, 0, 0, 0},
+		{"sub sp, sp, #16", &SubImmediate{Sf: 1, Imm: 16, Rn: 31, Rd: 31}, 0, 0, 64, 0, 48, 0},
+		{"subs x0, x1, x2", &SubsShiftedRegister{Sf: 1, Rm: 2, Rn: 1, Rd: 0}, 5, 5, 0, 0, 0, z | c},
+		{"subs x0, x1, x2", &SubsShiftedRegister{Sf: 1, Rm: 2, Rn: 1, Rd: 0}, 3, 5, 0, 0xfffffffffffffffe, 0, n},
+		{"subs w0, w1, #1", &SubsImmediate{Imm: 1, Rn: 1, Rd: 0}, 0x80000000, 0, 0, 0x7fffffff, 0, c | v},
+		{"adds x0, x1, x2", &AddsShiftedRegister{Sf: 1, Rm: 2, Rn: 1, Rd: 0}, 0x7fffffffffffffff, 1, 0, 0x8000000000000000, 0, n | v},
+		{"adds x0, x1, #1", &AddsImmediate{Sf: 1, Imm: 1, Rn: 1, Rd: 0}, 0xffffffffffffffff, 0, 0, 0, 0, z | c},
+		{"cmn w1, w2", &AddsShiftedRegister{Rm: 2, Rn: 1, Rd: 31}, 0x12ffffffff, 1, 0, 0, 0, z | c},
+		{"cmp sp, x1", &SubsExtendedRegister{Sf: 1, Opt: 0b011, Rm: 1, Rn: 31, Rd: 31}, 65, 0, 64, 0, 64, n},
+		{"adds x0, sp, #0", &AddsImmediate{Sf: 1, Rn: 31, Rd: 0}, 0, 0, 0, 0, 0, z},
+		{"neg x0, x1, lsl #3", &SubShiftedRegister{Sf: 1, Rm: 1, Imm: 3, Rn: 31, Rd: 0}, 1, 0, 0, 0xfffffffffffffff8, 0, 0},
+		{"negs w0, w1", &SubsShiftedRegister{Rm: 1, Rn: 31, Rd: 0}, 0x80000000, 0, 0, 0x80000000, 0, n | v},
+		{"subs x0, x1, w2, uxtb", &SubsExtendedRegister{Sf: 1, Rm: 2, Rn: 1, Rd: 0}, 0x100, 0x1ff, 0, 1, 0, c},
+	} {
+		m := machine.New(0)
+		m.R[1], m.R[2], m.SP = tc.x1, tc.x2, tc.sp
+		tc.inst.Execute(m)
+		if m.R[0] != tc.wantX0 || m.SP != tc.wantSP || m.NZCV() != tc.wantNZCV {
+			t.Errorf("%s with x1=0x%x x2=0x%x sp=0x%x: got x0=0x%x sp=0x%x nzcv=%04b, want x0=0x%x sp=0x%x nzcv=%04b",
+				tc.asm, tc.x1, tc.x2, tc.sp, m.R[0], m.SP, m.NZCV(), tc.wantX0, tc.wantSP, tc.wantNZCV)
+		}
+		if tc.inst.String() != tc.asm {
+			t.Errorf("%s: String() = %q", tc.asm, tc.inst.String())
+		}
+	}
+}
+
+func TestAddSubWithCarryExecute(t *testing.T) {
+	const (
+		n = machine.FlagN
+		z = machine.FlagZ
+		c = machine.FlagC
+		v = machine.FlagV
+	)
+	for _, tc := range []struct {
+		asm      string
+		inst     Instruction
+		nzcv     uint32
+		x1, x2   uint64
+		wantX0   uint64
+		wantNZCV uint32
+	}{
+		{"adc x0, x1, x2", &Adc{Sf: 1, Rm: 2, Rn: 1}, c, 1, 2, 4, c},
+		{"adc x0, x1, x2", &Adc{Sf: 1, Rm: 2, Rn: 1}, z, 1, 2, 3, z},
+		{"adc w0, w1, w2", &Adc{Rm: 2, Rn: 1}, c, 0xffffffff, 0, 0, c},
+		{"adcs x0, x1, x2", &Adcs{Sf: 1, Rm: 2, Rn: 1}, c, 0xffffffffffffffff, 0, 0, z | c},
+		{"adcs x0, x1, x2", &Adcs{Sf: 1, Rm: 2, Rn: 1}, c, 0x7fffffffffffffff, 0, 0x8000000000000000, n | v},
+		{"adcs w0, w1, w2", &Adcs{Rm: 2, Rn: 1}, 0, 0xffffffff, 1, 0, z | c},
+		{"adcs w0, w1, w2", &Adcs{Rm: 2, Rn: 1}, c, 0x7ffffffe, 1, 0x80000000, n | v},
+		{"sbc x0, x1, x2", &Sbc{Sf: 1, Rm: 2, Rn: 1}, c, 5, 3, 2, c},
+		{"sbc x0, x1, x2", &Sbc{Sf: 1, Rm: 2, Rn: 1}, 0, 5, 3, 1, 0},
+		{"sbcs x0, x1, x2", &Sbcs{Sf: 1, Rm: 2, Rn: 1}, 0, 0, 0, 0xffffffffffffffff, n},
+		{"sbcs x0, x1, x2", &Sbcs{Sf: 1, Rm: 2, Rn: 1}, c, 0x8000000000000000, 1, 0x7fffffffffffffff, c | v},
+		{"sbcs w0, w1, w2", &Sbcs{Rm: 2, Rn: 1}, 0, 1, 0, 0, z | c},
+		{"sbcs w0, w1, w2", &Sbcs{Rm: 2, Rn: 1}, c, 0x80000000, 0x1, 0x7fffffff, c | v},
+		{"ngc w0, w2", &Sbc{Rm: 2, Rn: 31}, 0, 0, 5, 0xfffffffa, 0},
+		{"ngcs x0, x2", &Sbcs{Sf: 1, Rm: 2, Rn: 31}, c, 0, 0, 0, z | c},
+	} {
+		m := machine.New(0)
+		m.R[1], m.R[2] = tc.x1, tc.x2
+		m.SetNZCV(tc.nzcv)
+		tc.inst.Execute(m)
+		if m.R[0] != tc.wantX0 || m.NZCV() != tc.wantNZCV {
+			t.Errorf("%s with x1=0x%x x2=0x%x nzcv=%04b: got x0=0x%x nzcv=%04b, want x0=0x%x nzcv=%04b",
+				tc.asm, tc.x1, tc.x2, tc.nzcv, m.R[0], m.NZCV(), tc.wantX0, tc.wantNZCV)
+		}
+		if tc.inst.String() != tc.asm {
+			t.Errorf("%s: String() = %q", tc.asm, tc.inst.String())
+		}
+	}
+}
+
+func TestMultiPrecisionArithmetic(t *testing.T) {
+	// x1:x0 += x3:x2, then x5:x4 = x1:x0 - x3:x2, which must give back the original x1:x0.
+	m := load(
+		&AddsShiftedRegister{Sf: 1, Rm: 2, Rn: 0, Rd: 0},
+		&Adc{Sf: 1, Rm: 3, Rn: 1, Rd: 1},
+		&SubsShiftedRegister{Sf: 1, Rm: 2, Rn: 0, Rd: 4},
+		&Sbc{Sf: 1, Rm: 3, Rn: 1, Rd: 5},
+	)
+	m.R[0], m.R[1] = 0xffffffffffffffff, 0x0000000000000001
+	m.R[2], m.R[3] = 0x0000000000000002, 0x7fffffffffffffff
+	for i := 0; i < 4; i++ {
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Fatalf("Step returned %v, %v", reason, err)
+		}
+	}
+	if m.R[0] != 1 || m.R[1] != 0x8000000000000001 {
+		t.Errorf("sum = 0x%016x%016x, want 0x80000000000000010000000000000001", m.R[1], m.R[0])
+	}
+	if m.R[4] != 0xffffffffffffffff || m.R[5] != 1 {
+		t.Errorf("difference = 0x%016x%016x, want 0x0000000000000001ffffffffffffffff", m.R[5], m.R[4])
+	}
+}
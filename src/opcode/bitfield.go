@@ -0,0 +1,226 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func encodeBitfield(opc, sf, n, immr, imms, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{opc, 2},
+		{0b100110, 6},
+		{n, 1},
+		{immr, 6},
+		{imms, 6},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// ones returns a value with the low n bits set, for n from 0 to 64.
+func ones(n uint32) uint64 {
+	if n == 0 {
+		return 0
+	}
+	return ^uint64(0) >> (64 - n)
+}
+
+// executeBitfield executes SBFM, BFM or UBFM, selected by opc.  The source is rotated right by immr
+// and bits imms:0 of the original are inserted into the destination, which is sign extended by
+// SBFM, zero extended by UBFM and left alone by BFM.
+func executeBitfield(m *machine.Machine, opc, sf, immr, imms, rn, rd uint32) {
+	datasize := uint32(32) << (sf & 1)
+	r, s := immr&(datasize-1), imms&(datasize-1)
+	// These are the wmask and tmask of DecodeBitMasks, whose element is always the full register.
+	wmask := shiftReg(sf, ones(s+1), 0b11, r)
+	tmask := ones((s-r)&(datasize-1) + 1)
+
+	src := reg(m, sf, rn, false)
+	bot := shiftReg(sf, src, 0b11, r) & wmask
+	var result uint64
+	switch opc & 0b11 {
+	case 0b00:
+		var top uint64
+		if src>>s&1 == 1 {
+			top = ^uint64(0)
+		}
+		result = top&^tmask | bot&tmask
+	case 0b01:
+		dst := reg(m, sf, rd, false)
+		bot |= dst &^ wmask
+		result = dst&^tmask | bot&tmask
+	default:
+		result = bot & tmask
+	}
+	setReg(m, sf, rd, false, result)
+}
+
+// bfxPreferred reports whether SBFM or UBFM should be disassembled as SBFX or UBFX rather than one
+// of their more specific aliases.  uns is 1 for UBFM.
+func bfxPreferred(sf, uns, imms, immr uint32) bool {
+	switch {
+	case imms < immr:
+		return false // SBFIZ or UBFIZ
+	case imms == 31|sf<<5:
+		return false // ASR or LSR
+	case immr == 0 && imms == 7, immr == 0 && imms == 15:
+		// SXTB and SXTH always, UXTB and UXTH only for w registers.
+		return sf == 1 && uns == 1
+	case immr == 0 && imms == 31:
+		return uns == 1 // SXTW
+	}
+	return true
+}
+
+// bitfieldOperands formats the registers of a bitfield instruction followed by two immediates.
+func bitfieldOperands(sf, rd, rn, a, b uint32) string {
+	return fmt.Sprintf("%s, %s, #%d, #%d", regName(sf, rd, false), regName(sf, rn, false), a, b)
+}
+
+// SBFM
+type Sbfm struct {
+	Sf   uint32 // 1 bit
+	N    uint32 // 1 bit
+	Immr uint32 // 6 bits
+	Imms uint32 // 6 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sbfm) Encode() uint32 {
+	return encodeBitfield(0b00, op.Sf, op.N, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *Sbfm) Execute(m *machine.Machine) {
+	executeBitfield(m, 0b00, op.Sf, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *Sbfm) String() string {
+	sf, width := op.Sf&1, uint32(32)<<(op.Sf&1)
+	r, s := op.Immr&0x3f, op.Imms&0x3f
+	switch {
+	case s == width-1:
+		return fmt.Sprintf("asr %s, %s, #%d", regName(sf, op.Rd, false), regName(sf, op.Rn, false), r)
+	case s < r:
+		return "sbfiz " + bitfieldOperands(sf, op.Rd, op.Rn, width-r, s+1)
+	case bfxPreferred(sf, 0, s, r):
+		return "sbfx " + bitfieldOperands(sf, op.Rd, op.Rn, r, s-r+1)
+	case s == 7:
+		return fmt.Sprintf("sxtb %s, %s", regName(sf, op.Rd, false), regName(0, op.Rn, false))
+	case s == 15:
+		return fmt.Sprintf("sxth %s, %s", regName(sf, op.Rd, false), regName(0, op.Rn, false))
+	case s == 31:
+		return fmt.Sprintf("sxtw %s, %s", regName(sf, op.Rd, false), regName(0, op.Rn, false))
+	}
+	return "sbfm " + bitfieldOperands(sf, op.Rd, op.Rn, r, s)
+}
+
+// BFM
+type Bfm struct {
+	Sf   uint32 // 1 bit
+	N    uint32 // 1 bit
+	Immr uint32 // 6 bits
+	Imms uint32 // 6 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Bfm) Encode() uint32 {
+	return encodeBitfield(0b01, op.Sf, op.N, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *Bfm) Execute(m *machine.Machine) {
+	executeBitfield(m, 0b01, op.Sf, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *Bfm) String() string {
+	sf, width := op.Sf&1, uint32(32)<<(op.Sf&1)
+	r, s := op.Immr&0x3f, op.Imms&0x3f
+	switch {
+	case s < r && op.Rn&0b11111 == 31:
+		return fmt.Sprintf("bfc %s, #%d, #%d", regName(sf, op.Rd, false), width-r, s+1)
+	case s < r:
+		return "bfi " + bitfieldOperands(sf, op.Rd, op.Rn, width-r, s+1)
+	}
+	return "bfxil " + bitfieldOperands(sf, op.Rd, op.Rn, r, s-r+1)
+}
+
+// UBFM
+type Ubfm struct {
+	Sf   uint32 // 1 bit
+	N    uint32 // 1 bit
+	Immr uint32 // 6 bits
+	Imms uint32 // 6 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Ubfm) Encode() uint32 {
+	return encodeBitfield(0b10, op.Sf, op.N, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *Ubfm) Execute(m *machine.Machine) {
+	executeBitfield(m, 0b10, op.Sf, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *Ubfm) String() string {
+	sf, width := op.Sf&1, uint32(32)<<(op.Sf&1)
+	r, s := op.Immr&0x3f, op.Imms&0x3f
+	switch {
+	case s != width-1 && s+1 == r:
+		return fmt.Sprintf("lsl %s, %s, #%d", regName(sf, op.Rd, false), regName(sf, op.Rn, false), width-1-s)
+	case s == width-1:
+		return fmt.Sprintf("lsr %s, %s, #%d", regName(sf, op.Rd, false), regName(sf, op.Rn, false), r)
+	case s < r:
+		return "ubfiz " + bitfieldOperands(sf, op.Rd, op.Rn, width-r, s+1)
+	case bfxPreferred(sf, 1, s, r):
+		return "ubfx " + bitfieldOperands(sf, op.Rd, op.Rn, r, s-r+1)
+	case s == 7:
+		return fmt.Sprintf("uxtb %s, %s", regName(0, op.Rd, false), regName(0, op.Rn, false))
+	case s == 15:
+		return fmt.Sprintf("uxth %s, %s", regName(0, op.Rd, false), regName(0, op.Rn, false))
+	}
+	return "ubfm " + bitfieldOperands(sf, op.Rd, op.Rn, r, s)
+}
+
+// EXTR
+type Extr struct {
+	Sf   uint32 // 1 bit
+	N    uint32 // 1 bit
+	Rm   uint32 // 5 bits
+	Imms uint32 // 6 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Extr) Encode() uint32 {
+	return buildUint32([]bits{
+		{op.Sf, 1},
+		{0b00100111, 8},
+		{op.N, 1},
+		{0, 1},
+		{op.Rm, 5},
+		{op.Imms, 6},
+		{op.Rn, 5},
+		{op.Rd, 5},
+	}...)
+}
+
+func (op *Extr) Execute(m *machine.Machine) {
+	datasize := uint32(32) << (op.Sf & 1)
+	lsb := op.Imms & (datasize - 1)
+	result := reg(m, op.Sf, op.Rm, false) >> lsb
+	if lsb != 0 {
+		result |= reg(m, op.Sf, op.Rn, false) << (datasize - lsb)
+	}
+	setReg(m, op.Sf, op.Rd, false, result)
+}
+
+func (op *Extr) String() string {
+	if op.Rn&0b11111 == op.Rm&0b11111 {
+		return fmt.Sprintf("ror %s, %s, #%d", regName(op.Sf, op.Rd, false), regName(op.Sf, op.Rn, false), op.Imms&0x3f)
+	}
+	return fmt.Sprintf("extr %s, %s, %s, #%d", regName(op.Sf, op.Rd, false), regName(op.Sf, op.Rn, false), regName(op.Sf, op.Rm, false), op.Imms&0x3f)
+}
@@ -0,0 +1,47 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestBitfieldExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm    string
+		inst   Instruction
+		x0, x1 uint64
+		want   uint64
+	}{
+		{"asr x0, x1, #4", &Sbfm{Sf: 1, N: 1, Immr: 4, Imms: 63, Rn: 1}, 0, 0x8000000000000010, 0xf800000000000001},
+		{"asr w0, w1, #31", &Sbfm{Immr: 31, Imms: 31, Rn: 1}, 0, 0x80000000, 0xffffffff},
+		{"sxtb x0, w1", &Sbfm{Sf: 1, N: 1, Imms: 7, Rn: 1}, 0, 0x1280, 0xffffffffffffff80},
+		{"sxtw x0, w1", &Sbfm{Sf: 1, N: 1, Imms: 31, Rn: 1}, 0, 0x7fffffff, 0x7fffffff},
+		{"sbfx w0, w1, #4, #8", &Sbfm{Immr: 4, Imms: 11, Rn: 1}, 0, 0x0800, 0xffffff80},
+		{"sbfiz x0, x1, #4, #8", &Sbfm{Sf: 1, N: 1, Immr: 60, Imms: 7, Rn: 1}, 0, 0x180, 0xfffffffffffff800},
+		{"lsl w0, w1, #4", &Ubfm{Immr: 28, Imms: 27, Rn: 1}, 0, 0xf0000001, 0x10},
+		{"lsr x0, x1, #60", &Ubfm{Sf: 1, N: 1, Immr: 60, Imms: 63, Rn: 1}, 0, 0xf000000000000000, 0xf},
+		{"ubfx x0, x1, #8, #8", &Ubfm{Sf: 1, N: 1, Immr: 8, Imms: 15, Rn: 1}, 0, 0xabcd, 0xab},
+		{"ubfiz w0, w1, #3, #4", &Ubfm{Immr: 29, Imms: 3, Rn: 1}, 0, 0xff, 0x78},
+		{"uxth w0, w1", &Ubfm{Imms: 15, Rn: 1}, 0, 0xffff12345678, 0x5678},
+		{"bfi w0, w1, #8, #4", &Bfm{Immr: 24, Imms: 3, Rn: 1}, 0xffffffffffffffff, 0x5, 0xfffff5ff},
+		{"bfxil x0, x1, #4, #8", &Bfm{Sf: 1, N: 1, Immr: 4, Imms: 11, Rn: 1}, 0x1111111111111111, 0xabc, 0x11111111111111ab},
+		{"bfc x0, #8, #4", &Bfm{Sf: 1, N: 1, Immr: 56, Imms: 3, Rn: 31}, ^uint64(0), 0, 0xfffffffffffff0ff},
+		{"extr x0, x1, x0, #8", &Extr{Sf: 1, N: 1, Rm: 0, Imms: 8, Rn: 1}, 0x1122334455667788, 0x99, 0x9911223344556677},
+		{"ror w0, w1, #8", &Extr{Rm: 1, Imms: 8, Rn: 1}, 0, 0xffffffff12345678, 0x78123456},
+		{"extr x0, x1, x0, #0", &Extr{Sf: 1, N: 1, Rm: 0, Rn: 1}, 0x1234, 0x5678, 0x1234},
+	} {
+		m := load(tc.inst)
+		m.R[0], m.R[1] = tc.x0, tc.x1
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.R[0] != tc.want {
+			t.Errorf("%s: x0 = 0x%x, want 0x%x", tc.asm, m.R[0], tc.want)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
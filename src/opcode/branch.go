@@ -0,0 +1,301 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// PCRelative is implemented by instructions whose operand is an offset from their own address.
+type PCRelative interface {
+	Instruction
+	// Target returns the address the instruction refers to when it is located at pc.
+	Target(pc uint64) uint64
+}
+
+var condNames = [16]string{"eq", "ne", "hs", "lo", "mi", "pl", "vs", "vc", "hi", "ls", "ge", "lt", "gt", "le", "al", "nv"}
+
+// branchOffset returns the byte offset encoded by a width bit word offset.
+func branchOffset(imm uint32, width int) int64 {
+	return signExtend(uint64(imm), width) * 4
+}
+
+// B
+type B struct {
+	Imm uint32 // 26 bits
+}
+
+func (op *B) Encode() uint32 {
+	return buildUint32([]bits{
+		{0, 1}, // op
+		{0b00101, 5},
+		{op.Imm, 26},
+	}...)
+}
+
+func (op *B) Execute(m *machine.Machine) {
+	m.BranchTo(op.Target(m.PC))
+}
+
+func (op *B) Target(pc uint64) uint64 {
+	return pc + uint64(branchOffset(op.Imm, 26))
+}
+
+func (op *B) String() string {
+	return fmt.Sprintf("b #%d", branchOffset(op.Imm, 26))
+}
+
+// BL
+type Bl struct {
+	Imm uint32 // 26 bits
+}
+
+func (op *Bl) Encode() uint32 {
+	return buildUint32([]bits{
+		{1, 1}, // op
+		{0b00101, 5},
+		{op.Imm, 26},
+	}...)
+}
+
+func (op *Bl) Execute(m *machine.Machine) {
+	m.R[30] = m.PC + 4
+	m.BranchTo(op.Target(m.PC))
+}
+
+func (op *Bl) Target(pc uint64) uint64 {
+	return pc + uint64(branchOffset(op.Imm, 26))
+}
+
+func (op *Bl) String() string {
+	return fmt.Sprintf("bl #%d", branchOffset(op.Imm, 26))
+}
+
+func encodeBranchRegister(opc, rn uint32) uint32 {
+	return buildUint32([]bits{
+		{0b1101011, 7},
+		{opc, 4},
+		{0b11111, 5}, // op2
+		{0b000000, 6},
+		{rn, 5},
+		{0b00000, 5}, // op4
+	}...)
+}
+
+// BR
+type Br struct {
+	Rn uint32 // 5 bits
+}
+
+func (op *Br) Encode() uint32 {
+	return encodeBranchRegister(0b0000, op.Rn)
+}
+
+func (op *Br) Execute(m *machine.Machine) {
+	m.BranchTo(reg(m, 1, op.Rn, false))
+}
+
+func (op *Br) String() string {
+	return "br " + regName(1, op.Rn, false)
+}
+
+// BLR
+type Blr struct {
+	Rn uint32 // 5 bits
+}
+
+func (op *Blr) Encode() uint32 {
+	return encodeBranchRegister(0b0001, op.Rn)
+}
+
+func (op *Blr) Execute(m *machine.Machine) {
+	// Read the target first in case it is x30.
+	target := reg(m, 1, op.Rn, false)
+	m.R[30] = m.PC + 4
+	m.BranchTo(target)
+}
+
+func (op *Blr) String() string {
+	return "blr " + regName(1, op.Rn, false)
+}
+
+// RET
+type Ret struct {
+	Rn uint32 // 5 bits
+}
+
+func (op *Ret) Encode() uint32 {
+	return encodeBranchRegister(0b0010, op.Rn)
+}
+
+func (op *Ret) Execute(m *machine.Machine) {
+	m.BranchTo(reg(m, 1, op.Rn, false))
+}
+
+func (op *Ret) String() string {
+	if op.Rn&0b11111 == 30 {
+		return "ret"
+	}
+	return "ret " + regName(1, op.Rn, false)
+}
+
+// B.cond
+type BCond struct {
+	Imm  uint32 // 19 bits
+	Cond uint32 // 4 bits
+}
+
+func (op *BCond) Encode() uint32 {
+	return buildUint32([]bits{
+		{0b0101010, 7},
+		{0, 1}, // o1
+		{op.Imm, 19},
+		{0, 1}, // o0
+		{op.Cond, 4},
+	}...)
+}
+
+func (op *BCond) Execute(m *machine.Machine) {
+	if m.ConditionHolds(op.Cond) {
+		m.BranchTo(op.Target(m.PC))
+	}
+}
+
+func (op *BCond) Target(pc uint64) uint64 {
+	return pc + uint64(branchOffset(op.Imm, 19))
+}
+
+func (op *BCond) String() string {
+	return fmt.Sprintf("b.%s #%d", condNames[op.Cond&0b1111], branchOffset(op.Imm, 19))
+}
+
+func encodeCompareAndBranch(sf, nonzero, imm, rt uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{0b011010, 6},
+		{nonzero, 1}, // op
+		{imm, 19},
+		{rt, 5},
+	}...)
+}
+
+// CBZ
+type Cbz struct {
+	Sf  uint32 // 1 bit
+	Imm uint32 // 19 bits
+	Rt  uint32 // 5 bits
+}
+
+func (op *Cbz) Encode() uint32 {
+	return encodeCompareAndBranch(op.Sf, 0, op.Imm, op.Rt)
+}
+
+func (op *Cbz) Execute(m *machine.Machine) {
+	if reg(m, op.Sf, op.Rt, false) == 0 {
+		m.BranchTo(op.Target(m.PC))
+	}
+}
+
+func (op *Cbz) Target(pc uint64) uint64 {
+	return pc + uint64(branchOffset(op.Imm, 19))
+}
+
+func (op *Cbz) String() string {
+	return fmt.Sprintf("cbz %s, #%d", regName(op.Sf, op.Rt, false), branchOffset(op.Imm, 19))
+}
+
+// CBNZ
+type Cbnz struct {
+	Sf  uint32 // 1 bit
+	Imm uint32 // 19 bits
+	Rt  uint32 // 5 bits
+}
+
+func (op *Cbnz) Encode() uint32 {
+	return encodeCompareAndBranch(op.Sf, 1, op.Imm, op.Rt)
+}
+
+func (op *Cbnz) Execute(m *machine.Machine) {
+	if reg(m, op.Sf, op.Rt, false) != 0 {
+		m.BranchTo(op.Target(m.PC))
+	}
+}
+
+func (op *Cbnz) Target(pc uint64) uint64 {
+	return pc + uint64(branchOffset(op.Imm, 19))
+}
+
+func (op *Cbnz) String() string {
+	return fmt.Sprintf("cbnz %s, #%d", regName(op.Sf, op.Rt, false), branchOffset(op.Imm, 19))
+}
+
+func encodeTestAndBranch(nonzero, b5, b40, imm, rt uint32) uint32 {
+	return buildUint32([]bits{
+		{b5, 1},
+		{0b011011, 6},
+		{nonzero, 1}, // op
+		{b40, 5},
+		{imm, 14},
+		{rt, 5},
+	}...)
+}
+
+// testBitOperands formats the register and bit number tested by TBZ and TBNZ.  The register is
+// only named as an x register when the bit number requires it.
+func testBitOperands(b5, b40, rt uint32) string {
+	return fmt.Sprintf("%s, #%d", regName(b5, rt, false), (b5&1)<<5|b40&0b11111)
+}
+
+// TBZ
+type Tbz struct {
+	B5  uint32 // 1 bit
+	B40 uint32 // 5 bits
+	Imm uint32 // 14 bits
+	Rt  uint32 // 5 bits
+}
+
+func (op *Tbz) Encode() uint32 {
+	return encodeTestAndBranch(0, op.B5, op.B40, op.Imm, op.Rt)
+}
+
+func (op *Tbz) Execute(m *machine.Machine) {
+	bit := (op.B5&1)<<5 | op.B40&0b11111
+	if reg(m, 1, op.Rt, false)>>bit&1 == 0 {
+		m.BranchTo(op.Target(m.PC))
+	}
+}
+
+func (op *Tbz) Target(pc uint64) uint64 {
+	return pc + uint64(branchOffset(op.Imm, 14))
+}
+
+func (op *Tbz) String() string {
+	return fmt.Sprintf("tbz %s, #%d", testBitOperands(op.B5, op.B40, op.Rt), branchOffset(op.Imm, 14))
+}
+
+// TBNZ
+type Tbnz struct {
+	B5  uint32 // 1 bit
+	B40 uint32 // 5 bits
+	Imm uint32 // 14 bits
+	Rt  uint32 // 5 bits
+}
+
+func (op *Tbnz) Encode() uint32 {
+	return encodeTestAndBranch(1, op.B5, op.B40, op.Imm, op.Rt)
+}
+
+func (op *Tbnz) Execute(m *machine.Machine) {
+	bit := (op.B5&1)<<5 | op.B40&0b11111
+	if reg(m, 1, op.Rt, false)>>bit&1 == 1 {
+		m.BranchTo(op.Target(m.PC))
+	}
+}
+
+func (op *Tbnz) Target(pc uint64) uint64 {
+	return pc + uint64(branchOffset(op.Imm, 14))
+}
+
+func (op *Tbnz) String() string {
+	return fmt.Sprintf("tbnz %s, #%d", testBitOperands(op.B5, op.B40, op.Rt), branchOffset(op.Imm, 14))
+}
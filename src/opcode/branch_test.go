@@ -0,0 +1,69 @@
+package opcode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestBranchExecute(t *testing.T) {
+	for _, tc := range []struct {
+		inst   Instruction
+		x0, lr uint64
+		nzcv   uint32
+		wantPC uint64
+		wantLR uint64
+	}{
+		{&B{Imm: 4}, 0, 0, 0, 0x110, 0},
+		{&B{Imm: 0x3fffffc}, 0, 0, 0, 0xf0, 0},
+		{&Bl{Imm: 2}, 0, 0, 0, 0x108, 0x104},
+		{&Br{Rn: 0}, 0x2000, 0, 0, 0x2000, 0},
+		{&Blr{Rn: 0}, 0x2000, 0, 0, 0x2000, 0x104},
+		{&Ret{Rn: 30}, 0, 0x500, 0, 0x500, 0x500},
+		{&BCond{Imm: 2, Cond: 0b0000}, 0, 0, machine.FlagZ, 0x108, 0},
+		{&BCond{Imm: 2, Cond: 0b0000}, 0, 0, 0, 0x104, 0},
+		{&BCond{Imm: 2, Cond: 0b1011}, 0, 0, machine.FlagN, 0x108, 0},
+		{&Cbz{Imm: 2, Rt: 0}, 0x100000000, 0, 0, 0x108, 0},
+		{&Cbz{Sf: 1, Imm: 2, Rt: 0}, 0x100000000, 0, 0, 0x104, 0},
+		{&Cbnz{Sf: 1, Imm: 0x7ffff, Rt: 0}, 1, 0, 0, 0xfc, 0},
+		{&Tbz{B40: 3, Imm: 2, Rt: 0}, 0b0111, 0, 0, 0x108, 0},
+		{&Tbnz{B5: 1, B40: 31, Imm: 2, Rt: 0}, 1 << 63, 0, 0, 0x108, 0},
+		{&Tbnz{B5: 1, B40: 31, Imm: 2, Rt: 0}, 1 << 62, 0, 0, 0x104, 0},
+	} {
+		m := machine.New(0x1000)
+		m.Decode = Decoder
+		m.PC = 0x100
+		m.Write(m.PC, 4, uint64(tc.inst.Encode()))
+		m.R[0] = tc.x0
+		m.R[30] = tc.lr
+		m.SetNZCV(tc.nzcv)
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%v: Step returned %v, %v", tc.inst, reason, err)
+			continue
+		}
+		if m.PC != tc.wantPC || m.R[30] != tc.wantLR {
+			t.Errorf("%v with x0=0x%x nzcv=%04b: got pc=0x%x lr=0x%x, want pc=0x%x lr=0x%x", tc.inst, tc.x0, tc.nzcv, m.PC, m.R[30], tc.wantPC, tc.wantLR)
+		}
+	}
+}
+
+func TestBranchProgram(t *testing.T) {
+	m := load(
+		&Bl{Imm: 4},                  //  0: bl func
+		&Tbnz{B40: 3, Imm: 2, Rt: 1}, //  4: tbnz w1, #3, done
+		&Brk{},                       //  8: brk #0
+		&Hlt{},                       // 12: done: hlt #0
+		&AddShiftedRegister{Sf: 1, Rm: 0, Rn: 1, Rd: 1}, // 16: func: add x1, x1, x0
+		&SubsImmediate{Sf: 1, Imm: 1, Rn: 0, Rd: 0},     // 20: subs x0, x0, #1
+		&BCond{Imm: 0x7fffe, Cond: 0b0001},              // 24: b.ne func
+		&Ret{Rn: 30},                                    // 28: ret
+	)
+	m.R[0] = 5
+	if reason, err := m.Run(context.Background()); reason != machine.StopHalt {
+		t.Fatalf("Run returned %v, %v, want %v", reason, err, machine.StopHalt)
+	}
+	if m.PC != 12 || m.R[1] != 15 || m.R[30] != 4 || m.Retired != 18 {
+		t.Errorf("got pc=%d x1=%d lr=%d retired=%d, want pc=12 x1=15 lr=4 retired=18", m.PC, m.R[1], m.R[30], m.Retired)
+	}
+}
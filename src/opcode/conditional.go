@@ -0,0 +1,268 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func encodeConditionalSelect(sf, op, op2, rm, cond, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{op, 1},
+		{0b011010100, 9},
+		{rm, 5},
+		{cond, 4},
+		{op2, 2},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// executeConditionalSelect writes Rn to Rd if cond holds, and otherwise Rm after inverting it if
+// invert is set and then incrementing it if increment is set.
+func executeConditionalSelect(m *machine.Machine, sf uint32, invert, increment bool, rm, cond, rn, rd uint32) {
+	result := reg(m, sf, rn, false)
+	if !m.ConditionHolds(cond) {
+		result = reg(m, sf, rm, false)
+		if invert {
+			result = ^result
+		}
+		if increment {
+			result++
+		}
+	}
+	setReg(m, sf, rd, false, result)
+}
+
+// conditionalSelectOperands formats the operands of a conditional select instruction.
+func conditionalSelectOperands(sf, rm, cond, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s, %s, %s", regName(sf, rd, false), regName(sf, rn, false), regName(sf, rm, false), condNames[cond&0b1111])
+}
+
+// conditionalSelectAlias returns the operands of the alias of CSINC, CSINV or CSNEG that tests the
+// inverse of cond, or false if there isn't one.  Like the conditional branches, aliases are never
+// used with AL or NV because they cannot be inverted.  set is the alias that selects between zero
+// and a constant, if the instruction has one.
+func conditionalSelectAlias(sf, rm, cond, rn, rd uint32, set bool) (string, bool) {
+	rm, rn = rm&0b11111, rn&0b11111
+	switch {
+	case cond&0b1110 == 0b1110 || rm != rn:
+		return "", false
+	case set && rn == 31:
+		return fmt.Sprintf("%s, %s", regName(sf, rd, false), condNames[cond&0b1111^1]), true
+	}
+	return fmt.Sprintf("%s, %s, %s", regName(sf, rd, false), regName(sf, rn, false), condNames[cond&0b1111^1]), true
+}
+
+// CSEL
+type Csel struct {
+	Sf   uint32 // 1 bit
+	Rm   uint32 // 5 bits
+	Cond uint32 // 4 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Csel) Encode() uint32 {
+	return encodeConditionalSelect(op.Sf, 0, 0b00, op.Rm, op.Cond, op.Rn, op.Rd)
+}
+
+func (op *Csel) Execute(m *machine.Machine) {
+	executeConditionalSelect(m, op.Sf, false, false, op.Rm, op.Cond, op.Rn, op.Rd)
+}
+
+func (op *Csel) String() string {
+	return "csel " + conditionalSelectOperands(op.Sf, op.Rm, op.Cond, op.Rn, op.Rd)
+}
+
+// CSINC
+type Csinc struct {
+	Sf   uint32 // 1 bit
+	Rm   uint32 // 5 bits
+	Cond uint32 // 4 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Csinc) Encode() uint32 {
+	return encodeConditionalSelect(op.Sf, 0, 0b01, op.Rm, op.Cond, op.Rn, op.Rd)
+}
+
+func (op *Csinc) Execute(m *machine.Machine) {
+	executeConditionalSelect(m, op.Sf, false, true, op.Rm, op.Cond, op.Rn, op.Rd)
+}
+
+func (op *Csinc) String() string {
+	if operands, ok := conditionalSelectAlias(op.Sf, op.Rm, op.Cond, op.Rn, op.Rd, true); ok {
+		if op.Rn&0b11111 == 31 {
+			return "cset " + operands
+		}
+		return "cinc " + operands
+	}
+	return "csinc " + conditionalSelectOperands(op.Sf, op.Rm, op.Cond, op.Rn, op.Rd)
+}
+
+// CSINV
+type Csinv struct {
+	Sf   uint32 // 1 bit
+	Rm   uint32 // 5 bits
+	Cond uint32 // 4 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Csinv) Encode() uint32 {
+	return encodeConditionalSelect(op.Sf, 1, 0b00, op.Rm, op.Cond, op.Rn, op.Rd)
+}
+
+func (op *Csinv) Execute(m *machine.Machine) {
+	executeConditionalSelect(m, op.Sf, true, false, op.Rm, op.Cond, op.Rn, op.Rd)
+}
+
+func (op *Csinv) String() string {
+	if operands, ok := conditionalSelectAlias(op.Sf, op.Rm, op.Cond, op.Rn, op.Rd, true); ok {
+		if op.Rn&0b11111 == 31 {
+			return "csetm " + operands
+		}
+		return "cinv " + operands
+	}
+	return "csinv " + conditionalSelectOperands(op.Sf, op.Rm, op.Cond, op.Rn, op.Rd)
+}
+
+// CSNEG
+type Csneg struct {
+	Sf   uint32 // 1 bit
+	Rm   uint32 // 5 bits
+	Cond uint32 // 4 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Csneg) Encode() uint32 {
+	return encodeConditionalSelect(op.Sf, 1, 0b01, op.Rm, op.Cond, op.Rn, op.Rd)
+}
+
+func (op *Csneg) Execute(m *machine.Machine) {
+	executeConditionalSelect(m, op.Sf, true, true, op.Rm, op.Cond, op.Rn, op.Rd)
+}
+
+func (op *Csneg) String() string {
+	if operands, ok := conditionalSelectAlias(op.Sf, op.Rm, op.Cond, op.Rn, op.Rd, false); ok {
+		return "cneg " + operands
+	}
+	return "csneg " + conditionalSelectOperands(op.Sf, op.Rm, op.Cond, op.Rn, op.Rd)
+}
+
+func encodeConditionalCompare(sf, op, rm, cond, imm, rn, nzcv uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{op, 1},
+		{0b111010010, 9},
+		{rm, 5},
+		{cond, 4},
+		{imm, 1},
+		{0, 1},
+		{rn, 5},
+		{0, 1},
+		{nzcv, 4},
+	}...)
+}
+
+// executeConditionalCompare sets the flags by comparing Rn with op2 if cond holds, subtracting for
+// CCMP and adding for CCMN, and otherwise sets them to nzcv.
+func executeConditionalCompare(m *machine.Machine, sf uint32, sub bool, op2 uint64, cond, rn, nzcv uint32) {
+	if m.ConditionHolds(cond) {
+		carry := uint64(0)
+		if sub {
+			op2 = ^op2
+			carry = 1
+		}
+		_, nzcv = addWithCarry(sf, reg(m, sf, rn, false), op2, carry)
+	}
+	m.SetNZCV(nzcv)
+}
+
+// CCMN (register)
+type CcmnRegister struct {
+	Sf   uint32 // 1 bit
+	Rm   uint32 // 5 bits
+	Cond uint32 // 4 bits
+	Rn   uint32 // 5 bits
+	Nzcv uint32 // 4 bits
+}
+
+func (op *CcmnRegister) Encode() uint32 {
+	return encodeConditionalCompare(op.Sf, 0, op.Rm, op.Cond, 0, op.Rn, op.Nzcv)
+}
+
+func (op *CcmnRegister) Execute(m *machine.Machine) {
+	executeConditionalCompare(m, op.Sf, false, reg(m, op.Sf, op.Rm, false), op.Cond, op.Rn, op.Nzcv)
+}
+
+func (op *CcmnRegister) String() string {
+	return fmt.Sprintf("ccmn %s, %s, #%d, %s", regName(op.Sf, op.Rn, false), regName(op.Sf, op.Rm, false), op.Nzcv&0b1111, condNames[op.Cond&0b1111])
+}
+
+// CCMP (register)
+type CcmpRegister struct {
+	Sf   uint32 // 1 bit
+	Rm   uint32 // 5 bits
+	Cond uint32 // 4 bits
+	Rn   uint32 // 5 bits
+	Nzcv uint32 // 4 bits
+}
+
+func (op *CcmpRegister) Encode() uint32 {
+	return encodeConditionalCompare(op.Sf, 1, op.Rm, op.Cond, 0, op.Rn, op.Nzcv)
+}
+
+func (op *CcmpRegister) Execute(m *machine.Machine) {
+	executeConditionalCompare(m, op.Sf, true, reg(m, op.Sf, op.Rm, false), op.Cond, op.Rn, op.Nzcv)
+}
+
+func (op *CcmpRegister) String() string {
+	return fmt.Sprintf("ccmp %s, %s, #%d, %s", regName(op.Sf, op.Rn, false), regName(op.Sf, op.Rm, false), op.Nzcv&0b1111, condNames[op.Cond&0b1111])
+}
+
+// CCMN (immediate)
+type CcmnImmediate struct {
+	Sf   uint32 // 1 bit
+	Imm  uint32 // 5 bits
+	Cond uint32 // 4 bits
+	Rn   uint32 // 5 bits
+	Nzcv uint32 // 4 bits
+}
+
+func (op *CcmnImmediate) Encode() uint32 {
+	return encodeConditionalCompare(op.Sf, 0, op.Imm, op.Cond, 1, op.Rn, op.Nzcv)
+}
+
+func (op *CcmnImmediate) Execute(m *machine.Machine) {
+	executeConditionalCompare(m, op.Sf, false, uint64(op.Imm&0b11111), op.Cond, op.Rn, op.Nzcv)
+}
+
+func (op *CcmnImmediate) String() string {
+	return fmt.Sprintf("ccmn %s, #%d, #%d, %s", regName(op.Sf, op.Rn, false), op.Imm&0b11111, op.Nzcv&0b1111, condNames[op.Cond&0b1111])
+}
+
+// CCMP (immediate)
+type CcmpImmediate struct {
+	Sf   uint32 // 1 bit
+	Imm  uint32 // 5 bits
+	Cond uint32 // 4 bits
+	Rn   uint32 // 5 bits
+	Nzcv uint32 // 4 bits
+}
+
+func (op *CcmpImmediate) Encode() uint32 {
+	return encodeConditionalCompare(op.Sf, 1, op.Imm, op.Cond, 1, op.Rn, op.Nzcv)
+}
+
+func (op *CcmpImmediate) Execute(m *machine.Machine) {
+	executeConditionalCompare(m, op.Sf, true, uint64(op.Imm&0b11111), op.Cond, op.Rn, op.Nzcv)
+}
+
+func (op *CcmpImmediate) String() string {
+	return fmt.Sprintf("ccmp %s, #%d, #%d, %s", regName(op.Sf, op.Rn, false), op.Imm&0b11111, op.Nzcv&0b1111, condNames[op.Cond&0b1111])
+}
@@ -0,0 +1,88 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestConditionalSelectExecute(t *testing.T) {
+	const (
+		eq = 0b0000
+		ne = 0b0001
+		lt = 0b1011
+		nv = 0b1111
+	)
+	for _, tc := range []struct {
+		asm    string
+		inst   Instruction
+		nzcv   uint32
+		x1, x2 uint64
+		want   uint64
+	}{
+		{"csel x0, x1, x2, eq", &Csel{Sf: 1, Rm: 2, Cond: eq, Rn: 1}, machine.FlagZ, 1, 2, 1},
+		{"csel x0, x1, x2, eq", &Csel{Sf: 1, Rm: 2, Cond: eq, Rn: 1}, 0, 1, 2, 2},
+		{"csel w0, w1, w2, nv", &Csel{Rm: 2, Cond: nv, Rn: 1}, 0, 0x100000001, 2, 1},
+		{"csinc x0, x1, x2, lt", &Csinc{Sf: 1, Rm: 2, Cond: lt, Rn: 1}, machine.FlagN | machine.FlagV, 1, 2, 3},
+		{"csinc x0, x1, x2, lt", &Csinc{Sf: 1, Rm: 2, Cond: lt, Rn: 1}, machine.FlagN, 1, 2, 1},
+		{"cset w0, ne", &Csinc{Rm: 31, Cond: eq, Rn: 31}, 0, 0, 0, 1},
+		{"cinc w0, w1, eq", &Csinc{Rm: 1, Cond: ne, Rn: 1}, machine.FlagZ, 0xffffffff, 0, 0},
+		{"csinv x0, x1, x2, ne", &Csinv{Sf: 1, Rm: 2, Cond: ne, Rn: 1}, machine.FlagZ, 1, 0xf, 0xfffffffffffffff0},
+		{"csetm w0, ne", &Csinv{Rm: 31, Cond: eq, Rn: 31}, 0, 0, 0, 0xffffffff},
+		{"csneg x0, x1, x2, eq", &Csneg{Sf: 1, Rm: 2, Cond: eq, Rn: 1}, 0, 1, 5, 0xfffffffffffffffb},
+		{"cneg w0, w1, eq", &Csneg{Rm: 1, Cond: ne, Rn: 1}, machine.FlagZ, 0x80000000, 0, 0x80000000},
+	} {
+		m := load(tc.inst)
+		m.R[1], m.R[2] = tc.x1, tc.x2
+		m.SetNZCV(tc.nzcv)
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.R[0] != tc.want {
+			t.Errorf("%s: x0 = 0x%x, want 0x%x", tc.asm, m.R[0], tc.want)
+		}
+		if m.NZCV() != tc.nzcv {
+			t.Errorf("%s: NZCV = %04b, want it unchanged at %04b", tc.asm, m.NZCV(), tc.nzcv)
+		}
+	}
+}
+
+func TestConditionalCompareExecute(t *testing.T) {
+	const (
+		n = machine.FlagN
+		z = machine.FlagZ
+		c = machine.FlagC
+		v = machine.FlagV
+	)
+	for _, tc := range []struct {
+		asm    string
+		inst   Instruction
+		nzcv   uint32
+		x1, x2 uint64
+		want   uint32
+	}{
+		{"ccmp x1, x2, #0, eq", &CcmpRegister{Sf: 1, Rm: 2, Cond: 0b0000, Rn: 1}, z, 5, 5, z | c},
+		{"ccmp x1, x2, #0, eq", &CcmpRegister{Sf: 1, Rm: 2, Cond: 0b0000, Rn: 1}, 0, 5, 5, 0},
+		{"ccmp w1, w2, #15, ne", &CcmpRegister{Rm: 2, Cond: 0b0001, Rn: 1, Nzcv: 15}, 0, 0x80000000, 1, c | v},
+		{"ccmp w1, w2, #15, ne", &CcmpRegister{Rm: 2, Cond: 0b0001, Rn: 1, Nzcv: 15}, z, 0x80000000, 1, n | z | c | v},
+		{"ccmp x1, #31, #0, al", &CcmpImmediate{Sf: 1, Imm: 31, Cond: 0b1110, Rn: 1}, 0, 0, 0, n},
+		{"ccmn x1, x2, #2, nv", &CcmnRegister{Sf: 1, Rm: 2, Cond: 0b1111, Rn: 1, Nzcv: 2}, 0, ^uint64(0), 1, z | c},
+		{"ccmn w1, #1, #2, hs", &CcmnImmediate{Imm: 1, Cond: 0b0010, Rn: 1, Nzcv: 2}, c, 0x7fffffff, 0, n | v},
+		{"ccmn w1, #1, #2, hs", &CcmnImmediate{Imm: 1, Cond: 0b0010, Rn: 1, Nzcv: 2}, 0, 0x7fffffff, 0, c},
+	} {
+		m := load(tc.inst)
+		m.R[1], m.R[2] = tc.x1, tc.x2
+		m.SetNZCV(tc.nzcv)
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.NZCV() != tc.want {
+			t.Errorf("%s with NZCV = %04b: NZCV = %04b, want %04b", tc.asm, tc.nzcv, m.NZCV(), tc.want)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package opcode
+
+import (
+	"fmt"
+	gobits "math/bits"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// encodeSIMDCopy encodes an instruction from the Advanced SIMD copy group.
+func encodeSIMDCopy(q, op, imm5, imm4, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{0, 1},
+		{q, 1},
+		{op, 1},
+		{0b01110000, 8},
+		{imm5, 5},
+		{0, 1},
+		{imm4, 4},
+		{1, 1},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// copyElement decodes the imm5 field of an Advanced SIMD copy instruction, whose lowest set bit
+// gives the size field of the element it selects and whose higher bits give the index of the
+// element.  The size is 4 if imm5 is not a valid element.
+func copyElement(imm5 uint32) (size, index uint32) {
+	size = uint32(gobits.TrailingZeros32(imm5&0b11111 | 0b10000))
+	return size, imm5 & 0b11111 >> (size + 1)
+}
+
+// elementName returns the name of element index of Vn with the given size field, such as v0.s[2].
+func elementName(n, size, index uint32) string {
+	return fmt.Sprintf("v%d.%c[%d]", n&0b11111, "bhsd"[size&0b11], index)
+}
+
+// elementSf returns the sf field of the general register that holds an element with the given
+// size field, which is an X register for 64-bit elements and a W register otherwise.
+func elementSf(size uint32) uint32 {
+	if size == 0b11 {
+		return 1
+	}
+	return 0
+}
+
+// DUP (element)
+type DupElement struct {
+	Q    uint32 // 1 bit
+	Imm5 uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *DupElement) Encode() uint32 {
+	return encodeSIMDCopy(op.Q, 0, op.Imm5, 0b0000, op.Rn, op.Rd)
+}
+
+func (op *DupElement) Execute(m *machine.Machine) {
+	size, index := copyElement(op.Imm5)
+	esize := elementBits(size)
+	x := m.V[op.Rn&0b11111].Get(int(index), esize)
+	executeVectorUnary(m, op.Q, esize, op.Rn, op.Rd, func(uint64) uint64 { return x })
+}
+
+func (op *DupElement) String() string {
+	size, index := copyElement(op.Imm5)
+	return fmt.Sprintf("dup %s, %s", vecName(op.Rd, op.Q, size), elementName(op.Rn, size, index))
+}
+
+// DUP (general)
+type DupGeneral struct {
+	Q    uint32 // 1 bit
+	Imm5 uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *DupGeneral) Encode() uint32 {
+	return encodeSIMDCopy(op.Q, 0, op.Imm5, 0b0001, op.Rn, op.Rd)
+}
+
+func (op *DupGeneral) Execute(m *machine.Machine) {
+	size, _ := copyElement(op.Imm5)
+	x := reg(m, 1, op.Rn, false)
+	executeVectorUnary(m, op.Q, elementBits(size), op.Rd, op.Rd, func(uint64) uint64 { return x })
+}
+
+func (op *DupGeneral) String() string {
+	size, _ := copyElement(op.Imm5)
+	return fmt.Sprintf("dup %s, %s", vecName(op.Rd, op.Q, size), regName(elementSf(size), op.Rn, false))
+}
+
+// INS (element)
+type InsElement struct {
+	Imm5 uint32 // 5 bits
+	Imm4 uint32 // 4 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *InsElement) Encode() uint32 {
+	return encodeSIMDCopy(1, 1, op.Imm5, op.Imm4, op.Rn, op.Rd)
+}
+
+// Execute copies the element of Vn selected by Imm4 to the element of Vd selected by Imm5,
+// keeping the other elements of Vd.
+func (op *InsElement) Execute(m *machine.Machine) {
+	size, index := copyElement(op.Imm5)
+	esize := elementBits(size)
+	m.V[op.Rd&0b11111].Set(int(index), esize, m.V[op.Rn&0b11111].Get(int(op.Imm4&0b1111>>size), esize))
+}
+
+func (op *InsElement) String() string {
+	size, index := copyElement(op.Imm5)
+	return fmt.Sprintf("mov %s, %s", elementName(op.Rd, size, index), elementName(op.Rn, size, op.Imm4&0b1111>>size))
+}
+
+// INS (general)
+type InsGeneral struct {
+	Imm5 uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *InsGeneral) Encode() uint32 {
+	return encodeSIMDCopy(1, 0, op.Imm5, 0b0011, op.Rn, op.Rd)
+}
+
+// Execute copies the low bits of Rn to the element of Vd selected by Imm5, keeping the other
+// elements of Vd.
+func (op *InsGeneral) Execute(m *machine.Machine) {
+	size, index := copyElement(op.Imm5)
+	m.V[op.Rd&0b11111].Set(int(index), elementBits(size), reg(m, 1, op.Rn, false))
+}
+
+func (op *InsGeneral) String() string {
+	size, index := copyElement(op.Imm5)
+	return fmt.Sprintf("mov %s, %s", elementName(op.Rd, size, index), regName(elementSf(size), op.Rn, false))
+}
+
+// UMOV
+type Umov struct {
+	Q    uint32 // 1 bit
+	Imm5 uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Umov) Encode() uint32 {
+	return encodeSIMDCopy(op.Q, 0, op.Imm5, 0b0111, op.Rn, op.Rd)
+}
+
+func (op *Umov) Execute(m *machine.Machine) {
+	size, index := copyElement(op.Imm5)
+	setReg(m, op.Q, op.Rd, false, m.V[op.Rn&0b11111].Get(int(index), elementBits(size)))
+}
+
+// String uses the MOV alias for the forms that copy a whole W or X register.
+func (op *Umov) String() string {
+	size, index := copyElement(op.Imm5)
+	mnemonic := "umov"
+	if size >= 0b10 {
+		mnemonic = "mov"
+	}
+	return fmt.Sprintf("%s %s, %s", mnemonic, regName(op.Q, op.Rd, false), elementName(op.Rn, size, index))
+}
+
+// SMOV
+type Smov struct {
+	Q    uint32 // 1 bit
+	Imm5 uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Smov) Encode() uint32 {
+	return encodeSIMDCopy(op.Q, 0, op.Imm5, 0b0101, op.Rn, op.Rd)
+}
+
+func (op *Smov) Execute(m *machine.Machine) {
+	size, index := copyElement(op.Imm5)
+	esize := elementBits(size)
+	setReg(m, op.Q, op.Rd, false, extendElement(m.V[op.Rn&0b11111].Get(int(index), esize), esize, true))
+}
+
+func (op *Smov) String() string {
+	size, index := copyElement(op.Imm5)
+	return fmt.Sprintf("smov %s, %s", regName(op.Q, op.Rd, false), elementName(op.Rn, size, index))
+}
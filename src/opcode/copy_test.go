@@ -0,0 +1,52 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestCopyExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm  string
+		inst Instruction
+		want machine.VectorRegister
+		x0   uint64
+	}{
+		{"dup v0.4s, v1.s[2]", &DupElement{Q: 1, Imm5: 0b10100, Rn: 1},
+			machine.VectorRegister{0x88, 0x89, 0x8a, 0x8b, 0x88, 0x89, 0x8a, 0x8b, 0x88, 0x89, 0x8a, 0x8b, 0x88, 0x89, 0x8a, 0x8b}, ^uint64(0)},
+		{"dup v0.4h, v1.h[7]", &DupElement{Imm5: 0b11110, Rn: 1}, vec(0x8f8e8f8e8f8e8f8e, 0), ^uint64(0)},
+		{"dup v0.8b, w2", &DupGeneral{Imm5: 0b00001, Rn: 2}, vec(0xffffffffffffffff, 0), ^uint64(0)},
+		{"dup v0.2d, x2", &DupGeneral{Q: 1, Imm5: 0b01000, Rn: 2}, vec(0x8899aabbccddeeff, 0x8899aabbccddeeff), ^uint64(0)},
+		{"dup v0.2s, wzr", &DupGeneral{Imm5: 0b00100, Rn: 31}, vec(0, 0), ^uint64(0)},
+		{"mov v0.s[1], v1.s[3]", &InsElement{Imm5: 0b01100, Imm4: 0b1100, Rn: 1},
+			machine.VectorRegister{0xff, 0xff, 0xff, 0xff, 0x8c, 0x8d, 0x8e, 0x8f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, ^uint64(0)},
+		{"mov v0.d[1], x2", &InsGeneral{Imm5: 0b11000, Rn: 2}, vec(^uint64(0), 0x8899aabbccddeeff), ^uint64(0)},
+		{"mov v0.h[7], w2", &InsGeneral{Imm5: 0b11110, Rn: 2}, vec(^uint64(0), 0xeeffffffffffffff), ^uint64(0)},
+		{"umov w0, v1.b[3]", &Umov{Imm5: 0b00111, Rn: 1}, fill(0xff), 0x83},
+		{"mov w0, v1.s[1]", &Umov{Imm5: 0b01100, Rn: 1}, fill(0xff), 0x87868584},
+		{"mov x0, v1.d[1]", &Umov{Q: 1, Imm5: 0b11000, Rn: 1}, fill(0xff), 0x8f8e8d8c8b8a8988},
+		{"smov x0, v1.h[7]", &Smov{Q: 1, Imm5: 0b11110, Rn: 1}, fill(0xff), 0xffffffffffff8f8e},
+		{"smov w0, v1.b[1]", &Smov{Imm5: 0b00011, Rn: 1}, fill(0xff), 0xffffff81},
+		{"smov x0, v1.s[0]", &Smov{Q: 1, Imm5: 0b00100, Rn: 1}, fill(0xff), 0xffffffff83828180},
+	} {
+		m := load(tc.inst)
+		m.R[0] = ^uint64(0)
+		m.R[2] = 0x8899aabbccddeeff
+		m.V[0] = fill(0xff)
+		m.V[1] = bytesFrom(0x80, 1, 16)
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.V[0] != tc.want {
+			t.Errorf("%s: v0 = %x, want %x", tc.asm, m.V[0], tc.want)
+		}
+		if m.R[0] != tc.x0 {
+			t.Errorf("%s: x0 = 0x%x, want 0x%x", tc.asm, m.R[0], tc.x0)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
@@ -0,0 +1,1233 @@
+package opcode
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// ErrUnallocated is wrapped by a DecodeError when the architecture does not allocate the encoding
+// to any instruction.
+var ErrUnallocated = errors.New("unallocated encoding")
+
+// ErrUnimplemented is wrapped by a DecodeError when the encoding is a valid instruction that javelin
+// does not implement yet.
+var ErrUnimplemented = errors.New("unimplemented encoding")
+
+// DecodeError is returned by Decode for any word it cannot turn into an Instruction.  Use
+// errors.Is with ErrUnallocated or ErrUnimplemented to tell the two cases apart.
+type DecodeError struct {
+	Word        uint32
+	Unallocated bool
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("cannot decode 0x%08x: %v", e.Word, e.Unwrap())
+}
+
+func (e *DecodeError) Unwrap() error {
+	if e.Unallocated {
+		return ErrUnallocated
+	}
+	return ErrUnimplemented
+}
+
+func unallocated(word uint32) error {
+	return &DecodeError{Word: word, Unallocated: true}
+}
+
+func unimplemented(word uint32) error {
+	return &DecodeError{Word: word}
+}
+
+// field returns width bits of word starting at bit lo.
+func field(word uint32, lo, width int) uint32 {
+	return (word >> lo) & uint32((1<<width)-1)
+}
+
+// signExtend interprets the low width bits of v as a two's complement number.
+func signExtend(v uint64, width int) int64 {
+	return int64(v<<(64-width)) >> (64 - width)
+}
+
+// encoding matches any word for which word&mask == value.
+type encoding struct {
+	mask   uint32
+	value  uint32
+	decode func(word uint32) (Instruction, error)
+}
+
+// encodings is searched in order, so more specific encodings must come before anything that would
+// also match them.
+var encodings = []encoding{
+	// C4.1.86 Data Processing -- Immediate
+	{0x1f000000, 0x10000000, decodePCRelative},
+	{0x1f800000, 0x11000000, decodeAddSubImmediate},
+	{0x1f800000, 0x12000000, decodeLogicalImmediate},
+	{0x1f800000, 0x12800000, decodeMoveWide},
+	{0x1f800000, 0x13000000, decodeBitfield},
+	{0x1f800000, 0x13800000, decodeExtract},
+
+	// C4.1.87 Branches, Exception Generating and System instructions
+	{0x7c000000, 0x14000000, decodeUnconditionalBranchImmediate},
+	{0xff000010, 0x54000000, decodeConditionalBranch},
+	{0x7e000000, 0x34000000, decodeCompareAndBranch},
+	{0x7e000000, 0x36000000, decodeTestAndBranch},
+	{0xffdffc1f, 0xd61f0000, decodeUnconditionalBranchRegister},
+	{0xfffffc1f, 0xd65f0000, decodeUnconditionalBranchRegister},
+	{0xffe0001f, 0xd4200000, decodeBrk},
+	{0xffe0001f, 0xd4400000, decodeHlt},
+
+	// C4.1.88 Loads and Stores
+	{0x3b000000, 0x18000000, decodeLoadLiteral},
+	{0x3b200c00, 0x38000000, decodeLoadStoreUnscaled},
+	{0x3b200c00, 0x38000400, decodeLoadStorePostIndexed},
+	{0x3b200c00, 0x38000c00, decodeLoadStorePreIndexed},
+	{0x3b200c00, 0x38200800, decodeLoadStoreRegisterOffset},
+	{0x3b000000, 0x39000000, decodeLoadStoreUnsignedImmediate},
+	{0x3b800000, 0x28800000, decodeLoadStorePairPostIndexed},
+	{0x3b800000, 0x29000000, decodeLoadStorePairOffset},
+	{0x3b800000, 0x29800000, decodeLoadStorePairPreIndexed},
+	{0xbf800000, 0x0c000000, decodeSIMDLoadStoreMultiple},
+	{0xbf800000, 0x0c800000, decodeSIMDLoadStoreMultiple},
+	{0xbf800000, 0x0d000000, decodeSIMDLoadStoreSingle},
+	{0xbf800000, 0x0d800000, decodeSIMDLoadStoreSingle},
+
+	// C4.1.89 Data Processing -- Register
+	{0x1f000000, 0x0a000000, decodeLogicalShiftedRegister},
+	{0x1f200000, 0x0b000000, decodeAddSubShiftedRegister},
+	{0x1fe00000, 0x0b200000, decodeAddSubExtendedRegister},
+	{0x1fe0fc00, 0x1a000000, decodeAddSubWithCarry},
+	{0x1fe00000, 0x1a400000, decodeConditionalCompare},
+	{0x1fe00000, 0x1a800000, decodeConditionalSelect},
+	{0x5fe00000, 0x1ac00000, decodeDataProcessing2Source},
+	{0x5fe00000, 0x5ac00000, decodeDataProcessing1Source},
+	{0x1f000000, 0x1b000000, decodeDataProcessing3Source},
+
+	// C4.1.90 Data Processing -- Scalar Floating-Point and Advanced SIMD
+	{0x9f200400, 0x0e200400, decodeSIMDThreeSame},
+	{0x9f200c00, 0x0e200000, decodeSIMDThreeDifferent},
+	{0x9f3e0c00, 0x0e200800, decodeSIMDTwoRegisterMisc},
+	{0x9f3e0c00, 0x0e300800, decodeSIMDAcrossLanes},
+	{0xbf208c00, 0x0e000800, decodeSIMDPermute},
+	{0xbf208400, 0x2e000000, decodeSIMDExtract},
+	{0xbf208c00, 0x0e000000, decodeSIMDTableLookup},
+	{0x9fe08400, 0x0e000400, decodeSIMDCopy},
+	{0x9f800400, 0x0f000400, decodeSIMDShiftByImmediate},
+	{0x5f20fc00, 0x1e200000, decodeFPIntegerConversion},
+	{0x5f207c00, 0x1e204000, decodeFPDataProcessing1Source},
+	{0x5f203c00, 0x1e202000, decodeFPCompare},
+	{0x5f201c00, 0x1e201000, decodeFPImmediate},
+	{0x5f200c00, 0x1e200800, decodeFPDataProcessing2Source},
+	{0x5f200c00, 0x1e200c00, decodeFPConditionalSelect},
+	{0x5f000000, 0x1f000000, decodeFPDataProcessing3Source},
+}
+
+// Decode turns a 32-bit A64 machine word into the Instruction it encodes.  Words that javelin does
+// not recognise produce a *DecodeError.
+func Decode(word uint32) (Instruction, error) {
+	for _, enc := range encodings {
+		if word&enc.mask == enc.value {
+			return enc.decode(word)
+		}
+	}
+	// C4.1 A64 instruction set encoding, op0 is bits 28:25.
+	switch field(word, 25, 4) {
+	case 0b0000, 0b0001, 0b0011:
+		return nil, unallocated(word)
+	}
+	return nil, unimplemented(word)
+}
+
+// Decoder is Decode with the signature expected by machine.Machine.Decode.
+func Decoder(word uint32) (machine.Instruction, error) {
+	inst, err := Decode(word)
+	if err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
+
+func decodePCRelative(word uint32) (Instruction, error) {
+	immlo, immhi, rd := field(word, 29, 2), field(word, 5, 19), field(word, 0, 5)
+	if field(word, 31, 1) == 0 {
+		return &Adr{Immlo: immlo, Immhi: immhi, Rd: rd}, nil
+	}
+	return &Adrp{Immlo: immlo, Immhi: immhi, Rd: rd}, nil
+}
+
+func decodeAddSubImmediate(word uint32) (Instruction, error) {
+	sf, sh, imm, rn, rd := field(word, 31, 1), field(word, 22, 1), field(word, 10, 12), field(word, 5, 5), field(word, 0, 5)
+	switch field(word, 29, 2) { // op:S
+	case 0b00:
+		return &AddImmedite{Sf: sf, Sh: sh, Imm: imm, Rn: rn, Rd: rd}, nil
+	case 0b01:
+		return &AddsImmediate{Sf: sf, Sh: sh, Imm: imm, Rn: rn, Rd: rd}, nil
+	case 0b10:
+		return &SubImmediate{Sf: sf, Sh: sh, Imm: imm, Rn: rn, Rd: rd}, nil
+	default:
+		return &SubsImmediate{Sf: sf, Sh: sh, Imm: imm, Rn: rn, Rd: rd}, nil
+	}
+}
+
+func decodeLogicalImmediate(word uint32) (Instruction, error) {
+	sf, n, immr, imms, rn, rd := field(word, 31, 1), field(word, 22, 1), field(word, 16, 6), field(word, 10, 6), field(word, 5, 5), field(word, 0, 5)
+	if _, ok := DecodeBitmaskImmediate(n, immr, imms, 32<<sf); !ok {
+		return nil, unallocated(word)
+	}
+	switch field(word, 29, 2) { // opc
+	case 0b00:
+		return &AndImmediate{Sf: sf, N: n, Immr: immr, Imms: imms, Rn: rn, Rd: rd}, nil
+	case 0b01:
+		return &OrrImmediate{Sf: sf, N: n, Immr: immr, Imms: imms, Rn: rn, Rd: rd}, nil
+	case 0b10:
+		return &EorImmediate{Sf: sf, N: n, Immr: immr, Imms: imms, Rn: rn, Rd: rd}, nil
+	default:
+		return &AndsImmediate{Sf: sf, N: n, Immr: immr, Imms: imms, Rn: rn, Rd: rd}, nil
+	}
+}
+
+func decodeMoveWide(word uint32) (Instruction, error) {
+	sf, hw, imm, rd := field(word, 31, 1), field(word, 21, 2), field(word, 5, 16), field(word, 0, 5)
+	if sf == 0 && hw >= 0b10 {
+		return nil, unallocated(word)
+	}
+	switch field(word, 29, 2) { // opc
+	case 0b00:
+		return &Movn{Sf: sf, Hw: hw, Imm: imm, Rd: rd}, nil
+	case 0b10:
+		return &Movz{Sf: sf, Hw: hw, Imm: imm, Rd: rd}, nil
+	case 0b11:
+		return &Movk{Sf: sf, Hw: hw, Imm: imm, Rd: rd}, nil
+	}
+	return nil, unallocated(word)
+}
+
+func decodeBitfield(word uint32) (Instruction, error) {
+	sf, n, immr, imms, rn, rd := field(word, 31, 1), field(word, 22, 1), field(word, 16, 6), field(word, 10, 6), field(word, 5, 5), field(word, 0, 5)
+	if n != sf || (sf == 0 && (immr >= 32 || imms >= 32)) {
+		return nil, unallocated(word)
+	}
+	switch field(word, 29, 2) { // opc
+	case 0b00:
+		return &Sbfm{Sf: sf, N: n, Immr: immr, Imms: imms, Rn: rn, Rd: rd}, nil
+	case 0b01:
+		return &Bfm{Sf: sf, N: n, Immr: immr, Imms: imms, Rn: rn, Rd: rd}, nil
+	case 0b10:
+		return &Ubfm{Sf: sf, N: n, Immr: immr, Imms: imms, Rn: rn, Rd: rd}, nil
+	}
+	return nil, unallocated(word)
+}
+
+func decodeExtract(word uint32) (Instruction, error) {
+	sf, n, rm, imms, rn, rd := field(word, 31, 1), field(word, 22, 1), field(word, 16, 5), field(word, 10, 6), field(word, 5, 5), field(word, 0, 5)
+	// op21 and o0 must be zero.
+	if field(word, 29, 2) != 0 || field(word, 21, 1) != 0 || n != sf || (sf == 0 && imms >= 32) {
+		return nil, unallocated(word)
+	}
+	return &Extr{Sf: sf, N: n, Rm: rm, Imms: imms, Rn: rn, Rd: rd}, nil
+}
+
+func decodeLogicalShiftedRegister(word uint32) (Instruction, error) {
+	sf, shift, rm, imm, rn, rd := field(word, 31, 1), field(word, 22, 2), field(word, 16, 5), field(word, 10, 6), field(word, 5, 5), field(word, 0, 5)
+	if sf == 0 && imm >= 32 {
+		return nil, unallocated(word)
+	}
+	switch field(word, 29, 2)<<1 | field(word, 21, 1) { // opc:N
+	case 0b000:
+		return &AndShiftedRegister{Sf: sf, Shift: shift, Rm: rm, Imm: imm, Rn: rn, Rd: rd}, nil
+	case 0b001:
+		return &BicShiftedRegister{Sf: sf, Shift: shift, Rm: rm, Imm: imm, Rn: rn, Rd: rd}, nil
+	case 0b010:
+		return &OrrShiftedRegister{Sf: sf, Shift: shift, Rm: rm, Imm: imm, Rn: rn, Rd: rd}, nil
+	case 0b011:
+		return &OrnShiftedRegister{Sf: sf, Shift: shift, Rm: rm, Imm: imm, Rn: rn, Rd: rd}, nil
+	case 0b100:
+		return &EorShiftedRegister{Sf: sf, Shift: shift, Rm: rm, Imm: imm, Rn: rn, Rd: rd}, nil
+	case 0b101:
+		return &EonShiftedRegister{Sf: sf, Shift: shift, Rm: rm, Imm: imm, Rn: rn, Rd: rd}, nil
+	case 0b110:
+		return &AndsShiftedRegister{Sf: sf, Shift: shift, Rm: rm, Imm: imm, Rn: rn, Rd: rd}, nil
+	default:
+		return &BicsShiftedRegister{Sf: sf, Shift: shift, Rm: rm, Imm: imm, Rn: rn, Rd: rd}, nil
+	}
+}
+
+func decodeAddSubWithCarry(word uint32) (Instruction, error) {
+	sf, rm, rn, rd := field(word, 31, 1), field(word, 16, 5), field(word, 5, 5), field(word, 0, 5)
+	switch field(word, 29, 2) { // op:S
+	case 0b00:
+		return &Adc{Sf: sf, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b01:
+		return &Adcs{Sf: sf, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b10:
+		return &Sbc{Sf: sf, Rm: rm, Rn: rn, Rd: rd}, nil
+	default:
+		return &Sbcs{Sf: sf, Rm: rm, Rn: rn, Rd: rd}, nil
+	}
+}
+
+func decodeConditionalCompare(word uint32) (Instruction, error) {
+	sf, rm, cond, rn, nzcv := field(word, 31, 1), field(word, 16, 5), field(word, 12, 4), field(word, 5, 5), field(word, 0, 4)
+	// S must be set, and o2 and o3 clear.
+	if field(word, 29, 1) != 1 || field(word, 10, 1) != 0 || field(word, 4, 1) != 0 {
+		return nil, unallocated(word)
+	}
+	switch field(word, 30, 1)<<1 | field(word, 11, 1) { // op:imm
+	case 0b00:
+		return &CcmnRegister{Sf: sf, Rm: rm, Cond: cond, Rn: rn, Nzcv: nzcv}, nil
+	case 0b01:
+		return &CcmnImmediate{Sf: sf, Imm: rm, Cond: cond, Rn: rn, Nzcv: nzcv}, nil
+	case 0b10:
+		return &CcmpRegister{Sf: sf, Rm: rm, Cond: cond, Rn: rn, Nzcv: nzcv}, nil
+	default:
+		return &CcmpImmediate{Sf: sf, Imm: rm, Cond: cond, Rn: rn, Nzcv: nzcv}, nil
+	}
+}
+
+func decodeConditionalSelect(word uint32) (Instruction, error) {
+	sf, rm, cond, rn, rd := field(word, 31, 1), field(word, 16, 5), field(word, 12, 4), field(word, 5, 5), field(word, 0, 5)
+	if field(word, 29, 1) != 0 { // S
+		return nil, unallocated(word)
+	}
+	switch field(word, 30, 1)<<2 | field(word, 10, 2) { // op:op2
+	case 0b000:
+		return &Csel{Sf: sf, Rm: rm, Cond: cond, Rn: rn, Rd: rd}, nil
+	case 0b001:
+		return &Csinc{Sf: sf, Rm: rm, Cond: cond, Rn: rn, Rd: rd}, nil
+	case 0b100:
+		return &Csinv{Sf: sf, Rm: rm, Cond: cond, Rn: rn, Rd: rd}, nil
+	case 0b101:
+		return &Csneg{Sf: sf, Rm: rm, Cond: cond, Rn: rn, Rd: rd}, nil
+	}
+	return nil, unallocated(word)
+}
+
+func decodeDataProcessing1Source(word uint32) (Instruction, error) {
+	sf, s, opcode2, opcode, rn, rd := field(word, 31, 1), field(word, 29, 1), field(word, 16, 5), field(word, 10, 6), field(word, 5, 5), field(word, 0, 5)
+	switch {
+	case s == 0 && opcode2 == 0b00000:
+		switch opcode {
+		case 0b000000:
+			return &Rbit{Sf: sf, Rn: rn, Rd: rd}, nil
+		case 0b000001:
+			return &Rev16{Sf: sf, Rn: rn, Rd: rd}, nil
+		case 0b000010:
+			if sf == 1 {
+				return &Rev32{Rn: rn, Rd: rd}, nil
+			}
+			return &Rev{Sf: sf, Rn: rn, Rd: rd}, nil
+		case 0b000011:
+			if sf == 1 {
+				return &Rev{Sf: sf, Rn: rn, Rd: rd}, nil
+			}
+		case 0b000100:
+			return &Clz{Sf: sf, Rn: rn, Rd: rd}, nil
+		case 0b000101:
+			return &Cls{Sf: sf, Rn: rn, Rd: rd}, nil
+		case 0b000110, 0b000111, 0b001000:
+			// CTZ, CNT and ABS from FEAT_CSSC.
+			return nil, unimplemented(word)
+		}
+	case sf == 1 && s == 0 && opcode2 == 0b00001 && opcode < 0b010010:
+		// The pointer authentication instructions from FEAT_PAuth.
+		return nil, unimplemented(word)
+	}
+	return nil, unallocated(word)
+}
+
+func decodeDataProcessing2Source(word uint32) (Instruction, error) {
+	sf, s, rm, opcode, rn, rd := field(word, 31, 1), field(word, 29, 1), field(word, 16, 5), field(word, 10, 6), field(word, 5, 5), field(word, 0, 5)
+	if s == 0 {
+		switch opcode {
+		case 0b000010:
+			return &Udiv{Sf: sf, Rm: rm, Rn: rn, Rd: rd}, nil
+		case 0b000011:
+			return &Sdiv{Sf: sf, Rm: rm, Rn: rn, Rd: rd}, nil
+		case 0b001000:
+			return &Lslv{Sf: sf, Rm: rm, Rn: rn, Rd: rd}, nil
+		case 0b001001:
+			return &Lsrv{Sf: sf, Rm: rm, Rn: rn, Rd: rd}, nil
+		case 0b001010:
+			return &Asrv{Sf: sf, Rm: rm, Rn: rn, Rd: rd}, nil
+		case 0b001011:
+			return &Rorv{Sf: sf, Rm: rm, Rn: rn, Rd: rd}, nil
+		}
+	}
+	switch {
+	case s == 0 && opcode>>3 == 0b010:
+		// CRC32 and CRC32C, whose 64-bit forms are exactly those with sz = 0b11.
+		if (sf == 1) == (opcode&0b11 == 0b11) {
+			return nil, unimplemented(word)
+		}
+	case sf == 1 && s == 0 && (opcode == 0b000000 || opcode == 0b000100 || opcode == 0b000101 || opcode == 0b001100):
+		// SUBP, IRG and GMI from FEAT_MTE, and PACGA from FEAT_PAuth.
+		return nil, unimplemented(word)
+	case sf == 1 && s == 1 && opcode == 0b000000:
+		// SUBPS from FEAT_MTE.
+		return nil, unimplemented(word)
+	}
+	return nil, unallocated(word)
+}
+
+func decodeDataProcessing3Source(word uint32) (Instruction, error) {
+	sf, rm, ra, rn, rd := field(word, 31, 1), field(word, 16, 5), field(word, 10, 5), field(word, 5, 5), field(word, 0, 5)
+	if field(word, 29, 2) != 0 { // op54
+		return nil, unallocated(word)
+	}
+	op31, o0 := field(word, 21, 3), field(word, 15, 1)
+	if op31 == 0b000 {
+		if o0 == 0 {
+			return &Madd{Sf: sf, Rm: rm, Ra: ra, Rn: rn, Rd: rd}, nil
+		}
+		return &Msub{Sf: sf, Rm: rm, Ra: ra, Rn: rn, Rd: rd}, nil
+	}
+	if sf == 0 {
+		return nil, unallocated(word)
+	}
+	switch op31<<1 | o0 {
+	case 0b0010:
+		return &Smaddl{Rm: rm, Ra: ra, Rn: rn, Rd: rd}, nil
+	case 0b0011:
+		return &Smsubl{Rm: rm, Ra: ra, Rn: rn, Rd: rd}, nil
+	case 0b0100:
+		return &Smulh{Rm: rm, Ra: ra, Rn: rn, Rd: rd}, nil
+	case 0b1010:
+		return &Umaddl{Rm: rm, Ra: ra, Rn: rn, Rd: rd}, nil
+	case 0b1011:
+		return &Umsubl{Rm: rm, Ra: ra, Rn: rn, Rd: rd}, nil
+	case 0b1100:
+		return &Umulh{Rm: rm, Ra: ra, Rn: rn, Rd: rd}, nil
+	}
+	return nil, unallocated(word)
+}
+
+func decodeAddSubShiftedRegister(word uint32) (Instruction, error) {
+	sf, shift, rm, imm, rn, rd := field(word, 31, 1), field(word, 22, 2), field(word, 16, 5), field(word, 10, 6), field(word, 5, 5), field(word, 0, 5)
+	if shift == 0b11 || (sf == 0 && imm >= 32) {
+		return nil, unallocated(word)
+	}
+	switch field(word, 29, 2) { // op:S
+	case 0b00:
+		return &AddShiftedRegister{Sf: sf, Shift: shift, Rm: rm, Imm: imm, Rn: rn, Rd: rd}, nil
+	case 0b01:
+		return &AddsShiftedRegister{Sf: sf, Shift: shift, Rm: rm, Imm: imm, Rn: rn, Rd: rd}, nil
+	case 0b10:
+		return &SubShiftedRegister{Sf: sf, Shift: shift, Rm: rm, Imm: imm, Rn: rn, Rd: rd}, nil
+	default:
+		return &SubsShiftedRegister{Sf: sf, Shift: shift, Rm: rm, Imm: imm, Rn: rn, Rd: rd}, nil
+	}
+}
+
+func decodeAddSubExtendedRegister(word uint32) (Instruction, error) {
+	sf, rm, opt, imm, rn, rd := field(word, 31, 1), field(word, 16, 5), byte(field(word, 13, 3)), field(word, 10, 3), field(word, 5, 5), field(word, 0, 5)
+	if imm > 4 {
+		return nil, unallocated(word)
+	}
+	switch field(word, 29, 2) { // op:S
+	case 0b00:
+		return &AddExtendedRegister{Sf: sf, Opt: opt, Imm: imm, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b01:
+		return &AddsExtendedRegister{Sf: sf, Opt: opt, Imm: imm, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b10:
+		return &SubExtendedRegister{Sf: sf, Opt: opt, Imm: imm, Rm: rm, Rn: rn, Rd: rd}, nil
+	default:
+		return &SubsExtendedRegister{Sf: sf, Opt: opt, Imm: imm, Rm: rm, Rn: rn, Rd: rd}, nil
+	}
+}
+
+func decodeSIMDTwoRegisterMisc(word uint32) (Instruction, error) {
+	q, size, rn, rd := field(word, 30, 1), field(word, 22, 2), field(word, 5, 5), field(word, 0, 5)
+	switch field(word, 29, 1)<<5 | field(word, 12, 5) { // U:opcode
+	case 0b000000:
+		if size == 0b11 {
+			return nil, unallocated(word)
+		}
+		return &Rev64{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b000001:
+		if size != 0b00 {
+			return nil, unallocated(word)
+		}
+		return &Rev16Vector{Q: q, Rn: rn, Rd: rd}, nil
+	case 0b100000:
+		if size >= 0b10 {
+			return nil, unallocated(word)
+		}
+		return &Rev32Vector{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b100001:
+		return nil, unallocated(word)
+	case 0b000100:
+		if size == 0b11 {
+			return nil, unallocated(word)
+		}
+		return &ClsVector{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b100100:
+		if size == 0b11 {
+			return nil, unallocated(word)
+		}
+		return &ClzVector{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b000101:
+		if size != 0b00 {
+			return nil, unallocated(word)
+		}
+		return &Cnt{Q: q, Rn: rn, Rd: rd}, nil
+	case 0b001011:
+		if size == 0b11 && q == 0 {
+			return nil, unallocated(word)
+		}
+		return &AbsVector{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b101011:
+		if size == 0b11 && q == 0 {
+			return nil, unallocated(word)
+		}
+		return &NegVector{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b001000, 0b101000, 0b001001, 0b101001, 0b001010:
+		if size == 0b11 && q == 0 {
+			return nil, unallocated(word)
+		}
+		switch field(word, 29, 1)<<5 | field(word, 12, 5) {
+		case 0b001000:
+			return &CmgtZero{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+		case 0b101000:
+			return &CmgeZero{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+		case 0b001001:
+			return &CmeqZero{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+		case 0b101001:
+			return &CmleZero{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+		}
+		return &CmltZero{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b101010:
+		return nil, unallocated(word)
+	case 0b000010, 0b100010, 0b000110, 0b100110:
+		if size == 0b11 {
+			return nil, unallocated(word)
+		}
+		switch field(word, 29, 1)<<5 | field(word, 12, 5) {
+		case 0b000010:
+			return &Saddlp{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+		case 0b100010:
+			return &Uaddlp{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+		case 0b000110:
+			return &Sadalp{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+		}
+		return &Uadalp{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b010010:
+		if size == 0b11 {
+			return nil, unallocated(word)
+		}
+		return &Xtn{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b110010:
+		if size == 0b11 {
+			return nil, unallocated(word)
+		}
+		return &Sqxtun{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b110011:
+		if size == 0b11 {
+			return nil, unallocated(word)
+		}
+		return &Shll{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b010100:
+		if size == 0b11 {
+			return nil, unallocated(word)
+		}
+		return &Sqxtn{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b110100:
+		if size == 0b11 {
+			return nil, unallocated(word)
+		}
+		return &Uqxtn{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b100101:
+		switch size {
+		case 0b00:
+			return &Not{Q: q, Rn: rn, Rd: rd}, nil
+		case 0b01:
+			return &RbitVector{Q: q, Rn: rn, Rd: rd}, nil
+		}
+		return nil, unallocated(word)
+	}
+	return nil, unimplemented(word)
+}
+
+func decodeSIMDAcrossLanes(word uint32) (Instruction, error) {
+	q, size, rn, rd := field(word, 30, 1), field(word, 22, 2), field(word, 5, 5), field(word, 0, 5)
+	uopcode := field(word, 29, 1)<<5 | field(word, 12, 5) // U:opcode
+	if uopcode == 0b101100 {
+		// FMAXNMV and FMINNMV, with size<0> as sz.  Only the 4s arrangement is allocated.
+		if q == 0 || size&1 == 1 {
+			return nil, unallocated(word)
+		}
+		if size>>1 == 1 {
+			return &Fminnmv{Q: q, Rn: rn, Rd: rd}, nil
+		}
+		return &Fmaxnmv{Q: q, Rn: rn, Rd: rd}, nil
+	}
+	switch uopcode {
+	case 0b000011, 0b100011, 0b001010, 0b101010, 0b011010, 0b111010, 0b011011:
+		// The integer reductions need at least four elements.
+		if size == 0b11 || (size == 0b10 && q == 0) {
+			return nil, unallocated(word)
+		}
+	}
+	switch uopcode {
+	case 0b011011:
+		return &Addv{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b000011:
+		return &Saddlv{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b100011:
+		return &Uaddlv{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b001010:
+		return &Smaxv{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b101010:
+		return &Umaxv{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b011010:
+		return &Sminv{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b111010:
+		return &Uminv{Q: q, Size: size, Rn: rn, Rd: rd}, nil
+	case 0b111011:
+		return nil, unallocated(word)
+	}
+	return nil, unimplemented(word)
+}
+
+func decodeSIMDThreeSame(word uint32) (Instruction, error) {
+	q, size, rm, rn, rd := field(word, 30, 1), field(word, 22, 2), field(word, 16, 5), field(word, 5, 5), field(word, 0, 5)
+	u, opcode := field(word, 29, 1), field(word, 11, 5)
+	switch opcode {
+	case 0b00000, 0b00010, 0b01100, 0b01101, 0b01110, 0b10010, 0b10011:
+		if size == 0b11 {
+			return nil, unallocated(word)
+		}
+	case 0b00001, 0b00101, 0b00110, 0b00111, 0b01000, 0b01001, 0b01010, 0b01011, 0b10000, 0b10001, 0b10111:
+		if size == 0b11 && q == 0 {
+			return nil, unallocated(word)
+		}
+	case 0b10110:
+		if size == 0b00 || size == 0b11 {
+			return nil, unallocated(word)
+		}
+	}
+	if opcode == 0b00011 {
+		// The bitwise operations, with size selecting the operation rather than the element size.
+		switch u<<2 | size {
+		case 0b000:
+			return &AndVector{Q: q, Rm: rm, Rn: rn, Rd: rd}, nil
+		case 0b001:
+			return &BicVector{Q: q, Rm: rm, Rn: rn, Rd: rd}, nil
+		case 0b010:
+			return &OrrVector{Q: q, Rm: rm, Rn: rn, Rd: rd}, nil
+		case 0b011:
+			return &OrnVector{Q: q, Rm: rm, Rn: rn, Rd: rd}, nil
+		case 0b100:
+			return &EorVector{Q: q, Rm: rm, Rn: rn, Rd: rd}, nil
+		case 0b101:
+			return &Bsl{Q: q, Rm: rm, Rn: rn, Rd: rd}, nil
+		case 0b110:
+			return &Bit{Q: q, Rm: rm, Rn: rn, Rd: rd}, nil
+		}
+		return &Bif{Q: q, Rm: rm, Rn: rn, Rd: rd}, nil
+	}
+	switch u<<5 | opcode { // U:opcode
+	case 0b000000:
+		return &Shadd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b100000:
+		return &Uhadd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b000001:
+		return &Sqadd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b100001:
+		return &Uqadd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b000101:
+		return &Sqsub{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b100101:
+		return &Uqsub{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b010110:
+		return &Sqdmulh{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b110110:
+		return &Sqrdmulh{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b000010:
+		return &Srhadd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b100010:
+		return &Urhadd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b001100:
+		return &Smax{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b101100:
+		return &Umax{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b001101:
+		return &Smin{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b101101:
+		return &Umin{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b001110:
+		return &Sabd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b101110:
+		return &Uabd{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b010000:
+		return &AddVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b110000:
+		return &SubVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b010010:
+		return &MlaVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b110010:
+		return &MlsVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b010011:
+		return &MulVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b010111:
+		return &AddpVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b000110:
+		return &CmgtRegister{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b000111:
+		return &CmgeRegister{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b100110:
+		return &Cmhi{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b100111:
+		return &Cmhs{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b010001:
+		return &Cmtst{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b110001:
+		return &CmeqRegister{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b001000:
+		return &Sshl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b101000:
+		return &Ushl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b001001:
+		return &SqshlRegister{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b101001:
+		return &UqshlRegister{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b001010:
+		return &Srshl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b101010:
+		return &Urshl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b001011:
+		return &Sqrshl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b101011:
+		return &Uqrshl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b110111:
+		return nil, unallocated(word)
+	}
+	return nil, unimplemented(word)
+}
+
+func decodeSIMDThreeDifferent(word uint32) (Instruction, error) {
+	q, size, rm, rn, rd := field(word, 30, 1), field(word, 22, 2), field(word, 16, 5), field(word, 5, 5), field(word, 0, 5)
+	uopcode := field(word, 29, 1)<<4 | field(word, 12, 4) // U:opcode
+	switch {
+	case uopcode == 0b01110:
+		// PMULL
+		return nil, unimplemented(word)
+	case size == 0b11:
+		return nil, unallocated(word)
+	}
+	switch uopcode {
+	case 0b00000:
+		return &Saddl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b10000:
+		return &Uaddl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b00001:
+		return &Saddw{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b10001:
+		return &Uaddw{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b00010:
+		return &Ssubl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b10010:
+		return &Usubl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b00011:
+		return &Ssubw{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b10011:
+		return &Usubw{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b00100:
+		return &Addhn{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b10100:
+		return &Raddhn{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b00101:
+		return &Sabal{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b10101:
+		return &Uabal{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b00110:
+		return &Subhn{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b10110:
+		return &Rsubhn{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b00111:
+		return &Sabdl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b10111:
+		return &Uabdl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b01000:
+		return &Smlal{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b11000:
+		return &Umlal{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b01010:
+		return &Smlsl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b11010:
+		return &Umlsl{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b01100:
+		return &SmullVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b11100:
+		return &UmullVector{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b01001, 0b01011, 0b01101:
+		// SQDMLAL, SQDMLSL and SQDMULL
+		if size == 0b00 {
+			return nil, unallocated(word)
+		}
+		return nil, unimplemented(word)
+	}
+	return nil, unallocated(word)
+}
+
+func decodeBrk(word uint32) (Instruction, error) {
+	return &Brk{Imm: field(word, 5, 16)}, nil
+}
+
+func decodeHlt(word uint32) (Instruction, error) {
+	return &Hlt{Imm: field(word, 5, 16)}, nil
+}
+
+func decodeUnconditionalBranchImmediate(word uint32) (Instruction, error) {
+	if field(word, 31, 1) == 1 {
+		return &Bl{Imm: field(word, 0, 26)}, nil
+	}
+	return &B{Imm: field(word, 0, 26)}, nil
+}
+
+func decodeConditionalBranch(word uint32) (Instruction, error) {
+	return &BCond{Imm: field(word, 5, 19), Cond: field(word, 0, 4)}, nil
+}
+
+func decodeCompareAndBranch(word uint32) (Instruction, error) {
+	sf, imm, rt := field(word, 31, 1), field(word, 5, 19), field(word, 0, 5)
+	if field(word, 24, 1) == 1 {
+		return &Cbnz{Sf: sf, Imm: imm, Rt: rt}, nil
+	}
+	return &Cbz{Sf: sf, Imm: imm, Rt: rt}, nil
+}
+
+func decodeTestAndBranch(word uint32) (Instruction, error) {
+	b5, b40, imm, rt := field(word, 31, 1), field(word, 19, 5), field(word, 5, 14), field(word, 0, 5)
+	if field(word, 24, 1) == 1 {
+		return &Tbnz{B5: b5, B40: b40, Imm: imm, Rt: rt}, nil
+	}
+	return &Tbz{B5: b5, B40: b40, Imm: imm, Rt: rt}, nil
+}
+
+func decodeUnconditionalBranchRegister(word uint32) (Instruction, error) {
+	rn := field(word, 5, 5)
+	switch field(word, 21, 4) { // opc
+	case 0b0000:
+		return &Br{Rn: rn}, nil
+	case 0b0001:
+		return &Blr{Rn: rn}, nil
+	default:
+		return &Ret{Rn: rn}, nil
+	}
+}
+
+// memOpFields returns the size, V and opc fields shared by the load/store register instructions,
+// and an error if they do not describe a load or store.  PRFM is only allocated in the forms that
+// have no writeback, which are given by prefetch.
+func memOpFields(word uint32, prefetch bool) (size, v, opc uint32, err error) {
+	size, v, opc = field(word, 30, 2), field(word, 26, 1), field(word, 22, 2)
+	if _, ok := decodeMemOp(size, v, opc); ok {
+		return size, v, opc, nil
+	}
+	if prefetch && size == 0b11 && v == 0 && opc == 0b10 {
+		return 0, 0, 0, unimplemented(word)
+	}
+	return 0, 0, 0, unallocated(word)
+}
+
+func decodeLoadStoreUnsignedImmediate(word uint32) (Instruction, error) {
+	size, v, opc, err := memOpFields(word, true)
+	if err != nil {
+		return nil, err
+	}
+	return &LoadStoreUnsignedImmediate{Size: size, V: v, Opc: opc, Imm: field(word, 10, 12), Rn: field(word, 5, 5), Rt: field(word, 0, 5)}, nil
+}
+
+func decodeLoadStorePreIndexed(word uint32) (Instruction, error) {
+	size, v, opc, err := memOpFields(word, false)
+	if err != nil {
+		return nil, err
+	}
+	return &LoadStorePreIndexed{Size: size, V: v, Opc: opc, Imm: field(word, 12, 9), Rn: field(word, 5, 5), Rt: field(word, 0, 5)}, nil
+}
+
+func decodeLoadStorePostIndexed(word uint32) (Instruction, error) {
+	size, v, opc, err := memOpFields(word, false)
+	if err != nil {
+		return nil, err
+	}
+	return &LoadStorePostIndexed{Size: size, V: v, Opc: opc, Imm: field(word, 12, 9), Rn: field(word, 5, 5), Rt: field(word, 0, 5)}, nil
+}
+
+func decodeLoadStoreUnscaled(word uint32) (Instruction, error) {
+	size, v, opc, err := memOpFields(word, true)
+	if err != nil {
+		return nil, err
+	}
+	return &LoadStoreUnscaled{Size: size, V: v, Opc: opc, Imm: field(word, 12, 9), Rn: field(word, 5, 5), Rt: field(word, 0, 5)}, nil
+}
+
+func decodeLoadStoreRegisterOffset(word uint32) (Instruction, error) {
+	size, v, opc, err := memOpFields(word, true)
+	if err != nil {
+		return nil, err
+	}
+	op := &LoadStoreRegisterOffset{Size: size, V: v, Opc: opc, Rm: field(word, 16, 5), Option: field(word, 13, 3), S: field(word, 12, 1), Rn: field(word, 5, 5), Rt: field(word, 0, 5)}
+	if op.Option&0b010 == 0 {
+		return nil, unallocated(word)
+	}
+	return op, nil
+}
+
+// pairFields returns the opc, V and L fields shared by the load/store pair instructions, and an
+// error if they do not describe a load or store pair.
+func pairFields(word uint32) (opc, v, l uint32, err error) {
+	opc, v, l = field(word, 30, 2), field(word, 26, 1), field(word, 22, 1)
+	if _, ok := pairMemOp(opc, v, l); ok {
+		return opc, v, l, nil
+	}
+	if opc == 0b01 && v == 0 && l == 0 && field(word, 23, 2) != 0b00 {
+		// STGP
+		return 0, 0, 0, unimplemented(word)
+	}
+	return 0, 0, 0, unallocated(word)
+}
+
+func decodeLoadStorePairOffset(word uint32) (Instruction, error) {
+	opc, v, l, err := pairFields(word)
+	if err != nil {
+		return nil, err
+	}
+	return &LoadStorePairOffset{Opc: opc, V: v, L: l, Imm: field(word, 15, 7), Rt2: field(word, 10, 5), Rn: field(word, 5, 5), Rt: field(word, 0, 5)}, nil
+}
+
+func decodeLoadStorePairPreIndexed(word uint32) (Instruction, error) {
+	opc, v, l, err := pairFields(word)
+	if err != nil {
+		return nil, err
+	}
+	return &LoadStorePairPreIndexed{Opc: opc, V: v, L: l, Imm: field(word, 15, 7), Rt2: field(word, 10, 5), Rn: field(word, 5, 5), Rt: field(word, 0, 5)}, nil
+}
+
+func decodeLoadStorePairPostIndexed(word uint32) (Instruction, error) {
+	opc, v, l, err := pairFields(word)
+	if err != nil {
+		return nil, err
+	}
+	return &LoadStorePairPostIndexed{Opc: opc, V: v, L: l, Imm: field(word, 15, 7), Rt2: field(word, 10, 5), Rn: field(word, 5, 5), Rt: field(word, 0, 5)}, nil
+}
+
+// decodeSIMDLoadStoreMultiple decodes the Advanced SIMD load/store multiple structures
+// instructions, with or without a post-index.
+func decodeSIMDLoadStoreMultiple(word uint32) (Instruction, error) {
+	q, post, l, rm, opcode, size, rn, rt := field(word, 30, 1), field(word, 23, 1), field(word, 22, 1), field(word, 16, 5), field(word, 12, 4), field(word, 10, 2), field(word, 5, 5), field(word, 0, 5)
+	_, selem, ok := multipleStructures(opcode)
+	// Bits 21:16 are 0 without a post-index, and bit 21 is always 0.  Structures of more than one
+	// element cannot be made of 64-bit vectors with a single lane.
+	if !ok || field(word, 21, 1) != 0 || (post == 0 && rm != 0) || (selem > 1 && size == 0b11 && q == 0) {
+		return nil, unallocated(word)
+	}
+	if post == 0 {
+		return &LoadStoreMultiple{Q: q, L: l, Opcode: opcode, Size: size, Rn: rn, Rt: rt}, nil
+	}
+	return &LoadStoreMultiplePostIndexed{Q: q, L: l, Rm: rm, Opcode: opcode, Size: size, Rn: rn, Rt: rt}, nil
+}
+
+// decodeSIMDLoadStoreSingle decodes the Advanced SIMD load/store single structure instructions,
+// with or without a post-index.
+func decodeSIMDLoadStoreSingle(word uint32) (Instruction, error) {
+	q, post, l, r, rm, opcode, s, size, rn, rt := field(word, 30, 1), field(word, 23, 1), field(word, 22, 1), field(word, 21, 1), field(word, 16, 5), field(word, 13, 3), field(word, 12, 1), field(word, 10, 2), field(word, 5, 5), field(word, 0, 5)
+	if _, _, _, ok := singleStructure(q, l, opcode, s, size); !ok || (post == 0 && rm != 0) {
+		return nil, unallocated(word)
+	}
+	if post == 0 {
+		return &LoadStoreSingle{Q: q, L: l, R: r, Opcode: opcode, S: s, Size: size, Rn: rn, Rt: rt}, nil
+	}
+	return &LoadStoreSinglePostIndexed{Q: q, L: l, R: r, Rm: rm, Opcode: opcode, S: s, Size: size, Rn: rn, Rt: rt}, nil
+}
+
+func decodeLoadLiteral(word uint32) (Instruction, error) {
+	opc, v := field(word, 30, 2), field(word, 26, 1)
+	switch {
+	case v == 1 && opc == 0b11:
+		return nil, unallocated(word)
+	case v == 0 && opc == 0b11:
+		// PRFM (literal)
+		return nil, unimplemented(word)
+	}
+	return &LoadLiteral{Opc: opc, V: v, Imm: field(word, 5, 19), Rt: field(word, 0, 5)}, nil
+}
+
+func decodeSIMDPermute(word uint32) (Instruction, error) {
+	q, size, rm, rn, rd := field(word, 30, 1), field(word, 22, 2), field(word, 16, 5), field(word, 5, 5), field(word, 0, 5)
+	if size == 0b11 && q == 0 {
+		return nil, unallocated(word)
+	}
+	switch field(word, 12, 3) {
+	case 0b001:
+		return &Uzp1{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b010:
+		return &Trn1{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b011:
+		return &Zip1{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b101:
+		return &Uzp2{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b110:
+		return &Trn2{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b111:
+		return &Zip2{Q: q, Size: size, Rm: rm, Rn: rn, Rd: rd}, nil
+	}
+	return nil, unallocated(word)
+}
+
+func decodeSIMDExtract(word uint32) (Instruction, error) {
+	q, rm, imm4, rn, rd := field(word, 30, 1), field(word, 16, 5), field(word, 11, 4), field(word, 5, 5), field(word, 0, 5)
+	// The index must be within the first of the two 64-bit vectors if Q is 0.
+	if field(word, 22, 2) != 0b00 || (q == 0 && imm4>>3 == 1) {
+		return nil, unallocated(word)
+	}
+	return &Ext{Q: q, Rm: rm, Imm4: imm4, Rn: rn, Rd: rd}, nil
+}
+
+func decodeSIMDTableLookup(word uint32) (Instruction, error) {
+	q, rm, length, rn, rd := field(word, 30, 1), field(word, 16, 5), field(word, 13, 2), field(word, 5, 5), field(word, 0, 5)
+	switch {
+	case field(word, 22, 2) != 0b00:
+		return nil, unallocated(word)
+	case field(word, 12, 1) == 1:
+		return &Tbx{Q: q, Rm: rm, Len: length, Rn: rn, Rd: rd}, nil
+	}
+	return &Tbl{Q: q, Rm: rm, Len: length, Rn: rn, Rd: rd}, nil
+}
+
+func decodeSIMDCopy(word uint32) (Instruction, error) {
+	q, imm5, imm4, rn, rd := field(word, 30, 1), field(word, 16, 5), field(word, 11, 4), field(word, 5, 5), field(word, 0, 5)
+	size, _ := copyElement(imm5)
+	if size > 0b11 {
+		return nil, unallocated(word)
+	}
+	if field(word, 29, 1) == 1 {
+		if q == 0 {
+			return nil, unallocated(word)
+		}
+		return &InsElement{Imm5: imm5, Imm4: imm4, Rn: rn, Rd: rd}, nil
+	}
+	switch imm4 {
+	case 0b0000:
+		if size == 0b11 && q == 0 {
+			return nil, unallocated(word)
+		}
+		return &DupElement{Q: q, Imm5: imm5, Rn: rn, Rd: rd}, nil
+	case 0b0001:
+		if size == 0b11 && q == 0 {
+			return nil, unallocated(word)
+		}
+		return &DupGeneral{Q: q, Imm5: imm5, Rn: rn, Rd: rd}, nil
+	case 0b0011:
+		if q == 0 {
+			return nil, unallocated(word)
+		}
+		return &InsGeneral{Imm5: imm5, Rn: rn, Rd: rd}, nil
+	case 0b0101:
+		// SMOV extends a byte or halfword to a W or X register, or a word to an X register.
+		if size == 0b11 || (size == 0b10 && q == 0) {
+			return nil, unallocated(word)
+		}
+		return &Smov{Q: q, Imm5: imm5, Rn: rn, Rd: rd}, nil
+	case 0b0111:
+		// UMOV copies at most a word to a W register, and only a doubleword to an X register.
+		if (size == 0b11) != (q == 1) {
+			return nil, unallocated(word)
+		}
+		return &Umov{Q: q, Imm5: imm5, Rn: rn, Rd: rd}, nil
+	}
+	return nil, unallocated(word)
+}
+
+func decodeSIMDShiftByImmediate(word uint32) (Instruction, error) {
+	q, immh, immb, rn, rd := field(word, 30, 1), field(word, 19, 4), field(word, 16, 3), field(word, 5, 5), field(word, 0, 5)
+	u, opcode := field(word, 29, 1), field(word, 11, 5)
+	if immh == 0 {
+		// Advanced SIMD modified immediate: MOVI, MVNI, ORR, BIC and FMOV (vector, immediate).
+		return nil, unimplemented(word)
+	}
+	switch opcode {
+	case 0b10000, 0b10001, 0b10010, 0b10011:
+		// The narrowing shifts have no 64-bit destination elements.
+		if immh>>3 == 1 {
+			return nil, unallocated(word)
+		}
+	case 0b00000, 0b00010, 0b00100, 0b00110, 0b01000, 0b01010:
+		if immh>>3 == 1 && q == 0 {
+			return nil, unallocated(word)
+		}
+	}
+	switch u<<5 | opcode { // U:opcode
+	case 0b000000:
+		return &Sshr{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b100000:
+		return &Ushr{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b000010:
+		return &Ssra{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b100010:
+		return &Usra{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b000100:
+		return &Srshr{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b100100:
+		return &Urshr{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b000110:
+		return &Srsra{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b100110:
+		return &Ursra{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b101000:
+		return &Sri{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b001010:
+		return &Shl{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b101010:
+		return &Sli{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b010000:
+		return &Shrn{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b010001:
+		return &Rshrn{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b010010:
+		return &Sqshrn{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b110010:
+		return &Uqshrn{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b010011:
+		return &Sqrshrn{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b110011:
+		return &Uqrshrn{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b110000:
+		return &Sqshrun{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b110001:
+		return &Sqrshrun{Q: q, Immh: immh, Immb: immb, Rn: rn, Rd: rd}, nil
+	case 0b101100, 0b001110, 0b101110, 0b010100, 0b110100, 0b011100, 0b111100, 0b011111, 0b111111:
+		// SQSHLU, SQSHL and UQSHL (immediate), SSHLL and USHLL, and the fixed-point conversions.
+		return nil, unimplemented(word)
+	}
+	return nil, unallocated(word)
+}
+
+// fpUnallocated reports whether the M and S bits or the ftype field of a scalar floating-point
+// instruction are unallocated.  Half precision needs FEAT_FP16, which javelin implements.
+func fpUnallocated(word uint32) bool {
+	return field(word, 31, 1) != 0 || field(word, 29, 1) != 0 || field(word, 22, 2) == 0b10
+}
+
+func decodeFPDataProcessing1Source(word uint32) (Instruction, error) {
+	ftype, opcode, rn, rd := field(word, 22, 2), field(word, 15, 6), field(word, 5, 5), field(word, 0, 5)
+	if fpUnallocated(word) || opcode&0b100000 != 0 {
+		return nil, unallocated(word)
+	}
+	switch opcode {
+	case 0b000000:
+		return &FmovRegister{Ftype: ftype, Rn: rn, Rd: rd}, nil
+	case 0b000001:
+		return &Fabs{Ftype: ftype, Rn: rn, Rd: rd}, nil
+	case 0b000010:
+		return &Fneg{Ftype: ftype, Rn: rn, Rd: rd}, nil
+	case 0b000011:
+		return &Fsqrt{Ftype: ftype, Rn: rn, Rd: rd}, nil
+	}
+	// FCVT between precisions and the FRINT family.
+	return nil, unimplemented(word)
+}
+
+func decodeFPDataProcessing2Source(word uint32) (Instruction, error) {
+	ftype, rm, rn, rd := field(word, 22, 2), field(word, 16, 5), field(word, 5, 5), field(word, 0, 5)
+	if fpUnallocated(word) {
+		return nil, unallocated(word)
+	}
+	switch field(word, 12, 4) { // opcode
+	case 0b0000:
+		return &Fmul{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b0001:
+		return &Fdiv{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b0010:
+		return &Fadd{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b0011:
+		return &Fsub{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b0100:
+		return &Fmax{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b0101:
+		return &Fmin{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b0110:
+		return &Fmaxnm{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b0111:
+		return &Fminnm{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}, nil
+	case 0b1000:
+		return &Fnmul{Ftype: ftype, Rm: rm, Rn: rn, Rd: rd}, nil
+	}
+	return nil, unallocated(word)
+}
+
+func decodeFPDataProcessing3Source(word uint32) (Instruction, error) {
+	ftype, rm, ra, rn, rd := field(word, 22, 2), field(word, 16, 5), field(word, 10, 5), field(word, 5, 5), field(word, 0, 5)
+	if fpUnallocated(word) {
+		return nil, unallocated(word)
+	}
+	switch field(word, 21, 1)<<1 | field(word, 15, 1) { // o1:o0
+	case 0b00:
+		return &Fmadd{Ftype: ftype, Rm: rm, Ra: ra, Rn: rn, Rd: rd}, nil
+	case 0b01:
+		return &Fmsub{Ftype: ftype, Rm: rm, Ra: ra, Rn: rn, Rd: rd}, nil
+	case 0b10:
+		return &Fnmadd{Ftype: ftype, Rm: rm, Ra: ra, Rn: rn, Rd: rd}, nil
+	}
+	return &Fnmsub{Ftype: ftype, Rm: rm, Ra: ra, Rn: rn, Rd: rd}, nil
+}
+
+func decodeFPCompare(word uint32) (Instruction, error) {
+	ftype, rm, rn, opcode2 := field(word, 22, 2), field(word, 16, 5), field(word, 5, 5), field(word, 0, 5)
+	if fpUnallocated(word) || field(word, 14, 2) != 0b00 || opcode2&0b00111 != 0 {
+		return nil, unallocated(word)
+	}
+	// The forms that compare with zero ignore Rm.
+	switch opcode2 >> 3 {
+	case 0b00:
+		return &Fcmp{Ftype: ftype, Rm: rm, Rn: rn}, nil
+	case 0b01:
+		return &FcmpZero{Ftype: ftype, Rm: rm, Rn: rn}, nil
+	case 0b10:
+		return &Fcmpe{Ftype: ftype, Rm: rm, Rn: rn}, nil
+	}
+	return &FcmpeZero{Ftype: ftype, Rm: rm, Rn: rn}, nil
+}
+
+func decodeFPConditionalSelect(word uint32) (Instruction, error) {
+	ftype, rm, cond, rn, rd := field(word, 22, 2), field(word, 16, 5), field(word, 12, 4), field(word, 5, 5), field(word, 0, 5)
+	if fpUnallocated(word) {
+		return nil, unallocated(word)
+	}
+	return &Fcsel{Ftype: ftype, Rm: rm, Cond: cond, Rn: rn, Rd: rd}, nil
+}
+
+func decodeFPImmediate(word uint32) (Instruction, error) {
+	ftype, imm8, rd := field(word, 22, 2), field(word, 13, 8), field(word, 0, 5)
+	if fpUnallocated(word) || field(word, 5, 5) != 0 { // imm5
+		return nil, unallocated(word)
+	}
+	return &FmovImmediate{Ftype: ftype, Imm8: imm8, Rd: rd}, nil
+}
+
+func decodeFPIntegerConversion(word uint32) (Instruction, error) {
+	sf, ftype, rmode, opcode, rn, rd := field(word, 31, 1), field(word, 22, 2), field(word, 19, 2), field(word, 16, 3), field(word, 5, 5), field(word, 0, 5)
+	if field(word, 29, 1) != 0 { // S
+		return nil, unallocated(word)
+	}
+	if opcode&0b110 != 0b110 || rmode&0b10 != 0 {
+		// FCVT* to integer, SCVTF, UCVTF and FJCVTZS.
+		return nil, unimplemented(word)
+	}
+	// FMOV needs a general register the size of the floating-point register, except that half
+	// precision values go to or from either size, and ftype 0b10 with rmode 0b01 is the upper half
+	// of a vector and an X register.
+	switch {
+	case rmode == 0b00 && ftype == 0b11:
+	case rmode == 0b00 && ftype != 0b10 && sf == ftype:
+	case rmode == 0b01 && ftype == 0b10 && sf == 1:
+	default:
+		return nil, unallocated(word)
+	}
+	if opcode == 0b110 {
+		return &FmovToGeneral{Sf: sf, Ftype: ftype, Rmode: rmode, Rn: rn, Rd: rd}, nil
+	}
+	return &FmovFromGeneral{Sf: sf, Ftype: ftype, Rmode: rmode, Rn: rn, Rd: rd}, nil
+}
@@ -0,0 +1,468 @@
+package opcode
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	for _, tc := range []struct {
+		asm  string
+		word uint32
+		want Instruction
+	}{
+		{"add x2, x3, x5", 0x8b050062, &AddShiftedRegister{Sf: 1, Rm: 5, Rn: 3, Rd: 2}},
+		{"add w1, w2, w3, asr #31", 0x0b837c41, &AddShiftedRegister{Shift: 0b10, Rm: 3, Imm: 31, Rn: 2, Rd: 1}},
+		{"add x3, x4, x5, lsl #63", 0x8b05fc83, &AddShiftedRegister{Sf: 1, Rm: 5, Imm: 63, Rn: 4, Rd: 3}},
+		{"add w2, w3, #5", 0x11001462, &AddImmedite{Imm: 5, Rn: 3, Rd: 2}},
+		{"add sp, x1, #4095, lsl #12", 0x917ffc3f, &AddImmedite{Sf: 1, Sh: 1, Imm: 4095, Rn: 1, Rd: 31}},
+		{"add x0, sp, w1, uxtw #2", 0x8b214be0, &AddExtendedRegister{Sf: 1, Opt: 0b010, Imm: 2, Rm: 1, Rn: 31, Rd: 0}},
+		{"add x1, x2, x3, sxtx", 0x8b23e041, &AddExtendedRegister{Sf: 1, Opt: 0b111, Rm: 3, Rn: 2, Rd: 1}},
+		{"add v0.4s, v1.4s, v2.4s", 0x4ea28420, &AddVector{Q: 1, Size: 0b10, Rm: 2, Rn: 1, Rd: 0}},
+		{"add v3.8b, v4.8b, v31.8b", 0x0e3f8483, &AddVector{Rm: 31, Rn: 4, Rd: 3}},
+		{"adds x0, x1, #5", 0xb1001420, &AddsImmediate{Sf: 1, Imm: 5, Rn: 1, Rd: 0}},
+		{"sub sp, sp, #16", 0xd10043ff, &SubImmediate{Sf: 1, Imm: 16, Rn: 31, Rd: 31}},
+		{"cmn w1, #4, lsl #12", 0x3140103f, &AddsImmediate{Sh: 1, Imm: 4, Rn: 1, Rd: 31}},
+		{"cmp sp, #4", 0xf10013ff, &SubsImmediate{Sf: 1, Imm: 4, Rn: 31, Rd: 31}},
+		{"neg x0, x1, lsl #3", 0xcb010fe0, &SubShiftedRegister{Sf: 1, Rm: 1, Imm: 3, Rn: 31, Rd: 0}},
+		{"cmp w1, w2, lsr #1", 0x6b42043f, &SubsShiftedRegister{Shift: 0b01, Rm: 2, Imm: 1, Rn: 1, Rd: 31}},
+		{"cmn x1, x2", 0xab02003f, &AddsShiftedRegister{Sf: 1, Rm: 2, Rn: 1, Rd: 31}},
+		{"subs x0, x1, w2, sxtw #1", 0xeb22c420, &SubsExtendedRegister{Sf: 1, Opt: 0b110, Imm: 1, Rm: 2, Rn: 1, Rd: 0}},
+		{"sub x0, sp, x1", 0xcb2163e0, &SubExtendedRegister{Sf: 1, Opt: 0b011, Rm: 1, Rn: 31, Rd: 0}},
+		{"cmn wsp, w1", 0x2b2143ff, &AddsExtendedRegister{Opt: 0b010, Rm: 1, Rn: 31, Rd: 31}},
+		{"b #-8", 0x17fffffe, &B{Imm: 0x3fffffe}},
+		{"bl #0x7fffffc", 0x95ffffff, &Bl{Imm: 0x1ffffff}},
+		{"b.hs #-4", 0x54ffffe2, &BCond{Imm: 0x7ffff, Cond: 0b0010}},
+		{"b.nv #4", 0x5400002f, &BCond{Imm: 1, Cond: 0b1111}},
+		{"cbz x0, #8", 0xb4000040, &Cbz{Sf: 1, Imm: 2, Rt: 0}},
+		{"cbnz w3, #-16", 0x35ffff83, &Cbnz{Imm: 0x7fffc, Rt: 3}},
+		{"tbz w0, #3, #8", 0x36180040, &Tbz{B40: 3, Imm: 2, Rt: 0}},
+		{"tbnz x5, #63, #-32", 0xb7ffff05, &Tbnz{B5: 1, B40: 31, Imm: 0x3ff8, Rt: 5}},
+		{"br x3", 0xd61f0060, &Br{Rn: 3}},
+		{"blr x30", 0xd63f03c0, &Blr{Rn: 30}},
+		{"ret", 0xd65f03c0, &Ret{Rn: 30}},
+		{"ldr x0, [sp, #32760]", 0xf97fffe0, &LoadStoreUnsignedImmediate{Size: 0b11, Opc: 0b01, Imm: 4095, Rn: 31, Rt: 0}},
+		{"ldrsh x2, [x3, #2]", 0x79800462, &LoadStoreUnsignedImmediate{Size: 0b01, Opc: 0b10, Imm: 1, Rn: 3, Rt: 2}},
+		{"ldr q0, [x0, #16]", 0x3dc00400, &LoadStoreUnsignedImmediate{V: 1, Opc: 0b11, Imm: 1, Rn: 0, Rt: 0}},
+		{"str x0, [sp, #-16]!", 0xf81f0fe0, &LoadStorePreIndexed{Size: 0b11, Imm: 0x1f0, Rn: 31, Rt: 0}},
+		{"ldr x0, [x1], #-256", 0xf8500420, &LoadStorePostIndexed{Size: 0b11, Opc: 0b01, Imm: 0x100, Rn: 1, Rt: 0}},
+		{"ldursw x0, [x1]", 0xb8800020, &LoadStoreUnscaled{Size: 0b10, Opc: 0b10, Rn: 1, Rt: 0}},
+		{"ldr w0, [x1, w2, sxtw #2]", 0xb862d820, &LoadStoreRegisterOffset{Size: 0b10, Opc: 0b01, Rm: 2, Option: 0b110, S: 1, Rn: 1, Rt: 0}},
+		{"ldrsw x0, #1048572", 0x987fffe0, &LoadLiteral{Opc: 0b10, Imm: 0x3ffff, Rt: 0}},
+		{"ldr q4, #16", 0x9c000084, &LoadLiteral{Opc: 0b10, V: 1, Imm: 4, Rt: 4}},
+		{"stp x29, x30, [sp, #-16]!", 0xa9bf7bfd, &LoadStorePairPreIndexed{Opc: 0b10, Imm: 0x7e, Rt2: 30, Rn: 31, Rt: 29}},
+		{"ldp x29, x30, [sp], #16", 0xa8c17bfd, &LoadStorePairPostIndexed{Opc: 0b10, L: 1, Imm: 2, Rt2: 30, Rn: 31, Rt: 29}},
+		{"stp w3, w4, [x5, #-256]", 0x292010a3, &LoadStorePairOffset{Imm: 0x40, Rt2: 4, Rn: 5, Rt: 3}},
+		{"ldpsw x0, x1, [x2, #8]", 0x69410440, &LoadStorePairOffset{Opc: 0b01, L: 1, Imm: 2, Rt2: 1, Rn: 2, Rt: 0}},
+		{"ldp q0, q1, [x0, #32]", 0xad410400, &LoadStorePairOffset{Opc: 0b10, V: 1, L: 1, Imm: 2, Rt2: 1, Rn: 0, Rt: 0}},
+		{"movz x0, #0x1234, lsl #16", 0xd2a24680, &Movz{Sf: 1, Hw: 1, Imm: 0x1234, Rd: 0}},
+		{"movn w0, #0xffff", 0x129fffe0, &Movn{Imm: 0xffff, Rd: 0}},
+		{"movk x0, #0x1234, lsl #48", 0xf2e24680, &Movk{Sf: 1, Hw: 3, Imm: 0x1234, Rd: 0}},
+		{"orr x0, x1, #0xaaaaaaaaaaaaaaaa", 0xb201f020, &OrrImmediate{Sf: 1, Immr: 1, Imms: 0b111100, Rn: 1, Rd: 0}},
+		{"orr wsp, w1, #1", 0x3200003f, &OrrImmediate{Rn: 1, Rd: 31}},
+		{"and sp, x1, #0xff", 0x92401c3f, &AndImmediate{Sf: 1, N: 1, Imms: 7, Rn: 1, Rd: 31}},
+		{"eor w0, w1, #0x80000000", 0x52010020, &EorImmediate{Immr: 1, Rn: 1, Rd: 0}},
+		{"tst x1, #0xff", 0xf2401c3f, &AndsImmediate{Sf: 1, N: 1, Imms: 7, Rn: 1, Rd: 31}},
+		{"and x0, x1, x2, ror #3", 0x8ac20c20, &AndShiftedRegister{Sf: 1, Shift: 0b11, Rm: 2, Imm: 3, Rn: 1, Rd: 0}},
+		{"bic w0, w1, w2, lsr #0", 0x0a620020, &BicShiftedRegister{Shift: 0b01, Rm: 2, Rn: 1, Rd: 0}},
+		{"mov x0, x2", 0xaa0203e0, &OrrShiftedRegister{Sf: 1, Rm: 2, Rn: 31, Rd: 0}},
+		{"mvn w0, w2, asr #2", 0x2aa20be0, &OrnShiftedRegister{Shift: 0b10, Rm: 2, Imm: 2, Rn: 31, Rd: 0}},
+		{"eon x0, x1, x2", 0xca220020, &EonShiftedRegister{Sf: 1, Rm: 2, Rn: 1, Rd: 0}},
+		{"tst x1, x2, lsl #4", 0xea02103f, &AndsShiftedRegister{Sf: 1, Rm: 2, Imm: 4, Rn: 1, Rd: 31}},
+		{"bics xzr, x1, x2", 0xea22003f, &BicsShiftedRegister{Sf: 1, Rm: 2, Rn: 1, Rd: 31}},
+		{"asr x0, x1, #3", 0x9343fc20, &Sbfm{Sf: 1, N: 1, Immr: 3, Imms: 63, Rn: 1, Rd: 0}},
+		{"sxtw x0, w1", 0x93407c20, &Sbfm{Sf: 1, N: 1, Imms: 31, Rn: 1, Rd: 0}},
+		{"bfi w0, w1, #8, #4", 0x33180c20, &Bfm{Immr: 24, Imms: 3, Rn: 1, Rd: 0}},
+		{"lsl x0, x1, #4", 0xd37cec20, &Ubfm{Sf: 1, N: 1, Immr: 60, Imms: 59, Rn: 1, Rd: 0}},
+		{"uxtb w0, w1", 0x53001c20, &Ubfm{Imms: 7, Rn: 1, Rd: 0}},
+		{"extr x0, x1, x2, #7", 0x93c21c20, &Extr{Sf: 1, N: 1, Rm: 2, Imms: 7, Rn: 1, Rd: 0}},
+		{"ror w0, w1, #5", 0x13811420, &Extr{Rm: 1, Imms: 5, Rn: 1, Rd: 0}},
+		{"madd x0, x1, x2, x3", 0x9b020c20, &Madd{Sf: 1, Rm: 2, Ra: 3, Rn: 1, Rd: 0}},
+		{"mneg w0, w1, w2", 0x1b02fc20, &Msub{Rm: 2, Ra: 31, Rn: 1, Rd: 0}},
+		{"smull x0, w1, w2", 0x9b227c20, &Smaddl{Rm: 2, Ra: 31, Rn: 1, Rd: 0}},
+		{"umsubl x0, w1, w2, x3", 0x9ba28c20, &Umsubl{Rm: 2, Ra: 3, Rn: 1, Rd: 0}},
+		{"smulh x0, x1, x2", 0x9b427c20, &Smulh{Rm: 2, Ra: 31, Rn: 1, Rd: 0}},
+		{"umulh x0, x1, x2 with Ra = 0", 0x9bc20020, &Umulh{Rm: 2, Rn: 1, Rd: 0}},
+		{"udiv w0, w1, w2", 0x1ac20820, &Udiv{Rm: 2, Rn: 1, Rd: 0}},
+		{"sdiv x0, x1, x2", 0x9ac20c20, &Sdiv{Sf: 1, Rm: 2, Rn: 1, Rd: 0}},
+		{"lsl x0, x1, x2", 0x9ac22020, &Lslv{Sf: 1, Rm: 2, Rn: 1, Rd: 0}},
+		{"ror w0, w1, w2", 0x1ac22c20, &Rorv{Rm: 2, Rn: 1, Rd: 0}},
+		{"csel x0, x1, x2, eq", 0x9a820020, &Csel{Sf: 1, Rm: 2, Rn: 1, Rd: 0}},
+		{"cset x0, eq", 0x9a9f17e0, &Csinc{Sf: 1, Rm: 31, Cond: 0b0001, Rn: 31, Rd: 0}},
+		{"cinv w0, w1, le", 0x5a81c020, &Csinv{Rm: 1, Cond: 0b1100, Rn: 1, Rd: 0}},
+		{"csneg w0, w1, w2, lt", 0x5a82b420, &Csneg{Rm: 2, Cond: 0b1011, Rn: 1, Rd: 0}},
+		{"ccmp x1, x2, #4, ne", 0xfa421024, &CcmpRegister{Sf: 1, Rm: 2, Cond: 0b0001, Rn: 1, Nzcv: 4}},
+		{"ccmn w1, #31, #15, al", 0x3a5fe82f, &CcmnImmediate{Imm: 31, Cond: 0b1110, Rn: 1, Nzcv: 15}},
+		{"adc x0, x1, x2", 0x9a020020, &Adc{Sf: 1, Rm: 2, Rn: 1, Rd: 0}},
+		{"adcs w0, w1, w2", 0x3a020020, &Adcs{Rm: 2, Rn: 1, Rd: 0}},
+		{"ngc x0, x1", 0xda0103e0, &Sbc{Sf: 1, Rm: 1, Rn: 31, Rd: 0}},
+		{"sbcs w0, w1, w2", 0x7a020020, &Sbcs{Rm: 2, Rn: 1, Rd: 0}},
+		{"adr x0, #16", 0x10000080, &Adr{Immhi: 4}},
+		{"adr x3, #-1048576", 0x10800003, &Adr{Immhi: 0x40000, Rd: 3}},
+		{"adr x30, #1048575", 0x707ffffe, &Adr{Immlo: 3, Immhi: 0x3ffff, Rd: 30}},
+		{"adrp x0, #16384", 0x90000020, &Adrp{Immhi: 1}},
+		{"adrp xzr, #-4294967296", 0x9080001f, &Adrp{Immhi: 0x40000, Rd: 31}},
+		{"rbit w0, w1", 0x5ac00020, &Rbit{Rn: 1}},
+		{"rev16 x0, x1", 0xdac00420, &Rev16{Sf: 1, Rn: 1}},
+		{"rev w0, w1", 0x5ac00820, &Rev{Rn: 1}},
+		{"rev32 x0, x1", 0xdac00820, &Rev32{Rn: 1}},
+		{"rev x0, x1", 0xdac00c20, &Rev{Sf: 1, Rn: 1}},
+		{"clz x0, x1", 0xdac01020, &Clz{Sf: 1, Rn: 1}},
+		{"cls w0, w1", 0x5ac01420, &Cls{Rn: 1}},
+		{"cnt v0.16b, v1.16b", 0x4e205820, &Cnt{Q: 1, Rn: 1}},
+		{"rbit v0.8b, v1.8b", 0x2e605820, &RbitVector{Rn: 1}},
+		{"rev16 v0.16b, v1.16b", 0x4e201820, &Rev16Vector{Q: 1, Rn: 1}},
+		{"rev32 v0.8h, v1.8h", 0x6e600820, &Rev32Vector{Q: 1, Size: 0b01, Rn: 1}},
+		{"rev64 v0.2s, v1.2s", 0x0ea00820, &Rev64{Size: 0b10, Rn: 1}},
+		{"clz v0.4s, v1.4s", 0x6ea04820, &ClzVector{Q: 1, Size: 0b10, Rn: 1}},
+		{"cls v0.8h, v1.8h", 0x4e604820, &ClsVector{Q: 1, Size: 0b01, Rn: 1}},
+		{"ld1 { v0.16b }, [x0]", 0x4c407000, &LoadStoreMultiple{Q: 1, L: 1, Opcode: 0b0111}},
+		{"ld1 { v31.2d, v0.2d, v1.2d, v2.2d }, [x0]", 0x4c402c1f, &LoadStoreMultiple{Q: 1, L: 1, Opcode: 0b0010, Size: 0b11, Rt: 31}},
+		{"st2 { v0.4h, v1.4h }, [x1]", 0x0c008420, &LoadStoreMultiple{Opcode: 0b1000, Size: 0b01, Rn: 1}},
+		{"ld2 { v0.8h, v1.8h }, [x0], #32", 0x4cdf8400, &LoadStoreMultiplePostIndexed{Q: 1, L: 1, Rm: 31, Opcode: 0b1000, Size: 0b01}},
+		{"ld3 { v0.2s, v1.2s, v2.2s }, [x0], x2", 0x0cc24800, &LoadStoreMultiplePostIndexed{L: 1, Rm: 2, Opcode: 0b0100, Size: 0b10}},
+		{"ld1 { v0.b }[15], [x0]", 0x4d401c00, &LoadStoreSingle{Q: 1, L: 1, S: 1, Size: 0b11}},
+		{"ld4 { v0.d, v1.d, v2.d, v3.d }[1], [x0]", 0x4d60a400, &LoadStoreSingle{Q: 1, L: 1, R: 1, Opcode: 0b101, Size: 0b01}},
+		{"st1 { v0.d }[0], [sp]", 0x0d0087e0, &LoadStoreSingle{Opcode: 0b100, Size: 0b01, Rn: 31}},
+		{"ld1r { v0.4s }, [x0]", 0x4d40c800, &LoadStoreSingle{Q: 1, L: 1, Opcode: 0b110, Size: 0b10}},
+		{"ld2 { v0.s, v1.s }[1], [x0], x3", 0x0de39000, &LoadStoreSinglePostIndexed{L: 1, R: 1, Rm: 3, Opcode: 0b100, S: 1}},
+		{"ld4r { v0.8h, v1.8h, v2.8h, v3.8h }, [x0], #8", 0x4dffe400, &LoadStoreSinglePostIndexed{Q: 1, L: 1, R: 1, Rm: 31, Opcode: 0b111, Size: 0b01}},
+		{"sub v0.4s, v1.4s, v2.4s", 0x6ea28420, &SubVector{Q: 1, Size: 0b10, Rm: 2, Rn: 1}},
+		{"mul v0.8h, v1.8h, v2.8h", 0x4e629c20, &MulVector{Q: 1, Size: 0b01, Rm: 2, Rn: 1}},
+		{"mla v0.2s, v1.2s, v2.2s", 0x0ea29420, &MlaVector{Size: 0b10, Rm: 2, Rn: 1}},
+		{"mls v0.16b, v1.16b, v2.16b", 0x6e229420, &MlsVector{Q: 1, Rm: 2, Rn: 1}},
+		{"abs v0.2d, v1.2d", 0x4ee0b820, &AbsVector{Q: 1, Size: 0b11, Rn: 1}},
+		{"neg v0.4h, v1.4h", 0x2e60b820, &NegVector{Size: 0b01, Rn: 1}},
+		{"sabd v0.8b, v1.8b, v2.8b", 0x0e227420, &Sabd{Rm: 2, Rn: 1}},
+		{"uabd v0.4s, v1.4s, v2.4s", 0x6ea27420, &Uabd{Q: 1, Size: 0b10, Rm: 2, Rn: 1}},
+		{"smax v0.8h, v1.8h, v2.8h", 0x4e626420, &Smax{Q: 1, Size: 0b01, Rm: 2, Rn: 1}},
+		{"umax v0.16b, v1.16b, v2.16b", 0x6e226420, &Umax{Q: 1, Rm: 2, Rn: 1}},
+		{"smin v0.2s, v1.2s, v2.2s", 0x0ea26c20, &Smin{Size: 0b10, Rm: 2, Rn: 1}},
+		{"umin v0.4h, v1.4h, v2.4h", 0x2e626c20, &Umin{Size: 0b01, Rm: 2, Rn: 1}},
+		{"shadd v0.8b, v1.8b, v2.8b", 0x0e220420, &Shadd{Rm: 2, Rn: 1}},
+		{"uhadd v0.4s, v1.4s, v2.4s", 0x6ea20420, &Uhadd{Q: 1, Size: 0b10, Rm: 2, Rn: 1}},
+		{"srhadd v0.8h, v1.8h, v2.8h", 0x4e621420, &Srhadd{Q: 1, Size: 0b01, Rm: 2, Rn: 1}},
+		{"urhadd v0.16b, v1.16b, v2.16b", 0x6e221420, &Urhadd{Q: 1, Rm: 2, Rn: 1}},
+		{"addp v0.2d, v1.2d, v2.2d", 0x4ee2bc20, &AddpVector{Q: 1, Size: 0b11, Rm: 2, Rn: 1}},
+		{"sqadd v0.16b, v1.16b, v2.16b", 0x4e220c20, &Sqadd{Q: 1, Rm: 2, Rn: 1}},
+		{"uqadd v0.2d, v1.2d, v2.2d", 0x6ee20c20, &Uqadd{Q: 1, Size: 0b11, Rm: 2, Rn: 1}},
+		{"sqsub v0.4h, v1.4h, v2.4h", 0x0e622c20, &Sqsub{Size: 0b01, Rm: 2, Rn: 1}},
+		{"uqsub v0.4s, v1.4s, v2.4s", 0x6ea22c20, &Uqsub{Q: 1, Size: 0b10, Rm: 2, Rn: 1}},
+		{"sqdmulh v0.8h, v1.8h, v2.8h", 0x4e62b420, &Sqdmulh{Q: 1, Size: 0b01, Rm: 2, Rn: 1}},
+		{"sqrdmulh v0.2s, v1.2s, v2.2s", 0x2ea2b420, &Sqrdmulh{Size: 0b10, Rm: 2, Rn: 1}},
+		{"sqxtn v0.8b, v1.8h", 0x0e214820, &Sqxtn{Rn: 1}},
+		{"sqxtn2 v0.8h, v1.4s", 0x4e614820, &Sqxtn{Q: 1, Size: 0b01, Rn: 1}},
+		{"uqxtn v0.2s, v1.2d", 0x2ea14820, &Uqxtn{Size: 0b10, Rn: 1}},
+		{"saddl v0.8h, v1.8b, v2.8b", 0x0e220020, &Saddl{Rm: 2, Rn: 1}},
+		{"uaddl2 v0.4s, v1.8h, v2.8h", 0x6e620020, &Uaddl{Q: 1, Size: 0b01, Rm: 2, Rn: 1}},
+		{"saddw v0.2d, v1.2d, v2.2s", 0x0ea21020, &Saddw{Size: 0b10, Rm: 2, Rn: 1}},
+		{"uaddw2 v0.8h, v1.8h, v2.16b", 0x6e221020, &Uaddw{Q: 1, Rm: 2, Rn: 1}},
+		{"ssubl v0.4s, v1.4h, v2.4h", 0x0e622020, &Ssubl{Size: 0b01, Rm: 2, Rn: 1}},
+		{"usubl2 v0.2d, v1.4s, v2.4s", 0x6ea22020, &Usubl{Q: 1, Size: 0b10, Rm: 2, Rn: 1}},
+		{"ssubw2 v0.4s, v1.4s, v2.8h", 0x4e623020, &Ssubw{Q: 1, Size: 0b01, Rm: 2, Rn: 1}},
+		{"usubw v0.8h, v1.8h, v2.8b", 0x2e223020, &Usubw{Rm: 2, Rn: 1}},
+		{"addhn v0.8b, v1.8h, v2.8h", 0x0e224020, &Addhn{Rm: 2, Rn: 1}},
+		{"raddhn2 v0.8h, v1.4s, v2.4s", 0x6e624020, &Raddhn{Q: 1, Size: 0b01, Rm: 2, Rn: 1}},
+		{"subhn v0.2s, v1.2d, v2.2d", 0x0ea26020, &Subhn{Size: 0b10, Rm: 2, Rn: 1}},
+		{"rsubhn2 v0.16b, v1.8h, v2.8h", 0x6e226020, &Rsubhn{Q: 1, Rm: 2, Rn: 1}},
+		{"sabal v0.8h, v1.8b, v2.8b", 0x0e225020, &Sabal{Rm: 2, Rn: 1}},
+		{"uabal2 v0.4s, v1.8h, v2.8h", 0x6e625020, &Uabal{Q: 1, Size: 0b01, Rm: 2, Rn: 1}},
+		{"sabdl v0.2d, v1.2s, v2.2s", 0x0ea27020, &Sabdl{Size: 0b10, Rm: 2, Rn: 1}},
+		{"uabdl2 v0.8h, v1.16b, v2.16b", 0x6e227020, &Uabdl{Q: 1, Rm: 2, Rn: 1}},
+		{"smlal v0.4s, v1.4h, v2.4h", 0x0e628020, &Smlal{Size: 0b01, Rm: 2, Rn: 1}},
+		{"umlal2 v0.2d, v1.4s, v2.4s", 0x6ea28020, &Umlal{Q: 1, Size: 0b10, Rm: 2, Rn: 1}},
+		{"smlsl2 v0.8h, v1.16b, v2.16b", 0x4e22a020, &Smlsl{Q: 1, Rm: 2, Rn: 1}},
+		{"umlsl v0.4s, v1.4h, v2.4h", 0x2e62a020, &Umlsl{Size: 0b01, Rm: 2, Rn: 1}},
+		{"smull v0.8h, v1.8b, v2.8b", 0x0e22c020, &SmullVector{Rm: 2, Rn: 1}},
+		{"umull2 v0.4s, v1.8h, v2.8h", 0x6e62c020, &UmullVector{Q: 1, Size: 0b01, Rm: 2, Rn: 1}},
+		{"xtn v0.8b, v1.8h", 0x0e212820, &Xtn{Rn: 1}},
+		{"xtn2 v0.4s, v1.2d", 0x4ea12820, &Xtn{Q: 1, Size: 0b10, Rn: 1}},
+		{"sqxtun v0.4h, v1.4s", 0x2e612820, &Sqxtun{Size: 0b01, Rn: 1}},
+		{"sqxtun2 v0.16b, v1.8h", 0x6e212820, &Sqxtun{Q: 1, Rn: 1}},
+		{"shll v0.8h, v1.8b, #8", 0x2e213820, &Shll{Rn: 1}},
+		{"shll2 v0.2d, v1.4s, #32", 0x6ea13820, &Shll{Q: 1, Size: 0b10, Rn: 1}},
+		{"saddlp v0.4h, v1.8b", 0x0e202820, &Saddlp{Rn: 1}},
+		{"uaddlp v0.2d, v1.4s", 0x6ea02820, &Uaddlp{Q: 1, Size: 0b10, Rn: 1}},
+		{"sadalp v0.1d, v1.2s", 0x0ea06820, &Sadalp{Size: 0b10, Rn: 1}},
+		{"uadalp v0.8h, v1.16b", 0x6e206820, &Uadalp{Q: 1, Rn: 1}},
+		{"addv b0, v1.16b", 0x4e31b820, &Addv{Q: 1, Rn: 1}},
+		{"addv s0, v1.4s", 0x4eb1b820, &Addv{Q: 1, Size: 0b10, Rn: 1}},
+		{"saddlv h0, v1.8b", 0x0e303820, &Saddlv{Rn: 1}},
+		{"uaddlv d0, v1.4s", 0x6eb03820, &Uaddlv{Q: 1, Size: 0b10, Rn: 1}},
+		{"smaxv b0, v1.8b", 0x0e30a820, &Smaxv{Rn: 1}},
+		{"umaxv h0, v1.8h", 0x6e70a820, &Umaxv{Q: 1, Size: 0b01, Rn: 1}},
+		{"sminv s0, v1.4s", 0x4eb1a820, &Sminv{Q: 1, Size: 0b10, Rn: 1}},
+		{"uminv b0, v1.16b", 0x6e31a820, &Uminv{Q: 1, Rn: 1}},
+		{"fmaxnmv s0, v1.4s", 0x6e30c820, &Fmaxnmv{Q: 1, Rn: 1}},
+		{"fminnmv s0, v1.4s", 0x6eb0c820, &Fminnmv{Q: 1, Rn: 1}},
+		{"zip1 v0.16b, v1.16b, v2.16b", 0x4e023820, &Zip1{Q: 1, Rm: 2, Rn: 1}},
+		{"zip2 v0.8h, v1.8h, v2.8h", 0x4e427820, &Zip2{Q: 1, Size: 0b01, Rm: 2, Rn: 1}},
+		{"uzp1 v0.8b, v1.8b, v2.8b", 0x0e021820, &Uzp1{Rm: 2, Rn: 1}},
+		{"uzp2 v0.4s, v1.4s, v2.4s", 0x4e825820, &Uzp2{Q: 1, Size: 0b10, Rm: 2, Rn: 1}},
+		{"trn1 v0.2d, v1.2d, v2.2d", 0x4ec22820, &Trn1{Q: 1, Size: 0b11, Rm: 2, Rn: 1}},
+		{"trn2 v0.2s, v1.2s, v2.2s", 0x0e826820, &Trn2{Size: 0b10, Rm: 2, Rn: 1}},
+		{"ext v0.16b, v1.16b, v2.16b, #3", 0x6e021820, &Ext{Q: 1, Rm: 2, Imm4: 3, Rn: 1}},
+		{"ext v0.8b, v1.8b, v2.8b, #7", 0x2e023820, &Ext{Rm: 2, Imm4: 7, Rn: 1}},
+		{"tbl v0.16b, { v1.16b, v2.16b }, v3.16b", 0x4e032020, &Tbl{Q: 1, Rm: 3, Len: 1, Rn: 1}},
+		{"tbl v0.8b, { v1.16b, v2.16b, v3.16b }, v4.8b", 0x0e044020, &Tbl{Rm: 4, Len: 2, Rn: 1}},
+		{"tbx v0.8b, { v31.16b, v0.16b, v1.16b, v2.16b }, v3.8b", 0x0e0373e0, &Tbx{Rm: 3, Len: 3, Rn: 31}},
+		{"dup v0.4s, v1.s[2]", 0x4e140420, &DupElement{Q: 1, Imm5: 0b10100, Rn: 1}},
+		{"dup v0.16b, v1.b[15]", 0x4e1f0420, &DupElement{Q: 1, Imm5: 0b11111, Rn: 1}},
+		{"dup v0.2d, x1", 0x4e080c20, &DupGeneral{Q: 1, Imm5: 0b01000, Rn: 1}},
+		{"dup v0.2s, wzr", 0x0e040fe0, &DupGeneral{Imm5: 0b00100, Rn: 31}},
+		{"mov v0.s[1], v1.s[3]", 0x6e0c6420, &InsElement{Imm5: 0b01100, Imm4: 0b1100, Rn: 1}},
+		{"mov v31.b[15], v0.b[0]", 0x6e1f041f, &InsElement{Imm5: 0b11111, Rd: 31}},
+		{"mov v0.d[1], x1", 0x4e181c20, &InsGeneral{Imm5: 0b11000, Rn: 1}},
+		{"mov v0.h[7], w2", 0x4e1e1c40, &InsGeneral{Imm5: 0b11110, Rn: 2}},
+		{"umov w0, v1.b[3]", 0x0e073c20, &Umov{Imm5: 0b00111, Rn: 1}},
+		{"mov w0, v1.s[1]", 0x0e0c3c20, &Umov{Imm5: 0b01100, Rn: 1}},
+		{"mov x0, v1.d[1]", 0x4e183c20, &Umov{Q: 1, Imm5: 0b11000, Rn: 1}},
+		{"smov x0, v1.s[1]", 0x4e0c2c20, &Smov{Q: 1, Imm5: 0b01100, Rn: 1}},
+		{"smov w0, v1.h[7]", 0x0e1e2c20, &Smov{Imm5: 0b11110, Rn: 1}},
+		{"cmeq v0.4s, v1.4s, v2.4s", 0x6ea28c20, &CmeqRegister{Q: 1, Size: 0b10, Rm: 2, Rn: 1}},
+		{"cmgt v0.8b, v1.8b, v2.8b", 0x0e223420, &CmgtRegister{Rm: 2, Rn: 1}},
+		{"cmge v0.2d, v1.2d, v2.2d", 0x4ee23c20, &CmgeRegister{Q: 1, Size: 0b11, Rm: 2, Rn: 1}},
+		{"cmhi v0.8h, v1.8h, v2.8h", 0x6e623420, &Cmhi{Q: 1, Size: 0b01, Rm: 2, Rn: 1}},
+		{"cmhs v0.4h, v1.4h, v2.4h", 0x2e623c20, &Cmhs{Size: 0b01, Rm: 2, Rn: 1}},
+		{"cmtst v0.2s, v1.2s, v2.2s", 0x0ea28c20, &Cmtst{Size: 0b10, Rm: 2, Rn: 1}},
+		{"cmeq v0.2d, v1.2d, #0", 0x4ee09820, &CmeqZero{Q: 1, Size: 0b11, Rn: 1}},
+		{"cmgt v0.4h, v1.4h, #0", 0x0e608820, &CmgtZero{Size: 0b01, Rn: 1}},
+		{"cmge v0.16b, v1.16b, #0", 0x6e208820, &CmgeZero{Q: 1, Rn: 1}},
+		{"cmle v0.4s, v1.4s, #0", 0x6ea09820, &CmleZero{Q: 1, Size: 0b10, Rn: 1}},
+		{"cmlt v0.8b, v1.8b, #0", 0x0e20a820, &CmltZero{Rn: 1}},
+		{"and v0.8b, v1.8b, v2.8b", 0x0e221c20, &AndVector{Rm: 2, Rn: 1}},
+		{"bic v0.16b, v1.16b, v2.16b", 0x4e621c20, &BicVector{Q: 1, Rm: 2, Rn: 1}},
+		{"orr v0.8b, v1.8b, v2.8b", 0x0ea21c20, &OrrVector{Rm: 2, Rn: 1}},
+		{"mov v0.8b, v1.8b", 0x0ea11c20, &OrrVector{Rm: 1, Rn: 1}},
+		{"orn v0.16b, v1.16b, v2.16b", 0x4ee21c20, &OrnVector{Q: 1, Rm: 2, Rn: 1}},
+		{"eor v0.8b, v1.8b, v2.8b", 0x2e221c20, &EorVector{Rm: 2, Rn: 1}},
+		{"bsl v0.16b, v1.16b, v2.16b", 0x6e621c20, &Bsl{Q: 1, Rm: 2, Rn: 1}},
+		{"bit v0.8b, v1.8b, v2.8b", 0x2ea21c20, &Bit{Rm: 2, Rn: 1}},
+		{"bif v0.16b, v1.16b, v2.16b", 0x6ee21c20, &Bif{Q: 1, Rm: 2, Rn: 1}},
+		{"mvn v0.16b, v1.16b", 0x6e205820, &Not{Q: 1, Rn: 1}},
+		{"sshr v0.16b, v1.16b, #3", 0x4f0d0420, &Sshr{Q: 1, Immh: 0b0001, Immb: 0b101, Rn: 1}},
+		{"ushr v0.4s, v1.4s, #32", 0x6f200420, &Ushr{Q: 1, Immh: 0b0100, Rn: 1}},
+		{"sshr v0.2d, v1.2d, #64", 0x4f400420, &Sshr{Q: 1, Immh: 0b1000, Rn: 1}},
+		{"srshr v0.4h, v1.4h, #2", 0x0f1e2420, &Srshr{Immh: 0b0011, Immb: 0b110, Rn: 1}},
+		{"urshr v0.2d, v1.2d, #1", 0x6f7f2420, &Urshr{Q: 1, Immh: 0b1111, Immb: 0b111, Rn: 1}},
+		{"ssra v0.4s, v1.4s, #1", 0x4f3f1420, &Ssra{Q: 1, Immh: 0b0111, Immb: 0b111, Rn: 1}},
+		{"usra v0.8b, v1.8b, #8", 0x2f081420, &Usra{Immh: 0b0001, Rn: 1}},
+		{"srsra v0.8h, v1.8h, #5", 0x4f1b3420, &Srsra{Q: 1, Immh: 0b0011, Immb: 0b011, Rn: 1}},
+		{"ursra v0.2s, v1.2s, #7", 0x2f393420, &Ursra{Immh: 0b0111, Immb: 0b001, Rn: 1}},
+		{"shl v0.4h, v1.4h, #15", 0x0f1f5420, &Shl{Immh: 0b0011, Immb: 0b111, Rn: 1}},
+		{"sli v0.8b, v1.8b, #4", 0x2f0c5420, &Sli{Immh: 0b0001, Immb: 0b100, Rn: 1}},
+		{"sri v0.2d, v1.2d, #64", 0x6f404420, &Sri{Q: 1, Immh: 0b1000, Rn: 1}},
+		{"shrn v0.8b, v1.8h, #4", 0x0f0c8420, &Shrn{Immh: 0b0001, Immb: 0b100, Rn: 1}},
+		{"rshrn2 v0.16b, v1.8h, #4", 0x4f0c8c20, &Rshrn{Q: 1, Immh: 0b0001, Immb: 0b100, Rn: 1}},
+		{"sqshrn v0.4h, v1.4s, #8", 0x0f189420, &Sqshrn{Immh: 0b0011, Rn: 1}},
+		{"uqshrn v0.8b, v1.8h, #1", 0x2f0f9420, &Uqshrn{Immh: 0b0001, Immb: 0b111, Rn: 1}},
+		{"sqrshrn2 v0.4s, v1.2d, #32", 0x4f209c20, &Sqrshrn{Q: 1, Immh: 0b0100, Rn: 1}},
+		{"uqrshrn v0.2s, v1.2d, #3", 0x2f3d9c20, &Uqrshrn{Immh: 0b0111, Immb: 0b101, Rn: 1}},
+		{"sqshrun v0.8b, v1.8h, #3", 0x2f0d8420, &Sqshrun{Immh: 0b0001, Immb: 0b101, Rn: 1}},
+		{"sqrshrun v0.2s, v1.2d, #1", 0x2f3f8c20, &Sqrshrun{Immh: 0b0111, Immb: 0b111, Rn: 1}},
+		{"sshl v0.4s, v1.4s, v2.4s", 0x4ea24420, &Sshl{Q: 1, Size: 0b10, Rm: 2, Rn: 1}},
+		{"ushl v0.2d, v1.2d, v2.2d", 0x6ee24420, &Ushl{Q: 1, Size: 0b11, Rm: 2, Rn: 1}},
+		{"srshl v0.8b, v1.8b, v2.8b", 0x0e225420, &Srshl{Rm: 2, Rn: 1}},
+		{"urshl v0.2d, v1.2d, v2.2d", 0x6ee25420, &Urshl{Q: 1, Size: 0b11, Rm: 2, Rn: 1}},
+		{"sqshl v0.8h, v1.8h, v2.8h", 0x4e624c20, &SqshlRegister{Q: 1, Size: 0b01, Rm: 2, Rn: 1}},
+		{"uqshl v0.16b, v1.16b, v2.16b", 0x6e224c20, &UqshlRegister{Q: 1, Rm: 2, Rn: 1}},
+		{"sqrshl v0.2d, v1.2d, v2.2d", 0x4ee25c20, &Sqrshl{Q: 1, Size: 0b11, Rm: 2, Rn: 1}},
+		{"uqrshl v0.4h, v1.4h, v2.4h", 0x2e625c20, &Uqrshl{Size: 0b01, Rm: 2, Rn: 1}},
+		{"fmov s0, s1", 0x1e204020, &FmovRegister{Rn: 1}},
+		{"fabs d0, d1", 0x1e60c020, &Fabs{Ftype: 0b01, Rn: 1}},
+		{"fneg h0, h1", 0x1ee14020, &Fneg{Ftype: 0b11, Rn: 1}},
+		{"fsqrt s0, s1", 0x1e21c020, &Fsqrt{Rn: 1}},
+		{"fmul d0, d1, d2", 0x1e620820, &Fmul{Ftype: 0b01, Rm: 2, Rn: 1}},
+		{"fdiv s0, s1, s2", 0x1e221820, &Fdiv{Rm: 2, Rn: 1}},
+		{"fadd h0, h1, h2", 0x1ee22820, &Fadd{Ftype: 0b11, Rm: 2, Rn: 1}},
+		{"fsub d0, d1, d2", 0x1e623820, &Fsub{Ftype: 0b01, Rm: 2, Rn: 1}},
+		{"fmax s0, s1, s2", 0x1e224820, &Fmax{Rm: 2, Rn: 1}},
+		{"fmin d0, d1, d2", 0x1e625820, &Fmin{Ftype: 0b01, Rm: 2, Rn: 1}},
+		{"fmaxnm h0, h1, h2", 0x1ee26820, &Fmaxnm{Ftype: 0b11, Rm: 2, Rn: 1}},
+		{"fminnm s0, s1, s2", 0x1e227820, &Fminnm{Rm: 2, Rn: 1}},
+		{"fnmul d0, d1, d2", 0x1e628820, &Fnmul{Ftype: 0b01, Rm: 2, Rn: 1}},
+		{"fmadd s0, s1, s2, s3", 0x1f020c20, &Fmadd{Rm: 2, Ra: 3, Rn: 1}},
+		{"fmsub d0, d1, d2, d3", 0x1f428c20, &Fmsub{Ftype: 0b01, Rm: 2, Ra: 3, Rn: 1}},
+		{"fnmadd h0, h1, h2, h3", 0x1fe20c20, &Fnmadd{Ftype: 0b11, Rm: 2, Ra: 3, Rn: 1}},
+		{"fnmsub s0, s1, s2, s3", 0x1f228c20, &Fnmsub{Rm: 2, Ra: 3, Rn: 1}},
+		{"fcmp d0, d1", 0x1e612000, &Fcmp{Ftype: 0b01, Rm: 1}},
+		{"fcmp h0, #0.0", 0x1ee02008, &FcmpZero{Ftype: 0b11}},
+		{"fcmp s0, #0.0", 0x1e212008, &FcmpZero{Rm: 1}},
+		{"fcmpe s0, s1", 0x1e212010, &Fcmpe{Rm: 1}},
+		{"fcmpe d0, #0.0", 0x1e602018, &FcmpeZero{Ftype: 0b01}},
+		{"fcsel h0, h1, h2, lt", 0x1ee2bc20, &Fcsel{Ftype: 0b11, Rm: 2, Cond: 0b1011, Rn: 1}},
+		{"fmov d0, #-0.125", 0x1e781000, &FmovImmediate{Ftype: 0b01, Imm8: 0xc0}},
+		{"fmov s0, w1", 0x1e270020, &FmovFromGeneral{Rn: 1}},
+		{"fmov x0, d1", 0x9e660020, &FmovToGeneral{Sf: 1, Ftype: 0b01, Rn: 1}},
+		{"fmov w0, h1", 0x1ee60020, &FmovToGeneral{Ftype: 0b11, Rn: 1}},
+		{"fmov h0, x1", 0x9ee70020, &FmovFromGeneral{Sf: 1, Ftype: 0b11, Rn: 1}},
+		{"fmov x0, v1.d[1]", 0x9eae0020, &FmovToGeneral{Sf: 1, Ftype: 0b10, Rmode: 0b01, Rn: 1}},
+		{"fmov v0.d[1], x1", 0x9eaf0020, &FmovFromGeneral{Sf: 1, Ftype: 0b10, Rmode: 0b01, Rn: 1}},
+		{"brk #0x3e8", 0xd4207d00, &Brk{Imm: 0x3e8}},
+		{"hlt #0xffff", 0xd45fffe0, &Hlt{Imm: 0xffff}},
+	} {
+		got, err := Decode(tc.word)
+		if err != nil {
+			t.Errorf("Decode(0x%08x) [%s] failed: %v", tc.word, tc.asm, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Decode(0x%08x) [%s] = %#v, want %#v", tc.word, tc.asm, got, tc.want)
+		}
+		if enc := got.Encode(); enc != tc.word {
+			t.Errorf("Decode(0x%08x) [%s] re-encodes as 0x%08x", tc.word, tc.asm, enc)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		word uint32
+		want error
+	}{
+		{"udf #0", 0x00000000, ErrUnallocated},
+		{"op0 = 0b0011", 0x06000000, ErrUnallocated},
+		{"add shifted register with shift = 0b11", 0x8bc50062, ErrUnallocated},
+		{"32-bit add shifted register with imm6 >= 32", 0x0b058062, ErrUnallocated},
+		{"add extended register with imm3 > 4", 0x8b2157e0, ErrUnallocated},
+		{"add v0.1d, v1.1d, v2.1d", 0x0ee28420, ErrUnallocated},
+		{"dmb ish", 0xd5033bbf, ErrUnimplemented},
+		{"prfm pldl1keep, [x1]", 0xf9800020, ErrUnimplemented},
+		{"prfm pldl1keep, #8", 0xd8000040, ErrUnimplemented},
+		{"ldrsw with writeback and 64-bit size", 0xf8808c20, ErrUnallocated},
+		{"ldr with register offset and option = 0b000", 0xf8620820, ErrUnallocated},
+		{"ldr with V = 1, opc = 0b11 and size = 0b01", 0x7dc00400, ErrUnallocated},
+		{"ldr literal with V = 1 and opc = 0b11", 0xdc000040, ErrUnallocated},
+		{"ldp with opc = 0b11", 0xe9400440, ErrUnallocated},
+		{"ldp with V = 1 and opc = 0b11", 0xed400440, ErrUnallocated},
+		{"stgp x0, x1, [x2]", 0x69000440, ErrUnimplemented},
+		{"ldnp x0, x1, [x2]", 0xa8400440, ErrUnimplemented},
+		{"movz w0, #0, lsl #32", 0x52c00000, ErrUnallocated},
+		{"move wide with opc = 0b01", 0x92800000 | 0b01<<29, ErrUnallocated},
+		{"orr w0, w1 with N = 1", 0x32400020, ErrUnallocated},
+		{"orr x0, x1 with an all ones element", 0xb2400020 | 0b111111<<10, ErrUnallocated},
+		{"and w0, w1, w2, lsl #32", 0x0a028020, ErrUnallocated},
+		{"sbfm x0, x1 with N = 0", 0x9303fc20, ErrUnallocated},
+		{"bitfield with opc = 0b11", 0x7303fc20, ErrUnallocated},
+		{"ubfm w0, w1 with immr >= 32", 0x53207c20, ErrUnallocated},
+		{"extr with o0 = 1", 0x93e21c20, ErrUnallocated},
+		{"extr w0, w1, w2 with imms >= 32", 0x13828020, ErrUnallocated},
+		{"extr w0, w1, w2 with N = 1", 0x13c21c20, ErrUnallocated},
+		{"smulh with o0 = 1", 0x9b42fc20, ErrUnallocated},
+		{"smull w0, w1, w2 with sf = 0", 0x1b227c20, ErrUnallocated},
+		{"madd with op54 = 0b01", 0x3b027c20, ErrUnallocated},
+		{"2 source with opcode = 0b000000 and sf = 0", 0x1ac20020, ErrUnallocated},
+		{"udiv with S = 1", 0x3ac20820, ErrUnallocated},
+		{"crc32x w0, w1, x2", 0x9ac24c20, ErrUnimplemented},
+		{"crc32x with sf = 0", 0x1ac24c20, ErrUnallocated},
+		{"pacga x0, x1, x2", 0x9ac23020, ErrUnimplemented},
+		{"csinc with S = 1", 0x3a821420, ErrUnallocated},
+		{"conditional select with op2 = 0b10", 0x9a820820, ErrUnallocated},
+		{"ccmp with o2 = 1", 0xfa421424, ErrUnallocated},
+		{"ccmp with o3 = 1", 0xfa421034, ErrUnallocated},
+		{"ccmp with S = 0", 0xda421024, ErrUnallocated},
+		{"rmif x0, #1, #2 (FEAT_FlagM)", 0xba008402, ErrUnimplemented},
+		{"rev with sf = 0 and opc = 0b11", 0x5ac00c20, ErrUnallocated},
+		{"rbit with S = 1", 0x7ac00020, ErrUnallocated},
+		{"data processing (1 source) opcode 0b010000", 0x5ac04020, ErrUnallocated},
+		{"ctz w0, w1 (FEAT_CSSC)", 0x5ac01820, ErrUnimplemented},
+		{"pacia x0, x1 (FEAT_PAuth)", 0xdac10020, ErrUnimplemented},
+		{"pacia with sf = 0", 0x5ac10020, ErrUnallocated},
+		{"cnt with size = 0b01", 0x0e605820, ErrUnallocated},
+		{"rev16 (vector) with size = 0b01", 0x0e601820, ErrUnallocated},
+		{"rev32 (vector) with size = 0b11", 0x6ee00820, ErrUnallocated},
+		{"rev64 with size = 0b11", 0x0ee00820, ErrUnallocated},
+		{"clz (vector) with size = 0b11", 0x2ee04820, ErrUnallocated},
+		{"rbit (vector) with size = 0b10", 0x2ea05820, ErrUnallocated},
+		{"fsqrt v0.4s, v1.4s", 0x6ea1f820, ErrUnimplemented},
+		{"ld3 with .1d", 0x0c404c00, ErrUnallocated},
+		{"ld2 with .1d", 0x0c408c00, ErrUnallocated},
+		{"load/store multiple structures opcode 0b0001", 0x0c401000, ErrUnallocated},
+		{"ld4 without post-index and bit 21 set", 0x4c601000, ErrUnallocated},
+		{"ld1 without post-index and Rm = 2", 0x4c427000, ErrUnallocated},
+		{"ld1 post-indexed with bit 21 set", 0x4ce27000, ErrUnallocated},
+		{"st1r", 0x0d00c000, ErrUnallocated},
+		{"ld1r with S = 1", 0x0d40d000, ErrUnallocated},
+		{"ld1 (single, h) with size = 0b01", 0x0d404400, ErrUnallocated},
+		{"ld1 (single, d) with S = 1", 0x0d409400, ErrUnallocated},
+		{"ld1 (single) with opcode 0b100 and size = 0b11", 0x4d408c00, ErrUnallocated},
+		{"ld1 (single) without post-index and Rm = 1", 0x0d410000, ErrUnallocated},
+		{"sub v0.1d, v1.1d, v2.1d", 0x2ee28420, ErrUnallocated},
+		{"mul v0.2d, v1.2d, v2.2d", 0x4ee29c20, ErrUnallocated},
+		{"three same with U = 1 and opcode = 0b10111", 0x2e22bc20, ErrUnallocated},
+		{"abs v0.1d, v1.1d", 0x0ee0b820, ErrUnallocated},
+		{"pmul v0.8b, v1.8b, v2.8b", 0x2e229c20, ErrUnimplemented},
+		{"sqshl v0.4s, v1.4s, #3", 0x4f237420, ErrUnimplemented},
+		{"movi v0.16b, #1", 0x4f00e420, ErrUnimplemented},
+		{"sshll v0.8h, v1.8b, #1", 0x0f09a420, ErrUnimplemented},
+		{"sqdmull v0.4s, v1.4h, v2.4h", 0x0e62d020, ErrUnimplemented},
+		{"pmull v0.8h, v1.8b, v2.8b", 0x0e20e020, ErrUnimplemented},
+		{"sqdmulh v0.8b, v1.8b, v2.8b", 0x0e22b420, ErrUnallocated},
+		{"sqadd v0.1d, v1.1d, v2.1d", 0x0ee20c20, ErrUnallocated},
+		{"sqxtn with size = 0b11", 0x0ee14820, ErrUnallocated},
+		{"sqdmlal with size = 0b00", 0x0e209020, ErrUnallocated},
+		{"three different with U = 1 and opcode = 0b1111", 0x2e20f020, ErrUnallocated},
+		{"saddl with size = 0b11", 0x0ee20020, ErrUnallocated},
+		{"xtn with size = 0b11", 0x0ee12820, ErrUnallocated},
+		{"addv s0, v1.2s", 0x0eb1b820, ErrUnallocated},
+		{"addv d0, v1.2d", 0x4ef1b820, ErrUnallocated},
+		{"across lanes with U = 1 and opcode = 0b11011", 0x2e31b820, ErrUnallocated},
+		{"fminnmv s0, v1.2s", 0x2eb0c820, ErrUnallocated},
+		{"fmaxnmv d0, v1.2d", 0x6e70c820, ErrUnallocated},
+		{"fmaxv s0, v1.4s", 0x6e30f820, ErrUnimplemented},
+		{"fmaxnmv h0, v1.4h (FEAT_FP16)", 0x0e30c820, ErrUnimplemented},
+		{"zip with opcode = 0b000", 0x0e020820, ErrUnallocated},
+		{"trn1 v0.1d, v1.1d, v2.1d", 0x0ec22820, ErrUnallocated},
+		{"ext v0.8b, v1.8b, v2.8b, #9", 0x2e024820, ErrUnallocated},
+		{"tbl with op2 = 0b01", 0x0e400020, ErrUnallocated},
+		{"dup with imm5 = 0b10000", 0x4e100420, ErrUnallocated},
+		{"dup v0.1d, x1", 0x0e080c20, ErrUnallocated},
+		{"ins (element) with Q = 0", 0x2e0c0420, ErrUnallocated},
+		{"ins (general) with Q = 0", 0x0e081c20, ErrUnallocated},
+		{"copy with imm4 = 0b0100", 0x4e042420, ErrUnallocated},
+		{"smov w0, v1.s[0]", 0x0e042c20, ErrUnallocated},
+		{"smov x0, v1.d[0]", 0x4e082c20, ErrUnallocated},
+		{"umov w0, v1.d[0]", 0x0e083c20, ErrUnallocated},
+		{"umov x0, v1.s[0]", 0x4e043c20, ErrUnallocated},
+		{"cmtst v0.1d, v1.1d, v2.1d", 0x0ee28c20, ErrUnallocated},
+		{"cmeq v0.1d, v1.1d, #0", 0x0ee09820, ErrUnallocated},
+		{"cmlt v0.1d, v1.1d, #0", 0x0ee0a820, ErrUnallocated},
+		{"two-misc with U = 1 and opcode = 0b01010", 0x6e20a820, ErrUnallocated},
+		{"sshr v0.1d, v1.1d, #63", 0x0f410420, ErrUnallocated},
+		{"shrn with immh = 0b1000", 0x0f418420, ErrUnallocated},
+		{"shift by immediate with U = 0 and opcode = 0b01000", 0x0f084420, ErrUnallocated},
+		{"shift by immediate with opcode = 0b10101", 0x0f08ac20, ErrUnallocated},
+		{"sshl v0.1d, v1.1d, v2.1d", 0x0ee24420, ErrUnallocated},
+		{"ushl d0, d1, d2", 0x7ee24420, ErrUnimplemented},
+		{"fadd with ftype = 0b10", 0x1ea22820, ErrUnallocated},
+		{"fadd with M = 1", 0x9e222820, ErrUnallocated},
+		{"fadd with S = 1", 0x3e222820, ErrUnallocated},
+		{"fp 2-source with opcode = 0b1001", 0x1e229820, ErrUnallocated},
+		{"fp 1-source with opcode = 0b100000", 0x1e304020, ErrUnallocated},
+		{"fcmp with opcode2 = 0b00001", 0x1e202001, ErrUnallocated},
+		{"fcmp with op = 0b01", 0x1e206000, ErrUnallocated},
+		{"fmov (immediate) with imm5 = 0b00100", 0x1e201080, ErrUnallocated},
+		{"fmov w0, d1", 0x1e660020, ErrUnallocated},
+		{"fmov x0, s1", 0x9e260020, ErrUnallocated},
+		{"fmov w0, v1.d[1]", 0x1eae0020, ErrUnallocated},
+		{"fcvt d0, s1", 0x1e22c020, ErrUnimplemented},
+		{"frintn s0, s1", 0x1e244020, ErrUnimplemented},
+		{"scvtf s0, w1", 0x1e220020, ErrUnimplemented},
+		{"fcvtzs w0, s1", 0x1e380020, ErrUnimplemented},
+		{"fccmp s0, s1, #0, eq", 0x1e210400, ErrUnimplemented},
+		{"bc.eq #4 (FEAT_HBC)", 0x54000030, ErrUnimplemented},
+		{"br with opc = 0b0011", 0xd67f0060, ErrUnimplemented},
+	} {
+		_, err := Decode(tc.word)
+		var derr *DecodeError
+		if !errors.As(err, &derr) {
+			t.Errorf("Decode(0x%08x) [%s] returned %v, want a *DecodeError", tc.word, tc.desc, err)
+			continue
+		}
+		if !errors.Is(err, tc.want) {
+			t.Errorf("Decode(0x%08x) [%s] returned %v, want %v", tc.word, tc.desc, err, tc.want)
+		}
+	}
+}
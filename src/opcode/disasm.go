@@ -0,0 +1,59 @@
+package opcode
+
+import (
+	"fmt"
+)
+
+// regName returns the name of general purpose register n, which is a w register unless sf is 1.
+// Register 31 is the stack pointer if sp is true, otherwise the zero register.
+func regName(sf, n uint32, sp bool) string {
+	n &= 0b11111
+	switch {
+	case n == 31 && sp && sf&1 == 1:
+		return "sp"
+	case n == 31 && sp:
+		return "wsp"
+	case n == 31 && sf&1 == 1:
+		return "xzr"
+	case n == 31:
+		return "wzr"
+	case sf&1 == 1:
+		return fmt.Sprintf("x%d", n)
+	}
+	return fmt.Sprintf("w%d", n)
+}
+
+var shiftNames = [4]string{"lsl", "lsr", "asr", "ror"}
+
+var extendNames = [8]string{"uxtb", "uxth", "uxtw", "uxtx", "sxtb", "sxth", "sxtw", "sxtx"}
+
+// arrangement returns the arrangement specifier for a vector of 8<<size bit elements, which fills
+// the whole 128-bit register if q is 1 and the lower 64 bits otherwise.
+func arrangement(q, size uint32) string {
+	esize := 8 << (size & 0b11)
+	lanes := 64 / esize
+	if q&1 == 1 {
+		lanes *= 2
+	}
+	return fmt.Sprintf("%d%c", lanes, "bhsd"[size&0b11])
+}
+
+// vecName returns the name of vector register n with the given arrangement.
+func vecName(n, q, size uint32) string {
+	return fmt.Sprintf("v%d.%s", n&0b11111, arrangement(q, size))
+}
+
+// scalarName returns the name of SIMD&FP register n used as a scalar of 8<<size bits, from b0 for
+// a byte to q0 for the whole register.
+func scalarName(n, size uint32) string {
+	return fmt.Sprintf("%c%d", "bhsdq"[size], n&0b11111)
+}
+
+// hexImm formats an immediate the way the disassembler prints values that are usually thought of
+// as bit patterns rather than numbers.
+func hexImm(v uint64) string {
+	if v == 0 {
+		return "#0"
+	}
+	return fmt.Sprintf("#0x%x", v)
+}
@@ -0,0 +1,327 @@
+package opcode
+
+import (
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	for _, tc := range []struct {
+		word uint32
+		want string
+	}{
+		{0x8b050062, "add x2, x3, x5"},
+		{0x0b837c41, "add w1, w2, w3, asr #31"},
+		{0x0b430041, "add w1, w2, w3, lsr #0"},
+		{0x8b0003e0, "add x0, xzr, x0"},
+		{0x0b0303e1, "add w1, wzr, w3"},
+		{0x11001462, "add w2, w3, #5"},
+		{0x11000000, "add w0, w0, #0"},
+		{0x917ffc3f, "add sp, x1, #4095, lsl #12"},
+		{0x910003e0, "mov x0, sp"},
+		{0x9100003f, "mov sp, x1"},
+		{0x8b214be0, "add x0, sp, w1, uxtw #2"},
+		{0x8b23e041, "add x1, x2, x3, sxtx"},
+		{0x8b23e841, "add x1, x2, x3, sxtx #2"},
+		{0x8b206020, "add x0, x1, x0, uxtx"},
+		{0x8b2063e0, "add x0, sp, x0"},
+		{0x0b2043e0, "add w0, wsp, w0"},
+		{0x8b2043ff, "add sp, sp, w0, uxtw"},
+		{0x8b3f63ff, "add sp, sp, xzr"},
+		{0x4ea28420, "add v0.4s, v1.4s, v2.4s"},
+		{0x0e3f8483, "add v3.8b, v4.8b, v31.8b"},
+		{0x4ee08400, "add v0.2d, v0.2d, v0.2d"},
+		{0x0e608400, "add v0.4h, v0.4h, v0.4h"},
+		{0xb1001420, "adds x0, x1, #5"},
+		{0xb10003e0, "adds x0, sp, #0"},
+		{0xd10043ff, "sub sp, sp, #16"},
+		{0xd10003e0, "sub x0, sp, #0"},
+		{0xf10013ff, "cmp sp, #4"},
+		{0x3140103f, "cmn w1, #4, lsl #12"},
+		{0xeb0103ff, "cmp xzr, x1"},
+		{0xeb0103e0, "negs x0, x1"},
+		{0xcb010fe0, "neg x0, x1, lsl #3"},
+		{0x4b0103e0, "neg w0, w1"},
+		{0x4b0003ff, "neg wzr, w0"},
+		{0x6b42043f, "cmp w1, w2, lsr #1"},
+		{0xab02003f, "cmn x1, x2"},
+		{0xeb22c420, "subs x0, x1, w2, sxtw #1"},
+		{0xeb2163ff, "cmp sp, x1"},
+		{0xeb22003f, "cmp x1, w2, uxtb"},
+		{0xcb2163e0, "sub x0, sp, x1"},
+		{0x2b2143ff, "cmn wsp, w1"},
+		{0x6b3f43ff, "cmp wsp, wzr"},
+		{0x14000004, "b #16"},
+		{0x17fffc00, "b #-4096"},
+		{0x95ffffff, "bl #134217724"},
+		{0x96000000, "bl #-134217728"},
+		{0x54000040, "b.eq #8"},
+		{0x54ffffe2, "b.hs #-4"},
+		{0x54000003, "b.lo #0"},
+		{0x5400002e, "b.al #4"},
+		{0x5400002f, "b.nv #4"},
+		{0xb4000040, "cbz x0, #8"},
+		{0x35ffff83, "cbnz w3, #-16"},
+		{0x36180040, "tbz w0, #3, #8"},
+		{0xb7ffff05, "tbnz x5, #63, #-32"},
+		{0xd61f0060, "br x3"},
+		{0xd63f03c0, "blr x30"},
+		{0xd65f03c0, "ret"},
+		{0xd65f0040, "ret x2"},
+		{0xf9400020, "ldr x0, [x1]"},
+		{0xf97fffe0, "ldr x0, [sp, #32760]"},
+		{0xb9000483, "str w3, [x4, #4]"},
+		{0x397ffc20, "ldrb w0, [x1, #4095]"},
+		{0x39800020, "ldrsb x0, [x1]"},
+		{0x39c00020, "ldrsb w0, [x1]"},
+		{0x79800462, "ldrsh x2, [x3, #2]"},
+		{0xb98007e0, "ldrsw x0, [sp, #4]"},
+		{0x793ffc41, "strh w1, [x2, #8190]"},
+		{0xf8408c20, "ldr x0, [x1, #8]!"},
+		{0xf8500420, "ldr x0, [x1], #-256"},
+		{0xf81f0fe0, "str x0, [sp, #-16]!"},
+		{0xf8400c20, "ldr x0, [x1, #0]!"},
+		{0xb8400c3f, "ldr wzr, [x1, #0]!"},
+		{0xf85f8020, "ldur x0, [x1, #-8]"},
+		{0xb8001020, "stur w0, [x1, #1]"},
+		{0x384ff020, "ldurb w0, [x1, #255]"},
+		{0xb8800020, "ldursw x0, [x1]"},
+		{0x78dff020, "ldursh w0, [x1, #-1]"},
+		{0xf8626820, "ldr x0, [x1, x2]"},
+		{0xf8627820, "ldr x0, [x1, x2, lsl #3]"},
+		{0xb8624820, "ldr w0, [x1, w2, uxtw]"},
+		{0xb862d820, "ldr w0, [x1, w2, sxtw #2]"},
+		{0x38627820, "ldrb w0, [x1, x2, lsl #0]"},
+		{0x38625820, "ldrb w0, [x1, w2, uxtw #0]"},
+		{0x78a2d820, "ldrsh x0, [x1, w2, sxtw #1]"},
+		{0xf822ebe0, "str x0, [sp, x2, sxtx]"},
+		{0xf8626bff, "ldr xzr, [sp, x2]"},
+		{0x58000040, "ldr x0, #8"},
+		{0x18ffffe0, "ldr w0, #-4"},
+		{0x987fffe0, "ldrsw x0, #1048572"},
+		{0x3dc00400, "ldr q0, [x0, #16]"},
+		{0xfc1f8fe1, "str d1, [sp, #-8]!"},
+		{0x3d400000, "ldr b0, [x0]"},
+		{0x7c402400, "ldr h0, [x0], #2"},
+		{0xbc627823, "ldr s3, [x1, x2, lsl #2]"},
+		{0x3ce27820, "ldr q0, [x1, x2, lsl #4]"},
+		{0x9c000084, "ldr q4, #16"},
+		{0x5c000040, "ldr d0, #8"},
+		{0x3cdff041, "ldur q1, [x2, #-1]"},
+		{0x38001420, "strb w0, [x1], #1"},
+		{0x38dffc20, "ldrsb w0, [x1, #-1]!"},
+		{0xa9bf7bfd, "stp x29, x30, [sp, #-16]!"},
+		{0xa8c17bfd, "ldp x29, x30, [sp], #16"},
+		{0xa9400440, "ldp x0, x1, [x2]"},
+		{0xa8c00440, "ldp x0, x1, [x2], #0"},
+		{0xa9c00440, "ldp x0, x1, [x2, #0]!"},
+		{0xa95f8440, "ldp x0, x1, [x2, #504]"},
+		{0x292010a3, "stp w3, w4, [x5, #-256]"},
+		{0x68ff0440, "ldpsw x0, x1, [x2], #-8"},
+		{0x6dbc27e8, "stp d8, d9, [sp, #-64]!"},
+		{0x2cc08420, "ldp s0, s1, [x1], #4"},
+		{0xad1f87e0, "stp q0, q1, [sp, #1008]"},
+		{0xa9bf7fff, "stp xzr, xzr, [sp, #-16]!"},
+		{0xd2a24680, "mov x0, #305397760"},
+		{0x52800000, "mov w0, #0"},
+		{0xd2a00000, "movz x0, #0, lsl #16"},
+		{0x52a00000, "movz w0, #0, lsl #16"},
+		{0xd2f00000, "mov x0, #-9223372036854775808"},
+		{0x92800000, "mov x0, #-1"},
+		{0x12800000, "mov w0, #-1"},
+		{0x128000a0, "mov w0, #-6"},
+		{0x129fffe0, "movn w0, #65535"},
+		{0x12bfffe0, "movn w0, #65535, lsl #16"},
+		{0x12a24680, "mov w0, #-305397761"},
+		{0x92c00000, "movn x0, #0, lsl #32"},
+		{0x92f00000, "mov x0, #9223372036854775807"},
+		{0x92bfffe0, "mov x0, #-4294901761"},
+		{0xf2e24680, "movk x0, #4660, lsl #48"},
+		{0x728000a1, "movk w1, #5"},
+		{0xb200f3e0, "mov x0, #6148914691236517205"},
+		{0xb201f3e0, "mov x0, #-6148914691236517206"},
+		{0x32009fe0, "mov w0, #16711935"},
+		{0xb2401c20, "orr x0, x1, #0xff"},
+		{0xb2403fe0, "orr x0, xzr, #0xffff"},
+		{0x321003e0, "orr w0, wzr, #0x10000"},
+		{0x3200003f, "orr wsp, w1, #0x1"},
+		{0xb27f7be0, "mov x0, #4294967294"},
+		{0x92401c20, "and x0, x1, #0xff"},
+		{0x92401c3f, "and sp, x1, #0xff"},
+		{0x72000020, "ands w0, w1, #0x1"},
+		{0xf2401c3f, "tst x1, #0xff"},
+		{0x52010020, "eor w0, w1, #0x80000000"},
+		{0x9278dc20, "and x0, x1, #0xffffffffffffff00"},
+		{0x8ac20c20, "and x0, x1, x2, ror #3"},
+		{0x0a620020, "bic w0, w1, w2, lsr #0"},
+		{0xaa020020, "orr x0, x1, x2"},
+		{0xaa0203e0, "mov x0, x2"},
+		{0xaa1f03e0, "mov x0, xzr"},
+		{0xaa0207e0, "orr x0, xzr, x2, lsl #1"},
+		{0xaa4203e0, "orr x0, xzr, x2, lsr #0"},
+		{0xaa2203e0, "mvn x0, x2"},
+		{0x2aa20be0, "mvn w0, w2, asr #2"},
+		{0xca220020, "eon x0, x1, x2"},
+		{0xea020020, "ands x0, x1, x2"},
+		{0xea02103f, "tst x1, x2, lsl #4"},
+		{0xea22003f, "bics xzr, x1, x2"},
+		{0x6a22003f, "bics wzr, w1, w2"},
+		{0x9343fc20, "asr x0, x1, #3"},
+		{0x131f7c20, "asr w0, w1, #31"},
+		{0x9340fc20, "asr x0, x1, #0"},
+		{0x937c1c20, "sbfiz x0, x1, #4, #8"},
+		{0x13042c20, "sbfx w0, w1, #4, #8"},
+		{0x93400020, "sbfx x0, x1, #0, #1"},
+		{0x93401c20, "sxtb x0, w1"},
+		{0x13001c20, "sxtb w0, w1"},
+		{0x93403c20, "sxth x0, w1"},
+		{0x93407c20, "sxtw x0, w1"},
+		{0xd37cec20, "lsl x0, x1, #4"},
+		{0x53010020, "lsl w0, w1, #31"},
+		{0x531f7820, "lsl w0, w1, #1"},
+		{0x53007c20, "lsr w0, w1, #0"},
+		{0xd37ffc20, "lsr x0, x1, #63"},
+		{0xd340fc20, "lsr x0, x1, #0"},
+		{0x531d0c20, "ubfiz w0, w1, #3, #4"},
+		{0xd3483c20, "ubfx x0, x1, #8, #8"},
+		{0xd3401c20, "ubfx x0, x1, #0, #8"},
+		{0x53001c20, "uxtb w0, w1"},
+		{0x53003c20, "uxth w0, w1"},
+		{0xb3780fe0, "bfc x0, #8, #4"},
+		{0xb37f03e0, "bfc x0, #1, #1"},
+		{0x33180c20, "bfi w0, w1, #8, #4"},
+		{0x33010020, "bfi w0, w1, #31, #1"},
+		{0xb340fc20, "bfxil x0, x1, #0, #64"},
+		{0x330217e0, "bfxil w0, wzr, #2, #4"},
+		{0x93c21c20, "extr x0, x1, x2, #7"},
+		{0x13807c20, "extr w0, w1, w0, #31"},
+		{0x13811420, "ror w0, w1, #5"},
+		{0x93c10020, "ror x0, x1, #0"},
+		{0x9b020c20, "madd x0, x1, x2, x3"},
+		{0x1b028c20, "msub w0, w1, w2, w3"},
+		{0x9b027c20, "mul x0, x1, x2"},
+		{0x9b027fe0, "mul x0, xzr, x2"},
+		{0x1b02fc20, "mneg w0, w1, w2"},
+		{0x9b220c20, "smaddl x0, w1, w2, x3"},
+		{0x9b228c20, "smsubl x0, w1, w2, x3"},
+		{0x9ba20c20, "umaddl x0, w1, w2, x3"},
+		{0x9ba28c20, "umsubl x0, w1, w2, x3"},
+		{0x9b227c20, "smull x0, w1, w2"},
+		{0x9ba27c20, "umull x0, w1, w2"},
+		{0x9b22fc20, "smnegl x0, w1, w2"},
+		{0x9ba2fc20, "umnegl x0, w1, w2"},
+		{0x9b427c20, "smulh x0, x1, x2"},
+		{0x9bc20020, "umulh x0, x1, x2"},
+		{0x1ac20820, "udiv w0, w1, w2"},
+		{0x9ac20c20, "sdiv x0, x1, x2"},
+		{0x9ac22020, "lsl x0, x1, x2"},
+		{0x1ac22020, "lsl w0, w1, w2"},
+		{0x9ac22420, "lsr x0, x1, x2"},
+		{0x9ac22820, "asr x0, x1, x2"},
+		{0x1ac22c20, "ror w0, w1, w2"},
+		{0x9a820020, "csel x0, x1, x2, eq"},
+		{0x1a821420, "csinc w0, w1, w2, ne"},
+		{0xda822020, "csinv x0, x1, x2, hs"},
+		{0x5a82b420, "csneg w0, w1, w2, lt"},
+		{0x9a9f17e0, "cset x0, eq"},
+		{0x9a9f07e0, "cset x0, ne"},
+		{0x5a9f23e0, "csetm w0, lo"},
+		{0x9a81d420, "cinc x0, x1, gt"},
+		{0x9a810420, "cinc x0, x1, ne"},
+		{0x5a81c020, "cinv w0, w1, le"},
+		{0xda815420, "cneg x0, x1, mi"},
+		{0xda9f07e0, "cneg x0, xzr, ne"},
+		{0xda8107e0, "csneg x0, xzr, x1, eq"},
+		{0x9a9fe7e0, "csinc x0, xzr, xzr, al"},
+		{0xda9ff3e0, "csinv x0, xzr, xzr, nv"},
+		{0xda81e420, "csneg x0, x1, x1, al"},
+		{0x9a9f1420, "csinc x0, x1, xzr, ne"},
+		{0xfa421024, "ccmp x1, x2, #4, ne"},
+		{0x3a5fe82f, "ccmn w1, #31, #15, al"},
+		{0x7a400820, "ccmp w1, #0, #0, eq"},
+		{0xba42f020, "ccmn x1, x2, #0, nv"},
+		{0x9a020020, "adc x0, x1, x2"},
+		{0x3a020020, "adcs w0, w1, w2"},
+		{0x1a0203e0, "adc w0, wzr, w2"},
+		{0xda020020, "sbc x0, x1, x2"},
+		{0x7a020020, "sbcs w0, w1, w2"},
+		{0xda0203e0, "ngc x0, x2"},
+		{0x7a0103e0, "ngcs w0, w1"},
+		{0x10000080, "adr x0, #16"},
+		{0x30000000, "adr x0, #1"},
+		{0x70ffffe0, "adr x0, #-1"},
+		{0x90000020, "adrp x0, #16384"},
+		{0xf0ffffe0, "adrp x0, #-4096"},
+		{0xf07fffe1, "adrp x1, #4294963200"},
+		{0x5ac00020, "rbit w0, w1"},
+		{0xdac00020, "rbit x0, x1"},
+		{0x5ac00420, "rev16 w0, w1"},
+		{0x5ac00820, "rev w0, w1"},
+		{0xdac00820, "rev32 x0, x1"},
+		{0xdac00c20, "rev x0, x1"},
+		{0x5ac01020, "clz w0, w1"},
+		{0xdac01420, "cls x0, x1"},
+		{0x0e205820, "cnt v0.8b, v1.8b"},
+		{0x6e605820, "rbit v0.16b, v1.16b"},
+		{0x4e201820, "rev16 v0.16b, v1.16b"},
+		{0x2e200820, "rev32 v0.8b, v1.8b"},
+		{0x4e200820, "rev64 v0.16b, v1.16b"},
+		{0x4ea00820, "rev64 v0.4s, v1.4s"},
+		{0x6e204820, "clz v0.16b, v1.16b"},
+		{0x0ea04820, "cls v0.2s, v1.2s"},
+		{0x0c40a000, "ld1 { v0.8b, v1.8b }, [x0]"},
+		{0x4c406be0, "ld1 { v0.4s, v1.4s, v2.4s }, [sp]"},
+		{0x4c400000, "ld4 { v0.16b, v1.16b, v2.16b, v3.16b }, [x0]"},
+		{0x0c9f7c00, "st1 { v0.1d }, [x0], #8"},
+		{0x4c9f0800, "st4 { v0.4s, v1.4s, v2.4s, v3.4s }, [x0], #64"},
+		{0x0ddf5800, "ld1 { v0.h }[3], [x0], #2"},
+		{0x0d9f3c00, "st3 { v0.b, v1.b, v2.b }[7], [x0], #3"},
+		{0x0d40a000, "ld3 { v0.s, v1.s, v2.s }[0], [x0]"},
+		{0x0dffc000, "ld2r { v0.8b, v1.8b }, [x0], #2"},
+		{0x0dc5ec00, "ld3r { v0.1d, v1.1d, v2.1d }, [x0], x5"},
+		{0x0d40c400, "ld1r { v0.4h }, [x0]"},
+		{0x2e60b820, "neg v0.4h, v1.4h"},
+		{0x0e62bc20, "addp v0.4h, v1.4h, v2.4h"},
+		{0x6e221420, "urhadd v0.16b, v1.16b, v2.16b"},
+		{0x6e214820, "uqxtn2 v0.16b, v1.8h"},
+		{0x0e622c20, "sqsub v0.4h, v1.4h, v2.4h"},
+		{0x6e62c020, "umull2 v0.4s, v1.8h, v2.8h"},
+		{0x4e623020, "ssubw2 v0.4s, v1.4s, v2.8h"},
+		{0x6ea13820, "shll2 v0.2d, v1.4s, #32"},
+		{0x0ea06820, "sadalp v0.1d, v1.2s"},
+		{0x0e71b820, "addv h0, v1.4h"},
+		{0x4e703820, "saddlv s0, v1.8h"},
+		{0x4e427820, "zip2 v0.8h, v1.8h, v2.8h"},
+		{0x0e0373e0, "tbx v0.8b, { v31.16b, v0.16b, v1.16b, v2.16b }, v3.8b"},
+		{0x6e0c6420, "mov v0.s[1], v1.s[3]"},
+		{0x0e073c20, "umov w0, v1.b[3]"},
+		{0x4e183c20, "mov x0, v1.d[1]"},
+		{0x6e623420, "cmhi v0.8h, v1.8h, v2.8h"},
+		{0x6ea09820, "cmle v0.4s, v1.4s, #0"},
+		{0x4ea11c20, "mov v0.16b, v1.16b"},
+		{0x4ea21c20, "orr v0.16b, v1.16b, v2.16b"},
+		{0x2e205820, "mvn v0.8b, v1.8b"},
+		{0x4f0d0420, "sshr v0.16b, v1.16b, #3"},
+		{0x6f404420, "sri v0.2d, v1.2d, #64"},
+		{0x4f0c8c20, "rshrn2 v0.16b, v1.8h, #4"},
+		{0x2f3f8c20, "sqrshrun v0.2s, v1.2d, #1"},
+		{0x4e624c20, "sqshl v0.8h, v1.8h, v2.8h"},
+		{0x1ee22820, "fadd h0, h1, h2"},
+		{0x1f428c20, "fmsub d0, d1, d2, d3"},
+		{0x1ee02008, "fcmp h0, #0.0"},
+		{0x1ee2bc20, "fcsel h0, h1, h2, lt"},
+		{0x1e2ff000, "fmov s0, #1.93750000"},
+		{0x9eae0020, "fmov x0, v1.d[1]"},
+		{0xd4207d00, "brk #0x3e8"},
+		{0xd4400000, "hlt #0"},
+		{0xd45fffe0, "hlt #0xffff"},
+	} {
+		inst, err := Decode(tc.word)
+		if err != nil {
+			t.Errorf("Decode(0x%08x) failed: %v", tc.word, err)
+			continue
+		}
+		if got := inst.String(); got != tc.want {
+			t.Errorf("Decode(0x%08x).String() = %q, want %q", tc.word, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// C6.2.44 BRK
+type Brk struct {
+	Imm uint32 // 16 bits
+}
+
+func (op *Brk) Encode() uint32 {
+	return buildUint32([]bits{
+		{0b11010100, 8},
+		{0b001, 3},
+		{op.Imm, 16},
+		{0b000, 3},
+		{0b00, 2},
+	}...)
+}
+
+func (op *Brk) Execute(m *machine.Machine) {
+	m.Stop(machine.StopBreakpoint, nil)
+}
+
+func (op *Brk) String() string {
+	return fmt.Sprintf("brk %s", hexImm(uint64(op.Imm&0xffff)))
+}
+
+// C6.2.123 HLT
+type Hlt struct {
+	Imm uint32 // 16 bits
+}
+
+func (op *Hlt) Encode() uint32 {
+	return buildUint32([]bits{
+		{0b11010100, 8},
+		{0b010, 3},
+		{op.Imm, 16},
+		{0b000, 3},
+		{0b00, 2},
+	}...)
+}
+
+func (op *Hlt) Execute(m *machine.Machine) {
+	m.Stop(machine.StopHalt, nil)
+}
+
+func (op *Hlt) String() string {
+	return fmt.Sprintf("hlt %s", hexImm(uint64(op.Imm&0xffff)))
+}
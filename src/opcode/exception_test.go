@@ -0,0 +1,38 @@
+package opcode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// load returns a machine with insts encoded into memory starting at address 0.
+func load(insts ...Instruction) *machine.Machine {
+	m := machine.New(4096)
+	m.Decode = Decoder
+	for i, inst := range insts {
+		m.Write(uint64(4*i), 4, uint64(inst.Encode()))
+	}
+	return m
+}
+
+func TestExceptionStops(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		inst Instruction
+		want machine.StopReason
+	}{
+		{"hlt", &Hlt{}, machine.StopHalt},
+		{"brk", &Brk{Imm: 1}, machine.StopBreakpoint},
+	} {
+		m := load(&AddImmedite{Sf: 1, Imm: 1, Rd: 0, Rn: 0}, tc.inst, &AddImmedite{Sf: 1, Imm: 1, Rd: 0, Rn: 0})
+		reason, err := m.Run(context.Background())
+		if reason != tc.want || err != nil {
+			t.Errorf("%s: Run returned %v, %v, want %v", tc.desc, reason, err, tc.want)
+		}
+		if m.PC != 4 || m.R[0] != 1 {
+			t.Errorf("%s: stopped at pc=%d with x0=%d, want pc=4 with x0=1", tc.desc, m.PC, m.R[0])
+		}
+	}
+}
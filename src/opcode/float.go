@@ -0,0 +1,541 @@
+package opcode
+
+import (
+	"math"
+	"math/big"
+	gobits "math/bits"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// The floating-point helpers work on the bits of IEEE 754 half, single and double precision
+// values held in the low fsize bits of a uint64.
+
+// fpExponentBits returns the number of exponent bits in a floating-point value of fsize bits.
+func fpExponentBits(fsize int) int {
+	switch fsize {
+	case 16:
+		return 5
+	case 32:
+		return 8
+	}
+	return 11
+}
+
+// fpSignBit returns the sign bit of a floating-point value of fsize bits.
+func fpSignBit(fsize int) uint64 {
+	return 1 << (fsize - 1)
+}
+
+// fpQuietBit returns the most significant fraction bit of a floating-point value of fsize bits,
+// which distinguishes quiet NaNs from signalling NaNs.
+func fpQuietBit(fsize int) uint64 {
+	return 1 << (fsize - fpExponentBits(fsize) - 2)
+}
+
+// fpIsNaN reports whether x is a NaN: all its exponent bits are set and its fraction is not 0.
+func fpIsNaN(x uint64, fsize int) bool {
+	fraction := fsize - fpExponentBits(fsize) - 1
+	exponent := x >> fraction & (1<<fpExponentBits(fsize) - 1)
+	return exponent == 1<<fpExponentBits(fsize)-1 && x&(1<<fraction-1) != 0
+}
+
+// fpIsSignallingNaN reports whether x is a signalling NaN.
+func fpIsSignallingNaN(x uint64, fsize int) bool {
+	return fpIsNaN(x, fsize) && x&fpQuietBit(fsize) == 0
+}
+
+// fpDefaultNaN returns the default NaN, the positive quiet NaN with no other fraction bits set.
+func fpDefaultNaN(fsize int) uint64 {
+	return (1<<(fpExponentBits(fsize)+1) - 1) << (fsize - fpExponentBits(fsize) - 2)
+}
+
+// fpProcessNaN returns the result of an operation with the NaN operand x: x made quiet, or the
+// default NaN if FPCR.DN is set.  A signalling NaN raises the Invalid Operation exception.
+func fpProcessNaN(m *machine.Machine, x uint64, fsize int) uint64 {
+	if fpIsSignallingNaN(x, fsize) {
+		m.FPSR |= machine.FPSRIOC
+	}
+	if m.FPCR&machine.FPCRDN != 0 {
+		return fpDefaultNaN(fsize)
+	}
+	return x | fpQuietBit(fsize)
+}
+
+// fpProcessNaNs returns the result of an operation on a and b if either is a NaN, and whether
+// either was.  Signalling NaNs take priority over quiet NaNs, and a over b.
+func fpProcessNaNs(m *machine.Machine, a, b uint64, fsize int) (uint64, bool) {
+	switch {
+	case fpIsSignallingNaN(a, fsize):
+		return fpProcessNaN(m, a, fsize), true
+	case fpIsSignallingNaN(b, fsize):
+		return fpProcessNaN(m, b, fsize), true
+	case fpIsNaN(a, fsize):
+		return fpProcessNaN(m, a, fsize), true
+	case fpIsNaN(b, fsize):
+		return fpProcessNaN(m, b, fsize), true
+	}
+	return 0, false
+}
+
+// fpProcessNaNs3 is fpProcessNaNs for the three operands of a fused multiply-add, with a the
+// addend.
+func fpProcessNaNs3(m *machine.Machine, a, b, c uint64, fsize int) (uint64, bool) {
+	switch {
+	case fpIsSignallingNaN(a, fsize):
+		return fpProcessNaN(m, a, fsize), true
+	case fpIsSignallingNaN(b, fsize):
+		return fpProcessNaN(m, b, fsize), true
+	case fpIsSignallingNaN(c, fsize):
+		return fpProcessNaN(m, c, fsize), true
+	case fpIsNaN(a, fsize):
+		return fpProcessNaN(m, a, fsize), true
+	case fpIsNaN(b, fsize):
+		return fpProcessNaN(m, b, fsize), true
+	case fpIsNaN(c, fsize):
+		return fpProcessNaN(m, c, fsize), true
+	}
+	return 0, false
+}
+
+// The rounding modes selected by FPCR.RMode.
+const (
+	fpRoundNearest    = 0b00 // to nearest, ties to even
+	fpRoundPlusInf    = 0b01 // towards plus infinity
+	fpRoundMinusInf   = 0b10 // towards minus infinity
+	fpRoundTowardZero = 0b11 // towards zero
+)
+
+// fpRoundingMode returns FPCR.RMode.
+func fpRoundingMode(m *machine.Machine) uint32 {
+	return m.FPCR >> machine.FPCRRModeShift & 0b11
+}
+
+// fpFlushToZero reports whether denormal inputs and tiny results of fsize bits are replaced by
+// zero: FPCR.FZ controls single and double precision and FPCR.FZ16 half precision.
+func fpFlushToZero(m *machine.Machine, fsize int) bool {
+	if fsize == 16 {
+		return m.FPCR&machine.FPCRFZ16 != 0
+	}
+	return m.FPCR&machine.FPCRFZ != 0
+}
+
+// fpClass is the kind of value held by a floating-point number.
+type fpClass int
+
+const (
+	fpZero fpClass = iota
+	fpNumber
+	fpInfinity
+	fpQuietNaN
+	fpSignallingNaN
+)
+
+// fpValue is an unpacked floating-point number.  A number other than zero has the value
+// mantissa * 2^exponent, negated if sign is set.
+type fpValue struct {
+	class    fpClass
+	sign     bool
+	mantissa uint64
+	exponent int
+}
+
+// fpUnpack unpacks the fsize-bit floating-point number x.  A denormal is read as zero if flushing
+// to zero, which raises the Input Denormal exception for single and double precision.
+func fpUnpack(m *machine.Machine, x uint64, fsize int) fpValue {
+	e, f := fpExponentBits(fsize), fsize-fpExponentBits(fsize)-1
+	v := fpValue{sign: x&fpSignBit(fsize) != 0}
+	biased, fraction := int(x>>f)&(1<<e-1), x&(1<<f-1)
+	switch {
+	case biased == 0 && fraction == 0:
+		v.class = fpZero
+	case biased == 0 && fpFlushToZero(m, fsize):
+		v.class = fpZero
+		if fsize != 16 {
+			m.FPSR |= machine.FPSRIDC
+		}
+	case biased == 0:
+		v.class, v.mantissa, v.exponent = fpNumber, fraction, fpMinExponent(fsize)-f
+	case biased == 1<<e-1 && fraction == 0:
+		v.class = fpInfinity
+	case biased == 1<<e-1 && x&fpQuietBit(fsize) != 0:
+		v.class = fpQuietNaN
+	case biased == 1<<e-1:
+		v.class = fpSignallingNaN
+	default:
+		v.class, v.mantissa, v.exponent = fpNumber, fraction|1<<f, biased-fpBias(fsize)-f
+	}
+	return v
+}
+
+// float64 returns the value of v, which is not a NaN.  Every half, single and double precision
+// value is exactly representable.
+func (v fpValue) float64() float64 {
+	var f float64
+	switch v.class {
+	case fpInfinity:
+		f = math.Inf(1)
+	case fpNumber:
+		f = math.Ldexp(float64(v.mantissa), v.exponent)
+	}
+	if v.sign {
+		f = -f
+	}
+	return f
+}
+
+// fpBias returns the exponent bias of a floating-point value of fsize bits.
+func fpBias(fsize int) int {
+	return 1<<(fpExponentBits(fsize)-1) - 1
+}
+
+// fpMinExponent returns the exponent of the smallest normal floating-point value of fsize bits.
+func fpMinExponent(fsize int) int {
+	return 1 - fpBias(fsize)
+}
+
+// fpZeroBits returns the fsize-bit zero with the given sign.
+func fpZeroBits(sign bool, fsize int) uint64 {
+	if sign {
+		return fpSignBit(fsize)
+	}
+	return 0
+}
+
+// fpInfinityBits returns the fsize-bit infinity with the given sign.
+func fpInfinityBits(sign bool, fsize int) uint64 {
+	return fpZeroBits(sign, fsize) | (1<<fpExponentBits(fsize)-1)<<(fsize-fpExponentBits(fsize)-1)
+}
+
+// fpMaxNormalBits returns the fsize-bit number of largest magnitude with the given sign.
+func fpMaxNormalBits(sign bool, fsize int) uint64 {
+	return fpInfinityBits(sign, fsize) - 1
+}
+
+// fpInvalid returns the default NaN, the result of an invalid operation such as 0 * inf, and
+// raises the Invalid Operation exception.
+func fpInvalid(m *machine.Machine, fsize int) uint64 {
+	m.FPSR |= machine.FPSRIOC
+	return fpDefaultNaN(fsize)
+}
+
+// fpRound rounds the value (mantissa + sticky) * 2^exponent to fsize bits in the rounding mode
+// selected by FPCR, where sticky is a fraction strictly between 0 and 1 if it is set and 0 if not.
+// mantissa must not be 0, and if sticky is set it must have at least two more significant bits
+// than the result, so that its lowest bits tell which way to round.
+//
+// As in the Arm pseudocode, a result is tiny if it is smaller than the smallest normal number
+// before rounding.  Tiny results are flushed to zero, raising Underflow but not Inexact, when
+// flushing to zero, and raise Underflow if they are inexact otherwise.  Overflow gives infinity or
+// the largest normal number, depending on the rounding mode, and raises Overflow and Inexact.
+func fpRound(m *machine.Machine, sign bool, mantissa uint64, exponent int, sticky bool, fsize int) uint64 {
+	e, f := fpExponentBits(fsize), fsize-fpExponentBits(fsize)-1
+	// The value is in [2^top, 2^(top+1)).
+	top := exponent + 63 - gobits.LeadingZeros64(mantissa)
+	if top < fpMinExponent(fsize) && fpFlushToZero(m, fsize) {
+		m.FPSR |= machine.FPSRUFC
+		return fpZeroBits(sign, fsize)
+	}
+	biased := max(top-fpMinExponent(fsize)+1, 0)
+
+	// Split the mantissa into the integer number of units in the last place of the result and the
+	// remainder, which is compared with half a unit.
+	shift := max(top, fpMinExponent(fsize)) - f - exponent
+	var result, remainder, half uint64
+	switch {
+	case shift <= 0:
+		result = mantissa << -shift
+	case shift > 64:
+		remainder, half = 1, 2
+	default:
+		result, remainder, half = mantissa>>shift, mantissa&(1<<shift-1), 1<<(shift-1)
+	}
+	inexact := remainder != 0 || sticky
+	aboveHalf := remainder > half || remainder == half && sticky
+	if biased == 0 && inexact {
+		m.FPSR |= machine.FPSRUFC
+	}
+
+	var roundUp, overflowToInfinity bool
+	switch fpRoundingMode(m) {
+	case fpRoundNearest:
+		roundUp = aboveHalf || inexact && remainder == half && result&1 == 1
+		overflowToInfinity = true
+	case fpRoundPlusInf:
+		roundUp, overflowToInfinity = inexact && !sign, !sign
+	case fpRoundMinusInf:
+		roundUp, overflowToInfinity = inexact && sign, sign
+	}
+	if roundUp {
+		result++
+		switch result {
+		case 1 << f:
+			// A denormal rounded up to the smallest normal number.
+			biased = 1
+		case 1 << (f + 1):
+			biased, result = biased+1, result>>1
+		}
+	}
+
+	if biased >= 1<<e-1 {
+		m.FPSR |= machine.FPSROFC | machine.FPSRIXC
+		if overflowToInfinity {
+			return fpInfinityBits(sign, fsize)
+		}
+		return fpMaxNormalBits(sign, fsize)
+	}
+	if inexact {
+		m.FPSR |= machine.FPSRIXC
+	}
+	return fpZeroBits(sign, fsize) | uint64(biased)<<f | result&(1<<f-1)
+}
+
+// fpRoundBig is fpRound for a mantissa of any size.
+func fpRoundBig(m *machine.Machine, sign bool, mantissa *big.Int, exponent int, sticky bool, fsize int) uint64 {
+	if excess := mantissa.BitLen() - 64; excess > 0 {
+		sticky = sticky || mantissa.TrailingZeroBits() < uint(excess)
+		mantissa = new(big.Int).Rsh(mantissa, uint(excess))
+		exponent += excess
+	}
+	return fpRound(m, sign, mantissa.Uint64(), exponent, sticky, fsize)
+}
+
+// fpRoundSum rounds the exact sum of x and y, which are numbers or zeros.  A sum that is exactly
+// zero is +0, or -0 when rounding towards minus infinity.
+func fpRoundSum(m *machine.Machine, x, y fpTerm, fsize int) uint64 {
+	switch {
+	case x.mantissa.Sign() == 0:
+		x.exponent = y.exponent
+	case y.mantissa.Sign() == 0:
+		y.exponent = x.exponent
+	}
+	exponent := min(x.exponent, y.exponent)
+	sum, addend := x.scaled(exponent), y.scaled(exponent)
+	sum.Add(sum, addend)
+	if sum.Sign() == 0 {
+		return fpZeroBits(fpRoundingMode(m) == fpRoundMinusInf, fsize)
+	}
+	return fpRoundBig(m, sum.Sign() < 0, sum.Abs(sum), exponent, false, fsize)
+}
+
+// fpTerm is an exact intermediate result, mantissa * 2^exponent negated if sign is set.
+type fpTerm struct {
+	sign     bool
+	mantissa *big.Int
+	exponent int
+}
+
+// term returns v, which is a number or zero, as an fpTerm.
+func (v fpValue) term() fpTerm {
+	return fpTerm{v.sign, new(big.Int).SetUint64(v.mantissa), v.exponent}
+}
+
+// scaled returns the signed value of t as a multiple of 2^exponent, which is at most t.exponent.
+func (t fpTerm) scaled(exponent int) *big.Int {
+	n := new(big.Int).Lsh(t.mantissa, uint(t.exponent-exponent))
+	if t.sign {
+		n.Neg(n)
+	}
+	return n
+}
+
+// fpAddSub returns a + b, or a - b if subtract is set.
+func fpAddSub(m *machine.Machine, a, b uint64, fsize int, subtract bool) uint64 {
+	x, y := fpUnpack(m, a, fsize), fpUnpack(m, b, fsize)
+	if result, ok := fpProcessNaNs(m, a, b, fsize); ok {
+		return result
+	}
+	y.sign = y.sign != subtract
+	switch {
+	case x.class == fpInfinity && y.class == fpInfinity && x.sign != y.sign:
+		return fpInvalid(m, fsize)
+	case x.class == fpInfinity:
+		return fpInfinityBits(x.sign, fsize)
+	case y.class == fpInfinity:
+		return fpInfinityBits(y.sign, fsize)
+	case x.class == fpZero && y.class == fpZero && x.sign == y.sign:
+		return fpZeroBits(x.sign, fsize)
+	}
+	return fpRoundSum(m, x.term(), y.term(), fsize)
+}
+
+func fpAdd(m *machine.Machine, a, b uint64, fsize int) uint64 {
+	return fpAddSub(m, a, b, fsize, false)
+}
+
+func fpSub(m *machine.Machine, a, b uint64, fsize int) uint64 {
+	return fpAddSub(m, a, b, fsize, true)
+}
+
+func fpMul(m *machine.Machine, a, b uint64, fsize int) uint64 {
+	x, y := fpUnpack(m, a, fsize), fpUnpack(m, b, fsize)
+	if result, ok := fpProcessNaNs(m, a, b, fsize); ok {
+		return result
+	}
+	sign := x.sign != y.sign
+	switch {
+	case x.class == fpInfinity && y.class == fpZero || x.class == fpZero && y.class == fpInfinity:
+		return fpInvalid(m, fsize)
+	case x.class == fpInfinity || y.class == fpInfinity:
+		return fpInfinityBits(sign, fsize)
+	case x.class == fpZero || y.class == fpZero:
+		return fpZeroBits(sign, fsize)
+	}
+	product := new(big.Int).Mul(x.term().mantissa, y.term().mantissa)
+	return fpRoundBig(m, sign, product, x.exponent+y.exponent, false, fsize)
+}
+
+// fpDiv returns a / b, raising the Divide by Zero exception if b is zero and a is a number other
+// than zero.
+func fpDiv(m *machine.Machine, a, b uint64, fsize int) uint64 {
+	x, y := fpUnpack(m, a, fsize), fpUnpack(m, b, fsize)
+	if result, ok := fpProcessNaNs(m, a, b, fsize); ok {
+		return result
+	}
+	sign := x.sign != y.sign
+	switch {
+	case x.class == fpInfinity && y.class == fpInfinity || x.class == fpZero && y.class == fpZero:
+		return fpInvalid(m, fsize)
+	case x.class == fpInfinity:
+		return fpInfinityBits(sign, fsize)
+	case y.class == fpZero:
+		m.FPSR |= machine.FPSRDZC
+		return fpInfinityBits(sign, fsize)
+	case x.class == fpZero || y.class == fpInfinity:
+		return fpZeroBits(sign, fsize)
+	}
+	// Scale the dividend so that the quotient has 66 significant bits or more, and keep whether
+	// the division was exact in the sticky bit.
+	shift := 66 + gobits.Len64(y.mantissa) - gobits.Len64(x.mantissa)
+	dividend := new(big.Int).Lsh(x.term().mantissa, uint(shift))
+	quotient, remainder := dividend.QuoRem(dividend, y.term().mantissa, new(big.Int))
+	return fpRoundBig(m, sign, quotient, x.exponent-y.exponent-shift, remainder.Sign() != 0, fsize)
+}
+
+// fpSqrt returns the square root of a.  The square root of a number less than zero, other than
+// -0, is an invalid operation.
+func fpSqrt(m *machine.Machine, a uint64, fsize int) uint64 {
+	x := fpUnpack(m, a, fsize)
+	switch {
+	case fpIsNaN(a, fsize):
+		return fpProcessNaN(m, a, fsize)
+	case x.class == fpZero:
+		return fpZeroBits(x.sign, fsize)
+	case x.sign:
+		return fpInvalid(m, fsize)
+	case x.class == fpInfinity:
+		return fpInfinityBits(false, fsize)
+	}
+	// Make the exponent even and scale the mantissa so that its root has 66 significant bits or
+	// more, and keep whether the root was exact in the sticky bit.
+	n, exponent := x.term().mantissa, x.exponent
+	if exponent&1 != 0 {
+		n.Lsh(n, 1)
+		exponent--
+	}
+	shift := (132-n.BitLen())/2 + 1
+	n.Lsh(n, uint(2*shift))
+	root := new(big.Int).Sqrt(n)
+	exact := new(big.Int).Mul(root, root).Cmp(n) == 0
+	return fpRoundBig(m, false, root, exponent/2-shift, !exact, fsize)
+}
+
+// fpMulAdd returns addend + a * b with a single rounding.  0 * inf is an invalid operation even
+// when the addend is a quiet NaN.
+func fpMulAdd(m *machine.Machine, addend, a, b uint64, fsize int) uint64 {
+	z, x, y := fpUnpack(m, addend, fsize), fpUnpack(m, a, fsize), fpUnpack(m, b, fsize)
+	zeroTimesInfinity := x.class == fpInfinity && y.class == fpZero || x.class == fpZero && y.class == fpInfinity
+	result, ok := fpProcessNaNs3(m, addend, a, b, fsize)
+	if z.class == fpQuietNaN && zeroTimesInfinity {
+		return fpInvalid(m, fsize)
+	}
+	if ok {
+		return result
+	}
+	product := fpTerm{x.sign != y.sign, new(big.Int).Mul(x.term().mantissa, y.term().mantissa), x.exponent + y.exponent}
+	productInfinite := x.class == fpInfinity || y.class == fpInfinity
+	productZero := x.class == fpZero || y.class == fpZero
+	switch {
+	case zeroTimesInfinity || z.class == fpInfinity && productInfinite && z.sign != product.sign:
+		return fpInvalid(m, fsize)
+	case z.class == fpInfinity:
+		return fpInfinityBits(z.sign, fsize)
+	case productInfinite:
+		return fpInfinityBits(product.sign, fsize)
+	case z.class == fpZero && productZero && z.sign == product.sign:
+		return fpZeroBits(z.sign, fsize)
+	}
+	return fpRoundSum(m, z.term(), product, fsize)
+}
+
+// fpMax returns the larger of a and b, or the smaller if min is set, as FMAX and FMIN do: a NaN
+// operand gives a NaN result.  +0 is larger than -0.
+func fpMax(m *machine.Machine, a, b uint64, fsize int, min bool) uint64 {
+	x, y := fpUnpack(m, a, fsize), fpUnpack(m, b, fsize)
+	if result, ok := fpProcessNaNs(m, a, b, fsize); ok {
+		return result
+	}
+	v := y
+	if x.float64() > y.float64() != min && x.float64() != y.float64() {
+		v = x
+	}
+	switch v.class {
+	case fpInfinity:
+		return fpInfinityBits(v.sign, fsize)
+	case fpZero:
+		if min {
+			return fpZeroBits(x.sign || y.sign, fsize)
+		}
+		return fpZeroBits(x.sign && y.sign, fsize)
+	}
+	return fpRound(m, v.sign, v.mantissa, v.exponent, false, fsize)
+}
+
+// fpMaxNum returns the larger of a and b, or the smaller if min is set, as FMAXNM and FMINNM do: a
+// quiet NaN is only returned if both operands are NaNs, otherwise the number is.
+func fpMaxNum(m *machine.Machine, a, b uint64, fsize int, min bool) uint64 {
+	aQuiet, bQuiet := fpIsNaN(a, fsize) && !fpIsSignallingNaN(a, fsize), fpIsNaN(b, fsize) && !fpIsSignallingNaN(b, fsize)
+	// A single quiet NaN is replaced by the infinity that loses the comparison.
+	switch {
+	case aQuiet && !bQuiet:
+		a = fpInfinityBits(!min, fsize)
+	case bQuiet && !aQuiet:
+		b = fpInfinityBits(!min, fsize)
+	}
+	return fpMax(m, a, b, fsize, min)
+}
+
+// fpCompare compares a with b and returns the NZCV flags that FCMP sets: Z and C if they are
+// equal, N if a is less, C if it is greater and C and V if either is a NaN.  A signalling NaN
+// raises the Invalid Operation exception, as does a quiet NaN if signalling is set.
+func fpCompare(m *machine.Machine, a, b uint64, fsize int, signalling bool) uint32 {
+	x, y := fpUnpack(m, a, fsize), fpUnpack(m, b, fsize)
+	if fpIsNaN(a, fsize) || fpIsNaN(b, fsize) {
+		if signalling || fpIsSignallingNaN(a, fsize) || fpIsSignallingNaN(b, fsize) {
+			m.FPSR |= machine.FPSRIOC
+		}
+		return machine.FlagC | machine.FlagV
+	}
+	switch {
+	case x.float64() == y.float64():
+		return machine.FlagZ | machine.FlagC
+	case x.float64() < y.float64():
+		return machine.FlagN
+	}
+	return machine.FlagC
+}
+
+// fpToFloat64 returns the value of the fsize-bit floating-point number x, which is not a NaN.
+func fpToFloat64(x uint64, fsize int) float64 {
+	var m machine.Machine
+	return fpUnpack(&m, x, fsize).float64()
+}
+
+// fpExpandImm returns the fsize-bit floating-point number encoded by the 8-bit immediate of FMOV:
+// a sign bit, a 3-bit exponent and a 4-bit fraction.
+func fpExpandImm(imm8 uint32, fsize int) uint64 {
+	e, f := fpExponentBits(fsize), fsize-fpExponentBits(fsize)-1
+	b6 := uint64(imm8 >> 6 & 1)
+	exponent := (b6^1)<<(e-1) | (b6<<(e-3)-b6)<<2 | uint64(imm8>>4&0b11)
+	return uint64(imm8>>7&1)<<(fsize-1) | exponent<<f | uint64(imm8&0b1111)<<(f-4)
+}
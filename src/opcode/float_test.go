@@ -0,0 +1,403 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+const (
+	fpcrRP = fpRoundPlusInf << machine.FPCRRModeShift
+	fpcrRM = fpRoundMinusInf << machine.FPCRRModeShift
+	fpcrRZ = fpRoundTowardZero << machine.FPCRRModeShift
+)
+
+// TestFPVectors checks the floating-point core against vectors computed with exact arithmetic from
+// the Arm pseudocode.  The single and double precision vectors without NaNs or flushing to zero
+// also agree with an x86 FPU in the same rounding mode.
+func TestFPVectors(t *testing.T) {
+	for _, tc := range []struct {
+		op      string
+		fsize   int
+		fpcr    uint32
+		a, b, c uint64
+		want    uint64
+		fpsr    uint32
+	}{
+		// Tininess is detected before rounding, so a result that rounds up to the smallest normal
+		// number still underflows.
+		{"mul", 32, 0, 0x3f7fffff, 0x00800000, 0, 0x00800000, machine.FPSRIXC | machine.FPSRUFC},
+		{"mul", 32, fpcrRZ, 0x3f7fffff, 0x00800000, 0, 0x007fffff, machine.FPSRIXC | machine.FPSRUFC},
+		// A tiny result is flushed to zero without raising Inexact.
+		{"mul", 32, machine.FPCRFZ, 0x3f7fffff, 0x00800000, 0, 0, machine.FPSRUFC},
+		// FZ flushes denormal single precision inputs and raises Input Denormal; FZ16 flushes half
+		// precision ones silently.
+		{"add", 32, machine.FPCRFZ, 0x00000001, 0x3f800000, 0, 0x3f800000, machine.FPSRIDC},
+		{"add", 16, machine.FPCRFZ16, 0x0001, 0x3c00, 0, 0x3c00, 0},
+		{"add", 16, machine.FPCRFZ, 0x0001, 0x3c00, 0, 0x3c00, machine.FPSRIXC},
+		// A signalling NaN is quieted, or replaced by the default NaN with DN.
+		{"add", 64, 0, 0x7ff0000000000001, 0x3ff0000000000000, 0, 0x7ff8000000000001, machine.FPSRIOC},
+		{"add", 64, machine.FPCRDN, 0x7ff0000000000001, 0x3ff0000000000000, 0, 0x7ff8000000000000, machine.FPSRIOC},
+		// An exact zero sum is -0 only when rounding towards minus infinity.
+		{"sub", 32, fpcrRM, 0x3f800000, 0x3f800000, 0, 0x80000000, 0},
+		{"sub", 32, 0, 0x3f800000, 0x3f800000, 0, 0, 0},
+		{"fma", 64, fpcrRM, 0x3ff0000000000000, 0xbff0000000000000, 0x3ff0000000000000, 0x8000000000000000, 0},
+		// inf * 0 is invalid even when the addend is a quiet NaN.
+		{"fma", 32, 0, 0x7f800000, 0, 0x7fc00001, 0x7fc00000, machine.FPSRIOC},
+		{"div", 16, 0, 0x3c00, 0, 0, 0x7c00, machine.FPSRDZC},
+		{"sqrt", 64, 0, 0xbff0000000000000, 0, 0, 0x7ff8000000000000, machine.FPSRIOC},
+		// Overflow gives infinity or the largest normal number depending on the rounding mode.
+		{"mul", 16, fpcrRP, 0x7bff, 0x3c01, 0, 0x7c00, machine.FPSRIXC | machine.FPSROFC},
+		{"mul", 16, fpcrRZ, 0x7bff, 0x3c01, 0, 0x7bff, machine.FPSRIXC | machine.FPSROFC},
+		{"mul", 16, fpcrRM, 0x7bff, 0x3c01, 0, 0x7bff, machine.FPSRIXC | machine.FPSROFC},
+		{"maxnm", 32, 0, 0x7fc00000, 0xbf800000, 0, 0xbf800000, 0},
+		{"max", 32, 0, 0x7fc00000, 0xbf800000, 0, 0x7fc00000, 0},
+		{"min", 32, 0, 0x80000000, 0, 0, 0x80000000, 0},
+		{"max", 32, machine.FPCRFZ, 0x80000001, 0, 0, 0, machine.FPSRIDC},
+		{"cmp", 32, machine.FPCRFZ, 0x00000001, 0x80000000, 0, uint64(machine.FlagZ | machine.FlagC), machine.FPSRIDC},
+		{"cmpe", 64, 0, 0x7ff8000000000000, 0, 0, uint64(machine.FlagC | machine.FlagV), machine.FPSRIOC},
+
+		// Vectors sampled to cover each exception, rounding mode and FPCR setting for every operation.
+		{"add", 16, fpcrRP, 0xcf00, 0x2640, 0, 0xcefe, machine.FPSRIXC},
+		{"add", 16, fpcrRP | machine.FPCRFZ16, 0x033a, 0xbc00, 0, 0xbc00, 0},
+		{"add", 16, fpcrRM | machine.FPCRDN | machine.FPCRFZ, 0x7e00, 0x0171, 0, 0x7e00, 0},
+		{"add", 16, fpcrRZ, 0x0400, 0xbc8c, 0, 0xbc8b, machine.FPSRIXC},
+		{"add", 16, machine.FPCRDN | machine.FPCRFZ16, 0x6acf, 0x03ff, 0, 0x6acf, 0},
+		{"add", 16, fpcrRM, 0xc16a, 0x8140, 0, 0xc16b, machine.FPSRIXC},
+		{"add", 16, machine.FPCRFZ16, 0xf637, 0x54a5, 0, 0xf632, machine.FPSRIXC},
+		{"add", 16, fpcrRZ, 0xfbff, 0xfbfd, 0, 0xfbff, machine.FPSRIXC | machine.FPSROFC},
+		{"add", 16, fpcrRZ, 0x7c01, 0xee0d, 0, 0x7e01, machine.FPSRIOC},
+		{"add", 16, fpcrRZ | machine.FPCRFZ16, 0x0d08, 0x8d0a, 0, 0x8000, machine.FPSRUFC},
+		{"add", 32, machine.FPCRDN | machine.FPCRFZ16, 0x7d300000, 0x7d300003, 0, 0x7db00002, machine.FPSRIXC},
+		{"add", 32, fpcrRZ, 0x44080000, 0x002c9972, 0, 0x44080000, machine.FPSRIXC},
+		{"add", 32, fpcrRP, 0x0070b622, 0x8070b620, 0, 0x00000002, 0},
+		{"add", 32, machine.FPCRFZ, 0x8047074e, 0xff64fefd, 0, 0xff64fefd, machine.FPSRIDC},
+		{"add", 32, fpcrRP, 0xc2a46afd, 0x71200000, 0, 0x71200000, machine.FPSRIXC},
+		{"add", 32, fpcrRM, 0x60f62080, 0x8034db4e, 0, 0x60f6207f, machine.FPSRIXC},
+		{"add", 32, machine.FPCRDN | machine.FPCRFZ, 0xb3200000, 0, 0, 0xb3200000, 0},
+		{"add", 32, fpcrRP, 0xff800001, 0xa3800000, 0, 0xffc00001, machine.FPSRIOC},
+		{"add", 32, machine.FPCRFZ | machine.FPCRFZ16, 0x00b00000, 0x80b00003, 0, 0x80000000, machine.FPSRUFC},
+		{"add", 32, fpcrRP | machine.FPCRFZ16, 0x7f5cf970, 0x7f5cf970, 0, 0x7f800000, machine.FPSRIXC | machine.FPSROFC},
+		{"add", 32, fpcrRZ | machine.FPCRFZ, 0x7f800001, 0x8033a945, 0, 0x7fc00001, machine.FPSRIDC | machine.FPSRIOC},
+		{"add", 64, fpcrRZ, 0x7d5c776698b5658a, 0xa7f790bddf4a4248, 0, 0x7d5c776698b56589, machine.FPSRIXC},
+		{"add", 64, fpcrRM | machine.FPCRDN | machine.FPCRFZ, 0x9a1f6bea0549cef1, 0xd7c988d1e2509744, 0, 0xd7c988d1e2509745, machine.FPSRIXC},
+		{"add", 64, fpcrRM, 0x7fe41310dcc3aa27, 0x7fe41310dcc3aa26, 0, 0x7fefffffffffffff, machine.FPSRIXC | machine.FPSROFC},
+		{"add", 64, machine.FPCRDN | machine.FPCRFZ, 0x9557000000000000, 0x1557000000000003, 0, 0x1228000000000000, 0},
+		{"add", 64, machine.FPCRDN, 0x3ff0000000000000, 0xbff0000000000000, 0, 0, 0},
+		{"add", 64, fpcrRZ | machine.FPCRFZ | machine.FPCRFZ16, 0x8009e05338c41b6f, 0xc000381723d76a25, 0, 0xc000381723d76a25, machine.FPSRIDC},
+		{"add", 64, 0, 0x000d8947c4436f65, 0xffe29997b245d854, 0, 0xffe29997b245d854, machine.FPSRIXC},
+		{"add", 64, fpcrRP, 0xc04bdfaf7f513de0, 0x3ff470079483d677, 0, 0xc04b3c2f42ad1f2c, machine.FPSRIXC},
+		{"add", 64, machine.FPCRFZ, 0xfff0000000000001, 0x000a5b72145d2a25, 0, 0xfff8000000000001, machine.FPSRIDC | machine.FPSRIOC},
+		{"add", 64, fpcrRZ | machine.FPCRFZ16, 0xfff0000000000001, 0xfff0000000000000, 0, 0xfff8000000000001, machine.FPSRIOC},
+		{"add", 64, fpcrRP | machine.FPCRFZ, 0x8198000000000000, 0x0198000000000002, 0, 0, machine.FPSRUFC},
+		{"sub", 16, fpcrRM | machine.FPCRFZ, 0xb4a7, 0xefc0, 0, 0x6fbf, machine.FPSRIXC},
+		{"sub", 16, fpcrRP, 0x0001, 0x0002, 0, 0x8001, 0},
+		{"sub", 16, fpcrRP | machine.FPCRFZ16, 0x8000, 0x18c0, 0, 0x98c0, 0},
+		{"sub", 16, 0, 0xaa6d, 0x5896, 0, 0xd896, machine.FPSRIXC},
+		{"sub", 16, fpcrRZ | machine.FPCRFZ16, 0x0400, 0x0402, 0, 0x8000, machine.FPSRUFC},
+		{"sub", 16, fpcrRZ | machine.FPCRFZ, 0x8c3b, 0x7dd3, 0, 0x7fd3, machine.FPSRIOC},
+		{"sub", 16, fpcrRM | machine.FPCRDN | machine.FPCRFZ, 0x1a3e, 0x1a3d, 0, 0x0020, 0},
+		{"sub", 16, fpcrRZ, 0xb30d, 0x4740, 0, 0xc778, machine.FPSRIXC},
+		{"sub", 16, fpcrRP | machine.FPCRDN | machine.FPCRFZ, 0xf7b4, 0x648a, 0, 0xf7fc, machine.FPSRIXC},
+		{"sub", 16, machine.FPCRDN | machine.FPCRFZ16, 0xc934, 0x1568, 0, 0xc934, machine.FPSRIXC},
+		{"sub", 16, fpcrRP | machine.FPCRFZ16, 0x7bff, 0x8e9b, 0, 0x7c00, machine.FPSRIXC | machine.FPSROFC},
+		{"sub", 32, fpcrRP | machine.FPCRFZ | machine.FPCRFZ16, 0x35f80000, 0x8daefd8c, 0, 0x35f80001, machine.FPSRIXC},
+		{"sub", 32, fpcrRM | machine.FPCRFZ16, 0x8026fed0, 0x0f0e43b7, 0, 0x8f0e43b8, machine.FPSRIXC},
+		{"sub", 32, fpcrRP | machine.FPCRDN, 0xbce2a932, 0xff7fffff, 0, 0x7f7fffff, machine.FPSRIXC},
+		{"sub", 32, fpcrRM | machine.FPCRFZ, 0x3fe55ce2, 0, 0, 0x3fe55ce2, 0},
+		{"sub", 32, 0, 0xbc5dca09, 0xfae80000, 0, 0x7ae80000, machine.FPSRIXC},
+		{"sub", 32, fpcrRM | machine.FPCRFZ, 0x80472718, 0x0347e2fe, 0, 0x8347e2fe, machine.FPSRIDC},
+		{"sub", 32, fpcrRZ | machine.FPCRDN | machine.FPCRFZ, 0x38ef43c1, 0x8068b26f, 0, 0x38ef43c1, machine.FPSRIDC},
+		{"sub", 32, fpcrRZ | machine.FPCRFZ16, 0x6de2e3b9, 0xcc99b50d, 0, 0x6de2e3b9, machine.FPSRIXC},
+		{"sub", 32, fpcrRZ | machine.FPCRDN | machine.FPCRFZ, 0xfe200000, 0x7f800001, 0, 0x7fc00000, machine.FPSRIOC},
+		{"sub", 32, fpcrRP | machine.FPCRFZ | machine.FPCRFZ16, 0x7f7fffff, 0xff7ffffe, 0, 0x7f800000, machine.FPSRIXC | machine.FPSROFC},
+		{"sub", 32, fpcrRZ | machine.FPCRFZ, 0x017b2450, 0x019185f0, 0, 0x80000000, machine.FPSRUFC},
+		{"sub", 32, machine.FPCRDN | machine.FPCRFZ, 0xff800001, 0x002aef9f, 0, 0x7fc00000, machine.FPSRIDC | machine.FPSRIOC},
+		{"sub", 64, machine.FPCRDN, 0x803817af91907de3, 0x3e3e98f5ae950a7b, 0, 0xbe3e98f5ae950a7b, machine.FPSRIXC},
+		{"sub", 64, fpcrRM | machine.FPCRDN | machine.FPCRFZ, 0x4d5e000000000000, 0x0c9a000000000000, 0, 0x4d5dffffffffffff, machine.FPSRIXC},
+		{"sub", 64, fpcrRZ, 0x4005ec58a82f81d8, 0x32bb000000000000, 0, 0x4005ec58a82f81d7, machine.FPSRIXC},
+		{"sub", 64, fpcrRM, 0x36bed3846686c712, 0xb6bed3846686c710, 0, 0x36ced3846686c711, 0},
+		{"sub", 64, fpcrRP, 0xb4e8000000000000, 0xc0222c088b398110, 0, 0x40222c088b398110, machine.FPSRIXC},
+		{"sub", 64, machine.FPCRFZ, 0xad5b21c081580e12, 0x2d5b21c081580e11, 0, 0xad6b21c081580e12, machine.FPSRIXC},
+		{"sub", 64, fpcrRZ | machine.FPCRFZ | machine.FPCRFZ16, 0x0003113d88961040, 0x00099a41592c3a9d, 0, 0, machine.FPSRIDC},
+		{"sub", 64, fpcrRM | machine.FPCRFZ, 0xfff0000000000001, 0x0010000000000000, 0, 0xfff8000000000001, machine.FPSRIOC},
+		{"sub", 64, fpcrRP, 0x7fefffffffffffff, 0x8003c2dc05ecfc65, 0, 0x7ff0000000000000, machine.FPSRIXC | machine.FPSROFC},
+		{"sub", 64, fpcrRM | machine.FPCRFZ, 0xfff0000000000001, 0x80062ffc46a1b594, 0, 0xfff8000000000001, machine.FPSRIDC | machine.FPSRIOC},
+		{"sub", 64, machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0x80276ac7791c6f48, 0x80276ac7791c6f49, 0, 0, machine.FPSRUFC},
+		{"mul", 16, fpcrRP | machine.FPCRDN, 0x0292, 0xe513, 0, 0xaa85, machine.FPSRIXC},
+		{"mul", 16, machine.FPCRDN | machine.FPCRFZ16, 0xd0d1, 0xa47a, 0, 0x3964, machine.FPSRIXC},
+		{"mul", 16, fpcrRM | machine.FPCRFZ16, 0x4304, 0x825b, 0, 0x8000, 0},
+		{"mul", 16, fpcrRZ | machine.FPCRDN, 0x83aa, 0xc3cf, 0, 0x0b27, machine.FPSRIXC},
+		{"mul", 16, fpcrRM | machine.FPCRDN | machine.FPCRFZ, 0x794d, 0xb1e4, 0, 0xefcf, machine.FPSRIXC},
+		{"mul", 16, fpcrRZ | machine.FPCRFZ, 0xccc2, 0xd77d, 0, 0x6874, machine.FPSRIXC},
+		{"mul", 16, machine.FPCRFZ16, 0x78f5, 0x4046, 0, 0x7c00, machine.FPSRIXC | machine.FPSROFC},
+		{"mul", 16, machine.FPCRFZ16, 0x8acf, 0x3276, 0, 0x8000, machine.FPSRUFC},
+		{"mul", 16, machine.FPCRFZ, 0x8001, 0x3bd4, 0, 0x8001, machine.FPSRIXC | machine.FPSRUFC},
+		{"mul", 16, fpcrRP, 0xb5d5, 0x7d17, 0, 0x7f17, machine.FPSRIOC},
+		{"mul", 32, fpcrRZ | machine.FPCRDN, 0x289f8bb5, 0xf3a00000, 0, 0xdcc76ea2, machine.FPSRIXC},
+		{"mul", 32, machine.FPCRFZ16, 0xd930f3dc, 0xff7fffff, 0, 0x7f800000, machine.FPSRIXC | machine.FPSROFC},
+		{"mul", 32, 0, 0x3c877816, 0x80578166, 0, 0x80017272, machine.FPSRIXC | machine.FPSRUFC},
+		{"mul", 32, fpcrRM, 0x27000000, 0xff800001, 0, 0xffc00001, machine.FPSRIOC},
+		{"mul", 32, fpcrRM, 0xce4b26a4, 0x05f39d92, 0, 0x94c152b9, machine.FPSRIXC},
+		{"mul", 32, fpcrRM | machine.FPCRFZ, 0xb8900000, 0xcdc55b14, 0, 0x46de0676, machine.FPSRIXC},
+		{"mul", 32, fpcrRP | machine.FPCRFZ16, 0x7fc00000, 0x1cf00000, 0, 0x7fc00000, 0},
+		{"mul", 32, fpcrRP | machine.FPCRFZ, 0xfe780000, 0x7f1a4bf3, 0, 0xff7fffff, machine.FPSRIXC | machine.FPSROFC},
+		{"mul", 32, machine.FPCRFZ | machine.FPCRFZ16, 0x01a9ec88, 0x004a0aa5, 0, 0, machine.FPSRIDC},
+		{"mul", 32, machine.FPCRDN | machine.FPCRFZ, 0xa55bc44f, 0xbef3026b, 0, 0x24d09d6a, machine.FPSRIXC},
+		{"mul", 32, fpcrRP | machine.FPCRFZ, 0x2f20b984, 0x81d455db, 0, 0x80000000, machine.FPSRUFC},
+		{"mul", 32, machine.FPCRFZ, 0x0028690f, 0x7f800001, 0, 0x7fc00001, machine.FPSRIDC | machine.FPSRIOC},
+		{"mul", 64, fpcrRZ | machine.FPCRDN, 0x1ee5d498c5e048a9, 0xc06ff42dc163267f, 0, 0x9f65cc88437ac836, machine.FPSRIXC},
+		{"mul", 64, fpcrRP, 0x5b67000000000000, 0x80aeca89661babf6, 0, 0x9c262192c163e398, machine.FPSRIXC},
+		{"mul", 64, fpcrRP, 0xf40e000000000000, 0xffec6c7a4cf74029, 0, 0x7ff0000000000000, machine.FPSRIXC | machine.FPSROFC},
+		{"mul", 64, fpcrRP | machine.FPCRFZ16, 0x800fffffffffffff, 0x868848da647251ef, 0, 0x0000000000000001, machine.FPSRIXC | machine.FPSRUFC},
+		{"mul", 64, machine.FPCRDN | machine.FPCRFZ, 0x56f6000000000000, 0xf2d748d0ea628bf2, 0, 0xfff0000000000000, machine.FPSRIXC | machine.FPSROFC},
+		{"mul", 64, fpcrRM | machine.FPCRFZ | machine.FPCRFZ16, 0x19dd000000000000, 0x7ff8000000000000, 0, 0x7ff8000000000000, 0},
+		{"mul", 64, fpcrRP | machine.FPCRDN | machine.FPCRFZ, 0x8000f6f4a42c1676, 0x075d6ee9257d8058, 0, 0x8000000000000000, machine.FPSRIDC},
+		{"mul", 64, fpcrRM, 0x4041a5a909b1ee03, 0xffe50512e6942d29, 0, 0xfff0000000000000, machine.FPSRIXC | machine.FPSROFC},
+		{"mul", 64, fpcrRP | machine.FPCRFZ, 0x00162a148fc72e66, 0xbfa3fd8a6c8b68ce, 0, 0x8000000000000000, machine.FPSRUFC},
+		{"mul", 64, 0, 0x7ff0000000000001, 0xc7e519c10fb0f289, 0, 0x7ff8000000000001, machine.FPSRIOC},
+		{"mul", 64, fpcrRZ | machine.FPCRFZ, 0x7ff0000000000001, 0x000766d401d6e95e, 0, 0x7ff8000000000001, machine.FPSRIDC | machine.FPSRIOC},
+		{"div", 16, fpcrRM, 0x89ee, 0x030e, 0, 0xc3c4, machine.FPSRIXC},
+		{"div", 16, machine.FPCRDN, 0x5128, 0x8909, 0, 0xfc00, machine.FPSRIXC | machine.FPSROFC},
+		{"div", 16, fpcrRZ | machine.FPCRFZ | machine.FPCRFZ16, 0x5ee6, 0xde88, 0, 0xbc39, machine.FPSRIXC},
+		{"div", 16, fpcrRP | machine.FPCRFZ, 0x02e3, 0xac3b, 0, 0x9175, machine.FPSRIXC},
+		{"div", 16, fpcrRP | machine.FPCRFZ16, 0x0001, 0xbe21, 0, 0x8000, 0},
+		{"div", 16, fpcrRM | machine.FPCRDN | machine.FPCRFZ16, 0x0519, 0x20fa, 0, 0x2018, machine.FPSRIXC},
+		{"div", 16, fpcrRZ | machine.FPCRFZ16, 0x9540, 0xd6f0, 0, 0, machine.FPSRUFC},
+		{"div", 16, fpcrRM | machine.FPCRDN | machine.FPCRFZ, 0xf7fe, 0, 0, 0xfc00, machine.FPSRDZC},
+		{"div", 16, fpcrRZ | machine.FPCRDN | machine.FPCRFZ16, 0x8b9d, 0x7c01, 0, 0x7e00, machine.FPSRIOC},
+		{"div", 16, fpcrRP, 0x01bf, 0xfb10, 0, 0x8000, machine.FPSRIXC | machine.FPSRUFC},
+		{"div", 32, fpcrRM, 0x7f70c673, 0xbf24f373, 0, 0xff800000, machine.FPSRIXC | machine.FPSROFC},
+		{"div", 32, fpcrRP | machine.FPCRFZ, 0xd957be4d, 0xfd08f84a, 0, 0x1bc99d62, machine.FPSRIXC},
+		{"div", 32, fpcrRZ | machine.FPCRFZ16, 0x29880000, 0x3de51532, 0, 0x2b17fae0, machine.FPSRIXC},
+		{"div", 32, fpcrRZ | machine.FPCRFZ16, 0x019d12d7, 0xff7fffff, 0, 0x80000000, machine.FPSRIXC | machine.FPSRUFC},
+		{"div", 32, 0, 0x3d4f0208, 0xbd2113b5, 0, 0xbfa47fc4, machine.FPSRIXC},
+		{"div", 32, fpcrRM | machine.FPCRFZ, 0xff7fffff, 0x00424ce3, 0, 0xff800000, machine.FPSRIDC | machine.FPSRDZC},
+		{"div", 32, fpcrRM | machine.FPCRFZ | machine.FPCRFZ16, 0x004bbc3b, 0xea8bb3a6, 0, 0x80000000, machine.FPSRIDC},
+		{"div", 32, fpcrRZ | machine.FPCRDN, 0x47e32a63, 0x8037db96, 0, 0xff7fffff, machine.FPSRIXC | machine.FPSROFC},
+		{"div", 32, fpcrRM | machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0x5e8ee7a6, 0x25366371, 0, 0x78c894b9, machine.FPSRIXC},
+		{"div", 32, fpcrRP, 0xc193c4ce, 0xff800000, 0, 0, 0},
+		{"div", 32, fpcrRM | machine.FPCRFZ16, 0xc2883ba5, 0xff800001, 0, 0xffc00001, machine.FPSRIOC},
+		{"div", 32, fpcrRM | machine.FPCRFZ, 0x0028d722, 0x7f800001, 0, 0x7fc00001, machine.FPSRIDC | machine.FPSRIOC},
+		{"div", 32, fpcrRZ | machine.FPCRFZ, 0x00800000, 0xc2d5a959, 0, 0x80000000, machine.FPSRUFC},
+		{"div", 32, fpcrRP, 0x4d080000, 0, 0, 0x7f800000, machine.FPSRDZC},
+		{"div", 64, fpcrRP | machine.FPCRFZ16, 0x00166b73322c5cc3, 0x00f8000000000000, 0, 0x3f0de499983b265a, machine.FPSRIXC},
+		{"div", 64, machine.FPCRFZ16, 0x76cbc4bfad01e23d, 0x3fd25a7daeebf3b9, 0, 0x76e835304bb13b5e, machine.FPSRIXC},
+		{"div", 64, fpcrRM, 0xcf70000000000000, 0x7fefffffffffffff, 0, 0x8f70000000000001, machine.FPSRIXC},
+		{"div", 64, machine.FPCRFZ, 0xc001871ec3ff9f65, 0x333c000000000000, 0, 0xccb40823292423e1, machine.FPSRIXC},
+		{"div", 64, machine.FPCRDN, 0x86c3778ea41d5dc2, 0x52e6ed43c5c1606c, 0, 0x8000000000000000, machine.FPSRIXC | machine.FPSRUFC},
+		{"div", 64, 0, 0xe413000000000000, 0xa1e23f0992a0f514, 0, 0x7ff0000000000000, machine.FPSRIXC | machine.FPSROFC},
+		{"div", 64, machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0x3fa48ab3179ab4ed, 0xe3e3000000000000, 0, 0x9bb14c60eb74ce41, machine.FPSRIXC},
+		{"div", 64, fpcrRP | machine.FPCRDN, 0xfff8000000000003, 0xe690000000000000, 0, 0x7ff8000000000000, 0},
+		{"div", 64, fpcrRZ | machine.FPCRFZ16, 0x000ba192dbcfeb4c, 0xffefffffffffffff, 0, 0x8000000000000000, machine.FPSRIXC | machine.FPSRUFC},
+		{"div", 64, fpcrRZ | machine.FPCRFZ, 0x78c59572a66aad12, 0x000737d5fb9c6cbc, 0, 0x7ff0000000000000, machine.FPSRIDC | machine.FPSRDZC},
+		{"div", 64, fpcrRZ | machine.FPCRFZ, 0x800f9fdc6fda55bb, 0x800993914859800d, 0, 0x7ff8000000000000, machine.FPSRIDC | machine.FPSRIOC},
+		{"div", 64, fpcrRZ | machine.FPCRFZ | machine.FPCRFZ16, 0x8002dd9cce98e96e, 0x001b5f33b49eebfe, 0, 0x8000000000000000, machine.FPSRIDC},
+		{"div", 64, fpcrRP | machine.FPCRFZ | machine.FPCRFZ16, 0xbfc345c9793ec476, 0xffdf2852fda171e3, 0, 0, machine.FPSRUFC},
+		{"div", 64, machine.FPCRFZ16, 0x3fb5eec114b7dc5f, 0xfff0000000000001, 0, 0xfff8000000000001, machine.FPSRIOC},
+		{"div", 64, fpcrRM | machine.FPCRFZ | machine.FPCRFZ16, 0xbff8aa76a3e300b7, 0, 0, 0xfff0000000000000, machine.FPSRDZC},
+		{"sqrt", 16, fpcrRP | machine.FPCRFZ | machine.FPCRFZ16, 0x4336, 0x7724, 0, 0x3f99, machine.FPSRIXC},
+		{"sqrt", 16, fpcrRZ | machine.FPCRFZ | machine.FPCRFZ16, 0x48b4, 0x3fb6, 0, 0x4222, machine.FPSRIXC},
+		{"sqrt", 16, fpcrRM | machine.FPCRFZ16, 0x02a1, 0x3313, 0, 0, 0},
+		{"sqrt", 16, fpcrRM | machine.FPCRFZ, 0x531e, 0x7bff, 0, 0x478b, machine.FPSRIXC},
+		{"sqrt", 16, fpcrRP, 0xa1c8, 0x3c00, 0, 0x7e00, machine.FPSRIOC},
+		{"sqrt", 16, fpcrRM | machine.FPCRDN | machine.FPCRFZ16, 0xf080, 0xd9cf, 0, 0x7e00, machine.FPSRIOC},
+		{"sqrt", 16, machine.FPCRDN, 0xdc3f, 0x7c92, 0, 0x7e00, machine.FPSRIOC},
+		{"sqrt", 16, 0, 0x6840, 0x05a0, 0, 0x51d5, machine.FPSRIXC},
+		{"sqrt", 32, fpcrRM | machine.FPCRFZ16, 0x80000001, 0x42437256, 0, 0x7fc00000, machine.FPSRIOC},
+		{"sqrt", 32, machine.FPCRFZ | machine.FPCRFZ16, 0x3fc677c6, 0xc1b45e58, 0, 0x3f9f62cf, machine.FPSRIXC},
+		{"sqrt", 32, fpcrRP | machine.FPCRFZ16, 0x19a80000, 0xff49f0f7, 0, 0x2c92a476, machine.FPSRIXC},
+		{"sqrt", 32, machine.FPCRDN | machine.FPCRFZ16, 0x81731c4a, 0xd07be3d6, 0, 0x7fc00000, machine.FPSRIOC},
+		{"sqrt", 32, fpcrRM | machine.FPCRFZ16, 0x00000001, 0x3f800000, 0, 0x1a3504f3, machine.FPSRIXC},
+		{"sqrt", 32, fpcrRZ | machine.FPCRDN, 0x00289fc2, 0xe8a80000, 0, 0x1f90386a, machine.FPSRIXC},
+		{"sqrt", 32, machine.FPCRDN | machine.FPCRFZ, 0x3c907fb8, 0xfb280000, 0, 0x3e07ffde, machine.FPSRIXC},
+		{"sqrt", 32, fpcrRP | machine.FPCRDN, 0x3f800000, 0x81f24734, 0, 0x3f800000, 0},
+		{"sqrt", 32, fpcrRP | machine.FPCRFZ | machine.FPCRFZ16, 0x004b47e7, 0x0034a2ad, 0, 0, machine.FPSRIDC},
+		{"sqrt", 64, machine.FPCRFZ16, 0xbd0f000000000000, 0x3fb34f63a3584983, 0, 0x7ff8000000000000, machine.FPSRIOC},
+		{"sqrt", 64, fpcrRM | machine.FPCRDN, 0x1cea8177e4dd6674, 0x3fac95b8b0db288b, 0, 0x2e6d1fa3a5cf0943, machine.FPSRIXC},
+		{"sqrt", 64, fpcrRZ | machine.FPCRFZ, 0x000d227b03386b69, 0xbf9c578b142dce9c, 0, 0, machine.FPSRIDC},
+		{"sqrt", 64, fpcrRP, 0x00105cf617e1206c, 0x000cf13dd1218df1, 0, 0x20002e3849b997ee, machine.FPSRIXC},
+		{"sqrt", 64, 0, 0x503eb3d6fdfdbd06, 0x4d35ac5f0a76dc16, 0, 0x481629f79aed14a6, machine.FPSRIXC},
+		{"sqrt", 64, fpcrRZ, 0x6f1888e927dcd044, 0x25d310a5131d9449, 0, 0x5783d023ffd1163e, machine.FPSRIXC},
+		{"sqrt", 64, fpcrRP | machine.FPCRDN | machine.FPCRFZ, 0xfff0000000000001, 0xb0e0000000000000, 0, 0x7ff8000000000000, machine.FPSRIOC},
+		{"sqrt", 64, 0, 0x8000000000000000, 0x800a9bccd5603629, 0, 0x8000000000000000, 0},
+		{"fma", 16, fpcrRM | machine.FPCRFZ | machine.FPCRFZ16, 0x8178, 0xfbe8, 0xf900, 0xf900, 0},
+		{"fma", 16, 0, 0xffaa, 0x02c1, 0xdb15, 0xffaa, 0},
+		{"fma", 16, machine.FPCRFZ, 0x07a7, 0x830d, 0x62c0, 0x62c0, machine.FPSRIXC},
+		{"fma", 16, fpcrRZ | machine.FPCRDN, 0xbfe3, 0xfbed, 0xfc02, 0x7e00, machine.FPSRIOC},
+		{"fma", 16, fpcrRM | machine.FPCRFZ, 0xf940, 0x03a8, 0x1460, 0xc0cc, machine.FPSRIXC},
+		{"fma", 16, fpcrRM | machine.FPCRDN | machine.FPCRFZ, 0x1ac0, 0x8160, 0, 0x8002, machine.FPSRIXC | machine.FPSRUFC},
+		{"fma", 16, fpcrRP, 0x8325, 0xe244, 0x0001, 0x28ed, machine.FPSRIXC},
+		{"fma", 16, machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0x1527, 0x4cbd, 0x8db3, 0x2604, machine.FPSRIXC},
+		{"fma", 16, fpcrRZ, 0x4c49, 0x8541, 0x15a1, 0x0001, machine.FPSRIXC | machine.FPSRUFC},
+		{"fma", 16, fpcrRZ | machine.FPCRDN | machine.FPCRFZ16, 0x04d6, 0x43e7, 0x8cc7, 0x8000, machine.FPSRUFC},
+		{"fma", 16, machine.FPCRFZ | machine.FPCRFZ16, 0x7918, 0x750d, 0xcf80, 0x7c00, machine.FPSRIXC | machine.FPSROFC},
+		{"fma", 32, fpcrRM | machine.FPCRDN | machine.FPCRFZ, 0x6dd80000, 0x7f7fffff, 0x7fc00003, 0x7fc00000, 0},
+		{"fma", 32, fpcrRZ | machine.FPCRFZ | machine.FPCRFZ16, 0x40da6081, 0x3e363b8d, 0xeb580000, 0xeb57ffff, machine.FPSRIXC},
+		{"fma", 32, fpcrRM | machine.FPCRFZ16, 0x3daa8db4, 0x49f3ebf2, 0x801a644f, 0x482281b3, machine.FPSRIXC},
+		{"fma", 32, fpcrRM, 0x001d5abf, 0x80075ca3, 0, 0x80000001, machine.FPSRIXC | machine.FPSRUFC},
+		{"fma", 32, machine.FPCRFZ, 0xff800001, 0x809e1827, 0x32600000, 0xffc00001, machine.FPSRIOC},
+		{"fma", 32, machine.FPCRDN | machine.FPCRFZ, 0xaa32d236, 0x0059a6bd, 0x3cc10607, 0x3cc10607, machine.FPSRIDC},
+		{"fma", 32, 0, 0x465b7d1d, 0xf01cc8b6, 0x0137a4af, 0xf7066c53, machine.FPSRIXC},
+		{"fma", 32, fpcrRP, 0x804914e4, 0x3fb48657, 0x00671216, 0x00000002, machine.FPSRIXC | machine.FPSRUFC},
+		{"fma", 32, machine.FPCRDN, 0xc7600000, 0x011f7e32, 0x95900000, 0x95900000, machine.FPSRIXC},
+		{"fma", 32, fpcrRZ | machine.FPCRFZ, 0xee604f6f, 0xfc600000, 0xfed755f8, 0x7f7fffff, machine.FPSRIXC | machine.FPSROFC},
+		{"fma", 32, fpcrRM | machine.FPCRFZ, 0x814ade77, 0xc64956a1, 0x881f8d52, 0x80000000, machine.FPSRUFC},
+		{"fma", 32, machine.FPCRFZ, 0x8066a81b, 0xff800000, 0xfe816461, 0x7fc00000, machine.FPSRIDC | machine.FPSRIOC},
+		{"fma", 32, fpcrRM | machine.FPCRFZ, 0xff7fffff, 0xc1814cc8, 0x807b5c63, 0x7f7fffff, machine.FPSRIDC | machine.FPSRIXC | machine.FPSROFC},
+		{"fma", 32, fpcrRM | machine.FPCRFZ, 0xf0ec8d06, 0x16961301, 0x806a3cfd, 0xc80aac31, machine.FPSRIDC | machine.FPSRIXC},
+		{"fma", 32, fpcrRM | machine.FPCRFZ, 0x82780000, 0x25b00000, 0x00000002, 0x80000000, machine.FPSRIDC | machine.FPSRUFC},
+		{"fma", 64, fpcrRM | machine.FPCRDN, 0x80090185cf640d7f, 0x801ee2d18dfe123d, 0xa9f8000000000000, 0xa9f8000000000000, machine.FPSRIXC},
+		{"fma", 64, fpcrRZ | machine.FPCRDN | machine.FPCRFZ16, 0xaf14594615630f27, 0x8010000000000000, 0xc0682dda12264843, 0xc0682dda12264842, machine.FPSRIXC},
+		{"fma", 64, machine.FPCRFZ | machine.FPCRFZ16, 0x1d7a000000000000, 0x1dc823c5430fc7ca, 0x8000000000000000, 0, machine.FPSRUFC},
+		{"fma", 64, fpcrRM, 0xc04e01cc2b2e3b5b, 0xbfb82bb287662156, 0x3fb2a3f10d84fec5, 0x4016f4e2b7e9d123, machine.FPSRIXC},
+		{"fma", 64, fpcrRM, 0x000cea4a481fed8e, 0x40371cd9b9eb2dae, 0x8052a814894e6073, 0x800000000000001d, machine.FPSRIXC | machine.FPSRUFC},
+		{"fma", 64, fpcrRP, 0xf41ee83bc020f7c3, 0xe1495964ada56ac2, 0x58868e172fe66aa6, 0x7ff0000000000000, machine.FPSRIXC | machine.FPSROFC},
+		{"fma", 64, fpcrRP | machine.FPCRFZ16, 0x85f5000000000000, 0x9425b2455bcfe798, 0xfff0000000000000, 0xfff0000000000000, 0},
+		{"fma", 64, fpcrRZ | machine.FPCRFZ | machine.FPCRFZ16, 0x0008669013b54ba7, 0x2163000000000000, 0x8000000000000000, 0, machine.FPSRIDC},
+		{"fma", 64, machine.FPCRFZ16, 0x2c13d14f0c4af43b, 0x5b9ceeca0f652ff5, 0xc0788d02f154da25, 0x47c1eb070978c828, machine.FPSRIXC},
+		{"fma", 64, machine.FPCRDN | machine.FPCRFZ, 0xffefffffffffffff, 0xce7f000000000000, 0xbfde000000000000, 0x7ff0000000000000, machine.FPSRIXC | machine.FPSROFC},
+		{"fma", 64, fpcrRZ, 0x6b40000000000000, 0x7fef1ebcf6a72da0, 0xfff0000000000002, 0xfff8000000000002, machine.FPSRIOC},
+		{"fma", 64, machine.FPCRFZ | machine.FPCRFZ16, 0xe8173e7165a263b2, 0x307d9fec42d8efb5, 0x800fffffffffffff, 0xd8a584c0a49ed443, machine.FPSRIDC | machine.FPSRIXC},
+		{"fma", 64, fpcrRM | machine.FPCRDN | machine.FPCRFZ, 0x0016a2f755352a87, 0x2054cf9a31aebbe9, 0x8000000000000002, 0, machine.FPSRIDC | machine.FPSRUFC},
+		{"fma", 64, fpcrRM | machine.FPCRFZ | machine.FPCRFZ16, 0xfff0000000000001, 0x00023aa51a07f14f, 0x7ff8000000000003, 0xfff8000000000001, machine.FPSRIDC | machine.FPSRIOC},
+		{"fma", 64, machine.FPCRFZ | machine.FPCRFZ16, 0x7fed785e82dda5bd, 0x406296251919ed9f, 0x00095d02cf038454, 0x7ff0000000000000, machine.FPSRIDC | machine.FPSRIXC | machine.FPSROFC},
+		{"max", 16, fpcrRP | machine.FPCRFZ16, 0x0e10, 0xb980, 0, 0x0e10, 0},
+		{"max", 16, fpcrRZ, 0x83ff, 0x00c3, 0, 0x00c3, 0},
+		{"max", 16, machine.FPCRDN, 0x2af8, 0x8208, 0, 0x2af8, 0},
+		{"max", 16, fpcrRZ | machine.FPCRDN | machine.FPCRFZ16, 0xde85, 0xfc00, 0, 0xde85, 0},
+		{"max", 16, fpcrRZ, 0xebc0, 0x7c35, 0, 0x7e35, machine.FPSRIOC},
+		{"max", 32, fpcrRP, 0xacdf7326, 0xb42934b6, 0, 0xacdf7326, 0},
+		{"max", 32, fpcrRZ | machine.FPCRDN, 0x8d588390, 0xbd6f0e6a, 0, 0x8d588390, 0},
+		{"max", 32, fpcrRP | machine.FPCRFZ, 0xc78e1f06, 0x7f74bdaa, 0, 0x7f74bdaa, 0},
+		{"max", 32, machine.FPCRFZ, 0x001b176f, 0xbf800000, 0, 0, machine.FPSRIDC},
+		{"max", 32, fpcrRP | machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0x5462aacb, 0xa5400000, 0, 0x5462aacb, 0},
+		{"max", 32, fpcrRP | machine.FPCRFZ, 0xff800001, 0xc05ab9d7, 0, 0xffc00001, machine.FPSRIOC},
+		{"max", 32, fpcrRP | machine.FPCRFZ, 0x7f800001, 0x004ac158, 0, 0x7fc00001, machine.FPSRIDC | machine.FPSRIOC},
+		{"max", 64, fpcrRM | machine.FPCRDN | machine.FPCRFZ, 0x37f04acda4e1565e, 0x0007de516ec61abf, 0, 0x37f04acda4e1565e, machine.FPSRIDC},
+		{"max", 64, fpcrRP, 0x000abcf9120fb541, 0x3fe72be7da772e05, 0, 0x3fe72be7da772e05, 0},
+		{"max", 64, fpcrRZ | machine.FPCRFZ, 0xbff0000000000000, 0x8000000000000001, 0, 0x8000000000000000, machine.FPSRIDC},
+		{"max", 64, fpcrRZ | machine.FPCRFZ, 0x36ea000000000000, 0x7ff0000000000001, 0, 0x7ff8000000000001, machine.FPSRIOC},
+		{"max", 64, machine.FPCRDN, 0x0000000000000001, 0x3ffc9dd00d7ebf7c, 0, 0x3ffc9dd00d7ebf7c, 0},
+		{"max", 64, machine.FPCRFZ | machine.FPCRFZ16, 0x7ff0000000000001, 0x8009b7f3d572bb62, 0, 0x7ff8000000000001, machine.FPSRIDC | machine.FPSRIOC},
+		{"min", 16, fpcrRZ | machine.FPCRDN, 0xf580, 0xae7a, 0, 0xf580, 0},
+		{"min", 16, fpcrRM | machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0xff15, 0x77ef, 0, 0x7e00, 0},
+		{"min", 16, fpcrRM, 0xfe03, 0xc5e4, 0, 0xfe03, 0},
+		{"min", 16, fpcrRP | machine.FPCRFZ16, 0x3700, 0xd4c8, 0, 0xd4c8, 0},
+		{"min", 16, fpcrRP, 0x7e00, 0xfc01, 0, 0xfe01, machine.FPSRIOC},
+		{"min", 32, 0, 0x04400000, 0x44370256, 0, 0x04400000, 0},
+		{"min", 32, fpcrRZ | machine.FPCRDN, 0x3fb16035, 0x81d3b425, 0, 0x81d3b425, 0},
+		{"min", 32, fpcrRM | machine.FPCRFZ, 0x8000d138, 0xb62b5c83, 0, 0xb62b5c83, machine.FPSRIDC},
+		{"min", 32, fpcrRZ | machine.FPCRDN | machine.FPCRFZ, 0x004a7d56, 0x3b000000, 0, 0, machine.FPSRIDC},
+		{"min", 32, fpcrRP | machine.FPCRFZ16, 0xc3d3f9e1, 0x7f800001, 0, 0x7fc00001, machine.FPSRIOC},
+		{"min", 32, machine.FPCRFZ | machine.FPCRFZ16, 0x006e1215, 0xff800001, 0, 0xffc00001, machine.FPSRIDC | machine.FPSRIOC},
+		{"min", 64, fpcrRP | machine.FPCRDN | machine.FPCRFZ16, 0x98cd244632893ba6, 0x0026b480977bda7b, 0, 0x98cd244632893ba6, 0},
+		{"min", 64, fpcrRM | machine.FPCRFZ16, 0x800dd3716874d8e0, 0xf84d000000000000, 0, 0xf84d000000000000, 0},
+		{"min", 64, fpcrRP | machine.FPCRFZ, 0x000b7e97491a9882, 0x00078308291fba99, 0, 0, machine.FPSRIDC},
+		{"min", 64, fpcrRZ | machine.FPCRDN | machine.FPCRFZ, 0x3f5476d1e03452cc, 0x8000000000000000, 0, 0x8000000000000000, 0},
+		{"min", 64, fpcrRZ, 0x001165972b867d10, 0x7ff0000000000001, 0, 0x7ff8000000000001, machine.FPSRIOC},
+		{"min", 64, fpcrRZ | machine.FPCRFZ | machine.FPCRFZ16, 0x00065dad88698de4, 0xfff0000000000001, 0, 0xfff8000000000001, machine.FPSRIDC | machine.FPSRIOC},
+		{"maxnm", 16, fpcrRM | machine.FPCRDN, 0x0d9a, 0xad6b, 0, 0x0d9a, 0},
+		{"maxnm", 16, fpcrRZ, 0x77bb, 0xb14a, 0, 0x77bb, 0},
+		{"maxnm", 16, fpcrRZ | machine.FPCRFZ | machine.FPCRFZ16, 0x0483, 0x7bff, 0, 0x7bff, 0},
+		{"maxnm", 16, fpcrRM | machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0xfbff, 0x8bcb, 0, 0x8bcb, 0},
+		{"maxnm", 16, machine.FPCRFZ, 0xc342, 0x7cd6, 0, 0x7ed6, machine.FPSRIOC},
+		{"maxnm", 32, machine.FPCRFZ, 0xc1600000, 0x5d000000, 0, 0x5d000000, 0},
+		{"maxnm", 32, fpcrRP | machine.FPCRFZ16, 0x1c4fce22, 0x804c9b98, 0, 0x1c4fce22, 0},
+		{"maxnm", 32, fpcrRZ | machine.FPCRDN | machine.FPCRFZ16, 0x3c2409b7, 0xc0379f5d, 0, 0x3c2409b7, 0},
+		{"maxnm", 32, 0, 0x7f800001, 0x80000001, 0, 0x7fc00001, machine.FPSRIOC},
+		{"maxnm", 32, fpcrRZ | machine.FPCRFZ | machine.FPCRFZ16, 0x816cee8b, 0x8010940b, 0, 0x80000000, machine.FPSRIDC},
+		{"maxnm", 32, fpcrRM | machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0xbf929006, 0xbfc7fd44, 0, 0xbf929006, 0},
+		{"maxnm", 32, fpcrRM | machine.FPCRFZ, 0x001dd9f7, 0x7f800001, 0, 0x7fc00001, machine.FPSRIDC | machine.FPSRIOC},
+		{"maxnm", 64, fpcrRZ, 0x7fdad4b29a787768, 0x40086d999e19852b, 0, 0x7fdad4b29a787768, 0},
+		{"maxnm", 64, machine.FPCRDN, 0x402a04773816fa19, 0x800d5ed5fd223f3f, 0, 0x402a04773816fa19, 0},
+		{"maxnm", 64, fpcrRP | machine.FPCRDN | machine.FPCRFZ, 0x4003415c8ffd1e89, 0x3310fb7895ceae5b, 0, 0x4003415c8ffd1e89, 0},
+		{"maxnm", 64, fpcrRM | machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0x80143193e97c8c15, 0x000fffffffffffff, 0, 0, machine.FPSRIDC},
+		{"maxnm", 64, fpcrRM | machine.FPCRFZ, 0x9860620c36371800, 0x795add20e2b64dd4, 0, 0x795add20e2b64dd4, 0},
+		{"maxnm", 64, fpcrRM, 0xfff0000000000001, 0xdc6d000000000000, 0, 0xfff8000000000001, machine.FPSRIOC},
+		{"maxnm", 64, fpcrRP | machine.FPCRDN | machine.FPCRFZ, 0x7ff0000000000001, 0x800454297fb2967e, 0, 0x7ff8000000000000, machine.FPSRIDC | machine.FPSRIOC},
+		{"minnm", 16, fpcrRZ | machine.FPCRFZ | machine.FPCRFZ16, 0xd9c7, 0xf596, 0, 0xf596, 0},
+		{"minnm", 16, machine.FPCRFZ, 0x1377, 0xc30c, 0, 0xc30c, 0},
+		{"minnm", 16, machine.FPCRDN | machine.FPCRFZ, 0xdbdd, 0x5017, 0, 0xdbdd, 0},
+		{"minnm", 16, fpcrRP, 0x7c01, 0x6800, 0, 0x7e01, machine.FPSRIOC},
+		{"minnm", 16, machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0xe040, 0xf7f6, 0, 0xf7f6, 0},
+		{"minnm", 32, fpcrRM | machine.FPCRFZ16, 0x805c38db, 0xb9680000, 0, 0xb9680000, 0},
+		{"minnm", 32, fpcrRZ | machine.FPCRFZ, 0xbf800000, 0x08100000, 0, 0xbf800000, 0},
+		{"minnm", 32, machine.FPCRFZ, 0x99cdb955, 0x805b05bc, 0, 0x99cdb955, machine.FPSRIDC},
+		{"minnm", 32, fpcrRP | machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0x7f7fffff, 0xe8d1ceb7, 0, 0xe8d1ceb7, 0},
+		{"minnm", 32, fpcrRP | machine.FPCRDN | machine.FPCRFZ16, 0x000ab92d, 0x011d40f3, 0, 0x000ab92d, 0},
+		{"minnm", 32, fpcrRP | machine.FPCRFZ16, 0x7f800001, 0xc36b95d1, 0, 0x7fc00001, machine.FPSRIOC},
+		{"minnm", 32, fpcrRZ | machine.FPCRDN | machine.FPCRFZ, 0x003e4b15, 0x7f800001, 0, 0x7fc00000, machine.FPSRIDC | machine.FPSRIOC},
+		{"minnm", 64, fpcrRP | machine.FPCRFZ | machine.FPCRFZ16, 0x4024d4849c089acd, 0xd0b8000000000000, 0, 0xd0b8000000000000, 0},
+		{"minnm", 64, fpcrRM, 0x7ff0000000000001, 0x8019e9636f23df61, 0, 0x7ff8000000000001, machine.FPSRIOC},
+		{"minnm", 64, machine.FPCRDN, 0x000febb4ec2152c5, 0x400872304ed4ca4d, 0, 0x000febb4ec2152c5, 0},
+		{"minnm", 64, fpcrRZ | machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0x7388004463f8421b, 0x7fd1a6d0cd85c18f, 0, 0x7388004463f8421b, 0},
+		{"minnm", 64, fpcrRM | machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0x550e000000000000, 0x00078f47804651e7, 0, 0, machine.FPSRIDC},
+		{"minnm", 64, fpcrRZ | machine.FPCRFZ, 0x800b7a441a939889, 0x7ff0000000000001, 0, 0x7ff8000000000001, machine.FPSRIDC | machine.FPSRIOC},
+		{"cmp", 16, fpcrRM, 0x835e, 0x8244, 0, uint64(machine.FlagN), 0},
+		{"cmp", 16, fpcrRM | machine.FPCRDN | machine.FPCRFZ16, 0xfbff, 0xc96f, 0, uint64(machine.FlagN), 0},
+		{"cmp", 16, fpcrRM | machine.FPCRDN, 0x41b4, 0xb9a6, 0, uint64(machine.FlagC), 0},
+		{"cmp", 16, fpcrRP | machine.FPCRFZ | machine.FPCRFZ16, 0xe27e, 0x8043, 0, uint64(machine.FlagN), 0},
+		{"cmp", 16, machine.FPCRDN | machine.FPCRFZ | machine.FPCRFZ16, 0xfd4f, 0x340e, 0, uint64(machine.FlagC | machine.FlagV), machine.FPSRIOC},
+		{"cmp", 32, machine.FPCRDN | machine.FPCRFZ, 0x4171529b, 0x0165e5a9, 0, uint64(machine.FlagC), 0},
+		{"cmp", 32, fpcrRP | machine.FPCRDN, 0xed291c6c, 0x7fc00000, 0, uint64(machine.FlagC | machine.FlagV), 0},
+		{"cmp", 32, machine.FPCRFZ, 0xed400000, 0x65500000, 0, uint64(machine.FlagN), 0},
+		{"cmp", 32, 0, 0x82580000, 0x407480b7, 0, uint64(machine.FlagN), 0},
+		{"cmp", 32, fpcrRZ | machine.FPCRDN | machine.FPCRFZ, 0x80d8e457, 0x803970dd, 0, uint64(machine.FlagN), machine.FPSRIDC},
+		{"cmp", 32, 0, 0xc0f24769, 0x7f800001, 0, uint64(machine.FlagC | machine.FlagV), machine.FPSRIOC},
+		{"cmp", 32, fpcrRZ | machine.FPCRFZ | machine.FPCRFZ16, 0x800ef835, 0xff800001, 0, uint64(machine.FlagC | machine.FlagV), machine.FPSRIDC | machine.FPSRIOC},
+		{"cmp", 64, fpcrRM | machine.FPCRFZ16, 0x8005aee11e39a675, 0x8000000000000001, 0, uint64(machine.FlagN), 0},
+		{"cmp", 64, fpcrRZ, 0x7fd6be5fdaf5785e, 0x7ff0000000000001, 0, uint64(machine.FlagC | machine.FlagV), machine.FPSRIOC},
+		{"cmp", 64, fpcrRM | machine.FPCRFZ, 0xb64861b53da84644, 0x8000882e45557ba4, 0, uint64(machine.FlagN), machine.FPSRIDC},
+		{"cmp", 64, fpcrRP | machine.FPCRDN | machine.FPCRFZ16, 0x4029664a02a3b242, 0x1857000000000000, 0, uint64(machine.FlagC), 0},
+		{"cmp", 64, fpcrRP | machine.FPCRDN | machine.FPCRFZ, 0x800a09cf56e95b4d, 0xa0b8f897a0c55d95, 0, uint64(machine.FlagC), machine.FPSRIDC},
+		{"cmp", 64, machine.FPCRFZ, 0x7ff0000000000001, 0x80062c2ca52f0293, 0, uint64(machine.FlagC | machine.FlagV), machine.FPSRIDC | machine.FPSRIOC},
+		{"cmpe", 16, fpcrRM, 0x65df, 0xd800, 0, uint64(machine.FlagC), 0},
+		{"cmpe", 16, fpcrRP | machine.FPCRFZ, 0x1f4f, 0xfe03, 0, uint64(machine.FlagC | machine.FlagV), machine.FPSRIOC},
+		{"cmpe", 16, fpcrRM | machine.FPCRDN, 0x80a3, 0x3d40, 0, uint64(machine.FlagN), 0},
+		{"cmpe", 16, machine.FPCRFZ | machine.FPCRFZ16, 0x78e2, 0xd834, 0, uint64(machine.FlagC), 0},
+		{"cmpe", 16, fpcrRP | machine.FPCRDN | machine.FPCRFZ16, 0x0203, 0x0985, 0, uint64(machine.FlagN), 0},
+		{"cmpe", 32, fpcrRM, 0x9693f7de, 0x7a359c18, 0, uint64(machine.FlagN), 0},
+		{"cmpe", 32, fpcrRM | machine.FPCRDN, 0x14900748, 0x3fd5b583, 0, uint64(machine.FlagN), 0},
+		{"cmpe", 32, machine.FPCRFZ, 0xfa100000, 0xe3286c9d, 0, uint64(machine.FlagN), 0},
+		{"cmpe", 32, fpcrRP | machine.FPCRFZ | machine.FPCRFZ16, 0x7fc00000, 0x807de905, 0, uint64(machine.FlagC | machine.FlagV), machine.FPSRIDC | machine.FPSRIOC},
+		{"cmpe", 32, machine.FPCRDN | machine.FPCRFZ, 0x00016c55, 0x3f800000, 0, uint64(machine.FlagN), machine.FPSRIDC},
+		{"cmpe", 32, fpcrRM | machine.FPCRDN, 0xf25ee9a1, 0xff800001, 0, uint64(machine.FlagC | machine.FlagV), machine.FPSRIOC},
+		{"cmpe", 64, fpcrRP | machine.FPCRDN | machine.FPCRFZ, 0xdc615bdfeecf3f44, 0x001444d2ca3fa0df, 0, uint64(machine.FlagN), 0},
+		{"cmpe", 64, fpcrRM | machine.FPCRFZ16, 0x4d216c28c568d32f, 0x42e5502918a9d9c2, 0, uint64(machine.FlagC), 0},
+		{"cmpe", 64, fpcrRP | machine.FPCRFZ | machine.FPCRFZ16, 0x4de29eb7ea58f94f, 0x8f5c000000000000, 0, uint64(machine.FlagC), 0},
+		{"cmpe", 64, fpcrRZ | machine.FPCRDN, 0x3088000000000000, 0x403bb4c52407f388, 0, uint64(machine.FlagN), 0},
+		{"cmpe", 64, fpcrRM | machine.FPCRFZ, 0x8000000000000001, 0x3876878a80124454, 0, uint64(machine.FlagN), machine.FPSRIDC},
+		{"cmpe", 64, machine.FPCRFZ | machine.FPCRFZ16, 0xfff0000000000001, 0x0ba179ddc8e56736, 0, uint64(machine.FlagC | machine.FlagV), machine.FPSRIOC},
+		{"cmpe", 64, fpcrRP | machine.FPCRFZ, 0x7ff8000000000000, 0x8003825a976d2f41, 0, uint64(machine.FlagC | machine.FlagV), machine.FPSRIDC | machine.FPSRIOC},
+	} {
+		m := &machine.Machine{FPCR: tc.fpcr}
+		var got uint64
+		switch tc.op {
+		case "add":
+			got = fpAdd(m, tc.a, tc.b, tc.fsize)
+		case "sub":
+			got = fpSub(m, tc.a, tc.b, tc.fsize)
+		case "mul":
+			got = fpMul(m, tc.a, tc.b, tc.fsize)
+		case "div":
+			got = fpDiv(m, tc.a, tc.b, tc.fsize)
+		case "sqrt":
+			got = fpSqrt(m, tc.a, tc.fsize)
+		case "fma":
+			got = fpMulAdd(m, tc.c, tc.a, tc.b, tc.fsize)
+		case "max", "min":
+			got = fpMax(m, tc.a, tc.b, tc.fsize, tc.op == "min")
+		case "maxnm", "minnm":
+			got = fpMaxNum(m, tc.a, tc.b, tc.fsize, tc.op == "minnm")
+		case "cmp", "cmpe":
+			got = uint64(fpCompare(m, tc.a, tc.b, tc.fsize, tc.op == "cmpe"))
+		default:
+			t.Fatalf("unknown operation %q", tc.op)
+		}
+		if got != tc.want || m.FPSR != tc.fpsr {
+			t.Errorf("%s%d(0x%x, 0x%x, 0x%x) with FPCR = 0x%x = 0x%x with FPSR = 0x%x, want 0x%x with FPSR = 0x%x",
+				tc.op, tc.fsize, tc.a, tc.b, tc.c, tc.fpcr, got, m.FPSR, tc.want, tc.fpsr)
+		}
+	}
+}
@@ -0,0 +1,697 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// The scalar floating-point instructions have a 2-bit ftype field that selects single (0b00),
+// double (0b01) or half (0b11) precision.
+
+// fpBits returns the number of bits in a floating-point value of the given ftype.
+func fpBits(ftype uint32) int {
+	switch ftype & 0b11 {
+	case 0b00:
+		return 32
+	case 0b01:
+		return 64
+	}
+	return 16
+}
+
+// fpSize returns the size field that names the scalar register holding a value of the given ftype.
+func fpSize(ftype uint32) uint32 {
+	switch ftype & 0b11 {
+	case 0b00:
+		return 0b10
+	case 0b01:
+		return 0b11
+	}
+	return 0b01
+}
+
+func encodeFPDataProcessing1Source(ftype, opcode, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{0b00011110, 8},
+		{ftype, 2},
+		{1, 1},
+		{opcode, 6},
+		{0b10000, 5},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+func encodeFPDataProcessing2Source(ftype, rm, opcode, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{0b00011110, 8},
+		{ftype, 2},
+		{1, 1},
+		{rm, 5},
+		{opcode, 4},
+		{0b10, 2},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+func encodeFPDataProcessing3Source(ftype, o1, rm, o0, ra, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{0b00011111, 8},
+		{ftype, 2},
+		{o1, 1},
+		{rm, 5},
+		{o0, 1},
+		{ra, 5},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+func encodeFPCompare(ftype, rm, rn, opcode2 uint32) uint32 {
+	return buildUint32([]bits{
+		{0b00011110, 8},
+		{ftype, 2},
+		{1, 1},
+		{rm, 5},
+		{0b001000, 6},
+		{rn, 5},
+		{opcode2, 5},
+	}...)
+}
+
+func encodeFPConditionalSelect(ftype, rm, cond, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{0b00011110, 8},
+		{ftype, 2},
+		{1, 1},
+		{rm, 5},
+		{cond, 4},
+		{0b11, 2},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+func encodeFPImmediate(ftype, imm8, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{0b00011110, 8},
+		{ftype, 2},
+		{1, 1},
+		{imm8, 8},
+		{0b10000000, 8},
+		{rd, 5},
+	}...)
+}
+
+func encodeFPIntegerConversion(sf, ftype, rmode, opcode, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{0b0011110, 7},
+		{ftype, 2},
+		{1, 1},
+		{rmode, 2},
+		{opcode, 3},
+		{0, 6},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// executeFPUnary sets the scalar Vd to f applied to the scalar Vn.
+func executeFPUnary(m *machine.Machine, ftype, rn, rd uint32, f func(x uint64, fsize int) uint64) {
+	fsize := fpBits(ftype)
+	setScalar(m, rd, fsize, f(m.V[rn&0b11111].Get(0, fsize), fsize))
+}
+
+// executeFPBinary sets the scalar Vd to f applied to the scalars Vn and Vm.
+func executeFPBinary(m *machine.Machine, ftype, rm, rn, rd uint32, f func(x, y uint64, fsize int) uint64) {
+	fsize := fpBits(ftype)
+	setScalar(m, rd, fsize, f(m.V[rn&0b11111].Get(0, fsize), m.V[rm&0b11111].Get(0, fsize), fsize))
+}
+
+// executeFPMulAdd sets the scalar Vd to Va + Vn * Vm, negating Va first if negateAddend is set and
+// Vn if negateProduct is set, with a single rounding.
+func executeFPMulAdd(m *machine.Machine, ftype, rm, ra, rn, rd uint32, negateAddend, negateProduct bool) {
+	fsize := fpBits(ftype)
+	a, n := m.V[ra&0b11111].Get(0, fsize), m.V[rn&0b11111].Get(0, fsize)
+	if negateAddend {
+		a ^= fpSignBit(fsize)
+	}
+	if negateProduct {
+		n ^= fpSignBit(fsize)
+	}
+	setScalar(m, rd, fsize, fpMulAdd(m, a, n, m.V[rm&0b11111].Get(0, fsize), fsize))
+}
+
+// executeFPCompare sets NZCV to the result of comparing the scalar Vn with Vm, or with +0 if zero
+// is set.
+func executeFPCompare(m *machine.Machine, ftype, rm, rn uint32, zero, signalling bool) {
+	fsize := fpBits(ftype)
+	var b uint64
+	if !zero {
+		b = m.V[rm&0b11111].Get(0, fsize)
+	}
+	m.SetNZCV(fpCompare(m, m.V[rn&0b11111].Get(0, fsize), b, fsize, signalling))
+}
+
+// fpUnaryOperands formats the operands of a scalar floating-point instruction with one source.
+func fpUnaryOperands(ftype, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s", scalarName(rd, fpSize(ftype)), scalarName(rn, fpSize(ftype)))
+}
+
+// fpBinaryOperands formats the operands of a scalar floating-point instruction with two sources.
+func fpBinaryOperands(ftype, rm, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s, %s", scalarName(rd, fpSize(ftype)), scalarName(rn, fpSize(ftype)), scalarName(rm, fpSize(ftype)))
+}
+
+// fpTernaryOperands formats the operands of a floating-point multiply-add.
+func fpTernaryOperands(ftype, rm, ra, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s", fpBinaryOperands(ftype, rm, rn, rd), scalarName(ra, fpSize(ftype)))
+}
+
+// FMOV (register)
+type FmovRegister struct {
+	Ftype uint32 // 2 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *FmovRegister) Encode() uint32 {
+	return encodeFPDataProcessing1Source(op.Ftype, 0b000000, op.Rn, op.Rd)
+}
+
+func (op *FmovRegister) Execute(m *machine.Machine) {
+	executeFPUnary(m, op.Ftype, op.Rn, op.Rd, func(x uint64, fsize int) uint64 { return x })
+}
+
+func (op *FmovRegister) String() string {
+	return "fmov " + fpUnaryOperands(op.Ftype, op.Rn, op.Rd)
+}
+
+// FABS (scalar)
+type Fabs struct {
+	Ftype uint32 // 2 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fabs) Encode() uint32 {
+	return encodeFPDataProcessing1Source(op.Ftype, 0b000001, op.Rn, op.Rd)
+}
+
+func (op *Fabs) Execute(m *machine.Machine) {
+	executeFPUnary(m, op.Ftype, op.Rn, op.Rd, func(x uint64, fsize int) uint64 { return x &^ fpSignBit(fsize) })
+}
+
+func (op *Fabs) String() string {
+	return "fabs " + fpUnaryOperands(op.Ftype, op.Rn, op.Rd)
+}
+
+// FNEG (scalar)
+type Fneg struct {
+	Ftype uint32 // 2 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fneg) Encode() uint32 {
+	return encodeFPDataProcessing1Source(op.Ftype, 0b000010, op.Rn, op.Rd)
+}
+
+func (op *Fneg) Execute(m *machine.Machine) {
+	executeFPUnary(m, op.Ftype, op.Rn, op.Rd, func(x uint64, fsize int) uint64 { return x ^ fpSignBit(fsize) })
+}
+
+func (op *Fneg) String() string {
+	return "fneg " + fpUnaryOperands(op.Ftype, op.Rn, op.Rd)
+}
+
+// FSQRT (scalar)
+type Fsqrt struct {
+	Ftype uint32 // 2 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fsqrt) Encode() uint32 {
+	return encodeFPDataProcessing1Source(op.Ftype, 0b000011, op.Rn, op.Rd)
+}
+
+func (op *Fsqrt) Execute(m *machine.Machine) {
+	executeFPUnary(m, op.Ftype, op.Rn, op.Rd, func(x uint64, fsize int) uint64 { return fpSqrt(m, x, fsize) })
+}
+
+func (op *Fsqrt) String() string {
+	return "fsqrt " + fpUnaryOperands(op.Ftype, op.Rn, op.Rd)
+}
+
+// FMUL (scalar)
+type Fmul struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fmul) Encode() uint32 {
+	return encodeFPDataProcessing2Source(op.Ftype, op.Rm, 0b0000, op.Rn, op.Rd)
+}
+
+func (op *Fmul) Execute(m *machine.Machine) {
+	executeFPBinary(m, op.Ftype, op.Rm, op.Rn, op.Rd, func(x, y uint64, fsize int) uint64 { return fpMul(m, x, y, fsize) })
+}
+
+func (op *Fmul) String() string {
+	return "fmul " + fpBinaryOperands(op.Ftype, op.Rm, op.Rn, op.Rd)
+}
+
+// FDIV (scalar)
+type Fdiv struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fdiv) Encode() uint32 {
+	return encodeFPDataProcessing2Source(op.Ftype, op.Rm, 0b0001, op.Rn, op.Rd)
+}
+
+func (op *Fdiv) Execute(m *machine.Machine) {
+	executeFPBinary(m, op.Ftype, op.Rm, op.Rn, op.Rd, func(x, y uint64, fsize int) uint64 { return fpDiv(m, x, y, fsize) })
+}
+
+func (op *Fdiv) String() string {
+	return "fdiv " + fpBinaryOperands(op.Ftype, op.Rm, op.Rn, op.Rd)
+}
+
+// FADD (scalar)
+type Fadd struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fadd) Encode() uint32 {
+	return encodeFPDataProcessing2Source(op.Ftype, op.Rm, 0b0010, op.Rn, op.Rd)
+}
+
+func (op *Fadd) Execute(m *machine.Machine) {
+	executeFPBinary(m, op.Ftype, op.Rm, op.Rn, op.Rd, func(x, y uint64, fsize int) uint64 { return fpAdd(m, x, y, fsize) })
+}
+
+func (op *Fadd) String() string {
+	return "fadd " + fpBinaryOperands(op.Ftype, op.Rm, op.Rn, op.Rd)
+}
+
+// FSUB (scalar)
+type Fsub struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fsub) Encode() uint32 {
+	return encodeFPDataProcessing2Source(op.Ftype, op.Rm, 0b0011, op.Rn, op.Rd)
+}
+
+func (op *Fsub) Execute(m *machine.Machine) {
+	executeFPBinary(m, op.Ftype, op.Rm, op.Rn, op.Rd, func(x, y uint64, fsize int) uint64 { return fpSub(m, x, y, fsize) })
+}
+
+func (op *Fsub) String() string {
+	return "fsub " + fpBinaryOperands(op.Ftype, op.Rm, op.Rn, op.Rd)
+}
+
+// FMAX (scalar)
+type Fmax struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fmax) Encode() uint32 {
+	return encodeFPDataProcessing2Source(op.Ftype, op.Rm, 0b0100, op.Rn, op.Rd)
+}
+
+func (op *Fmax) Execute(m *machine.Machine) {
+	executeFPBinary(m, op.Ftype, op.Rm, op.Rn, op.Rd, func(x, y uint64, fsize int) uint64 { return fpMax(m, x, y, fsize, false) })
+}
+
+func (op *Fmax) String() string {
+	return "fmax " + fpBinaryOperands(op.Ftype, op.Rm, op.Rn, op.Rd)
+}
+
+// FMIN (scalar)
+type Fmin struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fmin) Encode() uint32 {
+	return encodeFPDataProcessing2Source(op.Ftype, op.Rm, 0b0101, op.Rn, op.Rd)
+}
+
+func (op *Fmin) Execute(m *machine.Machine) {
+	executeFPBinary(m, op.Ftype, op.Rm, op.Rn, op.Rd, func(x, y uint64, fsize int) uint64 { return fpMax(m, x, y, fsize, true) })
+}
+
+func (op *Fmin) String() string {
+	return "fmin " + fpBinaryOperands(op.Ftype, op.Rm, op.Rn, op.Rd)
+}
+
+// FMAXNM (scalar)
+type Fmaxnm struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fmaxnm) Encode() uint32 {
+	return encodeFPDataProcessing2Source(op.Ftype, op.Rm, 0b0110, op.Rn, op.Rd)
+}
+
+func (op *Fmaxnm) Execute(m *machine.Machine) {
+	executeFPBinary(m, op.Ftype, op.Rm, op.Rn, op.Rd, func(x, y uint64, fsize int) uint64 { return fpMaxNum(m, x, y, fsize, false) })
+}
+
+func (op *Fmaxnm) String() string {
+	return "fmaxnm " + fpBinaryOperands(op.Ftype, op.Rm, op.Rn, op.Rd)
+}
+
+// FMINNM (scalar)
+type Fminnm struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fminnm) Encode() uint32 {
+	return encodeFPDataProcessing2Source(op.Ftype, op.Rm, 0b0111, op.Rn, op.Rd)
+}
+
+func (op *Fminnm) Execute(m *machine.Machine) {
+	executeFPBinary(m, op.Ftype, op.Rm, op.Rn, op.Rd, func(x, y uint64, fsize int) uint64 { return fpMaxNum(m, x, y, fsize, true) })
+}
+
+func (op *Fminnm) String() string {
+	return "fminnm " + fpBinaryOperands(op.Ftype, op.Rm, op.Rn, op.Rd)
+}
+
+// FNMUL (scalar)
+type Fnmul struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fnmul) Encode() uint32 {
+	return encodeFPDataProcessing2Source(op.Ftype, op.Rm, 0b1000, op.Rn, op.Rd)
+}
+
+func (op *Fnmul) Execute(m *machine.Machine) {
+	executeFPBinary(m, op.Ftype, op.Rm, op.Rn, op.Rd, func(x, y uint64, fsize int) uint64 { return fpMul(m, x, y, fsize) ^ fpSignBit(fsize) })
+}
+
+func (op *Fnmul) String() string {
+	return "fnmul " + fpBinaryOperands(op.Ftype, op.Rm, op.Rn, op.Rd)
+}
+
+// FMADD
+type Fmadd struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Ra    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fmadd) Encode() uint32 {
+	return encodeFPDataProcessing3Source(op.Ftype, 0, op.Rm, 0, op.Ra, op.Rn, op.Rd)
+}
+
+func (op *Fmadd) Execute(m *machine.Machine) {
+	executeFPMulAdd(m, op.Ftype, op.Rm, op.Ra, op.Rn, op.Rd, false, false)
+}
+
+func (op *Fmadd) String() string {
+	return "fmadd " + fpTernaryOperands(op.Ftype, op.Rm, op.Ra, op.Rn, op.Rd)
+}
+
+// FMSUB
+type Fmsub struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Ra    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fmsub) Encode() uint32 {
+	return encodeFPDataProcessing3Source(op.Ftype, 0, op.Rm, 1, op.Ra, op.Rn, op.Rd)
+}
+
+func (op *Fmsub) Execute(m *machine.Machine) {
+	executeFPMulAdd(m, op.Ftype, op.Rm, op.Ra, op.Rn, op.Rd, false, true)
+}
+
+func (op *Fmsub) String() string {
+	return "fmsub " + fpTernaryOperands(op.Ftype, op.Rm, op.Ra, op.Rn, op.Rd)
+}
+
+// FNMADD
+type Fnmadd struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Ra    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fnmadd) Encode() uint32 {
+	return encodeFPDataProcessing3Source(op.Ftype, 1, op.Rm, 0, op.Ra, op.Rn, op.Rd)
+}
+
+func (op *Fnmadd) Execute(m *machine.Machine) {
+	executeFPMulAdd(m, op.Ftype, op.Rm, op.Ra, op.Rn, op.Rd, true, true)
+}
+
+func (op *Fnmadd) String() string {
+	return "fnmadd " + fpTernaryOperands(op.Ftype, op.Rm, op.Ra, op.Rn, op.Rd)
+}
+
+// FNMSUB
+type Fnmsub struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Ra    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fnmsub) Encode() uint32 {
+	return encodeFPDataProcessing3Source(op.Ftype, 1, op.Rm, 1, op.Ra, op.Rn, op.Rd)
+}
+
+func (op *Fnmsub) Execute(m *machine.Machine) {
+	executeFPMulAdd(m, op.Ftype, op.Rm, op.Ra, op.Rn, op.Rd, true, false)
+}
+
+func (op *Fnmsub) String() string {
+	return "fnmsub " + fpTernaryOperands(op.Ftype, op.Rm, op.Ra, op.Rn, op.Rd)
+}
+
+// FCMP
+type Fcmp struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+}
+
+func (op *Fcmp) Encode() uint32 {
+	return encodeFPCompare(op.Ftype, op.Rm, op.Rn, 0b00000)
+}
+
+func (op *Fcmp) Execute(m *machine.Machine) {
+	executeFPCompare(m, op.Ftype, op.Rm, op.Rn, false, false)
+}
+
+func (op *Fcmp) String() string {
+	return fmt.Sprintf("fcmp %s, %s", scalarName(op.Rn, fpSize(op.Ftype)), scalarName(op.Rm, fpSize(op.Ftype)))
+}
+
+// FCMPE
+type Fcmpe struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Rn    uint32 // 5 bits
+}
+
+func (op *Fcmpe) Encode() uint32 {
+	return encodeFPCompare(op.Ftype, op.Rm, op.Rn, 0b10000)
+}
+
+func (op *Fcmpe) Execute(m *machine.Machine) {
+	executeFPCompare(m, op.Ftype, op.Rm, op.Rn, false, true)
+}
+
+func (op *Fcmpe) String() string {
+	return fmt.Sprintf("fcmpe %s, %s", scalarName(op.Rn, fpSize(op.Ftype)), scalarName(op.Rm, fpSize(op.Ftype)))
+}
+
+// FCMP (zero)
+type FcmpZero struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits, ignored
+	Rn    uint32 // 5 bits
+}
+
+func (op *FcmpZero) Encode() uint32 {
+	return encodeFPCompare(op.Ftype, op.Rm, op.Rn, 0b01000)
+}
+
+func (op *FcmpZero) Execute(m *machine.Machine) {
+	executeFPCompare(m, op.Ftype, 0, op.Rn, true, false)
+}
+
+func (op *FcmpZero) String() string {
+	return fmt.Sprintf("fcmp %s, #0.0", scalarName(op.Rn, fpSize(op.Ftype)))
+}
+
+// FCMPE (zero)
+type FcmpeZero struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits, ignored
+	Rn    uint32 // 5 bits
+}
+
+func (op *FcmpeZero) Encode() uint32 {
+	return encodeFPCompare(op.Ftype, op.Rm, op.Rn, 0b11000)
+}
+
+func (op *FcmpeZero) Execute(m *machine.Machine) {
+	executeFPCompare(m, op.Ftype, 0, op.Rn, true, true)
+}
+
+func (op *FcmpeZero) String() string {
+	return fmt.Sprintf("fcmpe %s, #0.0", scalarName(op.Rn, fpSize(op.Ftype)))
+}
+
+// FCSEL
+type Fcsel struct {
+	Ftype uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Cond  uint32 // 4 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Fcsel) Encode() uint32 {
+	return encodeFPConditionalSelect(op.Ftype, op.Rm, op.Cond, op.Rn, op.Rd)
+}
+
+func (op *Fcsel) Execute(m *machine.Machine) {
+	executeFPBinary(m, op.Ftype, op.Rm, op.Rn, op.Rd, func(x, y uint64, fsize int) uint64 {
+		if m.ConditionHolds(op.Cond) {
+			return x
+		}
+		return y
+	})
+}
+
+func (op *Fcsel) String() string {
+	return fmt.Sprintf("fcsel %s, %s", fpBinaryOperands(op.Ftype, op.Rm, op.Rn, op.Rd), condNames[op.Cond&0b1111])
+}
+
+// FMOV (scalar, immediate)
+type FmovImmediate struct {
+	Ftype uint32 // 2 bits
+	Imm8  uint32 // 8 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *FmovImmediate) Encode() uint32 {
+	return encodeFPImmediate(op.Ftype, op.Imm8, op.Rd)
+}
+
+func (op *FmovImmediate) Execute(m *machine.Machine) {
+	fsize := fpBits(op.Ftype)
+	setScalar(m, op.Rd, fsize, fpExpandImm(op.Imm8, fsize))
+}
+
+func (op *FmovImmediate) String() string {
+	fsize := fpBits(op.Ftype)
+	return fmt.Sprintf("fmov %s, #%.8f", scalarName(op.Rd, fpSize(op.Ftype)), fpToFloat64(fpExpandImm(op.Imm8, fsize), fsize))
+}
+
+// fmovGeneralName names the floating-point side of an FMOV to or from a general register: a
+// scalar, or the upper 64 bits of Vn when ftype is 0b10.
+func fmovGeneralName(ftype, n uint32) string {
+	if ftype&0b11 == 0b10 {
+		return elementName(n, 0b11, 1)
+	}
+	return scalarName(n, fpSize(ftype))
+}
+
+// FMOV (general), from a floating-point register to a general register
+type FmovToGeneral struct {
+	Sf    uint32 // 1 bit
+	Ftype uint32 // 2 bits
+	Rmode uint32 // 2 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *FmovToGeneral) Encode() uint32 {
+	return encodeFPIntegerConversion(op.Sf, op.Ftype, op.Rmode, 0b110, op.Rn, op.Rd)
+}
+
+func (op *FmovToGeneral) Execute(m *machine.Machine) {
+	n := m.V[op.Rn&0b11111]
+	if op.Ftype&0b11 == 0b10 {
+		setReg(m, op.Sf, op.Rd, false, n.Get(1, 64))
+		return
+	}
+	setReg(m, op.Sf, op.Rd, false, n.Get(0, fpBits(op.Ftype)))
+}
+
+func (op *FmovToGeneral) String() string {
+	return fmt.Sprintf("fmov %s, %s", regName(op.Sf, op.Rd, false), fmovGeneralName(op.Ftype, op.Rn))
+}
+
+// FMOV (general), from a general register to a floating-point register
+type FmovFromGeneral struct {
+	Sf    uint32 // 1 bit
+	Ftype uint32 // 2 bits
+	Rmode uint32 // 2 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *FmovFromGeneral) Encode() uint32 {
+	return encodeFPIntegerConversion(op.Sf, op.Ftype, op.Rmode, 0b111, op.Rn, op.Rd)
+}
+
+func (op *FmovFromGeneral) Execute(m *machine.Machine) {
+	x := reg(m, op.Sf, op.Rn, false)
+	if op.Ftype&0b11 == 0b10 {
+		m.V[op.Rd&0b11111].Set(1, 64, x)
+		return
+	}
+	fsize := fpBits(op.Ftype)
+	setScalar(m, op.Rd, fsize, x&elementMask(fsize))
+}
+
+func (op *FmovFromGeneral) String() string {
+	return fmt.Sprintf("fmov %s, %s", fmovGeneralName(op.Ftype, op.Rd), regName(op.Sf, op.Rn, false))
+}
@@ -0,0 +1,157 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestFPScalarExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm     string
+		inst    Instruction
+		fpcr    uint32
+		nzcv    uint32
+		n, m, a uint64
+		want    machine.VectorRegister
+		fpsr    uint32
+	}{
+		{"fadd s0, s1, s2", &Fadd{Rm: 2, Rn: 1}, 0, 0, 0x3f800000, 0x40000000, 0, vec(0x40400000, 0), 0},
+		{"fadd d0, d1, d2", &Fadd{Ftype: 0b01, Rm: 2, Rn: 1}, 0, 0, 0x3fb999999999999a, 0x3fc999999999999a, 0, vec(0x3fd3333333333334, 0), machine.FPSRIXC},
+		{"fadd h0, h1, h2", &Fadd{Ftype: 0b11, Rm: 2, Rn: 1}, 0, 0, 0x3c00, 0x1000, 0, vec(0x3c00, 0), machine.FPSRIXC},
+		{"fadd h0, h1, h2", &Fadd{Ftype: 0b11, Rm: 2, Rn: 1}, 0, 0, 0x3c01, 0x1000, 0, vec(0x3c02, 0), machine.FPSRIXC},
+		{"fadd h0, h1, h2", &Fadd{Ftype: 0b11, Rm: 2, Rn: 1}, 0, 0, 0x7bff, 0x7bff, 0, vec(0x7c00, 0), machine.FPSROFC | machine.FPSRIXC},
+		{"fadd s0, s1, s2", &Fadd{Rm: 2, Rn: 1}, machine.FPCRDN, 0, 0x7fc00001, 0x3f800000, 0, vec(0x7fc00000, 0), 0},
+		{"fadd d0, d1, d2", &Fadd{Ftype: 0b01, Rm: 2, Rn: 1}, 0, 0, 0x7ff8000000000001, 0x7ff0000000000002, 0, vec(0x7ff8000000000002, 0), machine.FPSRIOC},
+		{"fsub s0, s1, s2", &Fsub{Rm: 2, Rn: 1}, 0, 0, 0x7f800000, 0x7f800000, 0, vec(0x7fc00000, 0), machine.FPSRIOC},
+		{"fsub d0, d1, d2", &Fsub{Ftype: 0b01, Rm: 2, Rn: 1}, 0, 0, 0x3ff0000000000000, 0x3ff0000000000000, 0, vec(0, 0), 0},
+		{"fmul s0, s1, s2", &Fmul{Rm: 2, Rn: 1}, 0, 0, 0, 0xff800000, 0, vec(0x7fc00000, 0), machine.FPSRIOC},
+		{"fmul h0, h1, h2", &Fmul{Ftype: 0b11, Rm: 2, Rn: 1}, 0, 0, 0x0001, 0x3800, 0, vec(0, 0), machine.FPSRUFC | machine.FPSRIXC},
+		{"fmul h0, h1, h2", &Fmul{Ftype: 0b11, Rm: 2, Rn: 1}, 0, 0, 0x8003, 0x3800, 0, vec(0x8002, 0), machine.FPSRUFC | machine.FPSRIXC},
+		{"fdiv s0, s1, s2", &Fdiv{Rm: 2, Rn: 1}, 0, 0, 0x3f800000, 0x80000000, 0, vec(0xff800000, 0), machine.FPSRDZC},
+		{"fdiv s0, s1, s2", &Fdiv{Rm: 2, Rn: 1}, 0, 0, 0x7f800000, 0, 0, vec(0x7f800000, 0), 0},
+		{"fdiv d0, d1, d2", &Fdiv{Ftype: 0b01, Rm: 2, Rn: 1}, 0, 0, 0, 0, 0, vec(0x7ff8000000000000, 0), machine.FPSRIOC},
+		{"fdiv s0, s1, s2", &Fdiv{Rm: 2, Rn: 1}, 0, 0, 0x3f800000, 0x40400000, 0, vec(0x3eaaaaab, 0), machine.FPSRIXC},
+		{"fnmul s0, s1, s2", &Fnmul{Rm: 2, Rn: 1}, 0, 0, 0x40000000, 0x40400000, 0, vec(0xc0c00000, 0), 0},
+		{"fmax s0, s1, s2", &Fmax{Rm: 2, Rn: 1}, 0, 0, 0x3f800000, 0x7fc00001, 0, vec(0x7fc00001, 0), 0},
+		{"fmax s0, s1, s2", &Fmax{Rm: 2, Rn: 1}, 0, 0, 0x7fc00002, 0x7f800001, 0, vec(0x7fc00001, 0), machine.FPSRIOC},
+		{"fmin d0, d1, d2", &Fmin{Ftype: 0b01, Rm: 2, Rn: 1}, 0, 0, 0, 0x8000000000000000, 0, vec(0x8000000000000000, 0), 0},
+		{"fmaxnm s0, s1, s2", &Fmaxnm{Rm: 2, Rn: 1}, 0, 0, 0x7fc00000, 0x3f800000, 0, vec(0x3f800000, 0), 0},
+		{"fminnm h0, h1, h2", &Fminnm{Ftype: 0b11, Rm: 2, Rn: 1}, 0, 0, 0x3c00, 0xc000, 0, vec(0xc000, 0), 0},
+		{"fsqrt s0, s1", &Fsqrt{Rn: 1}, 0, 0, 0x40000000, 0, 0, vec(0x3fb504f3, 0), machine.FPSRIXC},
+		{"fsqrt d0, d1", &Fsqrt{Ftype: 0b01, Rn: 1}, 0, 0, 0xbff0000000000000, 0, 0, vec(0x7ff8000000000000, 0), machine.FPSRIOC},
+		{"fsqrt s0, s1", &Fsqrt{Rn: 1}, 0, 0, 0x80000000, 0, 0, vec(0x80000000, 0), 0},
+		{"fsqrt h0, h1", &Fsqrt{Ftype: 0b11, Rn: 1}, 0, 0, 0x7d01, 0, 0, vec(0x7f01, 0), machine.FPSRIOC},
+		{"fabs s0, s1", &Fabs{Rn: 1}, 0, 0, 0xff800001, 0, 0, vec(0x7f800001, 0), 0},
+		{"fneg d0, d1", &Fneg{Ftype: 0b01, Rn: 1}, 0, 0, 0x3ff0000000000000, 0, 0, vec(0xbff0000000000000, 0), 0},
+		{"fmov h0, h1", &FmovRegister{Ftype: 0b11, Rn: 1}, 0, 0, 0xffff1234, 0, 0, vec(0x1234, 0), 0},
+		{"fmadd s0, s1, s2, s3", &Fmadd{Rm: 2, Ra: 3, Rn: 1}, 0, 0, 0x40000000, 0x40400000, 0x3f800000, vec(0x40e00000, 0), 0},
+		{"fmadd d0, d1, d2, d3", &Fmadd{Ftype: 0b01, Rm: 2, Ra: 3, Rn: 1}, 0, 0, 0x3ff0000000400000, 0x3ff0000000400000, 0xbff0000000800000, vec(0x3c30000000000000, 0), 0},
+		{"fmadd s0, s1, s2, s3", &Fmadd{Rm: 2, Ra: 3, Rn: 1}, 0, 0, 0, 0x7f800000, 0x7fc00001, vec(0x7fc00000, 0), machine.FPSRIOC},
+		{"fmsub s0, s1, s2, s3", &Fmsub{Rm: 2, Ra: 3, Rn: 1}, 0, 0, 0x40000000, 0x40400000, 0x3f800000, vec(0xc0a00000, 0), 0},
+		{"fmsub s0, s1, s2, s3", &Fmsub{Rm: 2, Ra: 3, Rn: 1}, 0, 0, 0x7fc00001, 0x3f800000, 0x3f800000, vec(0xffc00001, 0), 0},
+		{"fnmadd d0, d1, d2, d3", &Fnmadd{Ftype: 0b01, Rm: 2, Ra: 3, Rn: 1}, 0, 0, 0x4000000000000000, 0x4008000000000000, 0x3ff0000000000000, vec(0xc01c000000000000, 0), 0},
+		{"fnmsub h0, h1, h2, h3", &Fnmsub{Ftype: 0b11, Rm: 2, Ra: 3, Rn: 1}, 0, 0, 0x4000, 0x4200, 0x3c00, vec(0x4500, 0), 0},
+		{"fcsel s0, s1, s2, eq", &Fcsel{Rm: 2, Rn: 1}, 0, machine.FlagZ, 0x11111111, 0x22222222, 0, vec(0x11111111, 0), 0},
+		{"fcsel d0, d1, d2, ne", &Fcsel{Ftype: 0b01, Rm: 2, Cond: 0b0001, Rn: 1}, 0, machine.FlagZ, 0x11111111, 0x22222222, 0, vec(0x22222222, 0), 0},
+		{"fmov s0, #1.00000000", &FmovImmediate{Imm8: 0x70}, 0, 0, 0, 0, 0, vec(0x3f800000, 0), 0},
+		{"fmov d0, #-0.12500000", &FmovImmediate{Ftype: 0b01, Imm8: 0xc0}, 0, 0, 0, 0, 0, vec(0xbfc0000000000000, 0), 0},
+		{"fmov h0, #31.00000000", &FmovImmediate{Ftype: 0b11, Imm8: 0x3f}, 0, 0, 0, 0, 0, vec(0x4fc0, 0), 0},
+	} {
+		m := load(tc.inst)
+		m.FPCR = tc.fpcr
+		m.SetNZCV(tc.nzcv)
+		m.V[0] = vec(^uint64(0), ^uint64(0))
+		m.V[1] = vec(tc.n, 1)
+		m.V[2] = vec(tc.m, 2)
+		m.V[3] = vec(tc.a, 3)
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.V[0] != tc.want {
+			t.Errorf("%s with n = 0x%x, m = 0x%x, a = 0x%x: v0 = %x, want %x", tc.asm, tc.n, tc.m, tc.a, m.V[0], tc.want)
+		}
+		if m.FPSR != tc.fpsr {
+			t.Errorf("%s: FPSR = 0x%x, want 0x%x", tc.asm, m.FPSR, tc.fpsr)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
+
+func TestFPCompareExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm  string
+		inst Instruction
+		n, m uint64
+		nzcv uint32
+		fpsr uint32
+	}{
+		{"fcmp s0, s1", &Fcmp{Rm: 1}, 0x3f800000, 0x40000000, machine.FlagN, 0},
+		{"fcmp d0, d1", &Fcmp{Ftype: 0b01, Rm: 1}, 0x4000000000000000, 0x4000000000000000, machine.FlagZ | machine.FlagC, 0},
+		{"fcmp h0, h1", &Fcmp{Ftype: 0b11, Rm: 1}, 0x4000, 0x3c00, machine.FlagC, 0},
+		{"fcmp s0, #0.0", &FcmpZero{}, 0x80000000, 0x3f800000, machine.FlagZ | machine.FlagC, 0},
+		{"fcmp s0, s1", &Fcmp{Rm: 1}, 0x7fc00000, 0x3f800000, machine.FlagC | machine.FlagV, 0},
+		{"fcmpe s0, s1", &Fcmpe{Rm: 1}, 0x7fc00000, 0x3f800000, machine.FlagC | machine.FlagV, machine.FPSRIOC},
+		{"fcmp d0, d1", &Fcmp{Ftype: 0b01, Rm: 1}, 0, 0x7ff0000000000001, machine.FlagC | machine.FlagV, machine.FPSRIOC},
+		{"fcmpe h0, #0.0", &FcmpeZero{Ftype: 0b11}, 0xfc00, 0, machine.FlagN, 0},
+	} {
+		m := load(tc.inst)
+		m.SetNZCV(machine.FlagN | machine.FlagZ | machine.FlagC | machine.FlagV)
+		m.V[0] = vec(tc.n, 0)
+		m.V[1] = vec(tc.m, 0)
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if got := m.NZCV(); got != tc.nzcv {
+			t.Errorf("%s with 0x%x, 0x%x: NZCV = %04b, want %04b", tc.asm, tc.n, tc.m, got, tc.nzcv)
+		}
+		if m.FPSR != tc.fpsr {
+			t.Errorf("%s: FPSR = 0x%x, want 0x%x", tc.asm, m.FPSR, tc.fpsr)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
+
+func TestFmovGeneralExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm    string
+		inst   Instruction
+		x1     uint64
+		v0, v1 machine.VectorRegister
+		x0     uint64
+		want   machine.VectorRegister
+	}{
+		{"fmov w0, s1", &FmovToGeneral{Rn: 1}, 0, vec(5, 6), vec(0x1122334455667788, 1), 0x55667788, vec(5, 6)},
+		{"fmov x0, d1", &FmovToGeneral{Sf: 1, Ftype: 0b01, Rn: 1}, 0, vec(5, 6), vec(0x1122334455667788, 1), 0x1122334455667788, vec(5, 6)},
+		{"fmov x0, h1", &FmovToGeneral{Sf: 1, Ftype: 0b11, Rn: 1}, 0, vec(5, 6), vec(0x1122334455667788, 1), 0x7788, vec(5, 6)},
+		{"fmov x0, v1.d[1]", &FmovToGeneral{Sf: 1, Ftype: 0b10, Rmode: 0b01, Rn: 1}, 0, vec(5, 6), vec(0x1122334455667788, 1), 1, vec(5, 6)},
+		{"fmov s0, w1", &FmovFromGeneral{Rn: 1}, 0xffffffff12345678, vec(5, 6), vec(0, 0), 0, vec(0x12345678, 0)},
+		{"fmov h0, x1", &FmovFromGeneral{Sf: 1, Ftype: 0b11, Rn: 1}, 0xffffffff12345678, vec(5, 6), vec(0, 0), 0, vec(0x5678, 0)},
+		{"fmov d0, x1", &FmovFromGeneral{Sf: 1, Ftype: 0b01, Rn: 1}, 0xffffffff12345678, vec(5, 6), vec(0, 0), 0, vec(0xffffffff12345678, 0)},
+		{"fmov v0.d[1], x1", &FmovFromGeneral{Sf: 1, Ftype: 0b10, Rmode: 0b01, Rn: 1}, 0xffffffff12345678, vec(5, 6), vec(0, 0), 0, vec(5, 0xffffffff12345678)},
+	} {
+		m := load(tc.inst)
+		m.R[1] = tc.x1
+		m.V[0] = tc.v0
+		m.V[1] = tc.v1
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.R[0] != tc.x0 {
+			t.Errorf("%s: x0 = 0x%x, want 0x%x", tc.asm, m.R[0], tc.x0)
+		}
+		if m.V[0] != tc.want {
+			t.Errorf("%s: v0 = %x, want %x", tc.asm, m.V[0], tc.want)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
@@ -12,6 +12,8 @@ import (
 type Instruction interface {
 	Encode() uint32
 	Execute(m *machine.Machine)
+	// String returns the instruction in its preferred assembly syntax.
+	String() string
 }
 
 // C6.2.5 ADD (immediate)
@@ -43,42 +45,18 @@ func buildUint32(sbs ...bits) uint32 {
 }
 
 func (op *AddImmedite) Encode() uint32 {
-	return buildUint32([]bits{
-		{op.Sf, 1},
-		{0, 1}, // op
-		{0, 1}, // S
-		{0b100010, 6},
-		{op.Sh, 1},
-		{op.Imm, 12},
-		{op.Rn, 5},
-		{op.Rd, 5},
-	}...)
+	return encodeAddSubImmediate(0, 0, op.Sf, op.Sh, op.Imm, op.Rn, op.Rd)
 }
 
 func (op *AddImmedite) Execute(m *machine.Machine) {
-	var datamask uint64 = 0xffffffff
-	if op.Sf&0x01 == 1 {
-		datamask = 0xffffffffffffffff
-	}
-	imm := uint32(op.Imm)
-	if op.Sh&0x01 == 1 {
-		imm = imm << 12
-	}
-	var op1 uint64
-	if op.Rn&0b11111 == 31 {
-		op1 = m.SP
-	} else {
-		op1 = m.R[op.Rn&0b11111]
-	}
-	op1 = op1 & datamask
-	var op2 uint64
-	op2 = uint64(imm)
-	op2 = op2 & datamask
-	if op.Rn&0b11111 == 31 {
-		m.SP = op1 + op2
-	} else {
-		m.R[op.Rd&0b11111] = op1 + op2
+	executeAddSubImmediate(m, false, false, op.Sf, op.Sh, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *AddImmedite) String() string {
+	if op.Sh&1 == 0 && op.Imm == 0 && (op.Rd&0b11111 == 31 || op.Rn&0b11111 == 31) {
+		return fmt.Sprintf("mov %s, %s", regName(op.Sf, op.Rd, true), regName(op.Sf, op.Rn, true))
 	}
+	return fmt.Sprintf("add %s, %s", regName(op.Sf, op.Rd, true), addSubImmediateOperands(op.Sf, op.Sh, op.Imm, op.Rn))
 }
 
 // C6.2.6 ADD (shifted register)
@@ -92,71 +70,21 @@ type AddShiftedRegister struct {
 }
 
 func (op *AddShiftedRegister) Encode() uint32 {
-	return buildUint32([]bits{
-		{op.Sf, 1},
-		{0, 1}, // op
-		{0, 1}, // S
-		{0b01011, 5},
-		{op.Shift, 2},
-		{0, 1},
-		{op.Rm, 5},
-		{op.Imm, 6},
-		{op.Rn, 5},
-		{op.Rd, 5},
-	}...)
+	return encodeAddSubShiftedRegister(0, 0, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
 }
 
 func (op *AddShiftedRegister) Execute(m *machine.Machine) {
-	var datasize uint = 32
-	if op.Sf&0x01 == 1 {
-		datasize = 64
-	}
-
-	var op1 uint64
-	if op.Rn&0b11111 == 31 {
-		op1 = m.SP
-	} else {
-		op1 = m.R[op.Rn&0b11111]
-	}
-
-	var op2 uint64
-	op2 = m.R[op.Rm&0b11111]
-
-	shift_type := op.Shift
-	shift_amount := uint64(op.Imm)
-
-	var shifted_op2 uint64
-	switch shift_type {
-	case 0b00: // LSL
-		shifted_op2 = op2 << shift_amount
-	case 0b01: // LSR
-		shifted_op2 = op2 >> shift_amount
-	case 0b10: // ASR
-		if datasize == 32 {
-			shifted_op2 = uint64(int32(op2) >> shift_amount)
-		} else {
-			shifted_op2 = uint64(int64(op2) >> shift_amount)
-		}
-	}
-
-	var result uint64
-	if datasize == 32 {
-		result = uint64(uint32(op1) + uint32(shifted_op2))
-	} else {
-		result = op1 + shifted_op2
-	}
+	executeAddSubShiftedRegister(m, false, false, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
 
-	if op.Rd&0b11111 == 31 {
-		m.SP = result
-	} else {
-		m.R[op.Rd&0b11111] = result
-	}
+func (op *AddShiftedRegister) String() string {
+	return fmt.Sprintf("add %s, %s, %s", regName(op.Sf, op.Rd, false), regName(op.Sf, op.Rn, false), shiftedRegisterOperand(op.Sf, op.Rm, op.Shift, op.Imm))
 }
 
 // C6.2.4 ADD (extended register)
 type AddExtendedRegister struct {
 	Sf  uint32 // 1 bit
-	Opt byte   // 2 bits
+	Opt byte   // 3 bits
 	Imm uint32 // 3 bits
 	Rm  uint32 // 5 bits
 	Rn  uint32 // 5 bits
@@ -164,61 +92,15 @@ type AddExtendedRegister struct {
 }
 
 func (op *AddExtendedRegister) Encode() uint32 {
-	return 0
+	return encodeAddSubExtendedRegister(0, 0, op.Sf, uint32(op.Opt), op.Imm, op.Rm, op.Rn, op.Rd)
 }
 
 func (op *AddExtendedRegister) Execute(m *machine.Machine) {
-	var datasize uint = 32
-	if op.Sf&0x01 == 1 {
-		datasize = 64
-	}
-
-	var op1 uint64
-	if op.Rn&0b11111 == 31 {
-		op1 = m.SP
-	} else {
-		op1 = m.R[op.Rn&0b11111]
-	}
-
-	op2 := m.R[op.Rm&0b11111]
-
-	var extended_op2 uint64
-	extend_type := op.Opt
-	shift := op.Imm
-
-	switch extend_type {
-	case 0b000: // UXTB
-		extended_op2 = uint64(uint8(op2))
-	case 0b001: // UXTH
-		extended_op2 = uint64(uint16(op2))
-	case 0b010: // UXTW
-		extended_op2 = uint64(uint32(op2))
-	case 0b011: // UXTX
-		extended_op2 = op2
-	case 0b100: // SXTB
-		extended_op2 = uint64(int8(op2))
-	case 0b101: // SXTH
-		extended_op2 = uint64(int16(op2))
-	case 0b110: // SXTW
-		extended_op2 = uint64(int32(op2))
-	case 0b111: // SXTX
-		extended_op2 = op2
-	}
-
-	shifted_op2 := extended_op2 << shift
-
-	var result uint64
-	if datasize == 32 {
-		result = uint64(uint32(op1) + uint32(shifted_op2))
-	} else {
-		result = op1 + shifted_op2
-	}
+	executeAddSubExtendedRegister(m, false, false, op.Sf, uint32(op.Opt), op.Imm, op.Rm, op.Rn, op.Rd)
+}
 
-	if op.Rd&0b11111 == 31 {
-		m.SP = result
-	} else {
-		m.R[op.Rd&0b11111] = result
-	}
+func (op *AddExtendedRegister) String() string {
+	return fmt.Sprintf("add %s, %s, %s", regName(op.Sf, op.Rd, true), regName(op.Sf, op.Rn, true), extendedRegisterOperand(op.Sf, uint32(op.Opt), op.Imm, op.Rm, op.Rd&0b11111 == 31 || op.Rn&0b11111 == 31))
 }
 
 // ADD (vector)
@@ -231,32 +113,13 @@ type AddVector struct {
 }
 
 func (op *AddVector) Encode() uint32 {
-	return 0
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b10000, op.Rm, op.Rn, op.Rd)
 }
 
 func (op *AddVector) Execute(m *machine.Machine) {
-	var esize int
-	switch op.Size {
-	case 0b00:
-		esize = 8
-	case 0b01:
-		esize = 16
-	case 0b10:
-		esize = 32
-	case 0b11:
-		esize = 64
-	}
-
-	datasize := 64
-	if op.Q == 1 {
-		datasize = 128
-	}
-	lanes := datasize / esize
+	executeVectorBinary(m, op.Q, elementBits(op.Size), op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return x + y })
+}
 
-	for i := 0; i < lanes; i++ {
-		op1 := m.V[op.Rn].Get(i, esize)
-		op2 := m.V[op.Rm].Get(i, esize)
-		result := op1 + op2
-		m.V[op.Rd].Set(i, esize, result)
-	}
+func (op *AddVector) String() string {
+	return fmt.Sprintf("add %s, %s, %s", vecName(op.Rd, op.Q, op.Size), vecName(op.Rn, op.Q, op.Size), vecName(op.Rm, op.Q, op.Size))
 }
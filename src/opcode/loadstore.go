@@ -0,0 +1,488 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// memOp describes the transfer made by one of the load/store register instructions, which is
+// determined by the size, V and opc fields that they all share.
+type memOp struct {
+	mnemonic string
+	load     bool
+	signed   bool
+	vector   bool
+	scale    uint32 // log2 of the number of bytes transferred
+	sf       uint32 // 1 if a general purpose register is used as an x register
+}
+
+// decodeMemOp returns the transfer described by size, v and opc, or false if they do not describe
+// a load or store.  PRFM, which shares the encoding space, is not a load or store.
+func decodeMemOp(size, v, opc uint32) (memOp, bool) {
+	size &= 0b11
+	opc &= 0b11
+	if v&1 == 1 {
+		scale := (opc>>1)<<2 | size
+		if scale > 4 {
+			return memOp{}, false
+		}
+		mo := memOp{mnemonic: "str", vector: true, scale: scale}
+		if opc&1 == 1 {
+			mo.mnemonic, mo.load = "ldr", true
+		}
+		return mo, true
+	}
+	suffix := [4]string{"b", "h", "", ""}[size]
+	var sf uint32
+	if size == 0b11 {
+		sf = 1
+	}
+	switch {
+	case opc == 0b00:
+		return memOp{mnemonic: "str" + suffix, scale: size, sf: sf}, true
+	case opc == 0b01:
+		return memOp{mnemonic: "ldr" + suffix, load: true, scale: size, sf: sf}, true
+	case size == 0b11:
+		return memOp{}, false
+	case size == 0b10 && opc == 0b10:
+		return memOp{mnemonic: "ldrsw", load: true, signed: true, scale: size, sf: 1}, true
+	case size == 0b10:
+		return memOp{}, false
+	}
+	// LDRSB and LDRSH extend to 64 bits if opc is 0b10 and 32 bits if it is 0b11.
+	return memOp{mnemonic: "ldrs" + suffix, load: true, signed: true, scale: size, sf: ^opc & 1}, true
+}
+
+// unscaled returns the mnemonic used for the transfer with an unscaled immediate offset.
+func (mo memOp) unscaled() string {
+	return mo.mnemonic[:2] + "u" + mo.mnemonic[2:]
+}
+
+// rtName returns the name of the register transferred.
+func (mo memOp) rtName(rt uint32) string {
+	if mo.vector {
+		return scalarName(rt, mo.scale)
+	}
+	return regName(mo.sf, rt, false)
+}
+
+// transfer loads register rt from, or stores it to, addr.  It returns false after stopping the
+// machine if the access faults.
+func (mo memOp) transfer(m *machine.Machine, rt uint32, addr uint64) bool {
+	rt &= 0b11111
+	size := 1 << mo.scale
+	var err error
+	switch {
+	case mo.vector && mo.load:
+		var lo, hi uint64
+		if size == 16 {
+			lo, err = m.Read(addr, 8)
+			if err == nil {
+				hi, err = m.Read(addr+8, 8)
+			}
+		} else {
+			lo, err = m.Read(addr, size)
+		}
+		if err == nil {
+			m.V[rt] = machine.VectorRegister{}
+			m.V[rt].Set(0, 64, lo)
+			m.V[rt].Set(1, 64, hi)
+		}
+	case mo.vector:
+		if size == 16 {
+			err = m.Write(addr, 8, m.V[rt].Get(0, 64))
+			if err == nil {
+				err = m.Write(addr+8, 8, m.V[rt].Get(1, 64))
+			}
+		} else {
+			err = m.Write(addr, size, m.V[rt].Get(0, 8*size))
+		}
+	case mo.load:
+		var data uint64
+		data, err = m.Read(addr, size)
+		if err == nil {
+			if mo.signed {
+				data = uint64(signExtend(data, 8*size))
+			}
+			setReg(m, mo.sf, rt, false, data)
+		}
+	default:
+		err = m.Write(addr, size, reg(m, 1, rt, false))
+	}
+	if err != nil {
+		m.Stop(machine.StopFault, err)
+		return false
+	}
+	return true
+}
+
+// executeLoadStoreImmediate performs a transfer at base register rn plus offset.  The offset is
+// applied before the transfer unless postIndex is set, and the address with the offset applied is
+// written back to rn if writeback is set.
+func executeLoadStoreImmediate(m *machine.Machine, mo memOp, rn, rt uint32, offset int64, writeback, postIndex bool) {
+	addr := reg(m, 1, rn, true)
+	if !postIndex {
+		addr += uint64(offset)
+	}
+	if !mo.transfer(m, rt, addr) {
+		return
+	}
+	if writeback {
+		if postIndex {
+			addr += uint64(offset)
+		}
+		setReg(m, 1, rn, true, addr)
+	}
+}
+
+// encodeLoadStore encodes one of the load/store register instructions, which differ only in op2
+// and the 12 bits that hold the offset.
+func encodeLoadStore(size, v, op2, opc, offset, rn, rt uint32) uint32 {
+	return buildUint32([]bits{
+		{size, 2},
+		{0b111, 3},
+		{v, 1},
+		{op2, 2},
+		{opc, 2},
+		{offset, 12},
+		{rn, 5},
+		{rt, 5},
+	}...)
+}
+
+// immediateOffset returns the offset bits of the 9-bit immediate load/store forms.
+func immediateOffset(imm, idx uint32) uint32 {
+	return (imm&0x1ff)<<2 | idx
+}
+
+// Load/store register (unsigned immediate): LDR, LDRB, LDRH, LDRSB, LDRSH, LDRSW, STR, STRB and STRH
+type LoadStoreUnsignedImmediate struct {
+	Size uint32 // 2 bits
+	V    uint32 // 1 bit
+	Opc  uint32 // 2 bits
+	Imm  uint32 // 12 bits
+	Rn   uint32 // 5 bits
+	Rt   uint32 // 5 bits
+}
+
+func (op *LoadStoreUnsignedImmediate) Encode() uint32 {
+	return encodeLoadStore(op.Size, op.V, 0b01, op.Opc, op.Imm, op.Rn, op.Rt)
+}
+
+func (op *LoadStoreUnsignedImmediate) Execute(m *machine.Machine) {
+	mo, _ := decodeMemOp(op.Size, op.V, op.Opc)
+	executeLoadStoreImmediate(m, mo, op.Rn, op.Rt, int64(op.Imm&0xfff)<<mo.scale, false, false)
+}
+
+func (op *LoadStoreUnsignedImmediate) String() string {
+	mo, _ := decodeMemOp(op.Size, op.V, op.Opc)
+	if op.Imm&0xfff == 0 {
+		return fmt.Sprintf("%s %s, [%s]", mo.mnemonic, mo.rtName(op.Rt), regName(1, op.Rn, true))
+	}
+	return fmt.Sprintf("%s %s, [%s, #%d]", mo.mnemonic, mo.rtName(op.Rt), regName(1, op.Rn, true), (op.Imm&0xfff)<<mo.scale)
+}
+
+// Load/store register (immediate pre-indexed)
+type LoadStorePreIndexed struct {
+	Size uint32 // 2 bits
+	V    uint32 // 1 bit
+	Opc  uint32 // 2 bits
+	Imm  uint32 // 9 bits
+	Rn   uint32 // 5 bits
+	Rt   uint32 // 5 bits
+}
+
+func (op *LoadStorePreIndexed) Encode() uint32 {
+	return encodeLoadStore(op.Size, op.V, 0b00, op.Opc, immediateOffset(op.Imm, 0b11), op.Rn, op.Rt)
+}
+
+func (op *LoadStorePreIndexed) Execute(m *machine.Machine) {
+	mo, _ := decodeMemOp(op.Size, op.V, op.Opc)
+	executeLoadStoreImmediate(m, mo, op.Rn, op.Rt, signExtend(uint64(op.Imm), 9), true, false)
+}
+
+func (op *LoadStorePreIndexed) String() string {
+	mo, _ := decodeMemOp(op.Size, op.V, op.Opc)
+	return fmt.Sprintf("%s %s, [%s, #%d]!", mo.mnemonic, mo.rtName(op.Rt), regName(1, op.Rn, true), signExtend(uint64(op.Imm), 9))
+}
+
+// Load/store register (immediate post-indexed)
+type LoadStorePostIndexed struct {
+	Size uint32 // 2 bits
+	V    uint32 // 1 bit
+	Opc  uint32 // 2 bits
+	Imm  uint32 // 9 bits
+	Rn   uint32 // 5 bits
+	Rt   uint32 // 5 bits
+}
+
+func (op *LoadStorePostIndexed) Encode() uint32 {
+	return encodeLoadStore(op.Size, op.V, 0b00, op.Opc, immediateOffset(op.Imm, 0b01), op.Rn, op.Rt)
+}
+
+func (op *LoadStorePostIndexed) Execute(m *machine.Machine) {
+	mo, _ := decodeMemOp(op.Size, op.V, op.Opc)
+	executeLoadStoreImmediate(m, mo, op.Rn, op.Rt, signExtend(uint64(op.Imm), 9), true, true)
+}
+
+func (op *LoadStorePostIndexed) String() string {
+	mo, _ := decodeMemOp(op.Size, op.V, op.Opc)
+	return fmt.Sprintf("%s %s, [%s], #%d", mo.mnemonic, mo.rtName(op.Rt), regName(1, op.Rn, true), signExtend(uint64(op.Imm), 9))
+}
+
+// Load/store register (unscaled immediate): LDUR, LDURB, LDURH, LDURSB, LDURSH, LDURSW, STUR, STURB
+// and STURH
+type LoadStoreUnscaled struct {
+	Size uint32 // 2 bits
+	V    uint32 // 1 bit
+	Opc  uint32 // 2 bits
+	Imm  uint32 // 9 bits
+	Rn   uint32 // 5 bits
+	Rt   uint32 // 5 bits
+}
+
+func (op *LoadStoreUnscaled) Encode() uint32 {
+	return encodeLoadStore(op.Size, op.V, 0b00, op.Opc, immediateOffset(op.Imm, 0b00), op.Rn, op.Rt)
+}
+
+func (op *LoadStoreUnscaled) Execute(m *machine.Machine) {
+	mo, _ := decodeMemOp(op.Size, op.V, op.Opc)
+	executeLoadStoreImmediate(m, mo, op.Rn, op.Rt, signExtend(uint64(op.Imm), 9), false, false)
+}
+
+func (op *LoadStoreUnscaled) String() string {
+	mo, _ := decodeMemOp(op.Size, op.V, op.Opc)
+	if op.Imm&0x1ff == 0 {
+		return fmt.Sprintf("%s %s, [%s]", mo.unscaled(), mo.rtName(op.Rt), regName(1, op.Rn, true))
+	}
+	return fmt.Sprintf("%s %s, [%s, #%d]", mo.unscaled(), mo.rtName(op.Rt), regName(1, op.Rn, true), signExtend(uint64(op.Imm), 9))
+}
+
+// Load/store register (register offset)
+type LoadStoreRegisterOffset struct {
+	Size   uint32 // 2 bits
+	V      uint32 // 1 bit
+	Opc    uint32 // 2 bits
+	Rm     uint32 // 5 bits
+	Option uint32 // 3 bits
+	S      uint32 // 1 bit
+	Rn     uint32 // 5 bits
+	Rt     uint32 // 5 bits
+}
+
+func (op *LoadStoreRegisterOffset) Encode() uint32 {
+	offset := 1<<11 | (op.Rm&0b11111)<<6 | (op.Option&0b111)<<3 | (op.S&1)<<2 | 0b10
+	return encodeLoadStore(op.Size, op.V, 0b00, op.Opc, offset, op.Rn, op.Rt)
+}
+
+func (op *LoadStoreRegisterOffset) Execute(m *machine.Machine) {
+	mo, _ := decodeMemOp(op.Size, op.V, op.Opc)
+	shift := uint32(0)
+	if op.S&1 == 1 {
+		shift = mo.scale
+	}
+	offset := extendReg(1, reg(m, 1, op.Rm, false), op.Option, shift)
+	mo.transfer(m, op.Rt, reg(m, 1, op.Rn, true)+offset)
+}
+
+func (op *LoadStoreRegisterOffset) String() string {
+	mo, _ := decodeMemOp(op.Size, op.V, op.Opc)
+	// Only LSL and SXTX take an x register.
+	rm := regName(op.Option&1, op.Rm, false)
+	var extend string
+	switch {
+	case op.Option&0b111 == 0b011 && op.S&1 == 1:
+		extend = fmt.Sprintf(", lsl #%d", mo.scale)
+	case op.Option&0b111 == 0b011:
+	case op.S&1 == 1:
+		extend = fmt.Sprintf(", %s #%d", extendNames[op.Option&0b111], mo.scale)
+	default:
+		extend = ", " + extendNames[op.Option&0b111]
+	}
+	return fmt.Sprintf("%s %s, [%s, %s%s]", mo.mnemonic, mo.rtName(op.Rt), regName(1, op.Rn, true), rm, extend)
+}
+
+// Load register (literal): LDR and LDRSW
+type LoadLiteral struct {
+	Opc uint32 // 2 bits
+	V   uint32 // 1 bit
+	Imm uint32 // 19 bits
+	Rt  uint32 // 5 bits
+}
+
+// literalMemOp returns the transfer made by a literal load, whose opc field encodes the size
+// differently from the other load/store register instructions.
+func literalMemOp(opc, v uint32) memOp {
+	if v&1 == 1 {
+		return memOp{mnemonic: "ldr", load: true, vector: true, scale: 2 + opc&0b11}
+	}
+	if opc&0b11 == 0b10 {
+		return memOp{mnemonic: "ldrsw", load: true, signed: true, scale: 2, sf: 1}
+	}
+	return memOp{mnemonic: "ldr", load: true, scale: 2 + opc&1, sf: opc & 1}
+}
+
+func (op *LoadLiteral) Encode() uint32 {
+	return buildUint32([]bits{
+		{op.Opc, 2},
+		{0b011, 3},
+		{op.V, 1},
+		{0b00, 2},
+		{op.Imm, 19},
+		{op.Rt, 5},
+	}...)
+}
+
+func (op *LoadLiteral) Execute(m *machine.Machine) {
+	literalMemOp(op.Opc, op.V).transfer(m, op.Rt, op.Target(m.PC))
+}
+
+func (op *LoadLiteral) Target(pc uint64) uint64 {
+	return pc + uint64(branchOffset(op.Imm, 19))
+}
+
+func (op *LoadLiteral) String() string {
+	mo := literalMemOp(op.Opc, op.V)
+	return fmt.Sprintf("%s %s, #%d", mo.mnemonic, mo.rtName(op.Rt), branchOffset(op.Imm, 19))
+}
+
+// pairMemOp returns the transfer made by each register of a load/store pair instruction, or false
+// if opc, v and l do not describe one.
+func pairMemOp(opc, v, l uint32) (memOp, bool) {
+	opc &= 0b11
+	mo := memOp{mnemonic: "stp"}
+	if l&1 == 1 {
+		mo.mnemonic, mo.load = "ldp", true
+	}
+	switch {
+	case v&1 == 1 && opc != 0b11:
+		mo.vector, mo.scale = true, 2+opc
+	case opc == 0b00:
+		mo.scale = 2
+	case opc == 0b10:
+		mo.scale, mo.sf = 3, 1
+	case opc == 0b01 && mo.load:
+		mo.mnemonic, mo.signed, mo.scale, mo.sf = "ldpsw", true, 2, 1
+	default:
+		return memOp{}, false
+	}
+	return mo, true
+}
+
+// executeLoadStorePair transfers rt and then rt2 at consecutive addresses starting at base register
+// rn plus offset, in the same way as executeLoadStoreImmediate.
+func executeLoadStorePair(m *machine.Machine, mo memOp, rn, rt, rt2 uint32, offset int64, writeback, postIndex bool) {
+	addr := reg(m, 1, rn, true)
+	if !postIndex {
+		addr += uint64(offset)
+	}
+	if !mo.transfer(m, rt, addr) || !mo.transfer(m, rt2, addr+1<<mo.scale) {
+		return
+	}
+	if writeback {
+		if postIndex {
+			addr += uint64(offset)
+		}
+		setReg(m, 1, rn, true, addr)
+	}
+}
+
+// encodeLoadStorePair encodes one of the load/store pair instructions, which differ only in op2.
+func encodeLoadStorePair(opc, v, op2, l, imm, rt2, rn, rt uint32) uint32 {
+	return buildUint32([]bits{
+		{opc, 2},
+		{0b101, 3},
+		{v, 1},
+		{0, 1},
+		{op2, 2},
+		{l, 1},
+		{imm, 7},
+		{rt2, 5},
+		{rn, 5},
+		{rt, 5},
+	}...)
+}
+
+// pairOffset returns the byte offset encoded by the 7-bit scaled immediate of a pair instruction.
+func pairOffset(mo memOp, imm uint32) int64 {
+	return signExtend(uint64(imm), 7) << mo.scale
+}
+
+// Load/store register pair (signed offset): LDP, LDPSW and STP
+type LoadStorePairOffset struct {
+	Opc uint32 // 2 bits
+	V   uint32 // 1 bit
+	L   uint32 // 1 bit
+	Imm uint32 // 7 bits
+	Rt2 uint32 // 5 bits
+	Rn  uint32 // 5 bits
+	Rt  uint32 // 5 bits
+}
+
+func (op *LoadStorePairOffset) Encode() uint32 {
+	return encodeLoadStorePair(op.Opc, op.V, 0b10, op.L, op.Imm, op.Rt2, op.Rn, op.Rt)
+}
+
+func (op *LoadStorePairOffset) Execute(m *machine.Machine) {
+	mo, _ := pairMemOp(op.Opc, op.V, op.L)
+	executeLoadStorePair(m, mo, op.Rn, op.Rt, op.Rt2, pairOffset(mo, op.Imm), false, false)
+}
+
+func (op *LoadStorePairOffset) String() string {
+	mo, _ := pairMemOp(op.Opc, op.V, op.L)
+	if op.Imm&0x7f == 0 {
+		return fmt.Sprintf("%s %s, %s, [%s]", mo.mnemonic, mo.rtName(op.Rt), mo.rtName(op.Rt2), regName(1, op.Rn, true))
+	}
+	return fmt.Sprintf("%s %s, %s, [%s, #%d]", mo.mnemonic, mo.rtName(op.Rt), mo.rtName(op.Rt2), regName(1, op.Rn, true), pairOffset(mo, op.Imm))
+}
+
+// Load/store register pair (pre-indexed)
+type LoadStorePairPreIndexed struct {
+	Opc uint32 // 2 bits
+	V   uint32 // 1 bit
+	L   uint32 // 1 bit
+	Imm uint32 // 7 bits
+	Rt2 uint32 // 5 bits
+	Rn  uint32 // 5 bits
+	Rt  uint32 // 5 bits
+}
+
+func (op *LoadStorePairPreIndexed) Encode() uint32 {
+	return encodeLoadStorePair(op.Opc, op.V, 0b11, op.L, op.Imm, op.Rt2, op.Rn, op.Rt)
+}
+
+func (op *LoadStorePairPreIndexed) Execute(m *machine.Machine) {
+	mo, _ := pairMemOp(op.Opc, op.V, op.L)
+	executeLoadStorePair(m, mo, op.Rn, op.Rt, op.Rt2, pairOffset(mo, op.Imm), true, false)
+}
+
+func (op *LoadStorePairPreIndexed) String() string {
+	mo, _ := pairMemOp(op.Opc, op.V, op.L)
+	return fmt.Sprintf("%s %s, %s, [%s, #%d]!", mo.mnemonic, mo.rtName(op.Rt), mo.rtName(op.Rt2), regName(1, op.Rn, true), pairOffset(mo, op.Imm))
+}
+
+// Load/store register pair (post-indexed)
+type LoadStorePairPostIndexed struct {
+	Opc uint32 // 2 bits
+	V   uint32 // 1 bit
+	L   uint32 // 1 bit
+	Imm uint32 // 7 bits
+	Rt2 uint32 // 5 bits
+	Rn  uint32 // 5 bits
+	Rt  uint32 // 5 bits
+}
+
+func (op *LoadStorePairPostIndexed) Encode() uint32 {
+	return encodeLoadStorePair(op.Opc, op.V, 0b01, op.L, op.Imm, op.Rt2, op.Rn, op.Rt)
+}
+
+func (op *LoadStorePairPostIndexed) Execute(m *machine.Machine) {
+	mo, _ := pairMemOp(op.Opc, op.V, op.L)
+	executeLoadStorePair(m, mo, op.Rn, op.Rt, op.Rt2, pairOffset(mo, op.Imm), true, true)
+}
+
+func (op *LoadStorePairPostIndexed) String() string {
+	mo, _ := pairMemOp(op.Opc, op.V, op.L)
+	return fmt.Sprintf("%s %s, %s, [%s], #%d", mo.mnemonic, mo.rtName(op.Rt), mo.rtName(op.Rt2), regName(1, op.Rn, true), pairOffset(mo, op.Imm))
+}
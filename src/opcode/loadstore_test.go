@@ -0,0 +1,175 @@
+package opcode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestLoadStoreExecute(t *testing.T) {
+	const pc = 0x400
+	for _, tc := range []struct {
+		inst   Instruction
+		setup  func(m *machine.Machine)
+		check  func(m *machine.Machine) bool
+		reason machine.StopReason
+	}{
+		{
+			inst:  &LoadStorePreIndexed{Size: 0b11, Imm: 0x1f0, Rn: 31, Rt: 0}, // str x0, [sp, #-16]!
+			setup: func(m *machine.Machine) { m.SP = 0x100; m.R[0] = 0x1122334455667788 },
+			check: func(m *machine.Machine) bool {
+				v, _ := m.Read(0xf0, 8)
+				return m.SP == 0xf0 && m.Memory[0xf0] == 0x88 && v == 0x1122334455667788
+			},
+		},
+		{
+			inst:  &LoadStorePostIndexed{Size: 0b11, Opc: 0b01, Imm: 16, Rn: 31, Rt: 1}, // ldr x1, [sp], #16
+			setup: func(m *machine.Machine) { m.SP = 0xf0; m.Write(0xf0, 8, 0xdeadbeefcafef00d) },
+			check: func(m *machine.Machine) bool { return m.SP == 0x100 && m.R[1] == 0xdeadbeefcafef00d },
+		},
+		{
+			inst:  &LoadStoreUnsignedImmediate{Size: 0b00, Opc: 0b10, Imm: 3, Rn: 1, Rt: 2}, // ldrsb x2, [x1, #3]
+			setup: func(m *machine.Machine) { m.R[1] = 0x80; m.Memory[0x83] = 0x80 },
+			check: func(m *machine.Machine) bool { return m.R[2] == 0xffffffffffffff80 && m.R[1] == 0x80 },
+		},
+		{
+			inst:  &LoadStoreUnsignedImmediate{Size: 0b00, Opc: 0b11, Imm: 3, Rn: 1, Rt: 2}, // ldrsb w2, [x1, #3]
+			setup: func(m *machine.Machine) { m.R[1] = 0x80; m.R[2] = ^uint64(0); m.Memory[0x83] = 0x80 },
+			check: func(m *machine.Machine) bool { return m.R[2] == 0xffffff80 },
+		},
+		{
+			inst:  &LoadStoreUnsignedImmediate{Size: 0b01, Opc: 0b01, Imm: 1, Rn: 1, Rt: 2}, // ldrh w2, [x1, #2]
+			setup: func(m *machine.Machine) { m.R[1] = 0x80; m.Write(0x80, 4, 0xabcd1234) },
+			check: func(m *machine.Machine) bool { return m.R[2] == 0xabcd },
+		},
+		{
+			inst:  &LoadStoreUnscaled{Size: 0b10, Imm: 0x1ff, Rn: 1, Rt: 31}, // stur wzr, [x1, #-1]
+			setup: func(m *machine.Machine) { m.R[1] = 0x81; m.Write(0x80, 8, ^uint64(0)) },
+			check: func(m *machine.Machine) bool { v, _ := m.Read(0x80, 8); return v == 0xffffffff00000000 },
+		},
+		{
+			inst:  &LoadStoreRegisterOffset{Size: 0b10, Opc: 0b01, Rm: 2, Option: 0b110, S: 1, Rn: 1, Rt: 0}, // ldr w0, [x1, w2, sxtw #2]
+			setup: func(m *machine.Machine) { m.R[1] = 0x200; m.R[2] = 0xffffffff; m.Write(0x1fc, 4, 0x76543210) },
+			check: func(m *machine.Machine) bool { return m.R[0] == 0x76543210 },
+		},
+		{
+			inst:  &LoadLiteral{Opc: 0b10, Imm: 0x7fffe, Rt: 0}, // ldrsw x0, #-8
+			setup: func(m *machine.Machine) { m.Write(pc-8, 4, 0x80000000) },
+			check: func(m *machine.Machine) bool { return m.R[0] == 0xffffffff80000000 },
+		},
+		{
+			inst: &LoadStoreUnsignedImmediate{V: 1, Opc: 0b11, Imm: 1, Rn: 1, Rt: 3}, // ldr q3, [x1, #16]
+			setup: func(m *machine.Machine) {
+				m.R[1] = 0x80
+				m.Write(0x90, 8, 0x0706050403020100)
+				m.Write(0x98, 8, 0x0f0e0d0c0b0a0908)
+			},
+			check: func(m *machine.Machine) bool {
+				return m.V[3] == machine.VectorRegister{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+			},
+		},
+		{
+			inst: &LoadStoreUnsignedImmediate{Size: 0b10, V: 1, Opc: 0b01, Rn: 1, Rt: 3}, // ldr s3, [x1]
+			setup: func(m *machine.Machine) {
+				m.R[1] = 0x80
+				m.Write(0x80, 4, 0x03020100)
+				m.V[3] = machine.VectorRegister{15: 0xff}
+			},
+			check: func(m *machine.Machine) bool { return m.V[3] == machine.VectorRegister{0, 1, 2, 3} },
+		},
+		{
+			inst: &LoadStorePostIndexed{Size: 0b11, V: 1, Imm: 8, Rn: 1, Rt: 3}, // str d3, [x1], #8
+			setup: func(m *machine.Machine) {
+				m.R[1] = 0x80
+				m.V[3] = machine.VectorRegister{1, 2, 3, 4, 5, 6, 7, 8, 9}
+			},
+			check: func(m *machine.Machine) bool {
+				v, _ := m.Read(0x80, 8)
+				return v == 0x0807060504030201 && m.Memory[0x88] == 0 && m.R[1] == 0x88
+			},
+		},
+		{
+			inst:  &LoadStorePairPreIndexed{Opc: 0b10, Imm: 0x7e, Rt2: 30, Rn: 31, Rt: 29}, // stp x29, x30, [sp, #-16]!
+			setup: func(m *machine.Machine) { m.SP = 0x100; m.R[29] = 0x1111; m.R[30] = 0x2222 },
+			check: func(m *machine.Machine) bool {
+				fp, _ := m.Read(0xf0, 8)
+				lr, _ := m.Read(0xf8, 8)
+				return m.SP == 0xf0 && fp == 0x1111 && lr == 0x2222
+			},
+		},
+		{
+			inst: &LoadStorePairPostIndexed{Opc: 0b10, L: 1, Imm: 2, Rt2: 30, Rn: 31, Rt: 29}, // ldp x29, x30, [sp], #16
+			setup: func(m *machine.Machine) {
+				m.SP = 0xf0
+				m.Write(0xf0, 8, 0x1111)
+				m.Write(0xf8, 8, 0x2222)
+			},
+			check: func(m *machine.Machine) bool { return m.SP == 0x100 && m.R[29] == 0x1111 && m.R[30] == 0x2222 },
+		},
+		{
+			inst:  &LoadStorePairOffset{L: 1, Imm: 0x7f, Rt2: 1, Rn: 2, Rt: 0}, // ldp w0, w1, [x2, #-4]
+			setup: func(m *machine.Machine) { m.R[2] = 0x84; m.R[0] = ^uint64(0); m.Write(0x80, 8, 0x8765432112345678) },
+			check: func(m *machine.Machine) bool { return m.R[0] == 0x12345678 && m.R[1] == 0x87654321 && m.R[2] == 0x84 },
+		},
+		{
+			inst:  &LoadStorePairOffset{Opc: 0b01, L: 1, Rt2: 1, Rn: 2, Rt: 0}, // ldpsw x0, x1, [x2]
+			setup: func(m *machine.Machine) { m.R[2] = 0x80; m.Write(0x80, 8, 0x7fffffff80000000) },
+			check: func(m *machine.Machine) bool { return m.R[0] == 0xffffffff80000000 && m.R[1] == 0x7fffffff },
+		},
+		{
+			inst: &LoadStorePairOffset{Opc: 0b10, V: 1, Imm: 1, Rt2: 2, Rn: 0, Rt: 1}, // stp q1, q2, [x0, #16]
+			setup: func(m *machine.Machine) {
+				m.R[0] = 0x80
+				m.V[1] = machine.VectorRegister{0: 1, 15: 2}
+				m.V[2] = machine.VectorRegister{0: 3, 15: 4}
+			},
+			check: func(m *machine.Machine) bool {
+				return m.Memory[0x90] == 1 && m.Memory[0x9f] == 2 && m.Memory[0xa0] == 3 && m.Memory[0xaf] == 4
+			},
+		},
+		{
+			inst: &LoadStorePairOffset{Opc: 0b01, V: 1, L: 1, Rt2: 2, Rn: 0, Rt: 1}, // ldp d1, d2, [x0]
+			setup: func(m *machine.Machine) {
+				m.R[0] = 0x80
+				m.Write(0x80, 8, 0x0807060504030201)
+				m.Write(0x88, 8, 0x1817161514131211)
+				m.V[1] = machine.VectorRegister{15: 0xff}
+			},
+			check: func(m *machine.Machine) bool {
+				return m.V[1] == machine.VectorRegister{1, 2, 3, 4, 5, 6, 7, 8} &&
+					m.V[2] == machine.VectorRegister{0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18}
+			},
+		},
+		{
+			inst:   &LoadStorePairPreIndexed{Opc: 0b10, L: 1, Imm: 0x7e, Rt2: 1, Rn: 2, Rt: 0}, // ldp x0, x1, [x2, #-16]!
+			setup:  func(m *machine.Machine) { m.R[2] = 0x1008 },
+			check:  func(m *machine.Machine) bool { return m.R[2] == 0x1008 && m.PC == pc },
+			reason: machine.StopFault,
+		},
+		{
+			inst:   &LoadStorePreIndexed{Size: 0b11, Opc: 0b01, Imm: 8, Rn: 1, Rt: 0}, // ldr x0, [x1, #8]!
+			setup:  func(m *machine.Machine) { m.R[1] = 0x1000 },
+			check:  func(m *machine.Machine) bool { return m.R[1] == 0x1000 && m.PC == pc },
+			reason: machine.StopFault,
+		},
+	} {
+		m := machine.New(0x1000)
+		m.Decode = Decoder
+		m.PC = pc
+		tc.setup(m)
+		m.Write(pc, 4, uint64(tc.inst.Encode()))
+		reason, err := m.Step()
+		if reason != tc.reason {
+			t.Errorf("%v: Step returned %v, %v, want %v", tc.inst, reason, err, tc.reason)
+			continue
+		}
+		var fault *machine.MemoryFault
+		if tc.reason == machine.StopFault && !errors.As(err, &fault) {
+			t.Errorf("%v: Step returned %v, want a *machine.MemoryFault", tc.inst, err)
+		}
+		if !tc.check(m) {
+			t.Errorf("%v: unexpected machine state after Step", tc.inst)
+		}
+	}
+}
@@ -0,0 +1,461 @@
+package opcode
+
+import (
+	"fmt"
+	gobits "math/bits"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// DecodeBitmaskImmediate returns the width bit value encoded by the N, immr and imms fields of a
+// logical immediate instruction, or false if they do not encode one.  width is 32 or 64.
+func DecodeBitmaskImmediate(n, immr, imms, width uint32) (uint64, bool) {
+	// The element size is given by the highest set bit of N:NOT(imms).
+	length := gobits.Len32((n&1)<<6|^imms&0x3f) - 1
+	if length < 1 || 1<<length > width {
+		return 0, false
+	}
+	esize := uint32(1) << length
+	levels := esize - 1
+	// A run of ones filling the whole element is reserved.
+	if imms&levels == levels {
+		return 0, false
+	}
+	s, r := imms&levels, immr&levels
+	welem := uint64(1)<<(s+1) - 1
+	// Rotate the run of ones right by r within the element and replicate it.
+	elem := (welem>>r | welem<<(esize-r)) & (1<<esize - 1)
+	var v uint64
+	for i := uint32(0); i < width; i += esize {
+		v |= elem << i
+	}
+	return v, true
+}
+
+// EncodeBitmaskImmediate returns the N, immr and imms fields that encode value as the immediate of
+// a logical instruction operating on width bits, or false if it cannot be encoded.  width is 32 or
+// 64, and only the low width bits of value are used.
+func EncodeBitmaskImmediate(value uint64, width uint32) (n, immr, imms uint32, ok bool) {
+	if width == 32 {
+		value = uint64(uint32(value))
+		value |= value << 32
+	}
+	if value == 0 || value == ^uint64(0) {
+		return 0, 0, 0, false
+	}
+	// Find the smallest element that value is a repetition of.
+	esize := uint32(64)
+	for esize > 2 {
+		half := esize / 2
+		mask := uint64(1)<<half - 1
+		if value&mask != value>>half&mask {
+			break
+		}
+		esize = half
+	}
+	mask := ^uint64(0) >> (64 - esize)
+	elem := value & mask
+	// The element must be a run of ones rotated right by immr.
+	ones := uint32(gobits.OnesCount64(elem))
+	welem := uint64(1)<<ones - 1
+	for r := uint32(0); r < esize; r++ {
+		if (welem>>r|welem<<(esize-r))&mask == elem {
+			if esize == 64 {
+				n = 1
+			}
+			return n, r, -(esize<<1)&0x3f | (ones - 1), true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// moveWidePreferred reports whether a value that can be encoded as a bitmask immediate can also be
+// written by a single MOVZ or MOVN, in which case the disassembler prefers those as mov.
+func moveWidePreferred(sf, n, immr, imms uint32) bool {
+	width := uint32(32) << (sf & 1)
+	if sf&1 == 1 && n&1 != 1 {
+		return false
+	}
+	if sf&1 == 0 && (n&1 != 0 || imms&0x20 != 0) {
+		return false
+	}
+	imms &= 0x3f
+	immr &= 0x3f
+	switch {
+	case imms < 16:
+		// At most 16 ones, which must not span a halfword boundary.
+		return (-immr)%16 <= 15-imms
+	case imms >= width-15:
+		// At most 16 zeros, which must not span a halfword boundary.
+		return immr%16 <= imms-(width-15)
+	}
+	return false
+}
+
+func encodeLogicalImmediate(opc, sf, n, immr, imms, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{opc, 2},
+		{0b100100, 6},
+		{n, 1},
+		{immr, 6},
+		{imms, 6},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// bitmask returns the immediate of a logical instruction, which must be a valid encoding.
+func bitmask(sf, n, immr, imms uint32) uint64 {
+	v, _ := DecodeBitmaskImmediate(n, immr, imms, 32<<(sf&1))
+	return v
+}
+
+// logical combines op1 and op2 using the operation selected by the opc field shared by all logical
+// instructions, and sets NZCV if it is ANDS.
+func logical(m *machine.Machine, sf, opc uint32, op1, op2 uint64) uint64 {
+	var result uint64
+	switch opc & 0b11 {
+	case 0b00, 0b11:
+		result = op1 & op2
+	case 0b01:
+		result = op1 | op2
+	case 0b10:
+		result = op1 ^ op2
+	}
+	if sf&1 == 0 {
+		result = uint64(uint32(result))
+	}
+	if opc&0b11 == 0b11 {
+		var nzcv uint32
+		if result>>(31+32*(sf&1))&1 == 1 {
+			nzcv |= machine.FlagN
+		}
+		if result == 0 {
+			nzcv |= machine.FlagZ
+		}
+		m.SetNZCV(nzcv)
+	}
+	return result
+}
+
+// executeLogicalImmediate executes a logical immediate instruction.  Rd is the stack pointer
+// unless the instruction sets flags.
+func executeLogicalImmediate(m *machine.Machine, opc, sf, n, immr, imms, rn, rd uint32) {
+	result := logical(m, sf, opc, reg(m, sf, rn, false), bitmask(sf, n, immr, imms))
+	setReg(m, sf, rd, opc&0b11 != 0b11, result)
+}
+
+// logicalImmediateOperands formats the operands of a logical immediate instruction.
+func logicalImmediateOperands(sf, n, immr, imms, rn uint32) string {
+	return fmt.Sprintf("%s, #0x%x", regName(sf, rn, false), bitmask(sf, n, immr, imms))
+}
+
+// AND (immediate)
+type AndImmediate struct {
+	Sf   uint32 // 1 bit
+	N    uint32 // 1 bit
+	Immr uint32 // 6 bits
+	Imms uint32 // 6 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *AndImmediate) Encode() uint32 {
+	return encodeLogicalImmediate(0b00, op.Sf, op.N, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *AndImmediate) Execute(m *machine.Machine) {
+	executeLogicalImmediate(m, 0b00, op.Sf, op.N, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *AndImmediate) String() string {
+	return fmt.Sprintf("and %s, %s", regName(op.Sf, op.Rd, true), logicalImmediateOperands(op.Sf, op.N, op.Immr, op.Imms, op.Rn))
+}
+
+// ORR (immediate)
+type OrrImmediate struct {
+	Sf   uint32 // 1 bit
+	N    uint32 // 1 bit
+	Immr uint32 // 6 bits
+	Imms uint32 // 6 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *OrrImmediate) Encode() uint32 {
+	return encodeLogicalImmediate(0b01, op.Sf, op.N, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *OrrImmediate) Execute(m *machine.Machine) {
+	executeLogicalImmediate(m, 0b01, op.Sf, op.N, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *OrrImmediate) String() string {
+	if op.Rn&0b11111 == 31 && !moveWidePreferred(op.Sf, op.N, op.Immr, op.Imms) {
+		return movAlias(op.Sf, op.Rd, true, bitmask(op.Sf, op.N, op.Immr, op.Imms))
+	}
+	return fmt.Sprintf("orr %s, %s", regName(op.Sf, op.Rd, true), logicalImmediateOperands(op.Sf, op.N, op.Immr, op.Imms, op.Rn))
+}
+
+// EOR (immediate)
+type EorImmediate struct {
+	Sf   uint32 // 1 bit
+	N    uint32 // 1 bit
+	Immr uint32 // 6 bits
+	Imms uint32 // 6 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *EorImmediate) Encode() uint32 {
+	return encodeLogicalImmediate(0b10, op.Sf, op.N, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *EorImmediate) Execute(m *machine.Machine) {
+	executeLogicalImmediate(m, 0b10, op.Sf, op.N, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *EorImmediate) String() string {
+	return fmt.Sprintf("eor %s, %s", regName(op.Sf, op.Rd, true), logicalImmediateOperands(op.Sf, op.N, op.Immr, op.Imms, op.Rn))
+}
+
+// ANDS (immediate)
+type AndsImmediate struct {
+	Sf   uint32 // 1 bit
+	N    uint32 // 1 bit
+	Immr uint32 // 6 bits
+	Imms uint32 // 6 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *AndsImmediate) Encode() uint32 {
+	return encodeLogicalImmediate(0b11, op.Sf, op.N, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *AndsImmediate) Execute(m *machine.Machine) {
+	executeLogicalImmediate(m, 0b11, op.Sf, op.N, op.Immr, op.Imms, op.Rn, op.Rd)
+}
+
+func (op *AndsImmediate) String() string {
+	operands := logicalImmediateOperands(op.Sf, op.N, op.Immr, op.Imms, op.Rn)
+	if op.Rd&0b11111 == 31 {
+		return "tst " + operands
+	}
+	return fmt.Sprintf("ands %s, %s", regName(op.Sf, op.Rd, false), operands)
+}
+
+func encodeLogicalShiftedRegister(opc, n, sf, shift, rm, imm, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{opc, 2},
+		{0b01010, 5},
+		{shift, 2},
+		{n, 1},
+		{rm, 5},
+		{imm, 6},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// executeLogicalShiftedRegister executes a logical shifted register instruction, which inverts the
+// shifted register first if n is 1.
+func executeLogicalShiftedRegister(m *machine.Machine, opc, n, sf, shift, rm, imm, rn, rd uint32) {
+	op2 := shiftReg(sf, reg(m, sf, rm, false), shift, imm&0b111111)
+	if n&1 == 1 {
+		op2 = ^op2
+	}
+	setReg(m, sf, rd, false, logical(m, sf, opc, reg(m, sf, rn, false), op2))
+}
+
+// logicalShiftedRegisterOperands formats the operands of a logical shifted register instruction.
+func logicalShiftedRegisterOperands(sf, shift, rm, imm, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s, %s", regName(sf, rd, false), regName(sf, rn, false), shiftedRegisterOperand(sf, rm, shift, imm))
+}
+
+// AND (shifted register)
+type AndShiftedRegister struct {
+	Sf    uint32 // 1 bit
+	Shift uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Imm   uint32 // 6 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *AndShiftedRegister) Encode() uint32 {
+	return encodeLogicalShiftedRegister(0b00, 0, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *AndShiftedRegister) Execute(m *machine.Machine) {
+	executeLogicalShiftedRegister(m, 0b00, 0, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *AndShiftedRegister) String() string {
+	return "and " + logicalShiftedRegisterOperands(op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+// BIC (shifted register)
+type BicShiftedRegister struct {
+	Sf    uint32 // 1 bit
+	Shift uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Imm   uint32 // 6 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *BicShiftedRegister) Encode() uint32 {
+	return encodeLogicalShiftedRegister(0b00, 1, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *BicShiftedRegister) Execute(m *machine.Machine) {
+	executeLogicalShiftedRegister(m, 0b00, 1, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *BicShiftedRegister) String() string {
+	return "bic " + logicalShiftedRegisterOperands(op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+// ORR (shifted register)
+type OrrShiftedRegister struct {
+	Sf    uint32 // 1 bit
+	Shift uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Imm   uint32 // 6 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *OrrShiftedRegister) Encode() uint32 {
+	return encodeLogicalShiftedRegister(0b01, 0, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *OrrShiftedRegister) Execute(m *machine.Machine) {
+	executeLogicalShiftedRegister(m, 0b01, 0, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *OrrShiftedRegister) String() string {
+	if op.Rn&0b11111 == 31 && op.Shift&0b11 == 0 && op.Imm&0b111111 == 0 {
+		return fmt.Sprintf("mov %s, %s", regName(op.Sf, op.Rd, false), regName(op.Sf, op.Rm, false))
+	}
+	return "orr " + logicalShiftedRegisterOperands(op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+// ORN (shifted register)
+type OrnShiftedRegister struct {
+	Sf    uint32 // 1 bit
+	Shift uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Imm   uint32 // 6 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *OrnShiftedRegister) Encode() uint32 {
+	return encodeLogicalShiftedRegister(0b01, 1, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *OrnShiftedRegister) Execute(m *machine.Machine) {
+	executeLogicalShiftedRegister(m, 0b01, 1, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *OrnShiftedRegister) String() string {
+	if op.Rn&0b11111 == 31 {
+		return fmt.Sprintf("mvn %s, %s", regName(op.Sf, op.Rd, false), shiftedRegisterOperand(op.Sf, op.Rm, op.Shift, op.Imm))
+	}
+	return "orn " + logicalShiftedRegisterOperands(op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+// EOR (shifted register)
+type EorShiftedRegister struct {
+	Sf    uint32 // 1 bit
+	Shift uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Imm   uint32 // 6 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *EorShiftedRegister) Encode() uint32 {
+	return encodeLogicalShiftedRegister(0b10, 0, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *EorShiftedRegister) Execute(m *machine.Machine) {
+	executeLogicalShiftedRegister(m, 0b10, 0, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *EorShiftedRegister) String() string {
+	return "eor " + logicalShiftedRegisterOperands(op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+// EON (shifted register)
+type EonShiftedRegister struct {
+	Sf    uint32 // 1 bit
+	Shift uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Imm   uint32 // 6 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *EonShiftedRegister) Encode() uint32 {
+	return encodeLogicalShiftedRegister(0b10, 1, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *EonShiftedRegister) Execute(m *machine.Machine) {
+	executeLogicalShiftedRegister(m, 0b10, 1, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *EonShiftedRegister) String() string {
+	return "eon " + logicalShiftedRegisterOperands(op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+// ANDS (shifted register)
+type AndsShiftedRegister struct {
+	Sf    uint32 // 1 bit
+	Shift uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Imm   uint32 // 6 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *AndsShiftedRegister) Encode() uint32 {
+	return encodeLogicalShiftedRegister(0b11, 0, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *AndsShiftedRegister) Execute(m *machine.Machine) {
+	executeLogicalShiftedRegister(m, 0b11, 0, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *AndsShiftedRegister) String() string {
+	if op.Rd&0b11111 == 31 {
+		return fmt.Sprintf("tst %s, %s", regName(op.Sf, op.Rn, false), shiftedRegisterOperand(op.Sf, op.Rm, op.Shift, op.Imm))
+	}
+	return "ands " + logicalShiftedRegisterOperands(op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+// BICS (shifted register)
+type BicsShiftedRegister struct {
+	Sf    uint32 // 1 bit
+	Shift uint32 // 2 bits
+	Rm    uint32 // 5 bits
+	Imm   uint32 // 6 bits
+	Rn    uint32 // 5 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *BicsShiftedRegister) Encode() uint32 {
+	return encodeLogicalShiftedRegister(0b11, 1, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *BicsShiftedRegister) Execute(m *machine.Machine) {
+	executeLogicalShiftedRegister(m, 0b11, 1, op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
+
+func (op *BicsShiftedRegister) String() string {
+	return "bics " + logicalShiftedRegisterOperands(op.Sf, op.Shift, op.Rm, op.Imm, op.Rn, op.Rd)
+}
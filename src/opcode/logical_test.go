@@ -0,0 +1,128 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestBitmaskImmediate(t *testing.T) {
+	for _, tc := range []struct {
+		value         uint64
+		width         uint32
+		n, immr, imms uint32
+	}{
+		{0x5555555555555555, 64, 0, 0, 0b111100},
+		{0xaaaaaaaaaaaaaaaa, 64, 0, 1, 0b111100},
+		{0xff, 64, 1, 0, 7},
+		{0xffff, 64, 1, 0, 15},
+		{0x8000000000000000, 64, 1, 1, 0},
+		{0xfffffffffffffffe, 64, 1, 63, 62},
+		{0x00ff00ff00ff00ff, 64, 0, 0, 0b100111},
+		{0xff00ff, 32, 0, 0, 0b100111},
+		{0x10000, 32, 0, 16, 0},
+		{0x1, 32, 0, 0, 0},
+		{0x80000001, 32, 0, 1, 1},
+	} {
+		n, immr, imms, ok := EncodeBitmaskImmediate(tc.value, tc.width)
+		if !ok || n != tc.n || immr != tc.immr || imms != tc.imms {
+			t.Errorf("EncodeBitmaskImmediate(0x%x, %d) = %d, %d, 0b%06b, %v, want %d, %d, 0b%06b, true", tc.value, tc.width, n, immr, imms, ok, tc.n, tc.immr, tc.imms)
+		}
+		if v, ok := DecodeBitmaskImmediate(tc.n, tc.immr, tc.imms, tc.width); !ok || v != tc.value {
+			t.Errorf("DecodeBitmaskImmediate(%d, %d, 0b%06b, %d) = 0x%x, %v, want 0x%x, true", tc.n, tc.immr, tc.imms, tc.width, v, ok, tc.value)
+		}
+	}
+
+	for _, tc := range []struct {
+		value uint64
+		width uint32
+	}{
+		{0, 64},
+		{^uint64(0), 64},
+		{0xffffffff, 32},
+		{0x100000000, 32},
+		{0x12345678, 64},
+		{0x5, 64},
+		{0xffffffff00000002, 64},
+	} {
+		if n, immr, imms, ok := EncodeBitmaskImmediate(tc.value, tc.width); ok {
+			t.Errorf("EncodeBitmaskImmediate(0x%x, %d) = %d, %d, 0b%06b, true, want false", tc.value, tc.width, n, immr, imms)
+		}
+	}
+
+	// Every encoding must decode to a value that encodes back to something that decodes to the
+	// same value, and there are 5334 distinct 64-bit values and 1302 distinct 32-bit values.
+	for _, tc := range []struct {
+		width uint32
+		want  int
+	}{{64, 5334}, {32, 1302}} {
+		values := map[uint64]bool{}
+		for n := uint32(0); n < 2; n++ {
+			for immr := uint32(0); immr < 64; immr++ {
+				for imms := uint32(0); imms < 64; imms++ {
+					v, ok := DecodeBitmaskImmediate(n, immr, imms, tc.width)
+					if !ok {
+						continue
+					}
+					values[v] = true
+					n2, immr2, imms2, ok := EncodeBitmaskImmediate(v, tc.width)
+					if v2, _ := DecodeBitmaskImmediate(n2, immr2, imms2, tc.width); !ok || v2 != v {
+						t.Errorf("0x%x (width %d) encodes as %d, %d, 0b%06b, %v, which decodes as 0x%x", v, tc.width, n2, immr2, imms2, ok, v2)
+					}
+				}
+			}
+		}
+		if len(values) != tc.want {
+			t.Errorf("found %d distinct %d-bit bitmask immediates, want %d", len(values), tc.width, tc.want)
+		}
+	}
+}
+
+func TestLogicalExecute(t *testing.T) {
+	const (
+		n = machine.FlagN
+		z = machine.FlagZ
+	)
+	for _, tc := range []struct {
+		asm      string
+		inst     Instruction
+		x1, x2   uint64
+		wantX0   uint64
+		wantSP   uint64
+		wantNZCV uint32
+	}{
+		{"and x0, x1, #0xff", &AndImmediate{Sf: 1, N: 1, Imms: 7, Rn: 1, Rd: 0}, 0x1234, 0, 0x34, 0, 0},
+		{"and sp, x1, #0xfffffffffffffff0", &AndImmediate{Sf: 1, N: 1, Immr: 60, Imms: 59, Rn: 1, Rd: 31}, 0x1234, 0, 0, 0x1230, 0},
+		{"eor w0, w1, #0x80000000", &EorImmediate{Immr: 1, Rn: 1, Rd: 0}, 0xffffffff00000001, 0, 0x80000001, 0, 0},
+		{"ands w0, w1, #0x80000000", &AndsImmediate{Immr: 1, Rn: 1, Rd: 0}, 0x80000000, 0, 0x80000000, 0, n},
+		{"tst x1, #0xff", &AndsImmediate{Sf: 1, N: 1, Imms: 7, Rn: 1, Rd: 31}, 0x100, 0, 0, 0, z},
+		{"and x0, x1, x2, ror #4", &AndShiftedRegister{Sf: 1, Shift: 0b11, Rm: 2, Imm: 4, Rn: 1, Rd: 0}, ^uint64(0), 0xf, 0xf000000000000000, 0, 0},
+		{"bic w0, w1, w2", &BicShiftedRegister{Rm: 2, Rn: 1, Rd: 0}, 0xff, 0xf, 0xf0, 0, 0},
+		{"orr x0, x1, x2, lsl #8", &OrrShiftedRegister{Sf: 1, Rm: 2, Imm: 8, Rn: 1, Rd: 0}, 0x1, 0x1, 0x101, 0, 0},
+		{"mvn w0, w2", &OrnShiftedRegister{Rm: 2, Rn: 31, Rd: 0}, 0, 0xf, 0xfffffff0, 0, 0},
+		{"eor x0, x1, x2, asr #63", &EorShiftedRegister{Sf: 1, Shift: 0b10, Rm: 2, Imm: 63, Rn: 1, Rd: 0}, 0x5, 0x8000000000000000, 0xfffffffffffffffa, 0, 0},
+		{"eon w0, w1, w2", &EonShiftedRegister{Rm: 2, Rn: 1, Rd: 0}, 0xf0, 0xff, 0xfffffff0, 0, 0},
+		{"ands x0, x1, x2", &AndsShiftedRegister{Sf: 1, Rm: 2, Rn: 1, Rd: 0}, 0xf0, 0x0f, 0, 0, z},
+		{"bics wzr, w1, w2", &BicsShiftedRegister{Rm: 2, Rn: 1, Rd: 31}, 0x80000001, 0x1, 0, 0, n},
+	} {
+		m := load(tc.inst)
+		m.R[1], m.R[2] = tc.x1, tc.x2
+		m.SetNZCV(machine.FlagC | machine.FlagV)
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.R[0] != tc.wantX0 || m.SP != tc.wantSP {
+			t.Errorf("%s: x0 = 0x%x, sp = 0x%x, want 0x%x, 0x%x", tc.asm, m.R[0], m.SP, tc.wantX0, tc.wantSP)
+		}
+		wantNZCV := tc.wantNZCV
+		switch tc.inst.(type) {
+		case *AndsImmediate, *AndsShiftedRegister, *BicsShiftedRegister:
+		default:
+			wantNZCV = machine.FlagC | machine.FlagV
+		}
+		if m.NZCV() != wantNZCV {
+			t.Errorf("%s: NZCV = %04b, want %04b", tc.asm, m.NZCV(), wantNZCV)
+		}
+	}
+}
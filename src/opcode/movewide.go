@@ -0,0 +1,103 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func encodeMoveWide(sf, opc, hw, imm, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{opc, 2},
+		{0b100101, 6},
+		{hw, 2},
+		{imm, 16},
+		{rd, 5},
+	}...)
+}
+
+// moveWideOperands formats the destination and shifted immediate of a move wide instruction.
+func moveWideOperands(sf, hw, imm, rd uint32) string {
+	if hw&0b11 == 0 {
+		return fmt.Sprintf("%s, #%d", regName(sf, rd, false), imm&0xffff)
+	}
+	return fmt.Sprintf("%s, #%d, lsl #%d", regName(sf, rd, false), imm&0xffff, 16*(hw&0b11))
+}
+
+// movAlias formats the mov alias that writes v to a register, which is printed as a signed number.
+func movAlias(sf, rd uint32, sp bool, v uint64) string {
+	if sf&1 == 0 {
+		return fmt.Sprintf("mov %s, #%d", regName(sf, rd, sp), int32(v))
+	}
+	return fmt.Sprintf("mov %s, #%d", regName(sf, rd, sp), int64(v))
+}
+
+// MOVN
+type Movn struct {
+	Sf  uint32 // 1 bit
+	Hw  uint32 // 2 bits
+	Imm uint32 // 16 bits
+	Rd  uint32 // 5 bits
+}
+
+func (op *Movn) Encode() uint32 {
+	return encodeMoveWide(op.Sf, 0b00, op.Hw, op.Imm, op.Rd)
+}
+
+func (op *Movn) Execute(m *machine.Machine) {
+	setReg(m, op.Sf, op.Rd, false, ^(uint64(op.Imm&0xffff) << (16 * (op.Hw & 0b11))))
+}
+
+func (op *Movn) String() string {
+	// MOV is not preferred if MOVZ could write the same value, or if it is all ones in a w register.
+	if (op.Imm&0xffff != 0 || op.Hw&0b11 == 0) && !(op.Sf&1 == 0 && op.Imm&0xffff == 0xffff) {
+		return movAlias(op.Sf, op.Rd, false, ^(uint64(op.Imm&0xffff) << (16 * (op.Hw & 0b11))))
+	}
+	return "movn " + moveWideOperands(op.Sf, op.Hw, op.Imm, op.Rd)
+}
+
+// MOVZ
+type Movz struct {
+	Sf  uint32 // 1 bit
+	Hw  uint32 // 2 bits
+	Imm uint32 // 16 bits
+	Rd  uint32 // 5 bits
+}
+
+func (op *Movz) Encode() uint32 {
+	return encodeMoveWide(op.Sf, 0b10, op.Hw, op.Imm, op.Rd)
+}
+
+func (op *Movz) Execute(m *machine.Machine) {
+	setReg(m, op.Sf, op.Rd, false, uint64(op.Imm&0xffff)<<(16*(op.Hw&0b11)))
+}
+
+func (op *Movz) String() string {
+	if op.Imm&0xffff != 0 || op.Hw&0b11 == 0 {
+		return movAlias(op.Sf, op.Rd, false, uint64(op.Imm&0xffff)<<(16*(op.Hw&0b11)))
+	}
+	return "movz " + moveWideOperands(op.Sf, op.Hw, op.Imm, op.Rd)
+}
+
+// MOVK
+type Movk struct {
+	Sf  uint32 // 1 bit
+	Hw  uint32 // 2 bits
+	Imm uint32 // 16 bits
+	Rd  uint32 // 5 bits
+}
+
+func (op *Movk) Encode() uint32 {
+	return encodeMoveWide(op.Sf, 0b11, op.Hw, op.Imm, op.Rd)
+}
+
+func (op *Movk) Execute(m *machine.Machine) {
+	shift := 16 * (op.Hw & 0b11)
+	v := reg(m, op.Sf, op.Rd, false)&^(0xffff<<shift) | uint64(op.Imm&0xffff)<<shift
+	setReg(m, op.Sf, op.Rd, false, v)
+}
+
+func (op *Movk) String() string {
+	return "movk " + moveWideOperands(op.Sf, op.Hw, op.Imm, op.Rd)
+}
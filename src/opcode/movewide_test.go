@@ -0,0 +1,36 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestMoveWideExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm  string
+		inst Instruction
+		x0   uint64
+		want uint64
+	}{
+		{"movz x0, #0x1234, lsl #16", &Movz{Sf: 1, Hw: 1, Imm: 0x1234}, ^uint64(0), 0x12340000},
+		{"movz w0, #0xffff, lsl #16", &Movz{Hw: 1, Imm: 0xffff}, ^uint64(0), 0xffff0000},
+		{"movn x0, #0", &Movn{Sf: 1}, 0, ^uint64(0)},
+		{"movn x0, #0x1234, lsl #48", &Movn{Sf: 1, Hw: 3, Imm: 0x1234}, 0, 0xedcbffffffffffff},
+		{"movn w0, #5", &Movn{Imm: 5}, 0, 0xfffffffa},
+		{"movk x0, #0xbeef, lsl #32", &Movk{Sf: 1, Hw: 2, Imm: 0xbeef}, 0x1111222233334444, 0x1111beef33334444},
+		{"movk w0, #0xbeef", &Movk{Imm: 0xbeef}, 0x1111222233334444, 0x3333beef},
+		{"mov x0, #0x5555555555555555", &OrrImmediate{Sf: 1, N: 0, Immr: 0, Imms: 0b111100, Rn: 31}, 7, 0x5555555555555555},
+		{"orr w0, w0, #0xff00ff", &OrrImmediate{Immr: 0, Imms: 0b100111}, 0xffffffff0000f000, 0x00fff0ff},
+	} {
+		m := load(tc.inst)
+		m.R[0] = tc.x0
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.R[0] != tc.want {
+			t.Errorf("%s: x0 = 0x%x, want 0x%x", tc.asm, m.R[0], tc.want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package opcode
+
+import (
+	"fmt"
+	gobits "math/bits"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func encodeDataProcessing3Source(sf, op31, o0, rm, ra, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{0b0011011, 7},
+		{op31, 3},
+		{rm, 5},
+		{o0, 1},
+		{ra, 5},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// multiplyAdd writes ra plus or minus op1 * op2 to rd, at the width given by sf.
+func multiplyAdd(m *machine.Machine, sf uint32, sub bool, op1, op2 uint64, ra, rd uint32) {
+	product := op1 * op2
+	if sub {
+		product = -product
+	}
+	setReg(m, sf, rd, false, reg(m, sf, ra, false)+product)
+}
+
+// multiplyHigh returns the upper 64 bits of the 128-bit product of x and y, which are treated as
+// two's complement numbers if signed is true.
+func multiplyHigh(x, y uint64, signed bool) uint64 {
+	hi, _ := gobits.Mul64(x, y)
+	if signed {
+		// Subtracting 2^64 from a negative operand subtracts the other operand from the high half.
+		if int64(x) < 0 {
+			hi -= y
+		}
+		if int64(y) < 0 {
+			hi -= x
+		}
+	}
+	return hi
+}
+
+// longOperand returns the low 32 bits of register n, sign extended if signed is true.
+func longOperand(m *machine.Machine, n uint32, signed bool) uint64 {
+	v := reg(m, 0, n, false)
+	if signed {
+		return uint64(int64(int32(v)))
+	}
+	return v
+}
+
+// multiplyOperands formats the registers of a 3 source instruction, omitting ra if it is the zero
+// register and the instruction is being printed as its multiply alias.
+func multiplyOperands(sf, nsf, rm, ra, rn, rd uint32, alias bool) string {
+	s := fmt.Sprintf("%s, %s, %s", regName(sf, rd, false), regName(nsf, rn, false), regName(nsf, rm, false))
+	if alias {
+		return s
+	}
+	return fmt.Sprintf("%s, %s", s, regName(sf, ra, false))
+}
+
+// MADD
+type Madd struct {
+	Sf uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Ra uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Madd) Encode() uint32 {
+	return encodeDataProcessing3Source(op.Sf, 0b000, 0, op.Rm, op.Ra, op.Rn, op.Rd)
+}
+
+func (op *Madd) Execute(m *machine.Machine) {
+	multiplyAdd(m, op.Sf, false, reg(m, op.Sf, op.Rn, false), reg(m, op.Sf, op.Rm, false), op.Ra, op.Rd)
+}
+
+func (op *Madd) String() string {
+	if op.Ra&0b11111 == 31 {
+		return "mul " + multiplyOperands(op.Sf, op.Sf, op.Rm, op.Ra, op.Rn, op.Rd, true)
+	}
+	return "madd " + multiplyOperands(op.Sf, op.Sf, op.Rm, op.Ra, op.Rn, op.Rd, false)
+}
+
+// MSUB
+type Msub struct {
+	Sf uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Ra uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Msub) Encode() uint32 {
+	return encodeDataProcessing3Source(op.Sf, 0b000, 1, op.Rm, op.Ra, op.Rn, op.Rd)
+}
+
+func (op *Msub) Execute(m *machine.Machine) {
+	multiplyAdd(m, op.Sf, true, reg(m, op.Sf, op.Rn, false), reg(m, op.Sf, op.Rm, false), op.Ra, op.Rd)
+}
+
+func (op *Msub) String() string {
+	if op.Ra&0b11111 == 31 {
+		return "mneg " + multiplyOperands(op.Sf, op.Sf, op.Rm, op.Ra, op.Rn, op.Rd, true)
+	}
+	return "msub " + multiplyOperands(op.Sf, op.Sf, op.Rm, op.Ra, op.Rn, op.Rd, false)
+}
+
+// SMADDL
+type Smaddl struct {
+	Rm uint32 // 5 bits
+	Ra uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Smaddl) Encode() uint32 {
+	return encodeDataProcessing3Source(1, 0b001, 0, op.Rm, op.Ra, op.Rn, op.Rd)
+}
+
+func (op *Smaddl) Execute(m *machine.Machine) {
+	multiplyAdd(m, 1, false, longOperand(m, op.Rn, true), longOperand(m, op.Rm, true), op.Ra, op.Rd)
+}
+
+func (op *Smaddl) String() string {
+	if op.Ra&0b11111 == 31 {
+		return "smull " + multiplyOperands(1, 0, op.Rm, op.Ra, op.Rn, op.Rd, true)
+	}
+	return "smaddl " + multiplyOperands(1, 0, op.Rm, op.Ra, op.Rn, op.Rd, false)
+}
+
+// SMSUBL
+type Smsubl struct {
+	Rm uint32 // 5 bits
+	Ra uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Smsubl) Encode() uint32 {
+	return encodeDataProcessing3Source(1, 0b001, 1, op.Rm, op.Ra, op.Rn, op.Rd)
+}
+
+func (op *Smsubl) Execute(m *machine.Machine) {
+	multiplyAdd(m, 1, true, longOperand(m, op.Rn, true), longOperand(m, op.Rm, true), op.Ra, op.Rd)
+}
+
+func (op *Smsubl) String() string {
+	if op.Ra&0b11111 == 31 {
+		return "smnegl " + multiplyOperands(1, 0, op.Rm, op.Ra, op.Rn, op.Rd, true)
+	}
+	return "smsubl " + multiplyOperands(1, 0, op.Rm, op.Ra, op.Rn, op.Rd, false)
+}
+
+// UMADDL
+type Umaddl struct {
+	Rm uint32 // 5 bits
+	Ra uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Umaddl) Encode() uint32 {
+	return encodeDataProcessing3Source(1, 0b101, 0, op.Rm, op.Ra, op.Rn, op.Rd)
+}
+
+func (op *Umaddl) Execute(m *machine.Machine) {
+	multiplyAdd(m, 1, false, longOperand(m, op.Rn, false), longOperand(m, op.Rm, false), op.Ra, op.Rd)
+}
+
+func (op *Umaddl) String() string {
+	if op.Ra&0b11111 == 31 {
+		return "umull " + multiplyOperands(1, 0, op.Rm, op.Ra, op.Rn, op.Rd, true)
+	}
+	return "umaddl " + multiplyOperands(1, 0, op.Rm, op.Ra, op.Rn, op.Rd, false)
+}
+
+// UMSUBL
+type Umsubl struct {
+	Rm uint32 // 5 bits
+	Ra uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Umsubl) Encode() uint32 {
+	return encodeDataProcessing3Source(1, 0b101, 1, op.Rm, op.Ra, op.Rn, op.Rd)
+}
+
+func (op *Umsubl) Execute(m *machine.Machine) {
+	multiplyAdd(m, 1, true, longOperand(m, op.Rn, false), longOperand(m, op.Rm, false), op.Ra, op.Rd)
+}
+
+func (op *Umsubl) String() string {
+	if op.Ra&0b11111 == 31 {
+		return "umnegl " + multiplyOperands(1, 0, op.Rm, op.Ra, op.Rn, op.Rd, true)
+	}
+	return "umsubl " + multiplyOperands(1, 0, op.Rm, op.Ra, op.Rn, op.Rd, false)
+}
+
+// SMULH
+type Smulh struct {
+	Rm uint32 // 5 bits
+	Ra uint32 // 5 bits, should be 31
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Smulh) Encode() uint32 {
+	return encodeDataProcessing3Source(1, 0b010, 0, op.Rm, op.Ra, op.Rn, op.Rd)
+}
+
+func (op *Smulh) Execute(m *machine.Machine) {
+	setReg(m, 1, op.Rd, false, multiplyHigh(reg(m, 1, op.Rn, false), reg(m, 1, op.Rm, false), true))
+}
+
+func (op *Smulh) String() string {
+	return "smulh " + multiplyOperands(1, 1, op.Rm, op.Ra, op.Rn, op.Rd, true)
+}
+
+// UMULH
+type Umulh struct {
+	Rm uint32 // 5 bits
+	Ra uint32 // 5 bits, should be 31
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Umulh) Encode() uint32 {
+	return encodeDataProcessing3Source(1, 0b110, 0, op.Rm, op.Ra, op.Rn, op.Rd)
+}
+
+func (op *Umulh) Execute(m *machine.Machine) {
+	setReg(m, 1, op.Rd, false, multiplyHigh(reg(m, 1, op.Rn, false), reg(m, 1, op.Rm, false), false))
+}
+
+func (op *Umulh) String() string {
+	return "umulh " + multiplyOperands(1, 1, op.Rm, op.Ra, op.Rn, op.Rd, true)
+}
@@ -0,0 +1,47 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestMultiplyExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm        string
+		inst       Instruction
+		x1, x2, x3 uint64
+		want       uint64
+	}{
+		{"madd x0, x1, x2, x3", &Madd{Sf: 1, Rm: 2, Ra: 3, Rn: 1}, 6, 7, 100, 142},
+		{"mul w0, w1, w2", &Madd{Rm: 2, Ra: 31, Rn: 1}, 0x10000, 0x10001, 0, 0x10000},
+		{"mul x0, x1, x2", &Madd{Sf: 1, Rm: 2, Ra: 31, Rn: 1}, 0xffffffffffffffff, 3, 0, 0xfffffffffffffffd},
+		{"msub w0, w1, w2, w3", &Msub{Rm: 2, Ra: 3, Rn: 1}, 3, 4, 0xffffffff00000005, 0xfffffff9},
+		{"mneg x0, x1, x2", &Msub{Sf: 1, Rm: 2, Ra: 31, Rn: 1}, 3, 4, 0, 0xfffffffffffffff4},
+		{"smull x0, w1, w2", &Smaddl{Rm: 2, Ra: 31, Rn: 1}, 0xffffffff, 0x80000000, 0, 0x80000000},
+		{"smaddl x0, w1, w2, x3", &Smaddl{Rm: 2, Ra: 3, Rn: 1}, 0x123456789, 0xfffffffe, 1, 0xffffffffb97530ef},
+		{"smnegl x0, w1, w2", &Smsubl{Rm: 2, Ra: 31, Rn: 1}, 0x80000000, 0x80000000, 0, 0xc000000000000000},
+		{"umull x0, w1, w2", &Umaddl{Rm: 2, Ra: 31, Rn: 1}, 0xffffffff, 0xffffffff, 0, 0xfffffffe00000001},
+		{"umsubl x0, w1, w2, x3", &Umsubl{Rm: 2, Ra: 3, Rn: 1}, 0x100000002, 3, 10, 4},
+		{"smulh x0, x1, x2", &Smulh{Rm: 2, Ra: 31, Rn: 1}, 0xffffffffffffffff, 0xffffffffffffffff, 0, 0},
+		{"smulh x0, x1, x2", &Smulh{Rm: 2, Ra: 31, Rn: 1}, 0x8000000000000000, 0x8000000000000000, 0, 0x4000000000000000},
+		{"smulh x0, x1, x2", &Smulh{Rm: 2, Ra: 31, Rn: 1}, 0x8000000000000000, 0x7fffffffffffffff, 0, 0xc000000000000000},
+		{"smulh x0, x1, x2", &Smulh{Rm: 2, Ra: 31, Rn: 1}, 0x123456789abcdef0, 0xfedcba9876543210, 0, 0xffeb49923cc09532},
+		{"umulh x0, x1, x2", &Umulh{Rm: 2, Ra: 31, Rn: 1}, 0xffffffffffffffff, 0xffffffffffffffff, 0, 0xfffffffffffffffe},
+		{"umulh x0, x1, x2", &Umulh{Rm: 2, Ra: 31, Rn: 1}, 0x8000000000000000, 0x7fffffffffffffff, 0, 0x3fffffffffffffff},
+		{"umulh x0, x1, x2", &Umulh{Rm: 2, Ra: 31, Rn: 1}, 0x123456789abcdef0, 0xfedcba9876543210, 0, 0x121fa00ad77d7422},
+	} {
+		m := load(tc.inst)
+		m.R[1], m.R[2], m.R[3] = tc.x1, tc.x2, tc.x3
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.R[0] != tc.want {
+			t.Errorf("%s: x0 = 0x%x, want 0x%x", tc.asm, m.R[0], tc.want)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
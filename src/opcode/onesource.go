@@ -0,0 +1,178 @@
+package opcode
+
+import (
+	"fmt"
+	gobits "math/bits"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func encodeDataProcessing1Source(sf, opcode, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{1, 1},
+		{0, 1}, // S
+		{0b11010110, 8},
+		{0b00000, 5}, // opcode2
+		{opcode, 6},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// countLeadingZeros returns the number of zero bits above the highest one bit in the low n bits
+// of x.
+func countLeadingZeros(x uint64, n int) uint64 {
+	if lz := gobits.LeadingZeros64(x << (64 - n)); lz < n {
+		return uint64(lz)
+	}
+	return uint64(n)
+}
+
+// countLeadingSignBits returns the number of bits below the top bit of the low n bits of x that
+// are the same as it.
+func countLeadingSignBits(x uint64, n int) uint64 {
+	return countLeadingZeros(x>>1^x, n-1)
+}
+
+// reverseBits reverses the order of the low n bits of x.
+func reverseBits(x uint64, n int) uint64 {
+	return gobits.Reverse64(x) >> (64 - n)
+}
+
+// reverseBytes reverses the order of the bytes in each container-bit chunk of x.
+func reverseBytes(x uint64, container int) uint64 {
+	var result uint64
+	for i := 0; i < 8; i++ {
+		j := i ^ (container/8 - 1)
+		result |= (x >> (8 * i) & 0xff) << (8 * j)
+	}
+	return result
+}
+
+// executeDataProcessing1Source sets Rd to f applied to Rn, where f is given the register size.
+func executeDataProcessing1Source(m *machine.Machine, sf, rn, rd uint32, f func(x uint64, n int) uint64) {
+	n := 32
+	if sf&1 == 1 {
+		n = 64
+	}
+	setReg(m, sf, rd, false, f(reg(m, sf, rn, false), n))
+}
+
+// oneSourceOperands formats the operands of a data processing (1 source) instruction.
+func oneSourceOperands(sf, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s", regName(sf, rd, false), regName(sf, rn, false))
+}
+
+// RBIT
+type Rbit struct {
+	Sf uint32 // 1 bit
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Rbit) Encode() uint32 {
+	return encodeDataProcessing1Source(op.Sf, 0b000000, op.Rn, op.Rd)
+}
+
+func (op *Rbit) Execute(m *machine.Machine) {
+	executeDataProcessing1Source(m, op.Sf, op.Rn, op.Rd, reverseBits)
+}
+
+func (op *Rbit) String() string {
+	return "rbit " + oneSourceOperands(op.Sf, op.Rn, op.Rd)
+}
+
+// REV16
+type Rev16 struct {
+	Sf uint32 // 1 bit
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Rev16) Encode() uint32 {
+	return encodeDataProcessing1Source(op.Sf, 0b000001, op.Rn, op.Rd)
+}
+
+func (op *Rev16) Execute(m *machine.Machine) {
+	executeDataProcessing1Source(m, op.Sf, op.Rn, op.Rd, func(x uint64, n int) uint64 { return reverseBytes(x, 16) })
+}
+
+func (op *Rev16) String() string {
+	return "rev16 " + oneSourceOperands(op.Sf, op.Rn, op.Rd)
+}
+
+// REV32
+type Rev32 struct {
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Rev32) Encode() uint32 {
+	return encodeDataProcessing1Source(1, 0b000010, op.Rn, op.Rd)
+}
+
+func (op *Rev32) Execute(m *machine.Machine) {
+	executeDataProcessing1Source(m, 1, op.Rn, op.Rd, func(x uint64, n int) uint64 { return reverseBytes(x, 32) })
+}
+
+func (op *Rev32) String() string {
+	return "rev32 " + oneSourceOperands(1, op.Rn, op.Rd)
+}
+
+// REV
+type Rev struct {
+	Sf uint32 // 1 bit
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Rev) Encode() uint32 {
+	return encodeDataProcessing1Source(op.Sf, 0b000010|op.Sf&1, op.Rn, op.Rd)
+}
+
+func (op *Rev) Execute(m *machine.Machine) {
+	executeDataProcessing1Source(m, op.Sf, op.Rn, op.Rd, reverseBytes)
+}
+
+func (op *Rev) String() string {
+	return "rev " + oneSourceOperands(op.Sf, op.Rn, op.Rd)
+}
+
+// CLZ
+type Clz struct {
+	Sf uint32 // 1 bit
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Clz) Encode() uint32 {
+	return encodeDataProcessing1Source(op.Sf, 0b000100, op.Rn, op.Rd)
+}
+
+func (op *Clz) Execute(m *machine.Machine) {
+	executeDataProcessing1Source(m, op.Sf, op.Rn, op.Rd, countLeadingZeros)
+}
+
+func (op *Clz) String() string {
+	return "clz " + oneSourceOperands(op.Sf, op.Rn, op.Rd)
+}
+
+// CLS
+type Cls struct {
+	Sf uint32 // 1 bit
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Cls) Encode() uint32 {
+	return encodeDataProcessing1Source(op.Sf, 0b000101, op.Rn, op.Rd)
+}
+
+func (op *Cls) Execute(m *machine.Machine) {
+	executeDataProcessing1Source(m, op.Sf, op.Rn, op.Rd, countLeadingSignBits)
+}
+
+func (op *Cls) String() string {
+	return "cls " + oneSourceOperands(op.Sf, op.Rn, op.Rd)
+}
@@ -0,0 +1,48 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestOneSourceExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm  string
+		inst Instruction
+		x1   uint64
+		want uint64
+	}{
+		{"rbit w0, w1", &Rbit{Rn: 1}, 0x100000001, 0x80000000},
+		{"rbit x0, x1", &Rbit{Sf: 1, Rn: 1}, 0x0123456789abcdef, 0xf7b3d591e6a2c480},
+		{"rev16 w0, w1", &Rev16{Rn: 1}, 0xffffffff11223344, 0x22114433},
+		{"rev16 x0, x1", &Rev16{Sf: 1, Rn: 1}, 0x1122334455667788, 0x2211443366558877},
+		{"rev32 x0, x1", &Rev32{Rn: 1}, 0x1122334455667788, 0x4433221188776655},
+		{"rev w0, w1", &Rev{Rn: 1}, 0xffffffff11223344, 0x44332211},
+		{"rev x0, x1", &Rev{Sf: 1, Rn: 1}, 0x1122334455667788, 0x8877665544332211},
+		{"clz w0, w1", &Clz{Rn: 1}, 0xffffffff00000000, 32},
+		{"clz w0, w1", &Clz{Rn: 1}, 0x10000, 15},
+		{"clz x0, x1", &Clz{Sf: 1, Rn: 1}, 0, 64},
+		{"clz x0, x1", &Clz{Sf: 1, Rn: 1}, 1, 63},
+		{"clz x0, x1", &Clz{Sf: 1, Rn: 1}, 0x8000000000000000, 0},
+		{"cls w0, w1", &Cls{Rn: 1}, 0, 31},
+		{"cls w0, w1", &Cls{Rn: 1}, 0xffffffff, 31},
+		{"cls w0, w1", &Cls{Rn: 1}, 0x10000, 14},
+		{"cls x0, x1", &Cls{Sf: 1, Rn: 1}, 0xc000000000000000, 1},
+		{"cls x0, x1", &Cls{Sf: 1, Rn: 1}, 1, 62},
+		{"cls x0, x1", &Cls{Sf: 1, Rn: 1}, 0x7fffffffffffffff, 0},
+	} {
+		m := load(tc.inst)
+		m.R[1] = tc.x1
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.R[0] != tc.want {
+			t.Errorf("%s with x1 = 0x%x: x0 = 0x%x, want 0x%x", tc.asm, tc.x1, m.R[0], tc.want)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func encodePCRelative(op, immlo, immhi, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{op, 1},
+		{immlo, 2},
+		{0b10000, 5},
+		{immhi, 19},
+		{rd, 5},
+	}...)
+}
+
+// pcRelativeOffset returns the signed 21-bit immediate formed by immhi:immlo.
+func pcRelativeOffset(immlo, immhi uint32) int64 {
+	return signExtend(uint64(immhi&0x7ffff)<<2|uint64(immlo&0b11), 21)
+}
+
+// ADR
+type Adr struct {
+	Immlo uint32 // 2 bits
+	Immhi uint32 // 19 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Adr) Encode() uint32 {
+	return encodePCRelative(0, op.Immlo, op.Immhi, op.Rd)
+}
+
+func (op *Adr) Execute(m *machine.Machine) {
+	setReg(m, 1, op.Rd, false, op.Target(m.PC))
+}
+
+func (op *Adr) Target(pc uint64) uint64 {
+	return pc + uint64(pcRelativeOffset(op.Immlo, op.Immhi))
+}
+
+func (op *Adr) String() string {
+	return fmt.Sprintf("adr %s, #%d", regName(1, op.Rd, false), pcRelativeOffset(op.Immlo, op.Immhi))
+}
+
+// ADRP
+type Adrp struct {
+	Immlo uint32 // 2 bits
+	Immhi uint32 // 19 bits
+	Rd    uint32 // 5 bits
+}
+
+func (op *Adrp) Encode() uint32 {
+	return encodePCRelative(1, op.Immlo, op.Immhi, op.Rd)
+}
+
+func (op *Adrp) Execute(m *machine.Machine) {
+	setReg(m, 1, op.Rd, false, op.Target(m.PC))
+}
+
+// Target returns the address of the 4KB page that the instruction refers to, which is an offset
+// in pages from the page containing pc.
+func (op *Adrp) Target(pc uint64) uint64 {
+	return pc&^0xfff + uint64(pcRelativeOffset(op.Immlo, op.Immhi)<<12)
+}
+
+func (op *Adrp) String() string {
+	return fmt.Sprintf("adrp %s, #%d", regName(1, op.Rd, false), pcRelativeOffset(op.Immlo, op.Immhi)<<12)
+}
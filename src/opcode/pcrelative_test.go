@@ -0,0 +1,49 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestPCRelativeExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm  string
+		inst Instruction
+		pc   uint64
+		want uint64
+	}{
+		{"adr x0, #16", &Adr{Immhi: 4}, 0x1000, 0x1010},
+		{"adr x0, #1", &Adr{Immlo: 1}, 0x1000, 0x1001},
+		{"adr x0, #-1", &Adr{Immlo: 3, Immhi: 0x7ffff}, 0x1000, 0xfff},
+		{"adr x0, #-1048576", &Adr{Immhi: 0x40000}, 0x100000, 0},
+		{"adrp x0, #16384", &Adrp{Immlo: 0, Immhi: 1}, 0x1234, 0x5000},
+		{"adrp x0, #-4096", &Adrp{Immlo: 3, Immhi: 0x7ffff}, 0x2ffc, 0x1000},
+		{"adrp x0, #0", &Adrp{}, 0xfff, 0},
+		{"adrp x0, #4294963200", &Adrp{Immlo: 3, Immhi: 0x3ffff}, 0x100000000, 0x1fffff000},
+	} {
+		m := load(tc.inst)
+		m.PC = tc.pc
+		tc.inst.Execute(m)
+		if m.R[0] != tc.want {
+			t.Errorf("%s at 0x%x: x0 = 0x%x, want 0x%x", tc.asm, tc.pc, m.R[0], tc.want)
+		}
+		if got := tc.inst.(PCRelative).Target(tc.pc); got != tc.want {
+			t.Errorf("%s at 0x%x: Target = 0x%x, want 0x%x", tc.asm, tc.pc, got, tc.want)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
+
+func TestPCRelativeZeroRegister(t *testing.T) {
+	m := load(&Adr{Immhi: 4, Rd: 31})
+	m.SP = 0x100
+	if reason, err := m.Step(); reason != machine.StopNone {
+		t.Fatalf("Step returned %v, %v", reason, err)
+	}
+	if m.SP != 0x100 {
+		t.Errorf("adr xzr, #16 changed sp to 0x%x", m.SP)
+	}
+}
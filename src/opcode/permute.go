@@ -0,0 +1,308 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// encodeSIMDPermute encodes an instruction from the Advanced SIMD permute group.
+func encodeSIMDPermute(q, size, opcode, rm, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{0, 1},
+		{q, 1},
+		{0b001110, 6},
+		{size, 2},
+		{0, 1},
+		{rm, 5},
+		{0, 1},
+		{opcode, 3},
+		{0b10, 2},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// executeVectorPermute sets each esize-bit element i of Vd to element f(i) of the elements of Vn
+// followed by the elements of Vm, clearing the upper half of Vd if q is 0.
+func executeVectorPermute(m *machine.Machine, q uint32, esize int, rn, rm, rd uint32, f func(i int) int) {
+	xs := append(elements(m, q, esize, rn), elements(m, q, esize, rm)...)
+	var result machine.VectorRegister
+	for i := 0; i < len(xs)/2; i++ {
+		result.Set(i, esize, xs[f(i)])
+	}
+	m.V[rd&0b11111] = result
+}
+
+// executeZip interleaves the elements of the lower (part 0) or upper (part 1) halves of Vn and Vm.
+func executeZip(m *machine.Machine, q, size uint32, part int, rn, rm, rd uint32) {
+	esize := elementBits(size)
+	lanes := vectorBits(q) / esize
+	executeVectorPermute(m, q, esize, rn, rm, rd, func(i int) int {
+		return i%2*lanes + part*lanes/2 + i/2
+	})
+}
+
+// executeUzp takes the even (part 0) or odd (part 1) elements of Vn followed by Vm.
+func executeUzp(m *machine.Machine, q, size uint32, part int, rn, rm, rd uint32) {
+	esize := elementBits(size)
+	executeVectorPermute(m, q, esize, rn, rm, rd, func(i int) int { return 2*i + part })
+}
+
+// executeTrn interleaves the even (part 0) or odd (part 1) elements of Vn and Vm, as if
+// transposing 2x2 matrices.
+func executeTrn(m *machine.Machine, q, size uint32, part int, rn, rm, rd uint32) {
+	esize := elementBits(size)
+	lanes := vectorBits(q) / esize
+	executeVectorPermute(m, q, esize, rn, rm, rd, func(i int) int {
+		return i%2*lanes + i&^1 + part
+	})
+}
+
+// ZIP1
+type Zip1 struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Zip1) Encode() uint32 {
+	return encodeSIMDPermute(op.Q, op.Size, 0b011, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Zip1) Execute(m *machine.Machine) {
+	executeZip(m, op.Q, op.Size, 0, op.Rn, op.Rm, op.Rd)
+}
+
+func (op *Zip1) String() string {
+	return "zip1 " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// ZIP2
+type Zip2 struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Zip2) Encode() uint32 {
+	return encodeSIMDPermute(op.Q, op.Size, 0b111, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Zip2) Execute(m *machine.Machine) {
+	executeZip(m, op.Q, op.Size, 1, op.Rn, op.Rm, op.Rd)
+}
+
+func (op *Zip2) String() string {
+	return "zip2 " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UZP1
+type Uzp1 struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uzp1) Encode() uint32 {
+	return encodeSIMDPermute(op.Q, op.Size, 0b001, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Uzp1) Execute(m *machine.Machine) {
+	executeUzp(m, op.Q, op.Size, 0, op.Rn, op.Rm, op.Rd)
+}
+
+func (op *Uzp1) String() string {
+	return "uzp1 " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UZP2
+type Uzp2 struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uzp2) Encode() uint32 {
+	return encodeSIMDPermute(op.Q, op.Size, 0b101, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Uzp2) Execute(m *machine.Machine) {
+	executeUzp(m, op.Q, op.Size, 1, op.Rn, op.Rm, op.Rd)
+}
+
+func (op *Uzp2) String() string {
+	return "uzp2 " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// TRN1
+type Trn1 struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Trn1) Encode() uint32 {
+	return encodeSIMDPermute(op.Q, op.Size, 0b010, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Trn1) Execute(m *machine.Machine) {
+	executeTrn(m, op.Q, op.Size, 0, op.Rn, op.Rm, op.Rd)
+}
+
+func (op *Trn1) String() string {
+	return "trn1 " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// TRN2
+type Trn2 struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Trn2) Encode() uint32 {
+	return encodeSIMDPermute(op.Q, op.Size, 0b110, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Trn2) Execute(m *machine.Machine) {
+	executeTrn(m, op.Q, op.Size, 1, op.Rn, op.Rm, op.Rd)
+}
+
+func (op *Trn2) String() string {
+	return "trn2 " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// EXT
+type Ext struct {
+	Q    uint32 // 1 bit
+	Rm   uint32 // 5 bits
+	Imm4 uint32 // 4 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Ext) Encode() uint32 {
+	return buildUint32([]bits{
+		{0, 1},
+		{op.Q, 1},
+		{0b101110, 6},
+		{0b00, 2},
+		{0, 1},
+		{op.Rm, 5},
+		{0, 1},
+		{op.Imm4, 4},
+		{0, 1},
+		{op.Rn, 5},
+		{op.Rd, 5},
+	}...)
+}
+
+// Execute extracts the bytes of Vd from the bytes of Vn followed by Vm, starting at byte Imm4.
+func (op *Ext) Execute(m *machine.Machine) {
+	executeVectorPermute(m, op.Q, 8, op.Rn, op.Rm, op.Rd, func(i int) int { return i + int(op.Imm4&0b1111) })
+}
+
+func (op *Ext) String() string {
+	return fmt.Sprintf("ext %s, #%d", vectorBinaryOperands(op.Q, 0b00, op.Rm, op.Rn, op.Rd), op.Imm4&0b1111)
+}
+
+// encodeSIMDTableLookup encodes an instruction from the Advanced SIMD table lookup group.
+func encodeSIMDTableLookup(q, length, op, rm, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{0, 1},
+		{q, 1},
+		{0b001110, 6},
+		{0b00, 2},
+		{0, 1},
+		{rm, 5},
+		{0, 1},
+		{length, 2},
+		{op, 1},
+		{0b00, 2},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// executeTableLookup sets each byte of Vd to the byte of the table of len+1 registers starting at
+// Vn, which wrap around from v31 to v0, indexed by the same byte of Vm.  An index past the end of
+// the table gives zero, or leaves the byte of Vd unchanged if extend is set.  The upper half of Vd
+// is cleared if q is 0.
+func executeTableLookup(m *machine.Machine, q, length uint32, extend bool, rm, rn, rd uint32) {
+	var table []byte
+	for i := uint32(0); i <= length&0b11; i++ {
+		v := m.V[(rn+i)&0b11111]
+		table = append(table, v[:]...)
+	}
+	var result machine.VectorRegister
+	indices := m.V[rm&0b11111]
+	for i := 0; i < vectorBits(q)/8; i++ {
+		switch {
+		case int(indices[i]) < len(table):
+			result[i] = table[indices[i]]
+		case extend:
+			result[i] = m.V[rd&0b11111][i]
+		}
+	}
+	m.V[rd&0b11111] = result
+}
+
+// tableLookupOperands formats the operands of a table lookup instruction.
+func tableLookupOperands(q, length, rm, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s, %s", vecName(rd, q, 0b00), vectorList(rn, int(length&0b11)+1, ".16b"), vecName(rm, q, 0b00))
+}
+
+// TBL
+type Tbl struct {
+	Q   uint32 // 1 bit
+	Rm  uint32 // 5 bits
+	Len uint32 // 2 bits
+	Rn  uint32 // 5 bits
+	Rd  uint32 // 5 bits
+}
+
+func (op *Tbl) Encode() uint32 {
+	return encodeSIMDTableLookup(op.Q, op.Len, 0, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Tbl) Execute(m *machine.Machine) {
+	executeTableLookup(m, op.Q, op.Len, false, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Tbl) String() string {
+	return "tbl " + tableLookupOperands(op.Q, op.Len, op.Rm, op.Rn, op.Rd)
+}
+
+// TBX
+type Tbx struct {
+	Q   uint32 // 1 bit
+	Rm  uint32 // 5 bits
+	Len uint32 // 2 bits
+	Rn  uint32 // 5 bits
+	Rd  uint32 // 5 bits
+}
+
+func (op *Tbx) Encode() uint32 {
+	return encodeSIMDTableLookup(op.Q, op.Len, 1, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Tbx) Execute(m *machine.Machine) {
+	executeTableLookup(m, op.Q, op.Len, true, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Tbx) String() string {
+	return "tbx " + tableLookupOperands(op.Q, op.Len, op.Rm, op.Rn, op.Rd)
+}
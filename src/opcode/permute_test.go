@@ -0,0 +1,61 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestPermuteExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm  string
+		inst Instruction
+		v3   machine.VectorRegister
+		want machine.VectorRegister
+	}{
+		{"zip1 v0.16b, v1.16b, v2.16b", &Zip1{Q: 1, Rm: 2, Rn: 1}, machine.VectorRegister{},
+			machine.VectorRegister{0x00, 0x10, 0x01, 0x11, 0x02, 0x12, 0x03, 0x13, 0x04, 0x14, 0x05, 0x15, 0x06, 0x16, 0x07, 0x17}},
+		{"zip2 v0.8h, v1.8h, v2.8h", &Zip2{Q: 1, Size: 0b01, Rm: 2, Rn: 1}, machine.VectorRegister{},
+			machine.VectorRegister{0x08, 0x09, 0x18, 0x19, 0x0a, 0x0b, 0x1a, 0x1b, 0x0c, 0x0d, 0x1c, 0x1d, 0x0e, 0x0f, 0x1e, 0x1f}},
+		{"zip1 v0.2s, v1.2s, v2.2s", &Zip1{Size: 0b10, Rm: 2, Rn: 1}, machine.VectorRegister{},
+			machine.VectorRegister{0x00, 0x01, 0x02, 0x03, 0x10, 0x11, 0x12, 0x13}},
+		{"uzp1 v0.4s, v1.4s, v2.4s", &Uzp1{Q: 1, Size: 0b10, Rm: 2, Rn: 1}, machine.VectorRegister{},
+			machine.VectorRegister{0x00, 0x01, 0x02, 0x03, 0x08, 0x09, 0x0a, 0x0b, 0x10, 0x11, 0x12, 0x13, 0x18, 0x19, 0x1a, 0x1b}},
+		{"uzp2 v0.2d, v1.2d, v2.2d", &Uzp2{Q: 1, Size: 0b11, Rm: 2, Rn: 1}, machine.VectorRegister{},
+			vec(0x0f0e0d0c0b0a0908, 0x1f1e1d1c1b1a1918)},
+		{"trn1 v0.4h, v1.4h, v2.4h", &Trn1{Size: 0b01, Rm: 2, Rn: 1}, machine.VectorRegister{},
+			machine.VectorRegister{0x00, 0x01, 0x10, 0x11, 0x04, 0x05, 0x14, 0x15}},
+		{"trn2 v0.8b, v1.8b, v2.8b", &Trn2{Rm: 2, Rn: 1}, machine.VectorRegister{},
+			machine.VectorRegister{0x01, 0x11, 0x03, 0x13, 0x05, 0x15, 0x07, 0x17}},
+		{"ext v0.16b, v1.16b, v2.16b, #3", &Ext{Q: 1, Rm: 2, Imm4: 3, Rn: 1}, machine.VectorRegister{},
+			machine.VectorRegister{0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12}},
+		{"ext v0.8b, v1.8b, v2.8b, #7", &Ext{Rm: 2, Imm4: 7, Rn: 1}, machine.VectorRegister{},
+			machine.VectorRegister{0x07, 0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16}},
+		{"tbl v0.16b, { v1.16b, v2.16b }, v3.16b", &Tbl{Q: 1, Rm: 3, Len: 1, Rn: 1},
+			machine.VectorRegister{0x1f, 0x00, 0x20, 0x10, 0x05, 0xff, 0x11, 0x0f, 0x02},
+			machine.VectorRegister{0x1f, 0x00, 0x00, 0x10, 0x05, 0x00, 0x11, 0x0f, 0x02}},
+		{"tbx v0.8b, { v1.16b }, v3.8b", &Tbx{Rm: 3, Rn: 1},
+			machine.VectorRegister{0x1f, 0x00, 0x20, 0x10, 0x05, 0xff, 0x11, 0x0f, 0x02},
+			machine.VectorRegister{0xff, 0x00, 0xff, 0xff, 0x05, 0xff, 0xff, 0x0f}},
+		{"tbl v0.8b, { v31.16b, v0.16b, v1.16b, v2.16b }, v3.8b", &Tbl{Rm: 3, Len: 3, Rn: 31},
+			machine.VectorRegister{0x1f, 0x00, 0x20, 0x10, 0x05, 0xff, 0x3f, 0x40},
+			machine.VectorRegister{0xff, 0xaa, 0x00, 0xff, 0xaa, 0x00, 0x1f, 0x00}},
+	} {
+		m := load(tc.inst)
+		m.V[0] = fill(0xff)
+		m.V[1] = bytesFrom(0x00, 1, 16)
+		m.V[2] = bytesFrom(0x10, 1, 16)
+		m.V[3] = tc.v3
+		m.V[31] = fill(0xaa)
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.V[0] != tc.want {
+			t.Errorf("%s: v0 = %x, want %x", tc.asm, m.V[0], tc.want)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
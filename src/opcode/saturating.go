@@ -0,0 +1,277 @@
+package opcode
+
+import (
+	gobits "math/bits"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// executeVectorSaturating is executeVectorBinary for instructions whose result can saturate: f
+// returns the result for a pair of elements and whether it saturated, and FPSR.QC is set if any
+// element did.
+func executeVectorSaturating(m *machine.Machine, q uint32, esize int, rn, rm, rd uint32, f func(x, y uint64) (uint64, bool)) {
+	var saturated bool
+	executeVectorBinary(m, q, esize, rn, rm, rd, func(x, y uint64) uint64 {
+		result, sat := f(x, y)
+		saturated = saturated || sat
+		return result
+	})
+	if saturated {
+		m.FPSR |= machine.FPSRQC
+	}
+}
+
+// signedSaturate returns x clamped to the range of an esize-bit signed integer, and whether it
+// had to be clamped.
+func signedSaturate(x int64, esize int) (uint64, bool) {
+	max := int64(1)<<(esize-1) - 1
+	switch {
+	case x > max:
+		return uint64(max), true
+	case x < -max-1:
+		return uint64(-max-1) & elementMask(esize), true
+	}
+	return uint64(x) & elementMask(esize), false
+}
+
+// unsignedSaturate returns x clamped to the range of an esize-bit unsigned integer, and whether
+// it had to be clamped.
+func unsignedSaturate(x uint64, esize int) (uint64, bool) {
+	if x > elementMask(esize) {
+		return elementMask(esize), true
+	}
+	return x, false
+}
+
+// elementMask returns a mask of the low esize bits.
+func elementMask(esize int) uint64 {
+	return ^uint64(0) >> (64 - esize)
+}
+
+// saturatingAdd returns x + y, or x - y if sub is set, for esize-bit elements, saturated to the
+// range of the element type.
+func saturatingAdd(x, y uint64, esize int, signed, sub bool) (uint64, bool) {
+	if !signed {
+		if sub {
+			if x < y {
+				return 0, true
+			}
+			return x - y, false
+		}
+		sum, carry := gobits.Add64(x, y, 0)
+		if carry != 0 {
+			return elementMask(esize), true
+		}
+		return unsignedSaturate(sum, esize)
+	}
+	a, b := signExtend(x, esize), signExtend(y, esize)
+	if esize < 64 {
+		if sub {
+			return signedSaturate(a-b, esize)
+		}
+		return signedSaturate(a+b, esize)
+	}
+	// The result overflows if it has a different sign to a when b has the same sign as a (for an
+	// addition) or a different sign (for a subtraction).
+	r := a + b
+	overflow := (a >= 0) == (b >= 0) && (r >= 0) != (a >= 0)
+	if sub {
+		r = a - b
+		overflow = (a >= 0) != (b >= 0) && (r >= 0) != (a >= 0)
+	}
+	if overflow {
+		if a >= 0 {
+			return 1<<63 - 1, true
+		}
+		return 1 << 63, true
+	}
+	return uint64(r), false
+}
+
+// doublingMultiplyHigh returns the high half of 2 * x * y for esize-bit signed elements, rounded
+// if round is set, and saturated to the range of the element type.
+func doublingMultiplyHigh(x, y uint64, esize int, round bool) (uint64, bool) {
+	// The product of two elements of at most 32 bits fits in an int64, so the doubling is folded
+	// into the shift.
+	p := signExtend(x, esize) * signExtend(y, esize)
+	if round {
+		p += 1 << (esize - 2)
+	}
+	return signedSaturate(p>>(esize-1), esize)
+}
+
+// SQADD
+type Sqadd struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sqadd) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b00001, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sqadd) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorSaturating(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) (uint64, bool) { return saturatingAdd(x, y, esize, true, false) })
+}
+
+func (op *Sqadd) String() string {
+	return "sqadd " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UQADD
+type Uqadd struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uqadd) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b00001, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Uqadd) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorSaturating(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) (uint64, bool) { return saturatingAdd(x, y, esize, false, false) })
+}
+
+func (op *Uqadd) String() string {
+	return "uqadd " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SQSUB
+type Sqsub struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sqsub) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b00101, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sqsub) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorSaturating(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) (uint64, bool) { return saturatingAdd(x, y, esize, true, true) })
+}
+
+func (op *Sqsub) String() string {
+	return "sqsub " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UQSUB
+type Uqsub struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uqsub) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b00101, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Uqsub) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorSaturating(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) (uint64, bool) { return saturatingAdd(x, y, esize, false, true) })
+}
+
+func (op *Uqsub) String() string {
+	return "uqsub " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SQDMULH (vector)
+type Sqdmulh struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sqdmulh) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b10110, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sqdmulh) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorSaturating(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) (uint64, bool) { return doublingMultiplyHigh(x, y, esize, false) })
+}
+
+func (op *Sqdmulh) String() string {
+	return "sqdmulh " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SQRDMULH (vector)
+type Sqrdmulh struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sqrdmulh) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b10110, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sqrdmulh) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorSaturating(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) (uint64, bool) { return doublingMultiplyHigh(x, y, esize, true) })
+}
+
+func (op *Sqrdmulh) String() string {
+	return "sqrdmulh " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SQXTN
+type Sqxtn struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sqxtn) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 0, op.Size, 0b10100, op.Rn, op.Rd)
+}
+
+func (op *Sqxtn) Execute(m *machine.Machine) {
+	esize, n := elementBits(op.Size), m.V[op.Rn&0b11111]
+	executeVectorNarrow(m, op.Q, esize, op.Rd, func(i int) (uint64, bool) {
+		return signedSaturate(signExtend(n.Get(i, 2*esize), 2*esize), esize)
+	})
+}
+
+func (op *Sqxtn) String() string {
+	return partMnemonic("sqxtn", op.Q) + " " + narrowOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
+
+// UQXTN
+type Uqxtn struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uqxtn) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 1, op.Size, 0b10100, op.Rn, op.Rd)
+}
+
+func (op *Uqxtn) Execute(m *machine.Machine) {
+	esize, n := elementBits(op.Size), m.V[op.Rn&0b11111]
+	executeVectorNarrow(m, op.Q, esize, op.Rd, func(i int) (uint64, bool) { return unsignedSaturate(n.Get(i, 2*esize), esize) })
+}
+
+func (op *Uqxtn) String() string {
+	return partMnemonic("uqxtn", op.Q) + " " + narrowOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
@@ -0,0 +1,97 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestSaturatingExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm    string
+		inst   Instruction
+		v1, v2 machine.VectorRegister
+		want   machine.VectorRegister
+		qc     bool
+	}{
+		{"sqadd v0.8b, v1.8b, v2.8b", &Sqadd{Rm: 2, Rn: 1}, vec(0x80_7f_01, 1), vec(0x01_01_01, 1), vec(0x81_7f_02, 0), true},
+		{"sqadd v0.8b, v1.8b, v2.8b", &Sqadd{Rm: 2, Rn: 1}, vec(0x80_7e_01, 1), vec(0xff_01_01, 1), vec(0x80_7f_02, 0), true},
+		{"sqadd v0.4h, v1.4h, v2.4h", &Sqadd{Size: 0b01, Rm: 2, Rn: 1}, vec(0x8000_7000, 0), vec(0x0001_0fff, 0), vec(0x8001_7fff, 0), false},
+		{"sqadd v0.2d, v1.2d, v2.2d", &Sqadd{Q: 1, Size: 0b11, Rm: 2, Rn: 1}, vec(1<<62, 1<<63), vec(1<<62, ^uint64(0)), vec(1<<63-1, 1<<63), true},
+		{"uqadd v0.16b, v1.16b, v2.16b", &Uqadd{Q: 1, Rm: 2, Rn: 1}, vec(0xff_01, 0xf0), vec(0x01_01, 0x0f), vec(0xff_02, 0xff), true},
+		{"uqadd v0.2d, v1.2d, v2.2d", &Uqadd{Q: 1, Size: 0b11, Rm: 2, Rn: 1}, vec(^uint64(0), 1), vec(1, 2), vec(^uint64(0), 3), true},
+		{"uqadd v0.2s, v1.2s, v2.2s", &Uqadd{Size: 0b10, Rm: 2, Rn: 1}, vec(0xfffffffe_00000001, 0), vec(0x00000001_00000002, 0), vec(0xffffffff_00000003, 0), false},
+		{"sqsub v0.4s, v1.4s, v2.4s", &Sqsub{Q: 1, Size: 0b10, Rm: 2, Rn: 1}, vec(0x80000000_7fffffff, 5), vec(0x00000001_ffffffff, 7), vec(0x80000000_7fffffff, 0xfffffffe), true},
+		{"sqsub v0.2d, v1.2d, v2.2d", &Sqsub{Q: 1, Size: 0b11, Rm: 2, Rn: 1}, vec(0, ^uint64(0)), vec(1<<63, 1<<63), vec(1<<63-1, 1<<63-1), true},
+		{"sqsub v0.2d, v1.2d, v2.2d", &Sqsub{Q: 1, Size: 0b11, Rm: 2, Rn: 1}, vec(1<<63, 5), vec(1, 7), vec(1<<63, ^uint64(1)), true},
+		{"uqsub v0.8h, v1.8h, v2.8h", &Uqsub{Q: 1, Size: 0b01, Rm: 2, Rn: 1}, vec(0x0001_0005, 0), vec(0x0002_0003, 0), vec(0x0000_0002, 0), true},
+		{"uqsub v0.8h, v1.8h, v2.8h", &Uqsub{Q: 1, Size: 0b01, Rm: 2, Rn: 1}, vec(0x0002_0005, 0), vec(0x0001_0003, 0), vec(0x0001_0002, 0), false},
+		{"sqdmulh v0.4h, v1.4h, v2.4h", &Sqdmulh{Size: 0b01, Rm: 2, Rn: 1}, vec(0x8000_4000_c000, 0), vec(0x8000_4000_4000, 0), vec(0x7fff_2000_e000, 0), true},
+		{"sqdmulh v0.2s, v1.2s, v2.2s", &Sqdmulh{Size: 0b10, Rm: 2, Rn: 1}, vec(0x00000001_40000000, 0), vec(0x00000001_40000000, 0), vec(0x00000000_20000000, 0), false},
+		{"sqrdmulh v0.4h, v1.4h, v2.4h", &Sqrdmulh{Size: 0b01, Rm: 2, Rn: 1}, vec(0x8000_0001_4000, 0), vec(0x8000_4000_0001, 0), vec(0x7fff_0001_0001, 0), true},
+		{"sqrdmulh v0.4s, v1.4s, v2.4s", &Sqrdmulh{Q: 1, Size: 0b10, Rm: 2, Rn: 1}, vec(0xffffffff_00000001, 0), vec(0x40000000_40000000, 0), vec(0x00000000_00000001, 0), false},
+	} {
+		m := load(tc.inst)
+		m.V[0] = vec(^uint64(0), ^uint64(0))
+		m.V[1], m.V[2] = tc.v1, tc.v2
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.V[0] != tc.want {
+			t.Errorf("%s with v1 = %x, v2 = %x: v0 = %x, want %x", tc.asm, tc.v1, tc.v2, m.V[0], tc.want)
+		}
+		if qc := m.FPSR&machine.FPSRQC != 0; qc != tc.qc {
+			t.Errorf("%s with v1 = %x, v2 = %x: QC = %t, want %t", tc.asm, tc.v1, tc.v2, qc, tc.qc)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
+
+func TestSaturatingNarrowExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm  string
+		inst Instruction
+		v1   machine.VectorRegister
+		want machine.VectorRegister
+		qc   bool
+	}{
+		{"sqxtn v0.8b, v1.8h", &Sqxtn{Rn: 1}, vec(0xff80_ff7f_0080_007f, 0x0001_fffe_8000_7fff), vec(0x01fe807f80807f7f, 0), true},
+		{"sqxtn v0.4h, v1.4s", &Sqxtn{Size: 0b01, Rn: 1}, vec(0xffff8000_00007fff, 0x00000001_fffffffe), vec(0x0001_fffe_8000_7fff, 0), false},
+		{"sqxtn2 v0.4s, v1.2d", &Sqxtn{Q: 1, Size: 0b10, Rn: 1}, vec(1<<40, 0xffffffff_00000000), vec(^uint64(0), 0x80000000_7fffffff), true},
+		{"uqxtn v0.8b, v1.8h", &Uqxtn{Rn: 1}, vec(0x0100_00ff_0080_0001, 0xffff), vec(0x000000ff_ffff8001, 0), true},
+		{"uqxtn2 v0.8h, v1.4s", &Uqxtn{Q: 1, Size: 0b01, Rn: 1}, vec(0x00000002_00000001, 0x0000ffff_00000003), vec(^uint64(0), 0xffff_0003_0002_0001), false},
+	} {
+		m := load(tc.inst)
+		m.V[0] = vec(^uint64(0), ^uint64(0))
+		m.V[1] = tc.v1
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.V[0] != tc.want {
+			t.Errorf("%s with v1 = %x: v0 = %x, want %x", tc.asm, tc.v1, m.V[0], tc.want)
+		}
+		if qc := m.FPSR&machine.FPSRQC != 0; qc != tc.qc {
+			t.Errorf("%s with v1 = %x: QC = %t, want %t", tc.asm, tc.v1, qc, tc.qc)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
+
+func TestSaturationIsCumulative(t *testing.T) {
+	m := load(&Sqadd{Rm: 2, Rn: 1}, &Sqadd{Rm: 2, Rn: 1})
+	m.V[1], m.V[2] = vec(0x7f, 0), vec(1, 0)
+	m.Step()
+	m.V[1] = vec(0, 0)
+	if reason, err := m.Step(); reason != machine.StopNone {
+		t.Fatalf("Step returned %v, %v", reason, err)
+	}
+	if m.FPSR != machine.FPSRQC {
+		t.Errorf("FPSR = 0x%x after a saturating then a non-saturating sqadd, want 0x%x", m.FPSR, machine.FPSRQC)
+	}
+}
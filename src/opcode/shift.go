@@ -0,0 +1,737 @@
+package opcode
+
+import (
+	"fmt"
+	"math"
+	gobits "math/bits"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// encodeSIMDShiftImmediate encodes an instruction from the Advanced SIMD shift by immediate
+// group.
+func encodeSIMDShiftImmediate(q, u, immh, immb, opcode, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{0, 1},
+		{q, 1},
+		{u, 1},
+		{0b011110, 6},
+		{immh, 4},
+		{immb, 3},
+		{opcode, 5},
+		{1, 1},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// shiftImmediateSize returns the size field of the elements of a shift by immediate instruction,
+// which is given by the highest set bit of immh.  For the narrowing shifts it is the size of the
+// narrow elements.
+func shiftImmediateSize(immh uint32) uint32 {
+	return uint32(gobits.Len32(immh&0b1111)) - 1
+}
+
+// shiftRightAmount returns the amount of a right shift by immediate, which immh:immb encodes as
+// 2*esize - shift, so that it is between 1 and esize.
+func shiftRightAmount(immh, immb uint32) int {
+	return 2*elementBits(shiftImmediateSize(immh)) - int(immh&0b1111<<3|immb&0b111)
+}
+
+// shiftLeftAmount returns the amount of a left shift by immediate, which immh:immb encodes as
+// esize + shift, so that it is between 0 and esize - 1.
+func shiftLeftAmount(immh, immb uint32) int {
+	return int(immh&0b1111<<3|immb&0b111) - elementBits(shiftImmediateSize(immh))
+}
+
+// shiftRight returns the esize-bit element x shifted right by shift bits, arithmetically if signed
+// is set, and rounded to nearest with ties up if round is set.  The result is sign extended to 64
+// bits if signed is set.  Rounding adds the last bit shifted out rather than 1<<(shift-1) before
+// shifting, so the result cannot overflow.
+func shiftRight(x uint64, shift, esize int, signed, round bool) uint64 {
+	var r, carry uint64
+	if signed {
+		v := signExtend(x, esize)
+		r = uint64(v >> shift)
+		if shift > 0 {
+			carry = uint64(v>>(shift-1)) & 1
+		}
+	} else {
+		r = x >> shift
+		if shift > 0 {
+			carry = x >> (shift - 1) & 1
+		}
+	}
+	if round {
+		r += carry
+	}
+	return r
+}
+
+// saturatingShiftLeft returns the esize-bit element x shifted left by shift bits, saturated to the
+// range of the element type, and whether it saturated.
+func saturatingShiftLeft(x uint64, shift, esize int, signed bool) (uint64, bool) {
+	if !signed {
+		if x == 0 {
+			return 0, false
+		}
+		if shift >= esize || x<<shift>>shift != x {
+			return elementMask(esize), true
+		}
+		return unsignedSaturate(x<<shift, esize)
+	}
+	v := signExtend(x, esize)
+	if v == 0 {
+		return 0, false
+	}
+	if shift >= esize || v<<shift>>shift != v {
+		if v < 0 {
+			r, _ := signedSaturate(math.MinInt64, esize)
+			return r, true
+		}
+		r, _ := signedSaturate(math.MaxInt64, esize)
+		return r, true
+	}
+	return signedSaturate(v<<shift, esize)
+}
+
+// saturate clamps x, which is a signed integer if signed is set, to the range of an esize-bit
+// element that is signed if signedResult is set, and reports whether it had to be clamped.
+func saturate(x uint64, esize int, signed, signedResult bool) (uint64, bool) {
+	switch {
+	case signed && signedResult:
+		return signedSaturate(int64(x), esize)
+	case signed && int64(x) < 0:
+		return 0, true
+	}
+	return unsignedSaturate(x, esize)
+}
+
+// shiftByRegister returns the esize-bit element x shifted by the signed amount in the low byte of
+// y: left if it is positive and right, rounded if round is set, if it is negative.
+func shiftByRegister(x, y uint64, esize int, signed, round bool) uint64 {
+	shift := int(int8(y))
+	if shift >= 0 {
+		return x << shift
+	}
+	return shiftRight(x, -shift, esize, signed, round)
+}
+
+// saturatingShiftByRegister is shiftByRegister with a left shift that saturates.
+func saturatingShiftByRegister(x, y uint64, esize int, signed, round bool) (uint64, bool) {
+	shift := int(int8(y))
+	if shift >= 0 {
+		return saturatingShiftLeft(x, shift, esize, signed)
+	}
+	return shiftRight(x, -shift, esize, signed, round), false
+}
+
+// executeShiftNarrow shifts each element of Vn right by the amount in immh:immb, arithmetically if
+// signed is set and rounded if round is set, and narrows it into Vd with narrow, as
+// executeVectorNarrow does.
+func executeShiftNarrow(m *machine.Machine, q, immh, immb, rn, rd uint32, signed, round bool, narrow func(x uint64, esize int) (uint64, bool)) {
+	esize, shift, n := elementBits(shiftImmediateSize(immh)), shiftRightAmount(immh, immb), m.V[rn&0b11111]
+	executeVectorNarrow(m, q, esize, rd, func(i int) (uint64, bool) {
+		return narrow(shiftRight(n.Get(i, 2*esize), shift, 2*esize, signed, round), esize)
+	})
+}
+
+// shiftImmediateOperands formats the operands of a shift by immediate instruction.
+func shiftImmediateOperands(q, size, rn, rd uint32, shift int) string {
+	return fmt.Sprintf("%s, #%d", vectorUnaryOperands(q, size, rn, rd), shift)
+}
+
+// narrowShiftOperands formats the operands of a narrowing shift by immediate instruction.
+func narrowShiftOperands(q, size, rn, rd uint32, shift int) string {
+	return fmt.Sprintf("%s, #%d", narrowOperands(q, size, rn, rd), shift)
+}
+
+// SSHR
+type Sshr struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sshr) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 0, op.Immh, op.Immb, 0b00000, op.Rn, op.Rd)
+}
+
+func (op *Sshr) Execute(m *machine.Machine) {
+	esize, shift := elementBits(shiftImmediateSize(op.Immh)), shiftRightAmount(op.Immh, op.Immb)
+	executeVectorUnary(m, op.Q, esize, op.Rn, op.Rd, func(x uint64) uint64 { return shiftRight(x, shift, esize, true, false) })
+}
+
+func (op *Sshr) String() string {
+	return "sshr " + shiftImmediateOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// USHR
+type Ushr struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Ushr) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 1, op.Immh, op.Immb, 0b00000, op.Rn, op.Rd)
+}
+
+func (op *Ushr) Execute(m *machine.Machine) {
+	esize, shift := elementBits(shiftImmediateSize(op.Immh)), shiftRightAmount(op.Immh, op.Immb)
+	executeVectorUnary(m, op.Q, esize, op.Rn, op.Rd, func(x uint64) uint64 { return shiftRight(x, shift, esize, false, false) })
+}
+
+func (op *Ushr) String() string {
+	return "ushr " + shiftImmediateOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// SSRA
+type Ssra struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Ssra) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 0, op.Immh, op.Immb, 0b00010, op.Rn, op.Rd)
+}
+
+func (op *Ssra) Execute(m *machine.Machine) {
+	esize, shift := elementBits(shiftImmediateSize(op.Immh)), shiftRightAmount(op.Immh, op.Immb)
+	executeVectorAccumulate(m, op.Q, esize, op.Rn, op.Rn, op.Rd, func(acc, x, _ uint64) uint64 { return acc + shiftRight(x, shift, esize, true, false) })
+}
+
+func (op *Ssra) String() string {
+	return "ssra " + shiftImmediateOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// USRA
+type Usra struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Usra) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 1, op.Immh, op.Immb, 0b00010, op.Rn, op.Rd)
+}
+
+func (op *Usra) Execute(m *machine.Machine) {
+	esize, shift := elementBits(shiftImmediateSize(op.Immh)), shiftRightAmount(op.Immh, op.Immb)
+	executeVectorAccumulate(m, op.Q, esize, op.Rn, op.Rn, op.Rd, func(acc, x, _ uint64) uint64 { return acc + shiftRight(x, shift, esize, false, false) })
+}
+
+func (op *Usra) String() string {
+	return "usra " + shiftImmediateOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// SRSHR
+type Srshr struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Srshr) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 0, op.Immh, op.Immb, 0b00100, op.Rn, op.Rd)
+}
+
+func (op *Srshr) Execute(m *machine.Machine) {
+	esize, shift := elementBits(shiftImmediateSize(op.Immh)), shiftRightAmount(op.Immh, op.Immb)
+	executeVectorUnary(m, op.Q, esize, op.Rn, op.Rd, func(x uint64) uint64 { return shiftRight(x, shift, esize, true, true) })
+}
+
+func (op *Srshr) String() string {
+	return "srshr " + shiftImmediateOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// URSHR
+type Urshr struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Urshr) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 1, op.Immh, op.Immb, 0b00100, op.Rn, op.Rd)
+}
+
+func (op *Urshr) Execute(m *machine.Machine) {
+	esize, shift := elementBits(shiftImmediateSize(op.Immh)), shiftRightAmount(op.Immh, op.Immb)
+	executeVectorUnary(m, op.Q, esize, op.Rn, op.Rd, func(x uint64) uint64 { return shiftRight(x, shift, esize, false, true) })
+}
+
+func (op *Urshr) String() string {
+	return "urshr " + shiftImmediateOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// SRSRA
+type Srsra struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Srsra) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 0, op.Immh, op.Immb, 0b00110, op.Rn, op.Rd)
+}
+
+func (op *Srsra) Execute(m *machine.Machine) {
+	esize, shift := elementBits(shiftImmediateSize(op.Immh)), shiftRightAmount(op.Immh, op.Immb)
+	executeVectorAccumulate(m, op.Q, esize, op.Rn, op.Rn, op.Rd, func(acc, x, _ uint64) uint64 { return acc + shiftRight(x, shift, esize, true, true) })
+}
+
+func (op *Srsra) String() string {
+	return "srsra " + shiftImmediateOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// URSRA
+type Ursra struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Ursra) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 1, op.Immh, op.Immb, 0b00110, op.Rn, op.Rd)
+}
+
+func (op *Ursra) Execute(m *machine.Machine) {
+	esize, shift := elementBits(shiftImmediateSize(op.Immh)), shiftRightAmount(op.Immh, op.Immb)
+	executeVectorAccumulate(m, op.Q, esize, op.Rn, op.Rn, op.Rd, func(acc, x, _ uint64) uint64 { return acc + shiftRight(x, shift, esize, false, true) })
+}
+
+func (op *Ursra) String() string {
+	return "ursra " + shiftImmediateOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// SHL
+type Shl struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Shl) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 0, op.Immh, op.Immb, 0b01010, op.Rn, op.Rd)
+}
+
+func (op *Shl) Execute(m *machine.Machine) {
+	esize, shift := elementBits(shiftImmediateSize(op.Immh)), shiftLeftAmount(op.Immh, op.Immb)
+	executeVectorUnary(m, op.Q, esize, op.Rn, op.Rd, func(x uint64) uint64 { return x << shift })
+}
+
+func (op *Shl) String() string {
+	return "shl " + shiftImmediateOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftLeftAmount(op.Immh, op.Immb))
+}
+
+// SLI
+type Sli struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sli) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 1, op.Immh, op.Immb, 0b01010, op.Rn, op.Rd)
+}
+
+// Execute shifts each element of Vn left and inserts it into Vd, keeping the bits of Vd below the
+// shift.
+func (op *Sli) Execute(m *machine.Machine) {
+	esize, shift := elementBits(shiftImmediateSize(op.Immh)), shiftLeftAmount(op.Immh, op.Immb)
+	executeVectorAccumulate(m, op.Q, esize, op.Rn, op.Rn, op.Rd, func(acc, x, _ uint64) uint64 { return acc&^(^uint64(0)<<shift) | x<<shift })
+}
+
+func (op *Sli) String() string {
+	return "sli " + shiftImmediateOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftLeftAmount(op.Immh, op.Immb))
+}
+
+// SRI
+type Sri struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sri) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 1, op.Immh, op.Immb, 0b01000, op.Rn, op.Rd)
+}
+
+// Execute shifts each element of Vn right and inserts it into Vd, keeping the bits of Vd above
+// the shift.
+func (op *Sri) Execute(m *machine.Machine) {
+	esize, shift := elementBits(shiftImmediateSize(op.Immh)), shiftRightAmount(op.Immh, op.Immb)
+	executeVectorAccumulate(m, op.Q, esize, op.Rn, op.Rn, op.Rd, func(acc, x, _ uint64) uint64 { return acc&^(elementMask(esize)>>shift) | x>>shift })
+}
+
+func (op *Sri) String() string {
+	return "sri " + shiftImmediateOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// SHRN
+type Shrn struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Shrn) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 0, op.Immh, op.Immb, 0b10000, op.Rn, op.Rd)
+}
+
+func (op *Shrn) Execute(m *machine.Machine) {
+	executeShiftNarrow(m, op.Q, op.Immh, op.Immb, op.Rn, op.Rd, false, false, func(x uint64, _ int) (uint64, bool) { return x, false })
+}
+
+func (op *Shrn) String() string {
+	return partMnemonic("shrn", op.Q) + " " + narrowShiftOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// RSHRN
+type Rshrn struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Rshrn) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 0, op.Immh, op.Immb, 0b10001, op.Rn, op.Rd)
+}
+
+func (op *Rshrn) Execute(m *machine.Machine) {
+	executeShiftNarrow(m, op.Q, op.Immh, op.Immb, op.Rn, op.Rd, false, true, func(x uint64, _ int) (uint64, bool) { return x, false })
+}
+
+func (op *Rshrn) String() string {
+	return partMnemonic("rshrn", op.Q) + " " + narrowShiftOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// SQSHRN
+type Sqshrn struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sqshrn) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 0, op.Immh, op.Immb, 0b10010, op.Rn, op.Rd)
+}
+
+func (op *Sqshrn) Execute(m *machine.Machine) {
+	executeShiftNarrow(m, op.Q, op.Immh, op.Immb, op.Rn, op.Rd, true, false, func(x uint64, esize int) (uint64, bool) { return saturate(x, esize, true, true) })
+}
+
+func (op *Sqshrn) String() string {
+	return partMnemonic("sqshrn", op.Q) + " " + narrowShiftOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// UQSHRN
+type Uqshrn struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uqshrn) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 1, op.Immh, op.Immb, 0b10010, op.Rn, op.Rd)
+}
+
+func (op *Uqshrn) Execute(m *machine.Machine) {
+	executeShiftNarrow(m, op.Q, op.Immh, op.Immb, op.Rn, op.Rd, false, false, func(x uint64, esize int) (uint64, bool) { return saturate(x, esize, false, false) })
+}
+
+func (op *Uqshrn) String() string {
+	return partMnemonic("uqshrn", op.Q) + " " + narrowShiftOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// SQRSHRN
+type Sqrshrn struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sqrshrn) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 0, op.Immh, op.Immb, 0b10011, op.Rn, op.Rd)
+}
+
+func (op *Sqrshrn) Execute(m *machine.Machine) {
+	executeShiftNarrow(m, op.Q, op.Immh, op.Immb, op.Rn, op.Rd, true, true, func(x uint64, esize int) (uint64, bool) { return saturate(x, esize, true, true) })
+}
+
+func (op *Sqrshrn) String() string {
+	return partMnemonic("sqrshrn", op.Q) + " " + narrowShiftOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// UQRSHRN
+type Uqrshrn struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uqrshrn) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 1, op.Immh, op.Immb, 0b10011, op.Rn, op.Rd)
+}
+
+func (op *Uqrshrn) Execute(m *machine.Machine) {
+	executeShiftNarrow(m, op.Q, op.Immh, op.Immb, op.Rn, op.Rd, false, true, func(x uint64, esize int) (uint64, bool) { return saturate(x, esize, false, false) })
+}
+
+func (op *Uqrshrn) String() string {
+	return partMnemonic("uqrshrn", op.Q) + " " + narrowShiftOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// SQSHRUN
+type Sqshrun struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sqshrun) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 1, op.Immh, op.Immb, 0b10000, op.Rn, op.Rd)
+}
+
+func (op *Sqshrun) Execute(m *machine.Machine) {
+	executeShiftNarrow(m, op.Q, op.Immh, op.Immb, op.Rn, op.Rd, true, false, func(x uint64, esize int) (uint64, bool) { return saturate(x, esize, true, false) })
+}
+
+func (op *Sqshrun) String() string {
+	return partMnemonic("sqshrun", op.Q) + " " + narrowShiftOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// SQRSHRUN
+type Sqrshrun struct {
+	Q    uint32 // 1 bit
+	Immh uint32 // 4 bits
+	Immb uint32 // 3 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sqrshrun) Encode() uint32 {
+	return encodeSIMDShiftImmediate(op.Q, 1, op.Immh, op.Immb, 0b10001, op.Rn, op.Rd)
+}
+
+func (op *Sqrshrun) Execute(m *machine.Machine) {
+	executeShiftNarrow(m, op.Q, op.Immh, op.Immb, op.Rn, op.Rd, true, true, func(x uint64, esize int) (uint64, bool) { return saturate(x, esize, true, false) })
+}
+
+func (op *Sqrshrun) String() string {
+	return partMnemonic("sqrshrun", op.Q) + " " + narrowShiftOperands(op.Q, shiftImmediateSize(op.Immh), op.Rn, op.Rd, shiftRightAmount(op.Immh, op.Immb))
+}
+
+// SSHL
+type Sshl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sshl) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b01000, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sshl) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return shiftByRegister(x, y, esize, true, false) })
+}
+
+func (op *Sshl) String() string {
+	return "sshl " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// USHL
+type Ushl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Ushl) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b01000, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Ushl) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return shiftByRegister(x, y, esize, false, false) })
+}
+
+func (op *Ushl) String() string {
+	return "ushl " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SRSHL
+type Srshl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Srshl) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b01010, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Srshl) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return shiftByRegister(x, y, esize, true, true) })
+}
+
+func (op *Srshl) String() string {
+	return "srshl " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// URSHL
+type Urshl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Urshl) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b01010, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Urshl) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return shiftByRegister(x, y, esize, false, true) })
+}
+
+func (op *Urshl) String() string {
+	return "urshl " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SQSHL (register)
+type SqshlRegister struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *SqshlRegister) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b01001, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *SqshlRegister) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorSaturating(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) (uint64, bool) { return saturatingShiftByRegister(x, y, esize, true, false) })
+}
+
+func (op *SqshlRegister) String() string {
+	return "sqshl " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UQSHL (register)
+type UqshlRegister struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *UqshlRegister) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b01001, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *UqshlRegister) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorSaturating(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) (uint64, bool) { return saturatingShiftByRegister(x, y, esize, false, false) })
+}
+
+func (op *UqshlRegister) String() string {
+	return "uqshl " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SQRSHL
+type Sqrshl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sqrshl) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b01011, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sqrshl) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorSaturating(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) (uint64, bool) { return saturatingShiftByRegister(x, y, esize, true, true) })
+}
+
+func (op *Sqrshl) String() string {
+	return "sqrshl " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UQRSHL
+type Uqrshl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uqrshl) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b01011, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Uqrshl) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorSaturating(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) (uint64, bool) { return saturatingShiftByRegister(x, y, esize, false, true) })
+}
+
+func (op *Uqrshl) String() string {
+	return "uqrshl " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
@@ -0,0 +1,86 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestShiftExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm        string
+		inst       Instruction
+		v0, v1, v2 machine.VectorRegister
+		want       machine.VectorRegister
+		fpsr       uint32
+	}{
+		{"sshr v0.16b, v1.16b, #3", &Sshr{Q: 1, Immh: 0b0001, Immb: 0b101, Rn: 1},
+			vec(0, 0), vec(0x08ff7f80, 0), vec(0, 0), vec(0x01ff0ff0, 0), 0},
+		{"ushr v0.4s, v1.4s, #32", &Ushr{Q: 1, Immh: 0b0100, Rn: 1},
+			vec(1, 1), vec(^uint64(0), ^uint64(0)), vec(0, 0), vec(0, 0), 0},
+		{"sshr v0.2d, v1.2d, #64", &Sshr{Q: 1, Immh: 0b1000, Rn: 1},
+			vec(0, 0), vec(0x8000000000000000, 0x7fffffffffffffff), vec(0, 0), vec(^uint64(0), 0), 0},
+		{"srshr v0.4h, v1.4h, #2", &Srshr{Immh: 0b0011, Immb: 0b110, Rn: 1},
+			vec(1, 1), vec(0xfff9fffa00050006, 7), vec(0, 0), vec(0xfffeffff00010002, 0), 0},
+		{"urshr v0.2d, v1.2d, #1", &Urshr{Q: 1, Immh: 0b1111, Immb: 0b111, Rn: 1},
+			vec(0, 0), vec(^uint64(0), 3), vec(0, 0), vec(0x8000000000000000, 2), 0},
+		{"ssra v0.4s, v1.4s, #1", &Ssra{Q: 1, Immh: 0b0111, Immb: 0b111, Rn: 1},
+			vec(0x0000000100000010, 0x0000000500000006), vec(0xfffffffe00000004, 0), vec(0, 0), vec(0x0000000000000012, 0x0000000500000006), 0},
+		{"usra v0.8b, v1.8b, #8", &Usra{Immh: 0b0001, Rn: 1},
+			vec(0x1122334455667788, 0x99), vec(^uint64(0), 0), vec(0, 0), vec(0x1122334455667788, 0), 0},
+		{"shl v0.4h, v1.4h, #15", &Shl{Immh: 0b0011, Immb: 0b111, Rn: 1},
+			vec(0, 0), vec(0x0003000200018001, 0), vec(0, 0), vec(0x8000000080008000, 0), 0},
+		{"sli v0.8b, v1.8b, #4", &Sli{Immh: 0b0001, Immb: 0b100, Rn: 1},
+			vec(0x0123456789abcdef, 1), vec(0xfedcba9876543210, 0), vec(0, 0), vec(0xe1c3a587694b2d0f, 0), 0},
+		{"sri v0.2d, v1.2d, #64", &Sri{Q: 1, Immh: 0b1000, Rn: 1},
+			vec(1, 2), vec(^uint64(0), ^uint64(0)), vec(0, 0), vec(1, 2), 0},
+		{"sri v0.4s, v1.4s, #8", &Sri{Q: 1, Immh: 0b0111, Rn: 1},
+			vec(0xaabbccdd11223344, 0), vec(0x123456789abcdef0, 0xffffffff00000000), vec(0, 0), vec(0xaa123456119abcde, 0x00ffffff00000000), 0},
+		{"shrn v0.8b, v1.8h, #4", &Shrn{Immh: 0b0001, Immb: 0b100, Rn: 1},
+			vec(1, 1), vec(0x123456789abcdef0, 0x0ff0f00f0001ffff), vec(0, 0), vec(0xff0000ff2367abef, 0), 0},
+		{"rshrn2 v0.16b, v1.8h, #4", &Rshrn{Q: 1, Immh: 0b0001, Immb: 0b100, Rn: 1},
+			vec(0x1111, 0x2222), vec(0x123456789abcdef0, 0x0ff0f00f0001ffff), vec(0, 0), vec(0x1111, 0xff0100002368acef), 0},
+		{"sqshrn v0.4h, v1.4s, #8", &Sqshrn{Immh: 0b0011, Rn: 1},
+			vec(1, 1), vec(0x7fffffff00012345, 0x80000000ffff0000), vec(0, 0), vec(0x8000ff007fff0123, 0), machine.FPSRQC},
+		{"uqshrn v0.8b, v1.8h, #1", &Uqshrn{Immh: 0b0001, Immb: 0b111, Rn: 1},
+			vec(1, 1), vec(0x01fe0002, 0), vec(0, 0), vec(0xff01, 0), 0},
+		{"sqrshrun v0.2s, v1.2d, #1", &Sqrshrun{Immh: 0b0111, Immb: 0b111, Rn: 1},
+			vec(1, 1), vec(^uint64(0), 0x00000001ffffffff), vec(0, 0), vec(0xffffffff00000000, 0), machine.FPSRQC},
+		{"sqshrun v0.8b, v1.8h, #3", &Sqshrun{Immh: 0b0001, Immb: 0b101, Rn: 1},
+			vec(1, 1), vec(0xfff8, 0), vec(0, 0), vec(0, 0), machine.FPSRQC},
+		{"sshl v0.4s, v1.4s, v2.4s", &Sshl{Q: 1, Size: 0b10, Rm: 2, Rn: 1},
+			vec(0, 0), vec(0x8000000000000001, 0xfffffff000000010), vec(0x000000ff0000001f, 0x00000081000000fe), vec(0xc000000080000000, 0xffffffff00000004), 0},
+		{"ushl v0.4s, v1.4s, v2.4s", &Ushl{Q: 1, Size: 0b10, Rm: 2, Rn: 1},
+			vec(0, 0), vec(0x8000000000000001, 0xfffffff000000010), vec(0x000000ff0000001f, 0x00000081000000fe), vec(0x4000000080000000, 0x0000000000000004), 0},
+		{"srshl v0.8b, v1.8b, v2.8b", &Srshl{Rm: 2, Rn: 1},
+			vec(1, 1), vec(0x0505, 0), vec(0xfeff, 0), vec(0x0103, 0), 0},
+		{"urshl v0.2d, v1.2d, v2.2d", &Urshl{Q: 1, Size: 0b11, Rm: 2, Rn: 1},
+			vec(0, 0), vec(^uint64(0), 1), vec(0xff, 0x40), vec(0x8000000000000000, 0), 0},
+		{"sqshl v0.8h, v1.8h, v2.8h", &SqshlRegister{Q: 1, Size: 0b01, Rm: 2, Rn: 1},
+			vec(0, 0), vec(0xffff0001c0004000, 0x1234), vec(0x000f000f00010001, 0x00fc), vec(0x80007fff80007fff, 0x0123), machine.FPSRQC},
+		{"uqshl v0.16b, v1.16b, v2.16b", &UqshlRegister{Q: 1, Rm: 2, Rn: 1},
+			vec(0, 0), vec(0x80, 0), vec(0x01, 0), vec(0xff, 0), machine.FPSRQC},
+		{"sqrshl v0.2d, v1.2d, v2.2d", &Sqrshl{Q: 1, Size: 0b11, Rm: 2, Rn: 1},
+			vec(0, 0), vec(0x4000000000000000, ^uint64(0)), vec(1, 0xc0), vec(0x7fffffffffffffff, 0), machine.FPSRQC},
+		{"uqrshl v0.4h, v1.4h, v2.4h", &Uqrshl{Size: 0b01, Rm: 2, Rn: 1},
+			vec(1, 1), vec(3, 0), vec(0xffff, 0), vec(2, 0), 0},
+	} {
+		m := load(tc.inst)
+		m.V[0] = tc.v0
+		m.V[1] = tc.v1
+		m.V[2] = tc.v2
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.V[0] != tc.want {
+			t.Errorf("%s with v1 = %x, v2 = %x: v0 = %x, want %x", tc.asm, tc.v1, tc.v2, m.V[0], tc.want)
+		}
+		if m.FPSR != tc.fpsr {
+			t.Errorf("%s: FPSR = 0x%x, want 0x%x", tc.asm, m.FPSR, tc.fpsr)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
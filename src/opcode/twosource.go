@@ -0,0 +1,167 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func encodeDataProcessing2Source(sf, opcode, rm, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{sf, 1},
+		{0b0011010110, 10},
+		{rm, 5},
+		{opcode, 6},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// divide writes Rn / Rm to Rd, rounding towards zero.  Dividing by zero gives zero rather than
+// raising an exception.
+func divide(m *machine.Machine, sf uint32, signed bool, rm, rn, rd uint32) {
+	n, d := reg(m, sf, rn, false), reg(m, sf, rm, false)
+	var result uint64
+	switch {
+	case d == 0:
+	case !signed:
+		result = n / d
+	case sf&1 == 0:
+		// The most negative number divided by -1 overflows back to itself.
+		result = uint64(int64(int32(n)) / int64(int32(d)))
+	default:
+		result = uint64(int64(n) / int64(d))
+	}
+	setReg(m, sf, rd, false, result)
+}
+
+// executeShiftVariable shifts Rn by Rm modulo the register width.
+func executeShiftVariable(m *machine.Machine, sf, shift, rm, rn, rd uint32) {
+	amount := reg(m, sf, rm, false) % (32 << (sf & 1))
+	setReg(m, sf, rd, false, shiftReg(sf, reg(m, sf, rn, false), shift, uint32(amount)))
+}
+
+// twoSourceOperands formats the registers of a data processing (2 source) instruction.
+func twoSourceOperands(sf, rm, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s, %s", regName(sf, rd, false), regName(sf, rn, false), regName(sf, rm, false))
+}
+
+// UDIV
+type Udiv struct {
+	Sf uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Udiv) Encode() uint32 {
+	return encodeDataProcessing2Source(op.Sf, 0b000010, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Udiv) Execute(m *machine.Machine) {
+	divide(m, op.Sf, false, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Udiv) String() string {
+	return "udiv " + twoSourceOperands(op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+// SDIV
+type Sdiv struct {
+	Sf uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Sdiv) Encode() uint32 {
+	return encodeDataProcessing2Source(op.Sf, 0b000011, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sdiv) Execute(m *machine.Machine) {
+	divide(m, op.Sf, true, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sdiv) String() string {
+	return "sdiv " + twoSourceOperands(op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+// LSLV
+type Lslv struct {
+	Sf uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Lslv) Encode() uint32 {
+	return encodeDataProcessing2Source(op.Sf, 0b001000, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Lslv) Execute(m *machine.Machine) {
+	executeShiftVariable(m, op.Sf, 0b00, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Lslv) String() string {
+	return "lsl " + twoSourceOperands(op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+// LSRV
+type Lsrv struct {
+	Sf uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Lsrv) Encode() uint32 {
+	return encodeDataProcessing2Source(op.Sf, 0b001001, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Lsrv) Execute(m *machine.Machine) {
+	executeShiftVariable(m, op.Sf, 0b01, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Lsrv) String() string {
+	return "lsr " + twoSourceOperands(op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+// ASRV
+type Asrv struct {
+	Sf uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Asrv) Encode() uint32 {
+	return encodeDataProcessing2Source(op.Sf, 0b001010, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Asrv) Execute(m *machine.Machine) {
+	executeShiftVariable(m, op.Sf, 0b10, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Asrv) String() string {
+	return "asr " + twoSourceOperands(op.Sf, op.Rm, op.Rn, op.Rd)
+}
+
+// RORV
+type Rorv struct {
+	Sf uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Rorv) Encode() uint32 {
+	return encodeDataProcessing2Source(op.Sf, 0b001011, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Rorv) Execute(m *machine.Machine) {
+	executeShiftVariable(m, op.Sf, 0b11, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Rorv) String() string {
+	return "ror " + twoSourceOperands(op.Sf, op.Rm, op.Rn, op.Rd)
+}
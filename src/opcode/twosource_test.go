@@ -0,0 +1,43 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestTwoSourceExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm    string
+		inst   Instruction
+		x1, x2 uint64
+		want   uint64
+	}{
+		{"udiv x0, x1, x2", &Udiv{Sf: 1, Rm: 2, Rn: 1}, 100, 7, 14},
+		{"udiv w0, w1, w2", &Udiv{Rm: 2, Rn: 1}, 0x1ffffffff, 0x100000002, 0x7fffffff},
+		{"udiv x0, x1, x2", &Udiv{Sf: 1, Rm: 2, Rn: 1}, 100, 0, 0},
+		{"sdiv x0, x1, x2", &Sdiv{Sf: 1, Rm: 2, Rn: 1}, 0xfffffffffffffff9, 2, 0xfffffffffffffffd},
+		{"sdiv w0, w1, w2", &Sdiv{Rm: 2, Rn: 1}, 7, 0xfffffffe, 0xfffffffd},
+		{"sdiv x0, x1, x2", &Sdiv{Sf: 1, Rm: 2, Rn: 1}, 0x8000000000000000, 0xffffffffffffffff, 0x8000000000000000},
+		{"sdiv w0, w1, w2", &Sdiv{Rm: 2, Rn: 1}, 0x80000000, 0xffffffff, 0x80000000},
+		{"sdiv w0, w1, w2", &Sdiv{Rm: 2, Rn: 1}, 0x80000000, 0xffffffff00000000, 0},
+		{"lsl x0, x1, x2", &Lslv{Sf: 1, Rm: 2, Rn: 1}, 1, 65, 2},
+		{"lsl w0, w1, w2", &Lslv{Rm: 2, Rn: 1}, 0xffffffff, 0x24, 0xfffffff0},
+		{"lsr x0, x1, x2", &Lsrv{Sf: 1, Rm: 2, Rn: 1}, 0x8000000000000000, 63, 1},
+		{"asr w0, w1, w2", &Asrv{Rm: 2, Rn: 1}, 0x80000000, 4, 0xf8000000},
+		{"ror x0, x1, x2", &Rorv{Sf: 1, Rm: 2, Rn: 1}, 0x1234, 0xfffffffffffffff8, 0x123400},
+	} {
+		m := load(tc.inst)
+		m.R[1], m.R[2] = tc.x1, tc.x2
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.R[0] != tc.want {
+			t.Errorf("%s: x0 = 0x%x, want 0x%x", tc.asm, m.R[0], tc.want)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
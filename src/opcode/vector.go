@@ -0,0 +1,93 @@
+package opcode
+
+import (
+	"github.com/runningwild/javelin/machine"
+)
+
+// elementBits returns the number of bits in each element of a vector with the given size field.
+func elementBits(size uint32) int {
+	return 8 << (size & 0b11)
+}
+
+// vectorBits returns the number of bits of a vector register that an instruction operates on,
+// which is all 128 if q is 1 and the lower 64 otherwise.
+func vectorBits(q uint32) int {
+	if q&1 == 1 {
+		return 128
+	}
+	return 64
+}
+
+// executeVectorUnary sets each esize-bit element of Vd to f applied to the same element of Vn.
+// Like every vector instruction that writes a 64-bit vector, it clears the upper half of Vd if q
+// is 0.
+func executeVectorUnary(m *machine.Machine, q uint32, esize int, rn, rd uint32, f func(x uint64) uint64) {
+	var result machine.VectorRegister
+	for i := 0; i < vectorBits(q)/esize; i++ {
+		result.Set(i, esize, f(m.V[rn&0b11111].Get(i, esize)))
+	}
+	m.V[rd&0b11111] = result
+}
+
+// executeVectorBinary sets each esize-bit element of Vd to f applied to the same elements of Vn
+// and Vm, clearing the upper half of Vd if q is 0.
+func executeVectorBinary(m *machine.Machine, q uint32, esize int, rn, rm, rd uint32, f func(x, y uint64) uint64) {
+	var result machine.VectorRegister
+	for i := 0; i < vectorBits(q)/esize; i++ {
+		result.Set(i, esize, f(m.V[rn&0b11111].Get(i, esize), m.V[rm&0b11111].Get(i, esize)))
+	}
+	m.V[rd&0b11111] = result
+}
+
+// executeVectorAccumulate sets each esize-bit element of Vd to f applied to the same elements of
+// Vd, Vn and Vm, clearing the upper half of Vd if q is 0.
+func executeVectorAccumulate(m *machine.Machine, q uint32, esize int, rn, rm, rd uint32, f func(acc, x, y uint64) uint64) {
+	var result machine.VectorRegister
+	for i := 0; i < vectorBits(q)/esize; i++ {
+		result.Set(i, esize, f(m.V[rd&0b11111].Get(i, esize), m.V[rn&0b11111].Get(i, esize), m.V[rm&0b11111].Get(i, esize)))
+	}
+	m.V[rd&0b11111] = result
+}
+
+// executeVectorNarrow sets element i of one half of Vd to the result of f(i), for each of the
+// 64/esize esize-bit elements in a half.  The lower half of Vd is written and the upper half
+// cleared if q is 0, otherwise the upper half is written and the lower half kept, as by the "2"
+// forms of the narrowing instructions.  FPSR.QC is set if f reports that any element saturated.
+func executeVectorNarrow(m *machine.Machine, q uint32, esize int, rd uint32, f func(i int) (uint64, bool)) {
+	var result machine.VectorRegister
+	if q&1 == 1 {
+		result = m.V[rd&0b11111]
+	}
+	lanes := 64 / esize
+	var saturated bool
+	for i := 0; i < lanes; i++ {
+		r, sat := f(i)
+		saturated = saturated || sat
+		result.Set(int(q&1)*lanes+i, esize, r)
+	}
+	m.V[rd&0b11111] = result
+	if saturated {
+		m.FPSR |= machine.FPSRQC
+	}
+}
+
+// partMnemonic returns the mnemonic of a widening or narrowing instruction, with a 2 suffix for
+// the form that operates on the upper half of its narrow vector.
+func partMnemonic(name string, q uint32) string {
+	if q&1 == 1 {
+		return name + "2"
+	}
+	return name
+}
+
+// narrowOperands formats the operands of a narrowing instruction.
+func narrowOperands(q, size, rn, rd uint32) string {
+	return vecName(rd, q, size) + ", " + vecName(rn, 1, size+1)
+}
+
+// setScalar writes the esize-bit value x to Vd as a scalar, clearing the rest of the register.
+func setScalar(m *machine.Machine, rd uint32, esize int, x uint64) {
+	var result machine.VectorRegister
+	result.Set(0, esize, x)
+	m.V[rd&0b11111] = result
+}
@@ -0,0 +1,404 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// encodeSIMDThreeSame encodes an instruction from the Advanced SIMD three same group.
+func encodeSIMDThreeSame(q, u, size, opcode, rm, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{0, 1},
+		{q, 1},
+		{u, 1},
+		{0b01110, 5},
+		{size, 2},
+		{1, 1},
+		{rm, 5},
+		{opcode, 5},
+		{1, 1},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// vectorBinaryOperands formats the operands of a vector instruction with two source registers.
+func vectorBinaryOperands(q, size, rm, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s, %s", vecName(rd, q, size), vecName(rn, q, size), vecName(rm, q, size))
+}
+
+// halvingAdd returns (x + y) >> 1 for esize-bit elements x and y, computed without overflow.  If
+// round is set 1 is added before halving.
+func halvingAdd(x, y uint64, esize int, signed, round bool) uint64 {
+	var r uint64
+	if round {
+		r = 1
+	}
+	if signed {
+		return uint64((signExtend(x, esize) + signExtend(y, esize) + int64(r)) >> 1)
+	}
+	return (x + y + r) >> 1
+}
+
+// absoluteDifference returns |x - y| for esize-bit elements x and y.
+func absoluteDifference(x, y uint64, esize int, signed bool) uint64 {
+	if signed {
+		d := signExtend(x, esize) - signExtend(y, esize)
+		if d < 0 {
+			d = -d
+		}
+		return uint64(d)
+	}
+	if x < y {
+		return y - x
+	}
+	return x - y
+}
+
+// maximum returns the larger of the esize-bit elements x and y, or the smaller if min is set.
+func maximum(x, y uint64, esize int, signed, min bool) uint64 {
+	less := x < y
+	if signed {
+		less = signExtend(x, esize) < signExtend(y, esize)
+	}
+	if less != min {
+		return y
+	}
+	return x
+}
+
+// SUB (vector)
+type SubVector struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *SubVector) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b10000, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *SubVector) Execute(m *machine.Machine) {
+	executeVectorBinary(m, op.Q, elementBits(op.Size), op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return x - y })
+}
+
+func (op *SubVector) String() string {
+	return "sub " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// MUL (vector)
+type MulVector struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *MulVector) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b10011, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *MulVector) Execute(m *machine.Machine) {
+	executeVectorBinary(m, op.Q, elementBits(op.Size), op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return x * y })
+}
+
+func (op *MulVector) String() string {
+	return "mul " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// MLA (vector)
+type MlaVector struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *MlaVector) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b10010, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *MlaVector) Execute(m *machine.Machine) {
+	executeVectorAccumulate(m, op.Q, elementBits(op.Size), op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return acc + x*y })
+}
+
+func (op *MlaVector) String() string {
+	return "mla " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// MLS (vector)
+type MlsVector struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *MlsVector) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b10010, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *MlsVector) Execute(m *machine.Machine) {
+	executeVectorAccumulate(m, op.Q, elementBits(op.Size), op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return acc - x*y })
+}
+
+func (op *MlsVector) String() string {
+	return "mls " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SABD
+type Sabd struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sabd) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b01110, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sabd) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return absoluteDifference(x, y, esize, true) })
+}
+
+func (op *Sabd) String() string {
+	return "sabd " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UABD
+type Uabd struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uabd) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b01110, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Uabd) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return absoluteDifference(x, y, esize, false) })
+}
+
+func (op *Uabd) String() string {
+	return "uabd " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SMAX
+type Smax struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Smax) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b01100, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Smax) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return maximum(x, y, esize, true, false) })
+}
+
+func (op *Smax) String() string {
+	return "smax " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UMAX
+type Umax struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Umax) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b01100, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Umax) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return maximum(x, y, esize, false, false) })
+}
+
+func (op *Umax) String() string {
+	return "umax " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SMIN
+type Smin struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Smin) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b01101, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Smin) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return maximum(x, y, esize, true, true) })
+}
+
+func (op *Smin) String() string {
+	return "smin " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UMIN
+type Umin struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Umin) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b01101, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Umin) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return maximum(x, y, esize, false, true) })
+}
+
+func (op *Umin) String() string {
+	return "umin " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SHADD
+type Shadd struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Shadd) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b00000, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Shadd) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return halvingAdd(x, y, esize, true, false) })
+}
+
+func (op *Shadd) String() string {
+	return "shadd " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UHADD
+type Uhadd struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uhadd) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b00000, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Uhadd) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return halvingAdd(x, y, esize, false, false) })
+}
+
+func (op *Uhadd) String() string {
+	return "uhadd " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SRHADD
+type Srhadd struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Srhadd) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b00010, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Srhadd) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return halvingAdd(x, y, esize, true, true) })
+}
+
+func (op *Srhadd) String() string {
+	return "srhadd " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// URHADD
+type Urhadd struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Urhadd) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b00010, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Urhadd) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return halvingAdd(x, y, esize, false, true) })
+}
+
+func (op *Urhadd) String() string {
+	return "urhadd " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// ADDP (vector)
+type AddpVector struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *AddpVector) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b10111, op.Rm, op.Rn, op.Rd)
+}
+
+// Execute adds adjacent pairs of elements of the concatenation of Vm and Vn, with the sums of the
+// pairs from Vn in the lower half of Vd.
+func (op *AddpVector) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	lanes := vectorBits(op.Q) / esize
+	var result machine.VectorRegister
+	for i, v := range []machine.VectorRegister{m.V[op.Rn&0b11111], m.V[op.Rm&0b11111]} {
+		for j := 0; j < lanes/2; j++ {
+			result.Set(i*lanes/2+j, esize, v.Get(2*j, esize)+v.Get(2*j+1, esize))
+		}
+	}
+	m.V[op.Rd&0b11111] = result
+}
+
+func (op *AddpVector) String() string {
+	return "addp " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
@@ -0,0 +1,53 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestVectorArithmeticExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm    string
+		inst   Instruction
+		v1, v2 machine.VectorRegister
+		want   machine.VectorRegister
+	}{
+		{"add v0.8b, v1.8b, v2.8b", &AddVector{Rm: 2, Rn: 1}, vec(0x01ff, 1), vec(0x0101, 1), vec(0x0200, 0)},
+		{"add v0.2d, v1.2d, v2.2d", &AddVector{Q: 1, Size: 0b11, Rm: 2, Rn: 1}, vec(^uint64(0), 2), vec(2, 3), vec(1, 5)},
+		{"sub v0.4s, v1.4s, v2.4s", &SubVector{Q: 1, Size: 0b10, Rm: 2, Rn: 1}, vec(0x0000000100000005, 0), vec(0x0000000200000003, 1), vec(0xffffffff00000002, 0xffffffff)},
+		{"sub v0.4h, v1.4h, v2.4h", &SubVector{Size: 0b01, Rm: 2, Rn: 1}, vec(0x0005, 7), vec(0x0006, 0), vec(0xffff, 0)},
+		{"mul v0.8h, v1.8h, v2.8h", &MulVector{Q: 1, Size: 0b01, Rm: 2, Rn: 1}, vec(0x0100_0003, 0xffff), vec(0x0100_0004, 0xffff), vec(0x000c, 0x0001)},
+		{"mla v0.2s, v1.2s, v2.2s", &MlaVector{Size: 0b10, Rm: 2, Rn: 1}, vec(0x0000000300000002, 1), vec(0x0000000400000005, 1), vec(0x0000000b_00000009, 0)},
+		{"mls v0.16b, v1.16b, v2.16b", &MlsVector{Q: 1, Rm: 2, Rn: 1}, vec(0x0203, 0), vec(0x0102, 0), vec(0xfffffffffffffdf9, ^uint64(0))},
+		{"abs v0.8b, v1.8b", &AbsVector{Rn: 1}, vec(0x807f01ff, 1), machine.VectorRegister{}, vec(0x807f0101, 0)},
+		{"abs v0.2d, v1.2d", &AbsVector{Q: 1, Size: 0b11, Rn: 1}, vec(^uint64(0), 5), machine.VectorRegister{}, vec(1, 5)},
+		{"neg v0.4s, v1.4s", &NegVector{Q: 1, Size: 0b10, Rn: 1}, vec(0x0000000180000000, 0), machine.VectorRegister{}, vec(0xffffffff80000000, 0)},
+		{"sabd v0.8b, v1.8b, v2.8b", &Sabd{Rm: 2, Rn: 1}, vec(0x807f05, 0), vec(0x7f8003, 0), vec(0xffff02, 0)},
+		{"uabd v0.4h, v1.4h, v2.4h", &Uabd{Size: 0b01, Rm: 2, Rn: 1}, vec(0x0003_ffff, 0), vec(0x0005_0001, 0), vec(0x0002_fffe, 0)},
+		{"smax v0.8b, v1.8b, v2.8b", &Smax{Rm: 2, Rn: 1}, vec(0x80_01_ff, 0), vec(0x7f_02_00, 0), vec(0x7f_02_00, 0)},
+		{"umax v0.8b, v1.8b, v2.8b", &Umax{Rm: 2, Rn: 1}, vec(0x80_01_ff, 0), vec(0x7f_02_00, 0), vec(0x80_02_ff, 0)},
+		{"smin v0.2s, v1.2s, v2.2s", &Smin{Size: 0b10, Rm: 2, Rn: 1}, vec(0x80000000_00000001, 0), vec(0x7fffffff_00000002, 0), vec(0x80000000_00000001, 0)},
+		{"umin v0.4s, v1.4s, v2.4s", &Umin{Q: 1, Size: 0b10, Rm: 2, Rn: 1}, vec(0x80000000_00000001, 7), vec(0x7fffffff_00000002, 3), vec(0x7fffffff_00000001, 3)},
+		{"shadd v0.8b, v1.8b, v2.8b", &Shadd{Rm: 2, Rn: 1}, vec(0x80_7f_ff, 0), vec(0x80_7f_fe, 0), vec(0x80_7f_fe, 0)},
+		{"uhadd v0.8b, v1.8b, v2.8b", &Uhadd{Rm: 2, Rn: 1}, vec(0x80_7f_ff, 0), vec(0x80_7f_fe, 0), vec(0x80_7f_fe, 0)},
+		{"srhadd v0.8b, v1.8b, v2.8b", &Srhadd{Rm: 2, Rn: 1}, vec(0x80_01_ff, 0), vec(0x81_02_fe, 0), vec(0x81_02_ff, 0)},
+		{"urhadd v0.8b, v1.8b, v2.8b", &Urhadd{Rm: 2, Rn: 1}, vec(0x80_01_ff, 0), vec(0x81_02_fe, 0), vec(0x81_02_ff, 0)},
+		{"addp v0.4s, v1.4s, v2.4s", &AddpVector{Q: 1, Size: 0b10, Rm: 2, Rn: 1}, vec(0x00000002_00000001, 0xffffffff_00000003), vec(0x00000020_00000010, 0x00000000_00000005), vec(0x00000002_00000003, 0x00000005_00000030)},
+		{"addp v0.4h, v1.4h, v2.4h", &AddpVector{Size: 0b01, Rm: 2, Rn: 1}, vec(0x0004_0003_0002_0001, 1), vec(0x0040_0030_0020_0010, 1), vec(0x0070_0030_0007_0003, 0)},
+	} {
+		m := load(tc.inst)
+		m.V[0] = vec(^uint64(0), ^uint64(0))
+		m.V[1], m.V[2] = tc.v1, tc.v2
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.V[0] != tc.want {
+			t.Errorf("%s with v1 = %x, v2 = %x: v0 = %x, want %x", tc.asm, tc.v1, tc.v2, m.V[0], tc.want)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
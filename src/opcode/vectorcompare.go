@@ -0,0 +1,287 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// compareMask returns an esize-bit element that is all ones if cond holds and all zeros otherwise.
+func compareMask(cond bool, esize int) uint64 {
+	if cond {
+		return elementMask(esize)
+	}
+	return 0
+}
+
+// compareElements returns -1, 0 or 1 as the esize-bit element x is less than, equal to or greater
+// than y, comparing them as signed integers if signed is set.
+func compareElements(x, y uint64, esize int, signed bool) int {
+	if signed {
+		a, b := signExtend(x, esize), signExtend(y, esize)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	}
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// executeVectorCompare sets each esize-bit element of Vd to all ones if f holds for the result of
+// comparing the same elements of Vn and Vm, and to all zeros otherwise.
+func executeVectorCompare(m *machine.Machine, q uint32, esize int, rn, rm uint32, signed bool, rd uint32, f func(c int) bool) {
+	executeVectorBinary(m, q, esize, rn, rm, rd, func(x, y uint64) uint64 {
+		return compareMask(f(compareElements(x, y, esize, signed)), esize)
+	})
+}
+
+// executeVectorCompareZero sets each esize-bit element of Vd to all ones if f holds for the result
+// of comparing the same element of Vn, as a signed integer, with zero, and to all zeros otherwise.
+func executeVectorCompareZero(m *machine.Machine, q uint32, esize int, rn, rd uint32, f func(c int) bool) {
+	executeVectorUnary(m, q, esize, rn, rd, func(x uint64) uint64 {
+		return compareMask(f(compareElements(x, 0, esize, true)), esize)
+	})
+}
+
+// CMEQ (register)
+type CmeqRegister struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *CmeqRegister) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b10001, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *CmeqRegister) Execute(m *machine.Machine) {
+	executeVectorCompare(m, op.Q, elementBits(op.Size), op.Rn, op.Rm, false, op.Rd, func(c int) bool { return c == 0 })
+}
+
+func (op *CmeqRegister) String() string {
+	return "cmeq " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// CMTST
+type Cmtst struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Cmtst) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b10001, op.Rm, op.Rn, op.Rd)
+}
+
+// Execute sets each element of Vd to all ones if the same elements of Vn and Vm have any set bits
+// in common.
+func (op *Cmtst) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorBinary(m, op.Q, esize, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return compareMask(x&y != 0, esize) })
+}
+
+func (op *Cmtst) String() string {
+	return "cmtst " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// CMGT (register)
+type CmgtRegister struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *CmgtRegister) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b00110, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *CmgtRegister) Execute(m *machine.Machine) {
+	executeVectorCompare(m, op.Q, elementBits(op.Size), op.Rn, op.Rm, true, op.Rd, func(c int) bool { return c > 0 })
+}
+
+func (op *CmgtRegister) String() string {
+	return "cmgt " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// CMGE (register)
+type CmgeRegister struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *CmgeRegister) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, op.Size, 0b00111, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *CmgeRegister) Execute(m *machine.Machine) {
+	executeVectorCompare(m, op.Q, elementBits(op.Size), op.Rn, op.Rm, true, op.Rd, func(c int) bool { return c >= 0 })
+}
+
+func (op *CmgeRegister) String() string {
+	return "cmge " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// CMHI
+type Cmhi struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Cmhi) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b00110, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Cmhi) Execute(m *machine.Machine) {
+	executeVectorCompare(m, op.Q, elementBits(op.Size), op.Rn, op.Rm, false, op.Rd, func(c int) bool { return c > 0 })
+}
+
+func (op *Cmhi) String() string {
+	return "cmhi " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// CMHS
+type Cmhs struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Cmhs) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, op.Size, 0b00111, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Cmhs) Execute(m *machine.Machine) {
+	executeVectorCompare(m, op.Q, elementBits(op.Size), op.Rn, op.Rm, false, op.Rd, func(c int) bool { return c >= 0 })
+}
+
+func (op *Cmhs) String() string {
+	return "cmhs " + vectorBinaryOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// vectorCompareZeroOperands formats the operands of a vector compare with zero.
+func vectorCompareZeroOperands(q, size, rn, rd uint32) string {
+	return fmt.Sprintf("%s, #0", vectorUnaryOperands(q, size, rn, rd))
+}
+
+// CMEQ (zero)
+type CmeqZero struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *CmeqZero) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 0, op.Size, 0b01001, op.Rn, op.Rd)
+}
+
+func (op *CmeqZero) Execute(m *machine.Machine) {
+	executeVectorCompareZero(m, op.Q, elementBits(op.Size), op.Rn, op.Rd, func(c int) bool { return c == 0 })
+}
+
+func (op *CmeqZero) String() string {
+	return "cmeq " + vectorCompareZeroOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
+
+// CMGT (zero)
+type CmgtZero struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *CmgtZero) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 0, op.Size, 0b01000, op.Rn, op.Rd)
+}
+
+func (op *CmgtZero) Execute(m *machine.Machine) {
+	executeVectorCompareZero(m, op.Q, elementBits(op.Size), op.Rn, op.Rd, func(c int) bool { return c > 0 })
+}
+
+func (op *CmgtZero) String() string {
+	return "cmgt " + vectorCompareZeroOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
+
+// CMGE (zero)
+type CmgeZero struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *CmgeZero) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 1, op.Size, 0b01000, op.Rn, op.Rd)
+}
+
+func (op *CmgeZero) Execute(m *machine.Machine) {
+	executeVectorCompareZero(m, op.Q, elementBits(op.Size), op.Rn, op.Rd, func(c int) bool { return c >= 0 })
+}
+
+func (op *CmgeZero) String() string {
+	return "cmge " + vectorCompareZeroOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
+
+// CMLE (zero)
+type CmleZero struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *CmleZero) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 1, op.Size, 0b01001, op.Rn, op.Rd)
+}
+
+func (op *CmleZero) Execute(m *machine.Machine) {
+	executeVectorCompareZero(m, op.Q, elementBits(op.Size), op.Rn, op.Rd, func(c int) bool { return c <= 0 })
+}
+
+func (op *CmleZero) String() string {
+	return "cmle " + vectorCompareZeroOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
+
+// CMLT (zero)
+type CmltZero struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *CmltZero) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 0, op.Size, 0b01010, op.Rn, op.Rd)
+}
+
+func (op *CmltZero) Execute(m *machine.Machine) {
+	executeVectorCompareZero(m, op.Q, elementBits(op.Size), op.Rn, op.Rd, func(c int) bool { return c < 0 })
+}
+
+func (op *CmltZero) String() string {
+	return "cmlt " + vectorCompareZeroOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
@@ -0,0 +1,51 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestVectorCompareExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm    string
+		inst   Instruction
+		v1, v2 machine.VectorRegister
+		want   machine.VectorRegister
+	}{
+		{"cmeq v0.16b, v1.16b, v2.16b", &CmeqRegister{Q: 1, Rm: 2, Rn: 1},
+			vec(0x0102030405060708, 0x1111111111111111), vec(0x0102ff0405ff0708, 0x1111111111111112), vec(0xffff00ffff00ffff, 0xffffffffffffff00)},
+		{"cmgt v0.8b, v1.8b, v2.8b", &CmgtRegister{Rm: 2, Rn: 1},
+			vec(0x807f0001ff050510, 1), vec(0x00800000fe05060f, 0), vec(0x00ff00ffff0000ff, 0)},
+		{"cmhi v0.8b, v1.8b, v2.8b", &Cmhi{Rm: 2, Rn: 1},
+			vec(0x807f0001ff050510, 1), vec(0x00800000fe05060f, 0), vec(0xff0000ffff0000ff, 0)},
+		{"cmge v0.4h, v1.4h, v2.4h", &CmgeRegister{Size: 0b01, Rm: 2, Rn: 1},
+			vec(0x80000001ffff0005, 0), vec(0x7fff000100000006, 0), vec(0x0000ffff00000000, 0)},
+		{"cmhs v0.2d, v1.2d, v2.2d", &Cmhs{Q: 1, Size: 0b11, Rm: 2, Rn: 1},
+			vec(5, 0x8000000000000000), vec(5, 0x8000000000000001), vec(^uint64(0), 0)},
+		{"cmtst v0.4s, v1.4s, v2.4s", &Cmtst{Q: 1, Size: 0b10, Rm: 2, Rn: 1},
+			vec(0x00000001000000f0, 0x8000000000000000), vec(0x000000010000000f, 0x80000000ffffffff), vec(0xffffffff00000000, 0xffffffff00000000)},
+		{"cmeq v0.8h, v1.8h, #0", &CmeqZero{Q: 1, Size: 0b01, Rn: 1},
+			vec(0x0000000100008000, 0xffff000000000000), machine.VectorRegister{}, vec(0xffff0000ffff0000, 0x0000ffffffffffff)},
+		{"cmgt v0.8b, v1.8b, #0", &CmgtZero{Rn: 1}, vec(0x807f0001ff050510, 1), machine.VectorRegister{}, vec(0x00ff00ff00ffffff, 0)},
+		{"cmge v0.8b, v1.8b, #0", &CmgeZero{Rn: 1}, vec(0x807f0001ff050510, 1), machine.VectorRegister{}, vec(0x00ffffff00ffffff, 0)},
+		{"cmle v0.8b, v1.8b, #0", &CmleZero{Rn: 1}, vec(0x807f0001ff050510, 1), machine.VectorRegister{}, vec(0xff00ff00ff000000, 0)},
+		{"cmlt v0.2d, v1.2d, #0", &CmltZero{Q: 1, Size: 0b11, Rn: 1}, vec(0x8000000000000000, 0), machine.VectorRegister{}, vec(^uint64(0), 0)},
+		{"cmlt v0.2s, v1.2s, #0", &CmltZero{Size: 0b10, Rn: 1}, vec(0xffffffff00000001, ^uint64(0)), machine.VectorRegister{}, vec(0xffffffff00000000, 0)},
+	} {
+		m := load(tc.inst)
+		m.V[0] = vec(^uint64(0), ^uint64(0))
+		m.V[1] = tc.v1
+		m.V[2] = tc.v2
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.V[0] != tc.want {
+			t.Errorf("%s with v1 = %x, v2 = %x: v0 = %x, want %x", tc.asm, tc.v1, tc.v2, m.V[0], tc.want)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package opcode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// multipleStructures returns the number of registers that are transferred one after another, and
+// the number of elements in each structure, for a load/store multiple structures opcode.  It
+// returns false if opcode is unallocated.
+func multipleStructures(opcode uint32) (rpt, selem int, ok bool) {
+	switch opcode & 0b1111 {
+	case 0b0000:
+		return 1, 4, true
+	case 0b0010:
+		return 4, 1, true
+	case 0b0100:
+		return 1, 3, true
+	case 0b0110:
+		return 3, 1, true
+	case 0b0111:
+		return 1, 1, true
+	case 0b1000:
+		return 1, 2, true
+	case 0b1010:
+		return 2, 1, true
+	}
+	return 0, 0, false
+}
+
+// singleStructure decodes the opcode, S and size fields of a load/store single structure
+// instruction.  It returns log2 of the number of bytes in each element, the index of the lane that
+// is transferred, and whether the structure is instead replicated to every lane.  It returns false
+// if the fields are unallocated.
+func singleStructure(q, l, opcode, s, size uint32) (scale, index uint32, replicate, ok bool) {
+	q, s, size = q&1, s&1, size&0b11
+	switch opcode >> 1 & 0b11 {
+	case 0:
+		return 0, q<<3 | s<<2 | size, false, true
+	case 1:
+		if size&1 == 1 {
+			return 0, 0, false, false
+		}
+		return 1, q<<2 | s<<1 | size>>1, false, true
+	case 2:
+		switch size {
+		case 0b00:
+			return 2, q<<1 | s, false, true
+		case 0b01:
+			if s == 0 {
+				return 3, q, false, true
+			}
+		}
+		return 0, 0, false, false
+	}
+	if l&1 == 0 || s == 1 {
+		return 0, 0, false, false
+	}
+	return size, 0, true, true
+}
+
+// singleStructureElements returns the number of elements in the structure transferred by a
+// load/store single structure instruction.
+func singleStructureElements(opcode, r uint32) int {
+	return int((opcode&1)<<1|r&1) + 1
+}
+
+// vectorList formats n consecutive vector registers starting at t, which wrap around from v31 to
+// v0, each followed by suffix.
+func vectorList(t uint32, n int, suffix string) string {
+	regs := make([]string, n)
+	for i := range regs {
+		regs[i] = fmt.Sprintf("v%d%s", (t+uint32(i))&0b11111, suffix)
+	}
+	return "{ " + strings.Join(regs, ", ") + " }"
+}
+
+// structureAddress formats the addressing mode of a load/store structure instruction.  If
+// postIndex is set, Rn is incremented by Rm, or by imm if Rm is 31.
+func structureAddress(rn, rm uint32, postIndex bool, imm int) string {
+	switch {
+	case !postIndex:
+		return fmt.Sprintf("[%s]", regName(1, rn, true))
+	case rm&0b11111 == 31:
+		return fmt.Sprintf("[%s], #%d", regName(1, rn, true), imm)
+	}
+	return fmt.Sprintf("[%s], %s", regName(1, rn, true), regName(1, rm, false))
+}
+
+// structureMnemonic returns the mnemonic of a load or store of selem-element structures.
+func structureMnemonic(l uint32, selem int) string {
+	if l&1 == 1 {
+		return fmt.Sprintf("ld%d", selem)
+	}
+	return fmt.Sprintf("st%d", selem)
+}
+
+// writebackStructure adds the post-index increment to Rn, which is Rm unless that is 31, in which
+// case it is the number of bytes transferred.
+func writebackStructure(m *machine.Machine, rn, rm uint32, size int) {
+	offset := uint64(size)
+	if rm&0b11111 != 31 {
+		offset = reg(m, 1, rm, false)
+	}
+	setReg(m, 1, rn, true, reg(m, 1, rn, true)+offset)
+}
+
+// executeLoadStoreMultiple transfers whole registers starting at Rt to or from consecutive
+// structures in memory at Rn, where each structure holds the same element of selem registers.
+// Registers are only written if every access succeeds.  It returns false after stopping the
+// machine if an access faults.
+func executeLoadStoreMultiple(m *machine.Machine, q, l, opcode, size, rn, rt uint32) bool {
+	rpt, selem, _ := multipleStructures(opcode)
+	esize := elementBits(size)
+	ebytes := esize / 8
+	addr := reg(m, 1, rn, true)
+	regs := m.V
+	if l&1 == 1 {
+		// A load of a 64-bit vector clears the upper half of the register.
+		for i := 0; i < rpt*selem; i++ {
+			regs[(rt+uint32(i))&0b11111].Set(1, 64, 0)
+		}
+	}
+	for r := 0; r < rpt; r++ {
+		for e := 0; e < vectorBits(q)/esize; e++ {
+			for s := 0; s < selem; s++ {
+				t := (rt + uint32(r+s)) & 0b11111
+				var err error
+				if l&1 == 1 {
+					var data uint64
+					if data, err = m.Read(addr, ebytes); err == nil {
+						regs[t].Set(e, esize, data)
+					}
+				} else {
+					err = m.Write(addr, ebytes, regs[t].Get(e, esize))
+				}
+				if err != nil {
+					m.Stop(machine.StopFault, err)
+					return false
+				}
+				addr += uint64(ebytes)
+			}
+		}
+	}
+	m.V = regs
+	return true
+}
+
+// loadStoreMultipleSize returns the number of bytes transferred by a load/store multiple
+// structures instruction.
+func loadStoreMultipleSize(q, opcode uint32) int {
+	rpt, selem, _ := multipleStructures(opcode)
+	return rpt * selem * vectorBits(q) / 8
+}
+
+// loadStoreMultipleString formats a load/store multiple structures instruction.
+func loadStoreMultipleString(q, l, rm, opcode, size, rn, rt uint32, postIndex bool) string {
+	rpt, selem, _ := multipleStructures(opcode)
+	return fmt.Sprintf("%s %s, %s", structureMnemonic(l, selem), vectorList(rt, rpt*selem, "."+arrangement(q, size)), structureAddress(rn, rm, postIndex, loadStoreMultipleSize(q, opcode)))
+}
+
+// executeLoadStoreSingle transfers one lane of each of selem registers starting at Rt to or from a
+// structure in memory at Rn, or loads a structure and replicates it to every lane.  Like
+// executeLoadStoreMultiple, registers are only written if every access succeeds.
+func executeLoadStoreSingle(m *machine.Machine, q, l, r, opcode, s, size, rn, rt uint32) bool {
+	scale, index, replicate, _ := singleStructure(q, l, opcode, s, size)
+	selem := singleStructureElements(opcode, r)
+	esize := 8 << scale
+	addr := reg(m, 1, rn, true)
+	regs := m.V
+	for i := 0; i < selem; i++ {
+		t := (rt + uint32(i)) & 0b11111
+		var err error
+		switch {
+		case replicate:
+			var data uint64
+			if data, err = m.Read(addr, esize/8); err == nil {
+				regs[t] = machine.VectorRegister{}
+				for e := 0; e < vectorBits(q)/esize; e++ {
+					regs[t].Set(e, esize, data)
+				}
+			}
+		case l&1 == 1:
+			var data uint64
+			if data, err = m.Read(addr, esize/8); err == nil {
+				regs[t].Set(int(index), esize, data)
+			}
+		default:
+			err = m.Write(addr, esize/8, regs[t].Get(int(index), esize))
+		}
+		if err != nil {
+			m.Stop(machine.StopFault, err)
+			return false
+		}
+		addr += uint64(esize / 8)
+	}
+	m.V = regs
+	return true
+}
+
+// loadStoreSingleSize returns the number of bytes transferred by a load/store single structure
+// instruction.
+func loadStoreSingleSize(q, l, r, opcode, s, size uint32) int {
+	scale, _, _, _ := singleStructure(q, l, opcode, s, size)
+	return singleStructureElements(opcode, r) << scale
+}
+
+// loadStoreSingleString formats a load/store single structure instruction.
+func loadStoreSingleString(q, l, r, rm, opcode, s, size, rn, rt uint32, postIndex bool) string {
+	scale, index, replicate, _ := singleStructure(q, l, opcode, s, size)
+	selem := singleStructureElements(opcode, r)
+	addr := structureAddress(rn, rm, postIndex, loadStoreSingleSize(q, l, r, opcode, s, size))
+	if replicate {
+		return fmt.Sprintf("ld%dr %s, %s", selem, vectorList(rt, selem, "."+arrangement(q, scale)), addr)
+	}
+	return fmt.Sprintf("%s %s[%d], %s", structureMnemonic(l, selem), vectorList(rt, selem, "."+string("bhsd"[scale])), index, addr)
+}
+
+// encodeLoadStoreStructure encodes one of the Advanced SIMD load/store structure instructions.
+// single selects the single structure forms, and the 6 bits that follow L are R:Rm for those
+// and 0:Rm for the multiple structure forms, where Rm is 0 unless postIndex is set.
+func encodeLoadStoreStructure(q, single, postIndex, l, r, rm, opcode, size, rn, rt uint32) uint32 {
+	return buildUint32([]bits{
+		{0, 1},
+		{q, 1},
+		{0b00110, 5},
+		{single, 1},
+		{postIndex, 1},
+		{l, 1},
+		{r, 1},
+		{rm, 5},
+		{opcode, 4},
+		{size, 2},
+		{rn, 5},
+		{rt, 5},
+	}...)
+}
+
+// Advanced SIMD load/store multiple structures: LD1, LD2, LD3, LD4, ST1, ST2, ST3 and ST4
+type LoadStoreMultiple struct {
+	Q      uint32 // 1 bit
+	L      uint32 // 1 bit
+	Opcode uint32 // 4 bits
+	Size   uint32 // 2 bits
+	Rn     uint32 // 5 bits
+	Rt     uint32 // 5 bits
+}
+
+func (op *LoadStoreMultiple) Encode() uint32 {
+	return encodeLoadStoreStructure(op.Q, 0, 0, op.L, 0, 0, op.Opcode, op.Size, op.Rn, op.Rt)
+}
+
+func (op *LoadStoreMultiple) Execute(m *machine.Machine) {
+	executeLoadStoreMultiple(m, op.Q, op.L, op.Opcode, op.Size, op.Rn, op.Rt)
+}
+
+func (op *LoadStoreMultiple) String() string {
+	return loadStoreMultipleString(op.Q, op.L, 0, op.Opcode, op.Size, op.Rn, op.Rt, false)
+}
+
+// Advanced SIMD load/store multiple structures (post-indexed)
+type LoadStoreMultiplePostIndexed struct {
+	Q      uint32 // 1 bit
+	L      uint32 // 1 bit
+	Rm     uint32 // 5 bits
+	Opcode uint32 // 4 bits
+	Size   uint32 // 2 bits
+	Rn     uint32 // 5 bits
+	Rt     uint32 // 5 bits
+}
+
+func (op *LoadStoreMultiplePostIndexed) Encode() uint32 {
+	return encodeLoadStoreStructure(op.Q, 0, 1, op.L, 0, op.Rm, op.Opcode, op.Size, op.Rn, op.Rt)
+}
+
+func (op *LoadStoreMultiplePostIndexed) Execute(m *machine.Machine) {
+	if executeLoadStoreMultiple(m, op.Q, op.L, op.Opcode, op.Size, op.Rn, op.Rt) {
+		writebackStructure(m, op.Rn, op.Rm, loadStoreMultipleSize(op.Q, op.Opcode))
+	}
+}
+
+func (op *LoadStoreMultiplePostIndexed) String() string {
+	return loadStoreMultipleString(op.Q, op.L, op.Rm, op.Opcode, op.Size, op.Rn, op.Rt, true)
+}
+
+// Advanced SIMD load/store single structure: LD1, LD2, LD3, LD4, ST1, ST2, ST3 and ST4 to a single
+// lane, and LD1R, LD2R, LD3R and LD4R
+type LoadStoreSingle struct {
+	Q      uint32 // 1 bit
+	L      uint32 // 1 bit
+	R      uint32 // 1 bit
+	Opcode uint32 // 3 bits
+	S      uint32 // 1 bit
+	Size   uint32 // 2 bits
+	Rn     uint32 // 5 bits
+	Rt     uint32 // 5 bits
+}
+
+func (op *LoadStoreSingle) Encode() uint32 {
+	return encodeLoadStoreStructure(op.Q, 1, 0, op.L, op.R, 0, op.Opcode<<1|op.S&1, op.Size, op.Rn, op.Rt)
+}
+
+func (op *LoadStoreSingle) Execute(m *machine.Machine) {
+	executeLoadStoreSingle(m, op.Q, op.L, op.R, op.Opcode, op.S, op.Size, op.Rn, op.Rt)
+}
+
+func (op *LoadStoreSingle) String() string {
+	return loadStoreSingleString(op.Q, op.L, op.R, 0, op.Opcode, op.S, op.Size, op.Rn, op.Rt, false)
+}
+
+// Advanced SIMD load/store single structure (post-indexed)
+type LoadStoreSinglePostIndexed struct {
+	Q      uint32 // 1 bit
+	L      uint32 // 1 bit
+	R      uint32 // 1 bit
+	Rm     uint32 // 5 bits
+	Opcode uint32 // 3 bits
+	S      uint32 // 1 bit
+	Size   uint32 // 2 bits
+	Rn     uint32 // 5 bits
+	Rt     uint32 // 5 bits
+}
+
+func (op *LoadStoreSinglePostIndexed) Encode() uint32 {
+	return encodeLoadStoreStructure(op.Q, 1, 1, op.L, op.R, op.Rm, op.Opcode<<1|op.S&1, op.Size, op.Rn, op.Rt)
+}
+
+func (op *LoadStoreSinglePostIndexed) Execute(m *machine.Machine) {
+	if executeLoadStoreSingle(m, op.Q, op.L, op.R, op.Opcode, op.S, op.Size, op.Rn, op.Rt) {
+		writebackStructure(m, op.Rn, op.Rm, loadStoreSingleSize(op.Q, op.L, op.R, op.Opcode, op.S, op.Size))
+	}
+}
+
+func (op *LoadStoreSinglePostIndexed) String() string {
+	return loadStoreSingleString(op.Q, op.L, op.R, op.Rm, op.Opcode, op.S, op.Size, op.Rn, op.Rt, true)
+}
@@ -0,0 +1,187 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// bytesFrom returns a vector register holding n bytes counting up from first in steps of step.
+func bytesFrom(first, step byte, n int) machine.VectorRegister {
+	var v machine.VectorRegister
+	for i := 0; i < n; i++ {
+		v[i] = first + byte(i)*step
+	}
+	return v
+}
+
+// fill returns a vector register with every byte set to b.
+func fill(b byte) machine.VectorRegister {
+	var v machine.VectorRegister
+	for i := range v {
+		v[i] = b
+	}
+	return v
+}
+
+func TestLoadStoreStructureExecute(t *testing.T) {
+	const pc = 0x400
+	for _, tc := range []struct {
+		asm    string
+		inst   Instruction
+		setup  func(m *machine.Machine)
+		check  func(m *machine.Machine) bool
+		reason machine.StopReason
+	}{
+		{
+			asm:   "ld1 { v0.16b }, [x0]",
+			inst:  &LoadStoreMultiple{Q: 1, L: 1, Opcode: 0b0111},
+			check: func(m *machine.Machine) bool { return m.V[0] == bytesFrom(0, 1, 16) && m.R[0] == 0x80 },
+		},
+		{
+			asm:  "ld1 { v0.8b, v1.8b }, [x0], #16",
+			inst: &LoadStoreMultiplePostIndexed{L: 1, Rm: 31, Opcode: 0b1010},
+			check: func(m *machine.Machine) bool {
+				return m.V[0] == bytesFrom(0, 1, 8) && m.V[1] == bytesFrom(8, 1, 8) && m.R[0] == 0x90
+			},
+		},
+		{
+			asm:  "ld2 { v0.8h, v1.8h }, [x0]",
+			inst: &LoadStoreMultiple{Q: 1, L: 1, Opcode: 0b1000, Size: 0b01},
+			check: func(m *machine.Machine) bool {
+				return m.V[0] == machine.VectorRegister{0, 1, 4, 5, 8, 9, 12, 13, 16, 17, 20, 21, 24, 25, 28, 29} &&
+					m.V[1] == machine.VectorRegister{2, 3, 6, 7, 10, 11, 14, 15, 18, 19, 22, 23, 26, 27, 30, 31}
+			},
+		},
+		{
+			asm:   "ld3 { v0.8b, v1.8b, v2.8b }, [x0], x2",
+			inst:  &LoadStoreMultiplePostIndexed{L: 1, Rm: 2, Opcode: 0b0100},
+			setup: func(m *machine.Machine) { m.R[2] = 5 },
+			check: func(m *machine.Machine) bool {
+				return m.V[0] == bytesFrom(0, 3, 8) && m.V[1] == bytesFrom(1, 3, 8) && m.V[2] == bytesFrom(2, 3, 8) && m.R[0] == 0x85
+			},
+		},
+		{
+			asm:  "ld4 { v30.4s, v31.4s, v0.4s, v1.4s }, [x0]",
+			inst: &LoadStoreMultiple{Q: 1, L: 1, Opcode: 0b0000, Size: 0b10, Rt: 30},
+			check: func(m *machine.Machine) bool {
+				return m.V[30] == machine.VectorRegister{0, 1, 2, 3, 16, 17, 18, 19, 32, 33, 34, 35, 48, 49, 50, 51} &&
+					m.V[1] == machine.VectorRegister{12, 13, 14, 15, 28, 29, 30, 31, 44, 45, 46, 47, 60, 61, 62, 63}
+			},
+		},
+		{
+			asm:  "st4 { v0.16b, v1.16b, v2.16b, v3.16b }, [x0]",
+			inst: &LoadStoreMultiple{Q: 1, Opcode: 0b0000},
+			setup: func(m *machine.Machine) {
+				for i := 0; i < 4; i++ {
+					m.V[i] = fill(byte(i))
+				}
+			},
+			check: func(m *machine.Machine) bool {
+				for i := 0; i < 64; i++ {
+					if m.Memory[0x80+i] != byte(i%4) {
+						return false
+					}
+				}
+				return m.Memory[0xc0] == 0xc0
+			},
+		},
+		{
+			asm:   "st1 { v0.1d }, [sp], #8",
+			inst:  &LoadStoreMultiplePostIndexed{L: 0, Rm: 31, Opcode: 0b0111, Size: 0b11, Rn: 31},
+			setup: func(m *machine.Machine) { m.SP = 0x100 },
+			check: func(m *machine.Machine) bool {
+				v, _ := m.Read(0x100, 8)
+				return v == 0xffffffffffffffff && m.Memory[0x108] == 0x88 && m.SP == 0x108
+			},
+		},
+		{
+			asm:  "ld1 { v0.h }[3], [x0], #2",
+			inst: &LoadStoreSinglePostIndexed{L: 1, Rm: 31, Opcode: 0b010, S: 1, Size: 0b10},
+			check: func(m *machine.Machine) bool {
+				want := fill(0xff)
+				want[6], want[7] = 0, 1
+				return m.V[0] == want && m.R[0] == 0x82
+			},
+		},
+		{
+			asm:  "ld2 { v0.s, v1.s }[1], [x0]",
+			inst: &LoadStoreSingle{L: 1, R: 1, Opcode: 0b100, S: 1},
+			check: func(m *machine.Machine) bool {
+				v0, v1 := fill(0xff), fill(0xff)
+				copy(v0[4:8], []byte{0, 1, 2, 3})
+				copy(v1[4:8], []byte{4, 5, 6, 7})
+				return m.V[0] == v0 && m.V[1] == v1
+			},
+		},
+		{
+			asm:  "st3 { v0.b, v1.b, v2.b }[7], [x0], #3",
+			inst: &LoadStoreSinglePostIndexed{Rm: 31, Opcode: 0b001, S: 1, Size: 0b11},
+			setup: func(m *machine.Machine) {
+				for i := 0; i < 3; i++ {
+					m.V[i] = bytesFrom(byte(16*i), 1, 16)
+				}
+			},
+			check: func(m *machine.Machine) bool {
+				return m.Memory[0x80] == 7 && m.Memory[0x81] == 23 && m.Memory[0x82] == 39 && m.Memory[0x83] == 3 && m.R[0] == 0x83
+			},
+		},
+		{
+			asm:  "ld1r { v0.4s }, [x0]",
+			inst: &LoadStoreSingle{Q: 1, L: 1, Opcode: 0b110, Size: 0b10},
+			check: func(m *machine.Machine) bool {
+				return m.V[0] == machine.VectorRegister{0, 1, 2, 3, 0, 1, 2, 3, 0, 1, 2, 3, 0, 1, 2, 3}
+			},
+		},
+		{
+			asm:  "ld2r { v0.8b, v1.8b }, [x0], #2",
+			inst: &LoadStoreSinglePostIndexed{L: 1, R: 1, Rm: 31, Opcode: 0b110},
+			check: func(m *machine.Machine) bool {
+				return m.V[0] == bytesFrom(0, 0, 8) && m.V[1] == bytesFrom(1, 0, 8) && m.R[0] == 0x82
+			},
+		},
+		{
+			asm:   "ld1 { v0.16b, v1.16b }, [x0], #32",
+			inst:  &LoadStoreMultiplePostIndexed{Q: 1, L: 1, Rm: 31, Opcode: 0b1010},
+			setup: func(m *machine.Machine) { m.R[0] = 0xff8 },
+			check: func(m *machine.Machine) bool {
+				return m.V[0] == fill(0xff) && m.V[1] == fill(0xff) && m.R[0] == 0xff8 && m.PC == pc
+			},
+			reason: machine.StopFault,
+		},
+		{
+			asm:    "st2 { v0.b, v1.b }[0], [x0], #2",
+			inst:   &LoadStoreSinglePostIndexed{R: 1, Rm: 31},
+			setup:  func(m *machine.Machine) { m.R[0] = 0xfff },
+			check:  func(m *machine.Machine) bool { return m.R[0] == 0xfff && m.PC == pc },
+			reason: machine.StopFault,
+		},
+	} {
+		m := machine.New(0x1000)
+		m.Decode = Decoder
+		m.PC = pc
+		m.R[0] = 0x80
+		for i := 0; i < 64; i++ {
+			m.Memory[0x80+i] = byte(i)
+		}
+		m.Memory[0xc0] = 0xc0
+		m.Memory[0x108] = 0x88
+		for i := range m.V {
+			m.V[i] = fill(0xff)
+		}
+		if tc.setup != nil {
+			tc.setup(m)
+		}
+		m.Write(pc, 4, uint64(tc.inst.Encode()))
+		if reason, err := m.Step(); reason != tc.reason {
+			t.Errorf("%s: Step returned %v, %v, want %v", tc.asm, reason, err, tc.reason)
+			continue
+		}
+		if !tc.check(m) {
+			t.Errorf("%s: unexpected machine state after Step", tc.asm)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package opcode
+
+import (
+	"github.com/runningwild/javelin/machine"
+)
+
+// AND (vector)
+type AndVector struct {
+	Q  uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *AndVector) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, 0b00, 0b00011, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *AndVector) Execute(m *machine.Machine) {
+	executeVectorBinary(m, op.Q, 64, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return x & y })
+}
+
+func (op *AndVector) String() string {
+	return "and " + vectorBinaryOperands(op.Q, 0b00, op.Rm, op.Rn, op.Rd)
+}
+
+// BIC (vector, register)
+type BicVector struct {
+	Q  uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *BicVector) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, 0b01, 0b00011, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *BicVector) Execute(m *machine.Machine) {
+	executeVectorBinary(m, op.Q, 64, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return x &^ y })
+}
+
+func (op *BicVector) String() string {
+	return "bic " + vectorBinaryOperands(op.Q, 0b00, op.Rm, op.Rn, op.Rd)
+}
+
+// ORR (vector, register)
+type OrrVector struct {
+	Q  uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *OrrVector) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, 0b10, 0b00011, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *OrrVector) Execute(m *machine.Machine) {
+	executeVectorBinary(m, op.Q, 64, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return x | y })
+}
+
+// String uses the MOV alias when Rn and Rm are the same register.
+func (op *OrrVector) String() string {
+	if op.Rn&0b11111 == op.Rm&0b11111 {
+		return "mov " + vectorUnaryOperands(op.Q, 0b00, op.Rn, op.Rd)
+	}
+	return "orr " + vectorBinaryOperands(op.Q, 0b00, op.Rm, op.Rn, op.Rd)
+}
+
+// ORN (vector)
+type OrnVector struct {
+	Q  uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *OrnVector) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 0, 0b11, 0b00011, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *OrnVector) Execute(m *machine.Machine) {
+	executeVectorBinary(m, op.Q, 64, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return x | ^y })
+}
+
+func (op *OrnVector) String() string {
+	return "orn " + vectorBinaryOperands(op.Q, 0b00, op.Rm, op.Rn, op.Rd)
+}
+
+// EOR (vector)
+type EorVector struct {
+	Q  uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *EorVector) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, 0b00, 0b00011, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *EorVector) Execute(m *machine.Machine) {
+	executeVectorBinary(m, op.Q, 64, op.Rn, op.Rm, op.Rd, func(x, y uint64) uint64 { return x ^ y })
+}
+
+func (op *EorVector) String() string {
+	return "eor " + vectorBinaryOperands(op.Q, 0b00, op.Rm, op.Rn, op.Rd)
+}
+
+// BSL
+type Bsl struct {
+	Q  uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Bsl) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, 0b01, 0b00011, op.Rm, op.Rn, op.Rd)
+}
+
+// Execute selects each bit of Vn where the same bit of Vd is set, and of Vm where it is clear.
+func (op *Bsl) Execute(m *machine.Machine) {
+	executeVectorAccumulate(m, op.Q, 64, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return acc&x | ^acc&y })
+}
+
+func (op *Bsl) String() string {
+	return "bsl " + vectorBinaryOperands(op.Q, 0b00, op.Rm, op.Rn, op.Rd)
+}
+
+// BIT
+type Bit struct {
+	Q  uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Bit) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, 0b10, 0b00011, op.Rm, op.Rn, op.Rd)
+}
+
+// Execute selects each bit of Vn where the same bit of Vm is set, keeping the bits of Vd where it is clear.
+func (op *Bit) Execute(m *machine.Machine) {
+	executeVectorAccumulate(m, op.Q, 64, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return x&y | acc&^y })
+}
+
+func (op *Bit) String() string {
+	return "bit " + vectorBinaryOperands(op.Q, 0b00, op.Rm, op.Rn, op.Rd)
+}
+
+// BIF
+type Bif struct {
+	Q  uint32 // 1 bit
+	Rm uint32 // 5 bits
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Bif) Encode() uint32 {
+	return encodeSIMDThreeSame(op.Q, 1, 0b11, 0b00011, op.Rm, op.Rn, op.Rd)
+}
+
+// Execute selects each bit of Vn where the same bit of Vm is clear, keeping the bits of Vd where it is set.
+func (op *Bif) Execute(m *machine.Machine) {
+	executeVectorAccumulate(m, op.Q, 64, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return acc&y | x&^y })
+}
+
+func (op *Bif) String() string {
+	return "bif " + vectorBinaryOperands(op.Q, 0b00, op.Rm, op.Rn, op.Rd)
+}
+
+// NOT
+type Not struct {
+	Q  uint32 // 1 bit
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Not) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 1, 0b00, 0b00101, op.Rn, op.Rd)
+}
+
+func (op *Not) Execute(m *machine.Machine) {
+	executeVectorUnary(m, op.Q, 64, op.Rn, op.Rd, func(x uint64) uint64 { return ^x })
+}
+
+// String uses the MVN alias, as the disassembler does.
+func (op *Not) String() string {
+	return "mvn " + vectorUnaryOperands(op.Q, 0b00, op.Rn, op.Rd)
+}
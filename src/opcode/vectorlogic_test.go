@@ -0,0 +1,43 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestVectorLogicExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm  string
+		inst Instruction
+		want machine.VectorRegister
+	}{
+		{"and v0.16b, v1.16b, v2.16b", &AndVector{Q: 1, Rm: 2, Rn: 1}, vec(0x0303030303030303, 0x5050505050505050)},
+		{"bic v0.8b, v1.8b, v2.8b", &BicVector{Rm: 2, Rn: 1}, vec(0x0c0c0c0c0c0c0c0c, 0)},
+		{"orr v0.16b, v1.16b, v2.16b", &OrrVector{Q: 1, Rm: 2, Rn: 1}, vec(0x3f3f3f3f3f3f3f3f, 0xf5f5f5f5f5f5f5f5)},
+		{"mov v0.16b, v1.16b", &OrrVector{Q: 1, Rm: 1, Rn: 1}, vec(0x0f0f0f0f0f0f0f0f, 0xf0f0f0f0f0f0f0f0)},
+		{"orn v0.16b, v1.16b, v2.16b", &OrnVector{Q: 1, Rm: 2, Rn: 1}, vec(0xcfcfcfcfcfcfcfcf, 0xfafafafafafafafa)},
+		{"eor v0.16b, v1.16b, v2.16b", &EorVector{Q: 1, Rm: 2, Rn: 1}, vec(0x3c3c3c3c3c3c3c3c, 0xa5a5a5a5a5a5a5a5)},
+		{"bsl v0.16b, v1.16b, v2.16b", &Bsl{Q: 1, Rm: 2, Rn: 1}, vec(0x0f330f330f330f33, 0x55f055f055f055f0)},
+		{"bsl v0.8b, v1.8b, v2.8b", &Bsl{Rm: 2, Rn: 1}, vec(0x0f330f330f330f33, 0)},
+		{"bit v0.16b, v1.16b, v2.16b", &Bit{Q: 1, Rm: 2, Rn: 1}, vec(0xcf03cf03cf03cf03, 0x50fa50fa50fa50fa)},
+		{"bif v0.16b, v1.16b, v2.16b", &Bif{Q: 1, Rm: 2, Rn: 1}, vec(0x3f0c3f0c3f0c3f0c, 0xa0f5a0f5a0f5a0f5)},
+		{"mvn v0.16b, v1.16b", &Not{Q: 1, Rn: 1}, vec(0xf0f0f0f0f0f0f0f0, 0x0f0f0f0f0f0f0f0f)},
+		{"mvn v0.8b, v1.8b", &Not{Rn: 1}, vec(0xf0f0f0f0f0f0f0f0, 0)},
+	} {
+		m := load(tc.inst)
+		m.V[0] = vec(0xff00ff00ff00ff00, 0x00ff00ff00ff00ff)
+		m.V[1] = vec(0x0f0f0f0f0f0f0f0f, 0xf0f0f0f0f0f0f0f0)
+		m.V[2] = vec(0x3333333333333333, 0x5555555555555555)
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.V[0] != tc.want {
+			t.Errorf("%s: v0 = %x, want %x", tc.asm, m.V[0], tc.want)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
@@ -0,0 +1,715 @@
+package opcode
+
+import (
+	"fmt"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// encodeSIMDThreeDifferent encodes an instruction from the Advanced SIMD three different group.
+func encodeSIMDThreeDifferent(q, u, size, opcode, rm, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{0, 1},
+		{q, 1},
+		{u, 1},
+		{0b01110, 5},
+		{size, 2},
+		{1, 1},
+		{rm, 5},
+		{opcode, 4},
+		{0b00, 2},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// extendElement returns the esize-bit element x sign or zero extended to 64 bits.
+func extendElement(x uint64, esize int, signed bool) uint64 {
+	if signed {
+		return uint64(signExtend(x, esize))
+	}
+	return x
+}
+
+// executeVectorLong sets each 2*esize-bit element of Vd to f applied to the same element of Vd and
+// the elements of Vn and Vm with the same index, extended to 64 bits.  The esize-bit source
+// elements come from the lower half of their registers if q is 0 and from the upper half if q is
+// 1.  If wide is set Vn holds 2*esize-bit elements like Vd.
+func executeVectorLong(m *machine.Machine, q uint32, esize int, signed, wide bool, rn, rm, rd uint32, f func(acc, x, y uint64) uint64) {
+	lanes := 64 / esize
+	part := int(q&1) * lanes
+	vn, vm, vd := m.V[rn&0b11111], m.V[rm&0b11111], m.V[rd&0b11111]
+	var result machine.VectorRegister
+	for i := 0; i < lanes; i++ {
+		x := extendElement(vn.Get(part+i, esize), esize, signed)
+		if wide {
+			x = extendElement(vn.Get(i, 2*esize), 2*esize, signed)
+		}
+		y := extendElement(vm.Get(part+i, esize), esize, signed)
+		result.Set(i, 2*esize, f(vd.Get(i, 2*esize), x, y))
+	}
+	m.V[rd&0b11111] = result
+}
+
+// executeVectorHighNarrow sets each esize-bit element of one half of Vd, as executeVectorNarrow
+// does, to the most significant half of the sum of the 2*esize-bit elements of Vn and Vm, or of
+// their difference if sub is set.  If round is set the result is rounded rather than truncated.
+func executeVectorHighNarrow(m *machine.Machine, q uint32, esize int, sub, round bool, rn, rm, rd uint32) {
+	vn, vm := m.V[rn&0b11111], m.V[rm&0b11111]
+	var r uint64
+	if round {
+		r = 1 << (esize - 1)
+	}
+	executeVectorNarrow(m, q, esize, rd, func(i int) (uint64, bool) {
+		x, y := vn.Get(i, 2*esize), vm.Get(i, 2*esize)
+		if sub {
+			y = -y
+		}
+		return (x + y + r) >> esize, false
+	})
+}
+
+// executeVectorPairwiseLong sets each 2*esize-bit element of Vd to the sum of a pair of adjacent
+// esize-bit elements of Vn, extended to 2*esize bits, and adds it to the element of Vd if
+// accumulate is set.
+func executeVectorPairwiseLong(m *machine.Machine, q uint32, esize int, signed, accumulate bool, rn, rd uint32) {
+	vn, vd := m.V[rn&0b11111], m.V[rd&0b11111]
+	var result machine.VectorRegister
+	for i := 0; i < vectorBits(q)/(2*esize); i++ {
+		sum := extendElement(vn.Get(2*i, esize), esize, signed) + extendElement(vn.Get(2*i+1, esize), esize, signed)
+		if accumulate {
+			sum += vd.Get(i, 2*esize)
+		}
+		result.Set(i, 2*esize, sum)
+	}
+	m.V[rd&0b11111] = result
+}
+
+// longOperands formats the operands of a three different instruction with a wide destination and
+// narrow sources.
+func longOperands(q, size, rm, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s, %s", vecName(rd, 1, size+1), vecName(rn, q, size), vecName(rm, q, size))
+}
+
+// wideOperands formats the operands of a three different instruction with a wide destination and
+// first source, and a narrow second source.
+func wideOperands(q, size, rm, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s, %s", vecName(rd, 1, size+1), vecName(rn, 1, size+1), vecName(rm, q, size))
+}
+
+// highNarrowOperands formats the operands of a three different instruction with a narrow
+// destination and wide sources.
+func highNarrowOperands(q, size, rm, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s, %s", vecName(rd, q, size), vecName(rn, 1, size+1), vecName(rm, 1, size+1))
+}
+
+// SADDL
+type Saddl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Saddl) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 0, op.Size, 0b0000, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Saddl) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), true, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return x + y })
+}
+
+func (op *Saddl) String() string {
+	return partMnemonic("saddl", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UADDL
+type Uaddl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uaddl) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 1, op.Size, 0b0000, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Uaddl) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), false, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return x + y })
+}
+
+func (op *Uaddl) String() string {
+	return partMnemonic("uaddl", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SADDW
+type Saddw struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Saddw) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 0, op.Size, 0b0001, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Saddw) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), true, true, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return x + y })
+}
+
+func (op *Saddw) String() string {
+	return partMnemonic("saddw", op.Q) + " " + wideOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UADDW
+type Uaddw struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uaddw) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 1, op.Size, 0b0001, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Uaddw) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), false, true, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return x + y })
+}
+
+func (op *Uaddw) String() string {
+	return partMnemonic("uaddw", op.Q) + " " + wideOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SSUBL
+type Ssubl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Ssubl) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 0, op.Size, 0b0010, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Ssubl) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), true, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return x - y })
+}
+
+func (op *Ssubl) String() string {
+	return partMnemonic("ssubl", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// USUBL
+type Usubl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Usubl) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 1, op.Size, 0b0010, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Usubl) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), false, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return x - y })
+}
+
+func (op *Usubl) String() string {
+	return partMnemonic("usubl", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SSUBW
+type Ssubw struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Ssubw) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 0, op.Size, 0b0011, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Ssubw) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), true, true, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return x - y })
+}
+
+func (op *Ssubw) String() string {
+	return partMnemonic("ssubw", op.Q) + " " + wideOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// USUBW
+type Usubw struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Usubw) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 1, op.Size, 0b0011, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Usubw) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), false, true, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return x - y })
+}
+
+func (op *Usubw) String() string {
+	return partMnemonic("usubw", op.Q) + " " + wideOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// ADDHN
+type Addhn struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Addhn) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 0, op.Size, 0b0100, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Addhn) Execute(m *machine.Machine) {
+	executeVectorHighNarrow(m, op.Q, elementBits(op.Size), false, false, op.Rn, op.Rm, op.Rd)
+}
+
+func (op *Addhn) String() string {
+	return partMnemonic("addhn", op.Q) + " " + highNarrowOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// RADDHN
+type Raddhn struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Raddhn) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 1, op.Size, 0b0100, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Raddhn) Execute(m *machine.Machine) {
+	executeVectorHighNarrow(m, op.Q, elementBits(op.Size), false, true, op.Rn, op.Rm, op.Rd)
+}
+
+func (op *Raddhn) String() string {
+	return partMnemonic("raddhn", op.Q) + " " + highNarrowOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SABAL
+type Sabal struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sabal) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 0, op.Size, 0b0101, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sabal) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), true, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return acc + absoluteDifference(x, y, 64, true) })
+}
+
+func (op *Sabal) String() string {
+	return partMnemonic("sabal", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UABAL
+type Uabal struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uabal) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 1, op.Size, 0b0101, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Uabal) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), false, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return acc + absoluteDifference(x, y, 64, false) })
+}
+
+func (op *Uabal) String() string {
+	return partMnemonic("uabal", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SUBHN
+type Subhn struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Subhn) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 0, op.Size, 0b0110, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Subhn) Execute(m *machine.Machine) {
+	executeVectorHighNarrow(m, op.Q, elementBits(op.Size), true, false, op.Rn, op.Rm, op.Rd)
+}
+
+func (op *Subhn) String() string {
+	return partMnemonic("subhn", op.Q) + " " + highNarrowOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// RSUBHN
+type Rsubhn struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Rsubhn) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 1, op.Size, 0b0110, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Rsubhn) Execute(m *machine.Machine) {
+	executeVectorHighNarrow(m, op.Q, elementBits(op.Size), true, true, op.Rn, op.Rm, op.Rd)
+}
+
+func (op *Rsubhn) String() string {
+	return partMnemonic("rsubhn", op.Q) + " " + highNarrowOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SABDL
+type Sabdl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sabdl) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 0, op.Size, 0b0111, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Sabdl) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), true, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return absoluteDifference(x, y, 64, true) })
+}
+
+func (op *Sabdl) String() string {
+	return partMnemonic("sabdl", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UABDL
+type Uabdl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uabdl) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 1, op.Size, 0b0111, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Uabdl) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), false, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return absoluteDifference(x, y, 64, false) })
+}
+
+func (op *Uabdl) String() string {
+	return partMnemonic("uabdl", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SMLAL (vector)
+type Smlal struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Smlal) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 0, op.Size, 0b1000, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Smlal) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), true, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return acc + x*y })
+}
+
+func (op *Smlal) String() string {
+	return partMnemonic("smlal", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UMLAL (vector)
+type Umlal struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Umlal) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 1, op.Size, 0b1000, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Umlal) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), false, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return acc + x*y })
+}
+
+func (op *Umlal) String() string {
+	return partMnemonic("umlal", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SMLSL (vector)
+type Smlsl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Smlsl) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 0, op.Size, 0b1010, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Smlsl) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), true, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return acc - x*y })
+}
+
+func (op *Smlsl) String() string {
+	return partMnemonic("smlsl", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UMLSL (vector)
+type Umlsl struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Umlsl) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 1, op.Size, 0b1010, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *Umlsl) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), false, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return acc - x*y })
+}
+
+func (op *Umlsl) String() string {
+	return partMnemonic("umlsl", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// SMULL (vector)
+type SmullVector struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *SmullVector) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 0, op.Size, 0b1100, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *SmullVector) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), true, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return x * y })
+}
+
+func (op *SmullVector) String() string {
+	return partMnemonic("smull", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// UMULL (vector)
+type UmullVector struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rm   uint32 // 5 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *UmullVector) Encode() uint32 {
+	return encodeSIMDThreeDifferent(op.Q, 1, op.Size, 0b1100, op.Rm, op.Rn, op.Rd)
+}
+
+func (op *UmullVector) Execute(m *machine.Machine) {
+	executeVectorLong(m, op.Q, elementBits(op.Size), false, false, op.Rn, op.Rm, op.Rd, func(acc, x, y uint64) uint64 { return x * y })
+}
+
+func (op *UmullVector) String() string {
+	return partMnemonic("umull", op.Q) + " " + longOperands(op.Q, op.Size, op.Rm, op.Rn, op.Rd)
+}
+
+// XTN
+type Xtn struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Xtn) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 0, op.Size, 0b10010, op.Rn, op.Rd)
+}
+
+func (op *Xtn) Execute(m *machine.Machine) {
+	esize, n := elementBits(op.Size), m.V[op.Rn&0b11111]
+	executeVectorNarrow(m, op.Q, esize, op.Rd, func(i int) (uint64, bool) { return n.Get(i, 2*esize), false })
+}
+
+func (op *Xtn) String() string {
+	return partMnemonic("xtn", op.Q) + " " + narrowOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
+
+// SQXTUN
+type Sqxtun struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sqxtun) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 1, op.Size, 0b10010, op.Rn, op.Rd)
+}
+
+func (op *Sqxtun) Execute(m *machine.Machine) {
+	esize, n := elementBits(op.Size), m.V[op.Rn&0b11111]
+	executeVectorNarrow(m, op.Q, esize, op.Rd, func(i int) (uint64, bool) {
+		x := signExtend(n.Get(i, 2*esize), 2*esize)
+		if x < 0 {
+			return 0, true
+		}
+		return unsignedSaturate(uint64(x), esize)
+	})
+}
+
+func (op *Sqxtun) String() string {
+	return partMnemonic("sqxtun", op.Q) + " " + narrowOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
+
+// SHLL
+type Shll struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Shll) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 1, op.Size, 0b10011, op.Rn, op.Rd)
+}
+
+func (op *Shll) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorLong(m, op.Q, esize, false, false, op.Rn, op.Rn, op.Rd, func(acc, x, y uint64) uint64 { return x << esize })
+}
+
+func (op *Shll) String() string {
+	return fmt.Sprintf("%s %s, %s, #%d", partMnemonic("shll", op.Q), vecName(op.Rd, 1, op.Size+1), vecName(op.Rn, op.Q, op.Size), elementBits(op.Size))
+}
+
+// SADDLP
+type Saddlp struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Saddlp) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 0, op.Size, 0b00010, op.Rn, op.Rd)
+}
+
+func (op *Saddlp) Execute(m *machine.Machine) {
+	executeVectorPairwiseLong(m, op.Q, elementBits(op.Size), true, false, op.Rn, op.Rd)
+}
+
+func (op *Saddlp) String() string {
+	return fmt.Sprintf("saddlp %s, %s", vecName(op.Rd, op.Q, op.Size+1), vecName(op.Rn, op.Q, op.Size))
+}
+
+// UADDLP
+type Uaddlp struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uaddlp) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 1, op.Size, 0b00010, op.Rn, op.Rd)
+}
+
+func (op *Uaddlp) Execute(m *machine.Machine) {
+	executeVectorPairwiseLong(m, op.Q, elementBits(op.Size), false, false, op.Rn, op.Rd)
+}
+
+func (op *Uaddlp) String() string {
+	return fmt.Sprintf("uaddlp %s, %s", vecName(op.Rd, op.Q, op.Size+1), vecName(op.Rn, op.Q, op.Size))
+}
+
+// SADALP
+type Sadalp struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Sadalp) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 0, op.Size, 0b00110, op.Rn, op.Rd)
+}
+
+func (op *Sadalp) Execute(m *machine.Machine) {
+	executeVectorPairwiseLong(m, op.Q, elementBits(op.Size), true, true, op.Rn, op.Rd)
+}
+
+func (op *Sadalp) String() string {
+	return fmt.Sprintf("sadalp %s, %s", vecName(op.Rd, op.Q, op.Size+1), vecName(op.Rn, op.Q, op.Size))
+}
+
+// UADALP
+type Uadalp struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Uadalp) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 1, op.Size, 0b00110, op.Rn, op.Rd)
+}
+
+func (op *Uadalp) Execute(m *machine.Machine) {
+	executeVectorPairwiseLong(m, op.Q, elementBits(op.Size), false, true, op.Rn, op.Rd)
+}
+
+func (op *Uadalp) String() string {
+	return fmt.Sprintf("uadalp %s, %s", vecName(op.Rd, op.Q, op.Size+1), vecName(op.Rn, op.Q, op.Size))
+}
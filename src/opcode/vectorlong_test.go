@@ -0,0 +1,66 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+func TestVectorLongExecute(t *testing.T) {
+	ones := vec(^uint64(0), ^uint64(0))
+	for _, tc := range []struct {
+		asm    string
+		inst   Instruction
+		v1, v2 machine.VectorRegister
+		want   machine.VectorRegister
+		qc     bool
+	}{
+		{"saddl v0.8h, v1.8b, v2.8b", &Saddl{Rm: 2, Rn: 1}, vec(0x01807fff, 0xaaaa), vec(0x028001ff, 0xbbbb), vec(0x0003_ff00_0080_fffe, 0), false},
+		{"uaddl2 v0.4s, v1.8h, v2.8h", &Uaddl{Q: 1, Size: 0b01, Rm: 2, Rn: 1}, vec(5, 0x0001_ffff), vec(7, 0x0002_0001), vec(0x00000003_00010000, 0), false},
+		{"saddw v0.2d, v1.2d, v2.2s", &Saddw{Size: 0b10, Rm: 2, Rn: 1}, vec(10, 20), vec(0x00000005_ffffffff, 99), vec(9, 25), false},
+		{"uaddw2 v0.8h, v1.8h, v2.16b", &Uaddw{Q: 1, Rm: 2, Rn: 1}, vec(0x0001_0001_0001_00ff, 0), vec(0, 0xff01), vec(0x0001_0001_0100_0100, 0), false},
+		{"ssubl v0.4s, v1.4h, v2.4h", &Ssubl{Size: 0b01, Rm: 2, Rn: 1}, vec(0x8000_0001, 0), vec(0x0001_0002, 0), vec(0xffff7fff_ffffffff, 0), false},
+		{"usubl2 v0.2d, v1.4s, v2.4s", &Usubl{Q: 1, Size: 0b10, Rm: 2, Rn: 1}, vec(0, 0x00000005_00000001), vec(0, 0x00000003_00000002), vec(^uint64(0), 2), false},
+		{"addhn v0.8b, v1.8h, v2.8h", &Addhn{Rm: 2, Rn: 1}, vec(0x7f80_12ff, 0), vec(0x0080_0001, 0), vec(0x8013, 0), false},
+		{"raddhn2 v0.16b, v1.8h, v2.8h", &Raddhn{Q: 1, Rm: 2, Rn: 1}, vec(0x0100_1280, 0), vec(0x007f_0000, 0), vec(^uint64(0), 0x0113), false},
+		{"subhn v0.2s, v1.2d, v2.2d", &Subhn{Size: 0b10, Rm: 2, Rn: 1}, vec(0x00000005_00000000, 0), vec(1, 1), vec(0xffffffff_00000004, 0), false},
+		{"rsubhn v0.4h, v1.4s, v2.4s", &Rsubhn{Size: 0b01, Rm: 2, Rn: 1}, vec(0x00038000, 0), vec(0x00010000, 0), vec(0x0003, 0), false},
+		{"sabal v0.8h, v1.8b, v2.8b", &Sabal{Rm: 2, Rn: 1}, vec(0x7f80, 0), vec(0x807f, 0), vec(0xffff_ffff_00fe_00fe, ^uint64(0)), false},
+		{"uabal2 v0.4s, v1.8h, v2.8h", &Uabal{Q: 1, Size: 0b01, Rm: 2, Rn: 1}, vec(0, 0xffff_0003), vec(0, 0x0000_0005), vec(0x0000fffe_00000001, ^uint64(0)), false},
+		{"sabdl v0.2d, v1.2s, v2.2s", &Sabdl{Size: 0b10, Rm: 2, Rn: 1}, vec(0x00000001_80000000, 0), vec(0xffffffff_7fffffff, 0), vec(0xffffffff, 2), false},
+		{"uabdl2 v0.8h, v1.16b, v2.16b", &Uabdl{Q: 1, Rm: 2, Rn: 1}, vec(0, 0xff00), vec(0, 0x00ff), vec(0x00ff_00ff, 0), false},
+		{"smlal v0.4s, v1.4h, v2.4h", &Smlal{Size: 0b01, Rm: 2, Rn: 1}, vec(0x0003_8000, 0), vec(0xfffe_8000, 0), vec(0xfffffff9_3fffffff, ^uint64(0)), false},
+		{"umlal2 v0.2d, v1.4s, v2.4s", &Umlal{Q: 1, Size: 0b10, Rm: 2, Rn: 1}, vec(0, 0x00000002_ffffffff), vec(0, 0x00000003_ffffffff), vec(0xfffffffe00000000, 5), false},
+		{"smlsl2 v0.8h, v1.16b, v2.16b", &Smlsl{Q: 1, Rm: 2, Rn: 1}, vec(0, 0x0280), vec(0, 0xff80), vec(0xffff_ffff_0001_bfff, ^uint64(0)), false},
+		{"umlsl v0.4s, v1.4h, v2.4h", &Umlsl{Size: 0b01, Rm: 2, Rn: 1}, vec(0xffff, 0), vec(0xffff, 0), vec(0xffffffff_0001fffe, ^uint64(0)), false},
+		{"smull v0.8h, v1.8b, v2.8b", &SmullVector{Rm: 2, Rn: 1}, vec(0xff80, 0), vec(0x0280, 0), vec(0xfffe_4000, 0), false},
+		{"umull2 v0.4s, v1.8h, v2.8h", &UmullVector{Q: 1, Size: 0b01, Rm: 2, Rn: 1}, vec(0, 0xffff), vec(0, 0xffff), vec(0xfffe0001, 0), false},
+		{"xtn v0.8b, v1.8h", &Xtn{Rn: 1}, vec(0xff80_1234, 0xabcd), machine.VectorRegister{}, vec(0x000000cd_00008034, 0), false},
+		{"xtn2 v0.4s, v1.2d", &Xtn{Q: 1, Size: 0b10, Rn: 1}, vec(0x1_00000002, 0xffffffff_00000003), machine.VectorRegister{}, vec(^uint64(0), 0x00000003_00000002), false},
+		{"sqxtun v0.4h, v1.4s", &Sqxtun{Size: 0b01, Rn: 1}, vec(0x00012345_80000000, 0x0000ffff_00007fff), machine.VectorRegister{}, vec(0xffff_7fff_ffff_0000, 0), true},
+		{"sqxtun2 v0.16b, v1.8h", &Sqxtun{Q: 1, Rn: 1}, vec(0x0000_00ff_0080_0001, 0), machine.VectorRegister{}, vec(^uint64(0), 0x00ff8001), false},
+		{"shll v0.8h, v1.8b, #8", &Shll{Rn: 1}, vec(0x0180, 0), machine.VectorRegister{}, vec(0x0100_8000, 0), false},
+		{"shll2 v0.2d, v1.4s, #32", &Shll{Q: 1, Size: 0b10, Rn: 1}, vec(0, 0x00000001_ffffffff), machine.VectorRegister{}, vec(0xffffffff00000000, 0x100000000), false},
+		{"saddlp v0.4h, v1.8b", &Saddlp{Rn: 1}, vec(0x80800201_7f7fffff, 99), machine.VectorRegister{}, vec(0xff00_0003_00fe_fffe, 0), false},
+		{"uaddlp v0.2d, v1.4s", &Uaddlp{Q: 1, Size: 0b10, Rn: 1}, vec(^uint64(0), 0x00000002_00000001), machine.VectorRegister{}, vec(0x1fffffffe, 3), false},
+		{"sadalp v0.1d, v1.2s", &Sadalp{Size: 0b10, Rn: 1}, vec(^uint64(0), 0), machine.VectorRegister{}, vec(^uint64(2), 0), false},
+		{"uadalp v0.8h, v1.16b", &Uadalp{Q: 1, Rn: 1}, vec(0x0101010101010101, 0x0101010101010101), machine.VectorRegister{}, vec(0x0001000100010001, 0x0001000100010001), false},
+	} {
+		m := load(tc.inst)
+		m.V[0] = ones
+		m.V[1], m.V[2] = tc.v1, tc.v2
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.V[0] != tc.want {
+			t.Errorf("%s with v1 = %x, v2 = %x: v0 = %x, want %x", tc.asm, tc.v1, tc.v2, m.V[0], tc.want)
+		}
+		if qc := m.FPSR&machine.FPSRQC != 0; qc != tc.qc {
+			t.Errorf("%s with v1 = %x: QC = %t, want %t", tc.asm, tc.v1, qc, tc.qc)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package opcode
+
+import (
+	"fmt"
+	gobits "math/bits"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// encodeSIMDTwoRegisterMisc encodes an instruction from the Advanced SIMD two-register
+// miscellaneous group.
+func encodeSIMDTwoRegisterMisc(q, u, size, opcode, rn, rd uint32) uint32 {
+	return buildUint32([]bits{
+		{0, 1},
+		{q, 1},
+		{u, 1},
+		{0b01110, 5},
+		{size, 2},
+		{0b10000, 5},
+		{opcode, 5},
+		{0b10, 2},
+		{rn, 5},
+		{rd, 5},
+	}...)
+}
+
+// executeVectorReverse reverses the order of the esize-bit elements within each container-bit
+// chunk of Vn, and writes the result to Vd.
+func executeVectorReverse(m *machine.Machine, q uint32, esize, container int, rn, rd uint32) {
+	var result machine.VectorRegister
+	for i := 0; i < vectorBits(q)/esize; i++ {
+		result.Set(i^(container/esize-1), esize, m.V[rn&0b11111].Get(i, esize))
+	}
+	m.V[rd&0b11111] = result
+}
+
+// vectorUnaryOperands formats the operands of a vector instruction with one source register.
+func vectorUnaryOperands(q, size, rn, rd uint32) string {
+	return fmt.Sprintf("%s, %s", vecName(rd, q, size), vecName(rn, q, size))
+}
+
+// CNT
+type Cnt struct {
+	Q  uint32 // 1 bit
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Cnt) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 0, 0b00, 0b00101, op.Rn, op.Rd)
+}
+
+func (op *Cnt) Execute(m *machine.Machine) {
+	executeVectorUnary(m, op.Q, 8, op.Rn, op.Rd, func(x uint64) uint64 { return uint64(gobits.OnesCount8(uint8(x))) })
+}
+
+func (op *Cnt) String() string {
+	return "cnt " + vectorUnaryOperands(op.Q, 0b00, op.Rn, op.Rd)
+}
+
+// RBIT (vector)
+type RbitVector struct {
+	Q  uint32 // 1 bit
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *RbitVector) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 1, 0b01, 0b00101, op.Rn, op.Rd)
+}
+
+func (op *RbitVector) Execute(m *machine.Machine) {
+	executeVectorUnary(m, op.Q, 8, op.Rn, op.Rd, func(x uint64) uint64 { return reverseBits(x, 8) })
+}
+
+func (op *RbitVector) String() string {
+	return "rbit " + vectorUnaryOperands(op.Q, 0b00, op.Rn, op.Rd)
+}
+
+// REV16 (vector)
+type Rev16Vector struct {
+	Q  uint32 // 1 bit
+	Rn uint32 // 5 bits
+	Rd uint32 // 5 bits
+}
+
+func (op *Rev16Vector) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 0, 0b00, 0b00001, op.Rn, op.Rd)
+}
+
+func (op *Rev16Vector) Execute(m *machine.Machine) {
+	executeVectorReverse(m, op.Q, 8, 16, op.Rn, op.Rd)
+}
+
+func (op *Rev16Vector) String() string {
+	return "rev16 " + vectorUnaryOperands(op.Q, 0b00, op.Rn, op.Rd)
+}
+
+// REV32 (vector)
+type Rev32Vector struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Rev32Vector) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 1, op.Size, 0b00000, op.Rn, op.Rd)
+}
+
+func (op *Rev32Vector) Execute(m *machine.Machine) {
+	executeVectorReverse(m, op.Q, elementBits(op.Size), 32, op.Rn, op.Rd)
+}
+
+func (op *Rev32Vector) String() string {
+	return "rev32 " + vectorUnaryOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
+
+// REV64
+type Rev64 struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *Rev64) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 0, op.Size, 0b00000, op.Rn, op.Rd)
+}
+
+func (op *Rev64) Execute(m *machine.Machine) {
+	executeVectorReverse(m, op.Q, elementBits(op.Size), 64, op.Rn, op.Rd)
+}
+
+func (op *Rev64) String() string {
+	return "rev64 " + vectorUnaryOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
+
+// CLZ (vector)
+type ClzVector struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *ClzVector) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 1, op.Size, 0b00100, op.Rn, op.Rd)
+}
+
+func (op *ClzVector) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorUnary(m, op.Q, esize, op.Rn, op.Rd, func(x uint64) uint64 { return countLeadingZeros(x, esize) })
+}
+
+func (op *ClzVector) String() string {
+	return "clz " + vectorUnaryOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
+
+// CLS (vector)
+type ClsVector struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *ClsVector) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 0, op.Size, 0b00100, op.Rn, op.Rd)
+}
+
+func (op *ClsVector) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorUnary(m, op.Q, esize, op.Rn, op.Rd, func(x uint64) uint64 { return countLeadingSignBits(x, esize) })
+}
+
+func (op *ClsVector) String() string {
+	return "cls " + vectorUnaryOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
+
+// ABS (vector)
+type AbsVector struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *AbsVector) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 0, op.Size, 0b01011, op.Rn, op.Rd)
+}
+
+func (op *AbsVector) Execute(m *machine.Machine) {
+	esize := elementBits(op.Size)
+	executeVectorUnary(m, op.Q, esize, op.Rn, op.Rd, func(x uint64) uint64 {
+		if signExtend(x, esize) < 0 {
+			return -x
+		}
+		return x
+	})
+}
+
+func (op *AbsVector) String() string {
+	return "abs " + vectorUnaryOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
+
+// NEG (vector)
+type NegVector struct {
+	Q    uint32 // 1 bit
+	Size uint32 // 2 bits
+	Rn   uint32 // 5 bits
+	Rd   uint32 // 5 bits
+}
+
+func (op *NegVector) Encode() uint32 {
+	return encodeSIMDTwoRegisterMisc(op.Q, 1, op.Size, 0b01011, op.Rn, op.Rd)
+}
+
+func (op *NegVector) Execute(m *machine.Machine) {
+	executeVectorUnary(m, op.Q, elementBits(op.Size), op.Rn, op.Rd, func(x uint64) uint64 { return -x })
+}
+
+func (op *NegVector) String() string {
+	return "neg " + vectorUnaryOperands(op.Q, op.Size, op.Rn, op.Rd)
+}
@@ -0,0 +1,50 @@
+package opcode
+
+import (
+	"testing"
+
+	"github.com/runningwild/javelin/machine"
+)
+
+// vec returns a vector register whose lower and upper 64 bits are lo and hi.
+func vec(lo, hi uint64) machine.VectorRegister {
+	var v machine.VectorRegister
+	v.Set(0, 64, lo)
+	v.Set(1, 64, hi)
+	return v
+}
+
+func TestVectorMiscExecute(t *testing.T) {
+	for _, tc := range []struct {
+		asm  string
+		inst Instruction
+		v1   machine.VectorRegister
+		want machine.VectorRegister
+	}{
+		{"cnt v0.8b, v1.8b", &Cnt{Rn: 1}, vec(0x0102030407ff8000, ^uint64(0)), vec(0x0101020103080100, 0)},
+		{"cnt v0.16b, v1.16b", &Cnt{Q: 1, Rn: 1}, vec(0, ^uint64(0)), vec(0, 0x0808080808080808)},
+		{"rbit v0.16b, v1.16b", &RbitVector{Q: 1, Rn: 1}, vec(0x0102030407ff8000, 0xf), vec(0x8040c020e0ff0100, 0xf0)},
+		{"rev16 v0.16b, v1.16b", &Rev16Vector{Q: 1, Rn: 1}, vec(0x1122334455667788, 0x0011223344556677), vec(0x2211443366558877, 0x1100332255447766)},
+		{"rev32 v0.8h, v1.8h", &Rev32Vector{Q: 1, Size: 0b01, Rn: 1}, vec(0x1122334455667788, 0), vec(0x3344112277885566, 0)},
+		{"rev32 v0.8b, v1.8b", &Rev32Vector{Rn: 1}, vec(0x1122334455667788, 1), vec(0x4433221188776655, 0)},
+		{"rev64 v0.2s, v1.2s", &Rev64{Size: 0b10, Rn: 1}, vec(0x1122334455667788, 1), vec(0x5566778811223344, 0)},
+		{"rev64 v0.16b, v1.16b", &Rev64{Q: 1, Rn: 1}, vec(0x1122334455667788, 0x0102), vec(0x8877665544332211, 0x0201000000000000)},
+		{"clz v0.4s, v1.4s", &ClzVector{Q: 1, Size: 0b10, Rn: 1}, vec(0x0000000180000000, 0), vec(0x0000001f00000000, 0x0000002000000020)},
+		{"clz v0.8b, v1.8b", &ClzVector{Rn: 1}, vec(0x0102040810204080, 1), vec(0x0706050403020100, 0)},
+		{"cls v0.8h, v1.8h", &ClsVector{Q: 1, Size: 0b01, Rn: 1}, vec(0xffff000000018000, 0), vec(0x000f000f000e0000, 0x000f000f000f000f)},
+	} {
+		m := load(tc.inst)
+		m.V[0] = vec(^uint64(0), ^uint64(0))
+		m.V[1] = tc.v1
+		if reason, err := m.Step(); reason != machine.StopNone {
+			t.Errorf("%s: Step returned %v, %v", tc.asm, reason, err)
+			continue
+		}
+		if m.V[0] != tc.want {
+			t.Errorf("%s with v1 = %x: v0 = %x, want %x", tc.asm, tc.v1, m.V[0], tc.want)
+		}
+		if got := tc.inst.String(); got != tc.asm {
+			t.Errorf("%v.String() = %q, want %q", tc.inst, got, tc.asm)
+		}
+	}
+}